@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"log"
+	"os"
 	"os/signal"
 	"syscall"
 
@@ -28,6 +29,19 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if flag.Arg(0) == "migrate" {
+		if err := app.Migrate(context.Background(), cfg, flag.Arg(1), os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if flag.Arg(0) == "apikey" {
+		if err := app.APIKey(context.Background(), cfg, flag.Args()[1:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	defer cancel()
 
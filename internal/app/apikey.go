@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/utils/closer"
+)
+
+var (
+	ErrAPIKeyCommand = errors.New("unknown apikey command, expected create, revoke, list or hash")
+	ErrAPIKeyArgs    = errors.New("wrong apikey arguments: create <name> <role>, revoke <name>, hash <key>")
+	// ErrAPIKeyMemory ключи в памяти пропадут вместе с процессом утилиты.
+	ErrAPIKeyMemory = errors.New("memory storage can't keep api keys, use auth.keys in configuration")
+)
+
+// APIKey выполнение команды "brutefp apikey create|revoke|list|hash" над ключами из хранилища конфигурации.
+func APIKey(ctx context.Context, config config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return ErrAPIKeyCommand
+	}
+	command, args := args[0], args[1:]
+	switch command {
+	case "hash":
+		if len(args) != 1 {
+			return ErrAPIKeyArgs
+		}
+		// хэш для auth.keys, хранилище не нужно.
+		fmt.Fprintln(out, model.HashAPIKey(args[0]))
+		return nil
+	case "create":
+		if len(args) != 2 {
+			return ErrAPIKeyArgs
+		}
+	case "revoke":
+		if len(args) != 1 {
+			return ErrAPIKeyArgs
+		}
+	case "list":
+	default:
+		return fmt.Errorf("'%s': %w", command, ErrAPIKeyCommand)
+	}
+	if config.Storage.Type == deps.StoreTypeInMemory && command != "list" {
+		return ErrAPIKeyMemory
+	}
+
+	logs, err := newLogger(config)
+	if err != nil {
+		return err
+	}
+	closes := closer.NewCloser()
+	defer closes.Close(ctx, logs)
+
+	db, err := openStorage(ctx, config, logs, closes)
+	if err != nil {
+		return err
+	}
+	repos, err := deps.NewRepos(config.Storage, db)
+	if err != nil {
+		return err
+	}
+	auth := deps.NewAuth(repos, config.Auth)
+
+	switch command {
+	case "create":
+		role, err := model.ParseRole(args[1])
+		if err != nil {
+			return fmt.Errorf("'%s': %w", args[1], err)
+		}
+		token, key, err := auth.CreateKey(ctx, args[0], role)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "api key '%s' (%s) created, it is shown only once:\n%s\n", key.Name, key.Role, token)
+		return nil
+	case "revoke":
+		if err := auth.RevokeKey(ctx, args[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "api key '%s' revoked\n", args[0])
+		return nil
+	}
+
+	keys, err := auth.GetKeys(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tROLE\tCREATED AT")
+	for _, key := range keys {
+		createdAt := "config"
+		if !key.CreatedAt.IsZero() {
+			createdAt = key.CreatedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", key.Name, key.Role, createdAt)
+	}
+	return tw.Flush()
+}
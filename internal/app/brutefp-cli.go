@@ -11,7 +11,10 @@ import (
 	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp-cli"
 	"github.com/vitermakov/otusgo-final/internal/app/deps/brutecli"
 	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+	grpcServ "github.com/vitermakov/otusgo-final/pkg/servers/grpc"
+	"github.com/vitermakov/otusgo-final/pkg/utils/tlsx"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
@@ -25,11 +28,17 @@ type BruteFPCli struct {
 
 func NewBruteFPCli(ctx context.Context, config config.Config) (App, error) {
 	cliCfg := config.GrpcClient
-	conn, err := grpc.DialContext(ctx,
-		net.JoinHostPort(cliCfg.Host, strconv.Itoa(cliCfg.Port)),
-		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	opts, err := dialOptions(config)
 	if err != nil {
-		return nil, fmt.Errorf("can't connect to grpc server on %s:%d: %w", cliCfg.Host, cliCfg.Port, err)
+		return nil, err
+	}
+	target := net.JoinHostPort(cliCfg.Host, strconv.Itoa(cliCfg.Port))
+	if cliCfg.Socket != "" {
+		target = "unix:" + cliCfg.Socket
+	}
+	conn, err := grpc.DialContext(ctx, target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("can't connect to grpc server on %s: %w", target, err)
 	}
 
 	irClient := pb.NewIPRuleClient(conn)
@@ -38,7 +47,14 @@ func NewBruteFPCli(ctx context.Context, config config.Config) (App, error) {
 	commands, err := brutecli.InitCommands([]brutecli.Command{
 		brutecli.NewListAdd(irClient),
 		brutecli.NewListRm(irClient),
+		brutecli.NewMove(irClient),
+		brutecli.NewList(irClient),
+		brutecli.NewHistory(irClient),
 		brutecli.NewReset(pmClient),
+		brutecli.NewWatch(pmClient, os.Stdout),
+		brutecli.NewImport(irClient),
+		brutecli.NewExport(irClient),
+		brutecli.NewCompact(irClient),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("can't init commands: %w", err)
@@ -52,6 +68,25 @@ func NewBruteFPCli(ctx context.Context, config config.Config) (App, error) {
 	}, nil
 }
 
+// dialOptions TLS и ключ API из конфигурации, без TLS ключ передается открытым текстом.
+func dialOptions(config config.Config) ([]grpc.DialOption, error) {
+	creds := insecure.NewCredentials()
+	if tlsCfg := config.GrpcClient.TLS; tlsCfg.Enabled {
+		clientTLS, err := tlsx.ClientConfig(tlsCfg.Files())
+		if err != nil {
+			return nil, fmt.Errorf("client TLS: %w", err)
+		}
+		creds = credentials.NewTLS(clientTLS)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if config.APIKey != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(
+			grpcServ.NewTokenCredentials(config.APIKey, config.GrpcClient.TLS.Enabled),
+		))
+	}
+	return opts, nil
+}
+
 func (cli *BruteFPCli) Run(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -81,6 +116,9 @@ func (cli *BruteFPCli) Run(ctx context.Context) error {
 				continue
 			}
 			if result.Success {
+				if result.Message != "" {
+					fmt.Println(result.Message)
+				}
 				fmt.Print("OK\n")
 			} else {
 				fmt.Printf("Error: %s\n", result.Message)
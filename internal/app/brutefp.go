@@ -3,16 +3,25 @@ package app
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/benbjohnson/clock"
 	"github.com/leporo/sqlf"
+	common "github.com/vitermakov/otusgo-final/internal/app/config"
 	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
 	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/feed"
 	"github.com/vitermakov/otusgo-final/internal/handler/grpc"
+	"github.com/vitermakov/otusgo-final/internal/handler/http"
+	"github.com/vitermakov/otusgo-final/internal/metrics"
+	"github.com/vitermakov/otusgo-final/internal/notify"
 	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+	"github.com/vitermakov/otusgo-final/internal/repository/sqlite"
 	"github.com/vitermakov/otusgo-final/pkg/logger"
+	grpcServ "github.com/vitermakov/otusgo-final/pkg/servers/grpc"
+	"github.com/vitermakov/otusgo-final/pkg/tracing"
 	"github.com/vitermakov/otusgo-final/pkg/utils/closer"
 	"github.com/vitermakov/otusgo-final/pkg/utils/pgconn"
 )
@@ -22,10 +31,99 @@ type BruteFP struct {
 	logger   logger.Logger
 	deps     *deps.Deps
 	services *deps.Services
+	feeds    *feed.Syncer
 	closer   *closer.Closer
 }
 
 func NewBruteFP(ctx context.Context, config config.Config) (App, error) {
+	logs, err := newLogger(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.Auth.Enabled {
+		logs.Warn("API authorization is disabled, any client can change rules and reset limits")
+	}
+	closes := closer.NewCloser()
+	if config.Tracing.Enabled {
+		shutdown, err := newTracing(config)
+		if err != nil {
+			return nil, fmt.Errorf("error init tracing: %w", err)
+		}
+		// спаны выгружаются после остановки серверов и хранилища, чтобы дошли и последние запросы.
+		closes.RegisterLast("Tracing", shutdown)
+	}
+	dbPool, err := openStorage(ctx, config, logs, closes)
+	if err != nil {
+		return nil, err
+	}
+	// файл SQLite принадлежит одному процессу, его схема всегда создается при запуске.
+	if config.Storage.AutoMigrate || config.Storage.Type == deps.StoreTypeInSqlite {
+		if err := autoMigrate(ctx, config, dbPool, logs); err != nil {
+			closes.Close(ctx, logs)
+			return nil, err
+		}
+	}
+	rateLimiter, closeFn, err := ratelimit.NewRateLimiter(config.Limits)
+	closes.Register("Rate Limiter", closeFn)
+	if err != nil {
+		return nil, fmt.Errorf("error init rate limiter: %w", err)
+	}
+	repos, err := deps.NewRepos(config.Storage, dbPool)
+	if err != nil {
+		return nil, fmt.Errorf("error init data layer %w", err)
+	}
+	health, err := grpc.NewHealthChecker(config.Health, dbPool, rateLimiter, logs)
+	if err != nil {
+		return nil, fmt.Errorf("error init health checker: %w", err)
+	}
+	dependencies := &deps.Deps{
+		Repos:       repos,
+		Logger:      logs,
+		RateLimiter: rateLimiter,
+		Clock:       clock.New(),
+		Health:      health,
+		CheckStream: config.CheckStream,
+	}
+	if config.Metrics.IsSet() {
+		if dependencies.Metrics, err = newMetrics(config, dbPool, rateLimiter); err != nil {
+			return nil, fmt.Errorf("error init metrics: %w", err)
+		}
+	}
+	// уведомления о блокировках пишутся в транзакции изменения правила, поэтому до сервисов.
+	if len(config.Notify.Webhooks) > 0 {
+		dependencies.Notifier, err = notify.NewNotifier(config.Notify, repos.NotificationOutbox, dependencies.Clock, logs)
+		if err != nil {
+			return nil, fmt.Errorf("error init notifier: %w", err)
+		}
+	}
+
+	services := deps.NewServices(dependencies, config)
+	if dependencies.Metrics != nil {
+		if err := dependencies.Metrics.RegisterRules(services.IPRule); err != nil {
+			return nil, fmt.Errorf("error init metrics: %w", err)
+		}
+		if err := dependencies.Metrics.RegisterEvents(services.Events); err != nil {
+			return nil, fmt.Errorf("error init metrics: %w", err)
+		}
+	}
+
+	feeds, err := feed.NewSyncer(config.Feeds, services.IPRule, feed.NewLoader(nil), logs)
+	if err != nil {
+		return nil, fmt.Errorf("error init feeds: %w", err)
+	}
+
+	return &BruteFP{
+		config:   config,
+		closer:   closes,
+		services: services,
+		feeds:    feeds,
+		deps:     dependencies,
+		logger:   logs,
+	}, nil
+}
+
+func newLogger(config config.Config) (logger.Logger, error) {
 	logLevel, err := logger.ParseLevel(config.Logger.Level)
 	if err != nil {
 		return nil, fmt.Errorf("'%s': %w", config.Logger.Level, err)
@@ -37,15 +135,51 @@ func NewBruteFP(ctx context.Context, config config.Config) (App, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable start logger: %w", err)
 	}
+	return logs, nil
+}
 
-	closes := closer.NewCloser()
-	var dbPool *sql.DB
-	if config.Storage.Type == deps.StoreTypeInPgsql {
+// newTracing глобальный провайдер спанов с экспортом в коллектор.
+func newTracing(config config.Config) (closer.CloseFunc, error) {
+	timeout, _ := config.Tracing.Timeout.AsDuration()
+	exporter, err := tracing.NewOTLPExporter(config.Tracing.Endpoint, config.Tracing.Headers, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return tracing.Setup(config.ServiceID, *config.Tracing.SampleRatio, exporter), nil
+}
+
+// newMetrics метрики ограничителя и пула соединений, метрики правил регистрируются после создания сервисов.
+func newMetrics(config config.Config, db *sql.DB, limiter ratelimit.RateLimiter) (*metrics.Metrics, error) {
+	m, err := metrics.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.RegisterLimiter(config.Limits.Method, limiter); err != nil {
+		return nil, err
+	}
+	if db != nil {
+		if err := m.RegisterDB(config.Storage.Type, db); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// openStorage подключение к базе хранилища, для memory - nil.
+func openStorage(ctx context.Context, config config.Config, logs logger.Logger, closes *closer.Closer) (*sql.DB, error) {
+	switch config.Storage.Type {
+	case deps.StoreTypeInSqlite:
+		db, closeFn, err := sqlite.Open(ctx, config.Storage.SQLite.Path)
+		if err != nil {
+			return nil, fmt.Errorf("unable open sqlite: %w", err)
+		}
+		closes.Register("DB", closeFn)
+		return db, nil
+	case deps.StoreTypeInPgsql:
 		pool, closeFn := pgconn.NewPgConn(config.ServiceID, config.Storage.PGConn, logs)
 		if pool == nil {
-			return nil, fmt.Errorf("unable start logger: %w", err)
+			return nil, errors.New("unable connect to pgsql")
 		}
-		dbPool = pool
 		closes.Register("DB", closeFn)
 
 		// устанавливаем диалект билдера запросов
@@ -62,48 +196,89 @@ func NewBruteFP(ctx context.Context, config config.Config) (App, error) {
 				}
 			}
 		}()
+		return pool, nil
 	}
-	rateLimiter, closeFn, err := ratelimit.NewRateLimiter(config.Limits)
-	closes.Register("Rate Limiter", closeFn)
+	return nil, nil
+}
+
+// autoMigrate применение недостающих миграций до создания репозиториев.
+func autoMigrate(ctx context.Context, config config.Config, db *sql.DB, logs logger.Logger) error {
+	migrator, err := deps.NewMigrator(config.Storage, db)
 	if err != nil {
-		return nil, fmt.Errorf("error init rate limiter: %w", err)
+		return err
 	}
-	repos, err := deps.NewRepos(config.Storage, dbPool)
-	if err != nil {
-		return nil, fmt.Errorf("error init data layer %w", err)
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		logs.Info("migration applied: %s", migration.Name)
 	}
-	dependencies := &deps.Deps{
-		Repos:       repos,
-		Logger:      logs,
-		RateLimiter: rateLimiter,
-		Clock:       clock.New(),
+	if err != nil {
+		return fmt.Errorf("auto migrate: %w", err)
 	}
-
-	services := deps.NewServices(dependencies, config)
-
-	return &BruteFP{
-		config:   config,
-		closer:   closes,
-		services: services,
-		deps:     dependencies,
-		logger:   logs,
-	}, nil
+	return nil
 }
 
 func (bfp *BruteFP) Run(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	grpcServer, closeFn := grpc.NewHandledServer(bfp.config.API, bfp.services, bfp.deps)
-	bfp.closer.Register("GRPC Server", closeFn)
+	// при остановке NOT_SERVING выставляется раньше, чем останавливаются серверы.
+	bfp.deps.Health.Start(ctx)
+	bfp.closer.RegisterFirst("Health", bfp.deps.Health.Shutdown)
+	// потоки WatchEvents не завершаются сами, без этого остановка GRPC сервера ждала бы клиентов.
+	bfp.closer.RegisterFirst("Events", bfp.services.Events.Close)
 
-	go func() {
-		bfp.logger.Info("GRPC server starting")
-		if err := grpcServer.Start(); err != nil {
-			bfp.logger.Error("failed to start GRPC server: %w", err)
-			cancel()
+	if bfp.config.Admin.IsSet() {
+		if err := bfp.startGRPC("GRPC Check Server", grpc.NewCheckServer, bfp.config.API, cancel); err != nil {
+			return err
 		}
-	}()
+		if err := bfp.startGRPC("GRPC Admin Server", grpc.NewAdminServer, bfp.config.Admin, cancel); err != nil {
+			return err
+		}
+	} else if err := bfp.startGRPC("GRPC Server", grpc.NewHandledServer, bfp.config.API, cancel); err != nil {
+		return err
+	}
+
+	if bfp.deps.Metrics != nil {
+		metricsServer, closeFn, err := http.NewMetricsServer(bfp.config.Metrics, bfp.deps)
+		if err != nil {
+			return fmt.Errorf("error init metrics server: %w", err)
+		}
+		bfp.closer.Register("Metrics Server", closeFn)
+
+		go func() {
+			bfp.logger.Info("Metrics server starting")
+			if err := metricsServer.Start(); err != nil {
+				bfp.logger.Error("failed to start metrics server: %w", err)
+				cancel()
+			}
+		}()
+	}
+
+	if bfp.config.HTTP.IsSet() {
+		httpServer, closeFn, err := http.NewHandledServer(bfp.config.HTTP, bfp.services, bfp.deps)
+		if err != nil {
+			return fmt.Errorf("error init HTTP server: %w", err)
+		}
+		bfp.closer.Register("HTTP Server", closeFn)
+
+		go func() {
+			bfp.logger.Info("HTTP server starting")
+			if err := httpServer.Start(); err != nil {
+				bfp.logger.Error("failed to start HTTP server: %w", err)
+				cancel()
+			}
+		}()
+	}
+
+	bfp.feeds.Start(ctx)
+	bfp.closer.Register("Feeds", bfp.feeds.Stop)
+
+	if bfp.deps.Notifier != nil {
+		if err := bfp.deps.Notifier.Start(ctx, bfp.services.Events); err != nil {
+			return fmt.Errorf("error start notifier: %w", err)
+		}
+		bfp.closer.Register("Notifier", bfp.deps.Notifier.Stop)
+	}
 
 	bfp.logger.Info("BruteFP is running...")
 	<-ctx.Done()
@@ -111,6 +286,26 @@ func (bfp *BruteFP) Run(ctx context.Context) error {
 	return nil
 }
 
+type grpcServerFunc func(common.Server, *deps.Services, *deps.Deps) (*grpcServ.Server, closer.CloseFunc, error)
+
+// startGRPC запуск GRPC сервера в фоне, ошибка запуска останавливает приложение.
+func (bfp *BruteFP) startGRPC(name string, newServer grpcServerFunc, config common.Server, cancel func()) error {
+	server, closeFn, err := newServer(config, bfp.services, bfp.deps)
+	if err != nil {
+		return fmt.Errorf("error init %s: %w", name, err)
+	}
+	bfp.closer.Register(name, closeFn)
+
+	go func() {
+		bfp.logger.Info("%s starting on %s", name, server.Address())
+		if err := server.Start(); err != nil {
+			bfp.logger.Error("failed to start %s: %w", name, err)
+			cancel()
+		}
+	}()
+	return nil
+}
+
 func (bfp *BruteFP) Close() {
 	// 10 секунд на завершение
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
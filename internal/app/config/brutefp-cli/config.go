@@ -7,9 +7,13 @@ import (
 )
 
 type Config struct {
-	ServiceID   string        `json:"serviceId"`
-	ServiceName string        `json:"serviceName"`
-	GrpcClient  common.Server `json:"grpcClient"`
+	ServiceID   string `json:"serviceId"`
+	ServiceName string `json:"serviceName"`
+	// GrpcClient адрес admin сервера, если он вынесен отдельно (socket - unix-сокет).
+	// В tls: caFile - CA сервера, certFile и keyFile - сертификат клиента для mTLS
+	GrpcClient common.Server `json:"grpcClient"`
+	// APIKey ключ API, если на сервере включена авторизация
+	APIKey string `json:"apiKey"`
 }
 
 func New(fileName string) (Config, error) {
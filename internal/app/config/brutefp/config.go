@@ -1,26 +1,160 @@
 package brutefp
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 
 	common "github.com/vitermakov/otusgo-final/internal/app/config"
+	"github.com/vitermakov/otusgo-final/internal/model"
 	"github.com/vitermakov/otusgo-final/pkg/utils/jsonx"
 )
 
+var (
+	ErrTracingEndpoint    = errors.New("collector endpoint is required")
+	ErrTracingSampleRatio = errors.New("must be between 0 and 1")
+	// ErrNoAuthNoSocket без авторизации адрес должен быть защищен правами файла сокета.
+	ErrNoAuthNoSocket = errors.New("noAuth is allowed only with socket")
+	// ErrAuthNoKeys ключи в памяти не создать, с такой конфигурацией API отклонил бы все запросы.
+	ErrAuthNoKeys = errors.New("enabled with memory storage, but auth.keys and auth.certs are empty")
+	// ErrAuthDisabled без авторизации любой клиент меняет списки, такой запуск нужно разрешить явно.
+	ErrAuthDisabled = errors.New("disabled, set auth.insecure to run without authorization")
+)
+
 const (
 	defLimitsLoginPerMin    = 10
 	defLimitsPasswordPerMin = 100
 	defLimitsIPPerMin       = 1000
+	defFeedRuleType         = "deny"
+	defEventsBuffer         = 1024
+	defNotifyMaxAttempts    = 10
+	defStreamMaxInFlight    = 64
+
+	WebhookFormatJSON  = "json"
+	WebhookFormatSlack = "slack"
 )
 
 type Config struct {
-	ServiceID   string         `json:"serviceId"`
-	ServiceName string         `json:"serviceName"`
-	Limits      Limits         `json:"limits"`
-	Logger      common.Logger  `json:"logger"`
-	API         common.Server  `json:"api"`
-	Storage     common.Storage `json:"storage"`
+	ServiceID   string        `json:"serviceId"`
+	ServiceName string        `json:"serviceName"`
+	Limits      Limits        `json:"limits"`
+	Logger      common.Logger `json:"logger"`
+	API         common.Server `json:"api"`
+	// Admin отдельный адрес для IPRule и сброса лимитов, тогда на API остается только CheckQuery.
+	// Если не задан, все методы доступны на API
+	Admin common.Server `json:"admin"`
+	// HTTP REST API, не запускается, если не задан порт или сокет
+	HTTP common.Server `json:"http"`
+	// Metrics адрес страницы /metrics для Prometheus, без авторизации; если не задан, метрики не собираются
+	Metrics common.Server  `json:"metrics"`
+	Storage common.Storage `json:"storage"`
+	Feeds   []Feed         `json:"feeds"`
+	Rules   Rules          `json:"rules"`
+	Auth    Auth           `json:"auth"`
+	Health  Health         `json:"health"`
+	Tracing Tracing        `json:"tracing"`
+	Events  Events         `json:"events"`
+	Notify  Notify         `json:"notify"`
+	// CheckStream ограничения потоков Permit.CheckStream
+	CheckStream CheckStream `json:"checkStream"`
+}
+
+// CheckStream ограничения одного потока проверок.
+type CheckStream struct {
+	// MaxInFlight запросов потока в обработке; пока все заняты, следующие запросы не читаются из потока
+	MaxInFlight int `json:"maxInFlight"`
+	// Timeout наибольшая длительность потока, 0 - без ограничения (действует дедлайн клиента)
+	Timeout jsonx.Duration `json:"timeout"`
+	// RequestTimeout на проверку одного запроса
+	RequestTimeout jsonx.Duration `json:"requestTimeout"`
+}
+
+// Notify уведомления о блокировках на вебхуки, без вебхуков не запускаются.
+type Notify struct {
+	Webhooks []Webhook `json:"webhooks"`
+	// MaxAttempts попыток доставки, после последней уведомление отбрасывается
+	MaxAttempts int `json:"maxAttempts"`
+	// RetryDelay пауза после первой неудачи, далее удваивается до MaxRetryDelay
+	RetryDelay    jsonx.Duration `json:"retryDelay"`
+	MaxRetryDelay jsonx.Duration `json:"maxRetryDelay"`
+	// PollInterval проверка очереди на уведомления, ожидающие повтора
+	PollInterval jsonx.Duration `json:"pollInterval"`
+	Timeout      jsonx.Duration `json:"timeout"`
+}
+
+type Webhook struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Secret ключ подписи HMAC-SHA256 (заголовок X-Brutefp-Signature), пусто - без подписи
+	Secret string `json:"secret"`
+	// Format json (по умолчанию) или slack - для Slack incoming webhook
+	Format string `json:"format"`
+	// Types ip_banned, login_locked, password_limit, ip_limit; пусто - все
+	Types []string `json:"types"`
+	// RateLimit не больше Limit уведомлений одного типа об одном IP или логине за Period
+	RateLimit WebhookRateLimit `json:"rateLimit"`
+}
+
+type WebhookRateLimit struct {
+	Limit  int            `json:"limit"`
+	Period jsonx.Duration `json:"period"`
+}
+
+// Events поток событий WatchEvents.
+type Events struct {
+	// Buffer событий в очереди каждого подписчика, при переполнении новые события ему не доставляются
+	Buffer int `json:"buffer"`
+}
+
+// Tracing экспорт спанов OpenTelemetry по OTLP/HTTP.
+type Tracing struct {
+	Enabled bool `json:"enabled"`
+	// Endpoint адрес коллектора, например http://otel-collector:4318
+	Endpoint string            `json:"endpoint"`
+	Headers  map[string]string `json:"headers"`
+	// SampleRatio доля записываемых новых трасс от 0 до 1, по умолчанию 1
+	SampleRatio *float64       `json:"sampleRatio"`
+	Timeout     jsonx.Duration `json:"timeout"`
+}
+
+// Health фоновая проверка зависимостей для grpc.health.v1.Health.
+type Health struct {
+	Interval jsonx.Duration `json:"interval"`
+	Timeout  jsonx.Duration `json:"timeout"`
+}
+
+// Auth авторизация клиентов GRPC и HTTP API по ключам.
+type Auth struct {
+	Enabled bool `json:"enabled"`
+	// Insecure разрешает запуск с выключенной авторизацией, например для тестового окружения
+	Insecure bool `json:"insecure"`
+	// Keys ключи из конфигурации, дополнительно к ключам хранилища ("brutefp apikey create")
+	Keys []APIKey `json:"keys"`
+	// Certs роли клиентов по CN сертификата при mTLS, если клиент не передал ключ
+	Certs []CertRole `json:"certs"`
+}
+
+type CertRole struct {
+	CommonName string `json:"commonName"`
+	Role       string `json:"role"`
+}
+
+type APIKey struct {
+	Name string `json:"name"`
+	// Role checker, operator или admin
+	Role string `json:"role"`
+	// Hash sha256 ключа в hex, см. "brutefp apikey hash"
+	Hash string `json:"hash"`
+}
+
+// Rules настройки применения white/black списков.
+type Rules struct {
+	// Precedence allow-wins (по умолчанию), deny-wins, most-specific или priority
+	Precedence string `json:"precedence"`
+	// OnConflict reject, warn (по умолчанию) или merge - при пересечении добавляемой сети с существующими
+	OnConflict string `json:"onConflict"`
 }
 
 type Limits struct {
@@ -33,6 +167,18 @@ type Limits struct {
 	BaseDuration jsonx.Duration `json:"baseDuration"`
 }
 
+// Feed внешний список сетей (Spamhaus DROP, FireHOL netset и т.п.), периодически
+// загружаемый в white/black списки.
+type Feed struct {
+	Name string `json:"name"`
+	// Source путь к файлу или http(s) URL
+	Source string `json:"source"`
+	// RuleType allow или deny (по умолчанию)
+	RuleType string         `json:"ruleType"`
+	Interval jsonx.Duration `json:"interval"`
+	Timeout  jsonx.Duration `json:"timeout"`
+}
+
 func New(fileName string) (Config, error) {
 	var cfg Config
 	if err := common.New(fileName, &cfg); err != nil {
@@ -56,5 +202,113 @@ func New(fileName string) (Config, error) {
 		log.Printf("wrong base duration value, set default 1 minute\n")
 		cfg.Limits.BaseDuration = jsonx.NewDuration(1, 'm')
 	}
+	precedence, err := model.ParsePrecedence(cfg.Rules.Precedence)
+	if err != nil {
+		return cfg, fmt.Errorf("rules precedence '%s': %w", cfg.Rules.Precedence, err)
+	}
+	cfg.Rules.Precedence = string(precedence)
+	onConflict, err := model.ParseConflictPolicy(cfg.Rules.OnConflict)
+	if err != nil {
+		return cfg, fmt.Errorf("rules conflict policy '%s': %w", cfg.Rules.OnConflict, err)
+	}
+	cfg.Rules.OnConflict = string(onConflict)
+	for _, key := range cfg.Auth.Keys {
+		if _, err := model.ParseRole(key.Role); err != nil {
+			return cfg, fmt.Errorf("auth key '%s' role '%s': %w", key.Name, key.Role, err)
+		}
+		if hash, err := hex.DecodeString(key.Hash); err != nil || len(hash) != sha256.Size {
+			return cfg, fmt.Errorf("auth key '%s': %w", key.Name, model.ErrAPIKeyHashBad)
+		}
+	}
+	for _, cert := range cfg.Auth.Certs {
+		if _, err := model.ParseRole(cert.Role); err != nil {
+			return cfg, fmt.Errorf("auth cert '%s' role '%s': %w", cert.CommonName, cert.Role, err)
+		}
+	}
+	for _, server := range []struct {
+		name   string
+		config common.Server
+	}{{"api", cfg.API}, {"admin", cfg.Admin}, {"http", cfg.HTTP}} {
+		if server.config.NoAuth && server.config.Socket == "" {
+			return cfg, fmt.Errorf("%s: %w", server.name, ErrNoAuthNoSocket)
+		}
+	}
+	if !cfg.Auth.Enabled && !cfg.Auth.Insecure {
+		return cfg, fmt.Errorf("auth: %w", ErrAuthDisabled)
+	}
+	if cfg.Auth.Enabled && cfg.Storage.Type == "memory" && len(cfg.Auth.Keys) == 0 && len(cfg.Auth.Certs) == 0 {
+		return cfg, fmt.Errorf("auth: %w", ErrAuthNoKeys)
+	}
+	if interval, err := cfg.Health.Interval.AsDuration(); err != nil || interval <= 0 {
+		cfg.Health.Interval = jsonx.NewDuration(5, 's')
+	}
+	if timeout, err := cfg.Health.Timeout.AsDuration(); err != nil || timeout <= 0 {
+		cfg.Health.Timeout = jsonx.NewDuration(2, 's')
+	}
+	if cfg.Tracing.Enabled && cfg.Tracing.Endpoint == "" {
+		return cfg, fmt.Errorf("tracing: %w", ErrTracingEndpoint)
+	}
+	if cfg.Tracing.SampleRatio == nil {
+		ratio := 1.0
+		cfg.Tracing.SampleRatio = &ratio
+	} else if ratio := *cfg.Tracing.SampleRatio; ratio < 0 || ratio > 1 {
+		return cfg, fmt.Errorf("tracing sample ratio '%v': %w", ratio, ErrTracingSampleRatio)
+	}
+	if !cfg.Tracing.Timeout.Valid() {
+		cfg.Tracing.Timeout = jsonx.NewDuration(10, 's')
+	}
+	if cfg.Events.Buffer <= 0 {
+		cfg.Events.Buffer = defEventsBuffer
+	}
+	setNotifyDefaults(&cfg.Notify)
+	if cfg.CheckStream.MaxInFlight <= 0 {
+		cfg.CheckStream.MaxInFlight = defStreamMaxInFlight
+	}
+	if !cfg.CheckStream.Timeout.Valid() {
+		cfg.CheckStream.Timeout = jsonx.NewDuration(1, 'h')
+	}
+	if timeout, err := cfg.CheckStream.RequestTimeout.AsDuration(); err != nil || timeout <= 0 {
+		cfg.CheckStream.RequestTimeout = jsonx.NewDuration(1, 's')
+	}
+	for i, feed := range cfg.Feeds {
+		if feed.RuleType == "" {
+			cfg.Feeds[i].RuleType = defFeedRuleType
+		}
+		if !feed.Interval.Valid() {
+			cfg.Feeds[i].Interval = jsonx.NewDuration(1, 'h')
+		}
+		if !feed.Timeout.Valid() {
+			cfg.Feeds[i].Timeout = jsonx.NewDuration(30, 's')
+		}
+	}
 	return cfg, nil
 }
+
+func setNotifyDefaults(cfg *Notify) {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defNotifyMaxAttempts
+	}
+	if !cfg.RetryDelay.Valid() {
+		cfg.RetryDelay = jsonx.NewDuration(5, 's')
+	}
+	if !cfg.MaxRetryDelay.Valid() {
+		cfg.MaxRetryDelay = jsonx.NewDuration(10, 'm')
+	}
+	if !cfg.PollInterval.Valid() {
+		cfg.PollInterval = jsonx.NewDuration(5, 's')
+	}
+	if !cfg.Timeout.Valid() {
+		cfg.Timeout = jsonx.NewDuration(10, 's')
+	}
+	for i, hook := range cfg.Webhooks {
+		if hook.Format == "" {
+			cfg.Webhooks[i].Format = WebhookFormatJSON
+		}
+		if hook.RateLimit.Limit <= 0 {
+			cfg.Webhooks[i].RateLimit.Limit = 1
+		}
+		if !hook.RateLimit.Period.Valid() {
+			cfg.Webhooks[i].RateLimit.Period = jsonx.NewDuration(10, 'm')
+		}
+	}
+}
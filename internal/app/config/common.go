@@ -2,7 +2,12 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	"github.com/vitermakov/otusgo-final/pkg/servers"
+	"github.com/vitermakov/otusgo-final/pkg/utils/tlsx"
 )
 
 type Logger struct {
@@ -13,6 +18,54 @@ type Logger struct {
 type Server struct {
 	Host string `json:"host"`
 	Port int    `json:"port"`
+	// Socket путь к unix-сокету вместо host и port
+	Socket string `json:"socket"`
+	TLS    TLS    `json:"tls"`
+	// NoAuth не проверять авторизацию на этом адресе, только вместе с Socket: доступ ограничен правами файла
+	NoAuth bool `json:"noAuth"`
+	// Quiet не писать в лог каждый запрос
+	Quiet bool `json:"quiet"`
+	// Reflection GRPC server reflection для grpcurl и т.п., на HTTP не действует
+	Reflection bool `json:"reflection"`
+}
+
+// IsSet задан ли адрес: порт или сокет.
+func (s Server) IsSet() bool {
+	return s.Port > 0 || s.Socket != ""
+}
+
+// Servers настройки для pkg/servers, сертификаты сервера перечитываются при изменении файлов.
+func (s Server) Servers(logs logger.Logger) (servers.Config, error) {
+	config := servers.NewConfig(s.Host, s.Port, false).WithSocket(s.Socket).WithQuiet(s.Quiet)
+	if !s.TLS.Enabled {
+		return config, nil
+	}
+	reloader, err := tlsx.NewReloader(s.TLS.Files(), logs)
+	if err != nil {
+		return config, fmt.Errorf("server TLS: %w", err)
+	}
+	return config.WithTLS(reloader.ServerConfig()), nil
+}
+
+// TLS для сервера CAFile включает mTLS, для клиента - CA сервера вместо системных.
+type TLS struct {
+	Enabled    bool   `json:"enabled"`
+	CertFile   string `json:"certFile"`
+	KeyFile    string `json:"keyFile"`
+	CAFile     string `json:"caFile"`
+	MinVersion string `json:"minVersion"`
+	// ServerName только для клиента: имя в сертификате сервера, если отличается от host
+	ServerName string `json:"serverName"`
+}
+
+func (t TLS) Files() tlsx.Files {
+	return tlsx.Files{
+		CertFile:   t.CertFile,
+		KeyFile:    t.KeyFile,
+		CAFile:     t.CAFile,
+		MinVersion: t.MinVersion,
+		ServerName: t.ServerName,
+	}
 }
 
 type API struct {
@@ -21,8 +74,17 @@ type API struct {
 }
 
 type Storage struct {
-	Type   string  `json:"type"`
-	PGConn SQLConn `json:"pgsql"`
+	Type   string     `json:"type"`
+	PGConn SQLConn    `json:"pgsql"`
+	SQLite SQLiteConn `json:"sqlite"`
+	// AutoMigrate применять недостающие встроенные миграции при запуске, для sqlite - всегда.
+	AutoMigrate bool `json:"autoMigrate"`
+}
+
+// SQLiteConn файл базы SQLite, создается при первом запуске, недостающие миграции применяются
+// при каждом запуске независимо от AutoMigrate. Драйверу нужна сборка с CGO_ENABLED=1.
+type SQLiteConn struct {
+	Path string `json:"path"`
 }
 
 type Queue struct {
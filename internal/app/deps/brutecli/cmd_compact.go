@@ -0,0 +1,64 @@
+package brutecli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+)
+
+type Compact struct {
+	client pb.IPRuleClient
+}
+
+func (c Compact) GetName() string {
+	return "compact"
+}
+
+func (c Compact) GetDesc() string {
+	return "Merge adjacent and nested networks into minimal cover: compact [white|black] [--dry-run]. " +
+		"Example: compact black --dry-run"
+}
+
+func (c Compact) Execute(ctx context.Context, args []string) (CmdResult, error) {
+	if len(args) > 2 {
+		return CmdResult{}, ErrWrongArgsCount
+	}
+	req := &pb.CompactReq{}
+	for _, arg := range args {
+		switch arg {
+		case typeListWhite, typeListBlack:
+			req.Type, _ = listTypeFromName(arg)
+		case optDryRun:
+			req.DryRun = true
+		default:
+			return CmdResult{}, fmt.Errorf("unknown argument: %s", arg)
+		}
+	}
+	res, err := c.client.CompactRules(ctx, req)
+	if err != nil {
+		return makeResult(err)
+	}
+	return CmdResult{Success: true, Message: compactDiff(res, req.DryRun)}, nil
+}
+
+// compactDiff изменения в виде diff: - удалено, + добавлено.
+func compactDiff(res *pb.CompactResult, dryRun bool) string {
+	var sb strings.Builder
+	for _, rule := range res.GetRemoved() {
+		sb.WriteString(fmt.Sprintf("- %s %s\n", rule.GetIPNet(), listTypeName(rule.GetType())))
+	}
+	for _, rule := range res.GetAdded() {
+		sb.WriteString(fmt.Sprintf("+ %s %s\n", rule.GetIPNet(), listTypeName(rule.GetType())))
+	}
+	sb.WriteString(fmt.Sprintf("removed: %d, added: %d", len(res.GetRemoved()), len(res.GetAdded())))
+	if dryRun {
+		sb.WriteString(" (dry-run)")
+	}
+	return sb.String()
+}
+
+func NewCompact(client pb.IPRuleClient) Command {
+	return &Compact{client}
+}
@@ -0,0 +1,87 @@
+package brutecli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+)
+
+type Export struct {
+	client pb.IPRuleClient
+}
+
+func (ex Export) GetName() string {
+	return "export"
+}
+
+func (ex Export) GetDesc() string {
+	return "Export networks to file (.csv, .json or plain list): export <file> [white|black]. " +
+		"Example: export ./rules.csv"
+}
+
+func (ex Export) Execute(ctx context.Context, args []string) (CmdResult, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return CmdResult{}, ErrWrongArgsCount
+	}
+	req := &pb.ExportReq{}
+	if len(args) == 2 {
+		typ, err := listTypeFromName(args[1])
+		if err != nil {
+			return CmdResult{}, err
+		}
+		req.Type = typ
+	}
+	format := fileFormat(args[0])
+	if format == formatPlain && req.Type == pb.ListType_ListNone {
+		return CmdResult{}, ErrNoListType
+	}
+
+	rules, err := receiveRules(ctx, ex.client, req)
+	if err != nil {
+		return makeResult(err)
+	}
+	records := make([]ruleRecord, 0, len(rules))
+	for _, rule := range rules {
+		records = append(records, ruleRecordFromPb(rule))
+	}
+
+	file, err := os.Create(args[0])
+	if err != nil {
+		return CmdResult{}, err
+	}
+	if err := writeRules(file, format, records); err != nil {
+		_ = file.Close()
+		return CmdResult{}, err
+	}
+	if err := file.Close(); err != nil {
+		return CmdResult{}, err
+	}
+	return CmdResult{Success: true, Message: fmt.Sprintf("exported: %d", len(records))}, nil
+}
+
+// receiveRules получение правил потоком ExportRules.
+func receiveRules(ctx context.Context, client pb.IPRuleClient, req *pb.ExportReq) ([]*pb.Rule, error) {
+	stream, err := client.ExportRules(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var rules []*pb.Rule
+	for {
+		rule, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return rules, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+}
+
+func NewExport(client pb.IPRuleClient) Command {
+	return &Export{client}
+}
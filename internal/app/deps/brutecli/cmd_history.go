@@ -0,0 +1,83 @@
+package brutecli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+)
+
+const defHistoryLimit = 50
+
+type History struct {
+	client pb.IPRuleClient
+}
+
+func (h History) GetName() string {
+	return "history"
+}
+
+func (h History) GetDesc() string {
+	return "Show rule changes, newest first: history [network|ip]. Example: history 10.20.0.0/16"
+}
+
+func (h History) Execute(ctx context.Context, args []string) (CmdResult, error) {
+	if len(args) > 1 {
+		return CmdResult{}, ErrWrongArgsCount
+	}
+	req := &pb.HistoryReq{Limit: defHistoryLimit}
+	if len(args) == 1 {
+		req.IPNet = args[0]
+	}
+	res, err := h.client.GetRuleHistory(ctx, req)
+	if err != nil {
+		return makeResult(err)
+	}
+
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TIME\tACTION\tNETWORK\tLIST\tACTOR\tCHANGE")
+	for _, event := range res.GetEvents() {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			event.GetCreatedAt().AsTime().Local().Format(time.RFC3339), event.GetAction(), event.GetIPNet(),
+			eventListName(event), event.GetActor(), eventChange(event),
+		)
+	}
+	_ = tw.Flush()
+	sb.WriteString(fmt.Sprintf("shown: %d", len(res.GetEvents())))
+
+	return CmdResult{Success: true, Message: sb.String()}, nil
+}
+
+func eventListName(event *pb.RuleEvent) string {
+	if event.GetAfter() != nil {
+		return listTypeName(event.GetAfter().GetType())
+	}
+	return listTypeName(event.GetBefore().GetType())
+}
+
+// eventChange краткое описание изменения: для update - что стало, для add/delete - комментарий правила.
+func eventChange(event *pb.RuleEvent) string {
+	before, after := event.GetBefore(), event.GetAfter()
+	if before == nil || after == nil {
+		if after != nil {
+			return after.GetComment()
+		}
+		return before.GetComment()
+	}
+	changes := make([]string, 0, 2)
+	if before.GetType() != after.GetType() {
+		changes = append(changes, fmt.Sprintf("list %s -> %s", listTypeName(before.GetType()), listTypeName(after.GetType())))
+	}
+	if before.GetComment() != after.GetComment() {
+		changes = append(changes, fmt.Sprintf("comment %q -> %q", before.GetComment(), after.GetComment()))
+	}
+	return strings.Join(changes, "; ")
+}
+
+func NewHistory(client pb.IPRuleClient) Command {
+	return &History{client}
+}
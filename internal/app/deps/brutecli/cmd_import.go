@@ -0,0 +1,109 @@
+package brutecli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+)
+
+const (
+	optAtomic = "--atomic"
+	optDryRun = "--dry-run"
+)
+
+type Import struct {
+	client pb.IPRuleClient
+}
+
+func (im Import) GetName() string {
+	return "import"
+}
+
+func (im Import) GetDesc() string {
+	return "Import networks from file (.csv, .json or plain list): import <file> [white|black] [--atomic] " +
+		"[--dry-run]. Example: import ./office.txt white --dry-run"
+}
+
+func (im Import) Execute(ctx context.Context, args []string) (CmdResult, error) {
+	if len(args) == 0 {
+		return CmdResult{}, ErrWrongArgsCount
+	}
+	var (
+		defType string
+		opts    pb.ImportOptions
+	)
+	for _, arg := range args[1:] {
+		switch arg {
+		case typeListWhite, typeListBlack:
+			defType = arg
+		case optAtomic:
+			opts.Atomic = true
+		case optDryRun:
+			opts.DryRun = true
+		default:
+			return CmdResult{}, fmt.Errorf("unknown argument: %s", arg)
+		}
+	}
+	file, err := os.Open(args[0])
+	if err != nil {
+		return CmdResult{}, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	records, err := readRules(file, fileFormat(args[0]), defType)
+	if err != nil {
+		return CmdResult{}, err
+	}
+
+	stream, err := im.client.ImportRules(ctx)
+	if err != nil {
+		return makeResult(err)
+	}
+	if err := stream.Send(&pb.ImportRuleReq{Payload: &pb.ImportRuleReq_Options{Options: &opts}}); err != nil {
+		return makeResult(err)
+	}
+	for _, record := range records {
+		if err := stream.Send(&pb.ImportRuleReq{Payload: &pb.ImportRuleReq_Rule{Rule: record.toPb()}}); err != nil {
+			return makeResult(err)
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return makeResult(err)
+	}
+	result := CmdResult{Success: true, Message: importDiff(res, opts.DryRun)}
+	if !res.GetApplied() && !opts.DryRun {
+		result.Success = false
+		result.Message = fmt.Sprintf("import rejected, nothing added\n%s", result.Message)
+	}
+	return result, nil
+}
+
+// importDiff изменения в виде diff: + добавлено, = уже есть, ! ошибка.
+func importDiff(res *pb.ImportResult, dryRun bool) string {
+	var sb strings.Builder
+	for _, item := range res.GetItems() {
+		rule := item.GetRule()
+		switch item.GetAction() {
+		case pb.ImportAction_ImportAdd:
+			sb.WriteString(fmt.Sprintf("+ %s %s\n", rule.GetIPNet(), listTypeName(rule.GetType())))
+		case pb.ImportAction_ImportExists:
+			sb.WriteString(fmt.Sprintf("= %s %s\n", rule.GetIPNet(), listTypeName(rule.GetType())))
+		case pb.ImportAction_ImportError:
+			sb.WriteString(fmt.Sprintf("! %s %s: %s\n", rule.GetIPNet(), listTypeName(rule.GetType()), item.GetError()))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("added: %d, skipped: %d, failed: %d", res.GetAdded(), res.GetSkipped(), res.GetFailed()))
+	if dryRun {
+		sb.WriteString(" (dry-run)")
+	}
+	return sb.String()
+}
+
+func NewImport(client pb.IPRuleClient) Command {
+	return &Import{client}
+}
@@ -0,0 +1,59 @@
+package brutecli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+)
+
+type List struct {
+	client pb.IPRuleClient
+}
+
+func (l List) GetName() string {
+	return "list"
+}
+
+func (l List) GetDesc() string {
+	return "Show networks with metadata: list [white|black]. Example: list black"
+}
+
+func (l List) Execute(ctx context.Context, args []string) (CmdResult, error) {
+	if len(args) > 1 {
+		return CmdResult{}, ErrWrongArgsCount
+	}
+	req := &pb.ExportReq{}
+	if len(args) == 1 {
+		typ, err := listTypeFromName(args[0])
+		if err != nil {
+			return CmdResult{}, err
+		}
+		req.Type = typ
+	}
+	rules, err := receiveRules(ctx, l.client, req)
+	if err != nil {
+		return makeResult(err)
+	}
+
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NETWORK\tRANGE\tLIST\tPRIORITY\tSOURCE\tCREATED BY\tUPDATED AT\tCOMMENT")
+	for _, rule := range rules {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			rule.GetIPNet(), rule.GetRange(), listTypeName(rule.GetType()), rule.GetPriority(),
+			rule.GetSource(), rule.GetCreatedBy(), rule.GetUpdatedAt().AsTime().Local().Format(time.RFC3339), rule.GetComment(),
+		)
+	}
+	_ = tw.Flush()
+	sb.WriteString(fmt.Sprintf("total: %d", len(rules)))
+
+	return CmdResult{Success: true, Message: sb.String()}, nil
+}
+
+func NewList(client pb.IPRuleClient) Command {
+	return &List{client}
+}
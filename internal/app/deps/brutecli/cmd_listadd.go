@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
 
 	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+	"github.com/vitermakov/otusgo-final/pkg/utils/netlist"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -13,8 +16,17 @@ import (
 const (
 	typeListWhite = "white"
 	typeListBlack = "black"
+
+	optPriority   = "--priority="
+	optOnConflict = "--on-conflict="
 )
 
+var conflictPolicies = map[string]pb.ConflictPolicy{
+	"reject": pb.ConflictPolicy_ConflictReject,
+	"warn":   pb.ConflictPolicy_ConflictWarn,
+	"merge":  pb.ConflictPolicy_ConflictMerge,
+}
+
 type ListAdd struct {
 	client pb.IPRuleClient
 }
@@ -24,7 +36,9 @@ func (la ListAdd) GetName() string {
 }
 
 func (la ListAdd) GetDesc() string {
-	return "Add network in list: add <type=white|black> <network>. Example: add white 192.168.2.0/24"
+	return "Add network or range in list: add <type=white|black> <network|start-end> [--priority=N] " +
+		"[--on-conflict=reject|warn|merge] [comment]. " +
+		"Example: add white 192.168.2.0/24 --priority=10 office VPN"
 }
 
 func (la ListAdd) Execute(ctx context.Context, args []string) (CmdResult, error) {
@@ -36,7 +50,8 @@ func NewListAdd(client pb.IPRuleClient) Command {
 }
 
 func addOrRemoveExecute(ctx context.Context, client pb.IPRuleClient, args []string, bAdd bool) (CmdResult, error) {
-	if len(args) != 2 {
+	// комментарий допустим только при добавлении.
+	if len(args) < 2 || (!bAdd && len(args) > 2) {
 		return CmdResult{}, ErrWrongArgsCount
 	}
 	tip := args[0]
@@ -44,29 +59,95 @@ func addOrRemoveExecute(ctx context.Context, client pb.IPRuleClient, args []stri
 	if tip != typeListWhite && tip != typeListBlack {
 		return CmdResult{}, fmt.Errorf("type must be %s or %s", typeListWhite, typeListBlack)
 	}
-	_, _, err := net.ParseCIDR(args[1])
+	var err error
+	if netlist.IsRange(nw) {
+		_, err = netlist.ParseRange(nw)
+	} else {
+		_, _, err = net.ParseCIDR(nw)
+	}
 	if err != nil {
 		return CmdResult{}, err
 	}
 	network := &pb.IPNet{IPNet: nw}
+	if err = parseRuleOptions(args[2:], network); err != nil {
+		return CmdResult{}, err
+	}
 	if bAdd {
+		var res *pb.AddResult
 		switch tip {
 		case typeListWhite:
-			_, err = client.AddToWhiteList(ctx, network)
+			res, err = client.AddToWhiteList(ctx, network)
 		case typeListBlack:
-			_, err = client.AddToBlackList(ctx, network)
+			res, err = client.AddToBlackList(ctx, network)
 		}
-	} else {
-		switch tip {
-		case typeListWhite:
-			_, err = client.DeleteFromWhiteList(ctx, network)
-		case typeListBlack:
-			_, err = client.DeleteFromBlackList(ctx, network)
+		if err != nil {
+			return makeResult(err)
 		}
+		return CmdResult{Success: true, Message: addResultMessage(res)}, nil
+	}
+	switch tip {
+	case typeListWhite:
+		_, err = client.DeleteFromWhiteList(ctx, network)
+	case typeListBlack:
+		_, err = client.DeleteFromBlackList(ctx, network)
 	}
 	return makeResult(err)
 }
 
+// parseRuleOptions выделяет из аргументов --priority=N и --on-conflict=..., остальное - комментарий.
+func parseRuleOptions(args []string, network *pb.IPNet) error {
+	var comment []string
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, optPriority):
+			value := strings.TrimPrefix(arg, optPriority)
+			priority, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return fmt.Errorf("wrong priority '%s': %w", value, err)
+			}
+			network.Priority = int32(priority)
+		case strings.HasPrefix(arg, optOnConflict):
+			value := strings.TrimPrefix(arg, optOnConflict)
+			policy, ok := conflictPolicies[value]
+			if !ok {
+				return fmt.Errorf("conflict policy must be reject, warn or merge, got '%s'", value)
+			}
+			network.OnConflict = policy
+		default:
+			comment = append(comment, arg)
+		}
+	}
+	network.Comment = strings.Join(comment, " ")
+	return nil
+}
+
+// addResultMessage пересечения с существующими правилами и результат объединения.
+func addResultMessage(res *pb.AddResult) string {
+	lines := make([]string, 0, len(res.GetConflicts())+len(res.GetMerged())+1)
+	for _, conflict := range res.GetConflicts() {
+		rule := conflict.GetRule()
+		line := fmt.Sprintf("warning: %s %s %s", conflict.GetRelation(), listTypeName(rule.GetType()), rule.GetIPNet())
+		if conflict.GetContradicts() {
+			line += " (contradicts)"
+		}
+		lines = append(lines, line)
+	}
+	for _, rule := range res.GetMerged() {
+		lines = append(lines, fmt.Sprintf("merged: %s %s removed", listTypeName(rule.GetType()), rule.GetIPNet()))
+	}
+	if rules := res.GetRules(); len(rules) > 0 {
+		nets := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			nets = append(nets, rule.GetIPNet())
+		}
+		lines = append(lines, fmt.Sprintf("range %s added as: %s", rules[0].GetRange(), strings.Join(nets, ", ")))
+	}
+	if res.GetSkipped() {
+		lines = append(lines, fmt.Sprintf("skipped: already covered by %s", res.GetRule().GetIPNet()))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func makeResult(err error) (CmdResult, error) {
 	s, ok := status.FromError(err)
 	res := CmdResult{Message: s.Message(), Code: int(s.Code())}
@@ -15,7 +15,8 @@ func (lr ListRm) GetName() string {
 }
 
 func (lr ListRm) GetDesc() string {
-	return "Remove network from list: rm <white|black> <network>. Example: rm black 192.168.2.0/24"
+	return "Remove network or whole range from list: rm <white|black> <network|start-end>. " +
+		"Example: rm black 192.168.2.0/24"
 }
 
 func (lr ListRm) Execute(ctx context.Context, args []string) (CmdResult, error) {
@@ -0,0 +1,54 @@
+package brutecli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+)
+
+type Move struct {
+	client pb.IPRuleClient
+}
+
+func (m Move) GetName() string {
+	return "move"
+}
+
+func (m Move) GetDesc() string {
+	return "Move network to another list atomically: move <network> <white|black> [comment]. " +
+		"Example: move 192.168.2.0/24 black compromised"
+}
+
+func (m Move) Execute(ctx context.Context, args []string) (CmdResult, error) {
+	if len(args) < 2 {
+		return CmdResult{}, ErrWrongArgsCount
+	}
+	if _, _, err := net.ParseCIDR(args[0]); err != nil {
+		return CmdResult{}, err
+	}
+	typ, err := listTypeFromName(args[1])
+	if err != nil {
+		return CmdResult{}, err
+	}
+	req := &pb.UpdateRuleReq{IPNet: args[0], Type: typ}
+	// без комментария прежний сохраняется.
+	if len(args) > 2 {
+		comment := strings.Join(args[2:], " ")
+		req.Comment = &comment
+	}
+	rule, err := m.client.UpdateRule(ctx, req)
+	if err != nil {
+		return makeResult(err)
+	}
+	return CmdResult{
+		Success: true,
+		Message: fmt.Sprintf("%s is now in %s list", rule.GetIPNet(), listTypeName(rule.GetType())),
+	}, nil
+}
+
+func NewMove(client pb.IPRuleClient) Command {
+	return &Move{client}
+}
@@ -0,0 +1,126 @@
+package brutecli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var ErrWatchArg = errors.New("watch argument must be type=<t1,t2>, net=<network|ip>, denied, count=<n> or for=<d>")
+
+type Watch struct {
+	client pb.PermitClient
+	out    io.Writer
+}
+
+func (w Watch) GetName() string {
+	return "watch"
+}
+
+func (w Watch) GetDesc() string {
+	return "Stream events until Ctrl+C: watch [type=decision,rule_add,rule_delete,rule_update,reset,auto_ban] " +
+		"[net=<network|ip>] [denied] [count=<n>] [for=<duration>]. Example: watch type=decision denied for=5m"
+}
+
+func (w Watch) Execute(ctx context.Context, args []string) (CmdResult, error) {
+	req, count, duration, err := parseWatchArgs(args)
+	if err != nil {
+		return CmdResult{}, err
+	}
+	// отмена закрывает поток и при выходе по count.
+	var cancel context.CancelFunc
+	if duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, duration)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+	stream, err := w.client.WatchEvents(ctx, req)
+	if err != nil {
+		return makeResult(err)
+	}
+
+	var received, dropped uint64
+	for count == 0 || received < count {
+		event, err := stream.Recv()
+		if err != nil {
+			// окончание по count/for или Ctrl+C - штатное завершение.
+			if code := status.Code(err); code != codes.Canceled && code != codes.DeadlineExceeded {
+				return makeResult(err)
+			}
+			break
+		}
+		received++
+		if event.GetDropped() > dropped {
+			_, _ = fmt.Fprintf(w.out, "! %d events dropped, consumer is too slow\n", event.GetDropped()-dropped)
+			dropped = event.GetDropped()
+		}
+		_, _ = fmt.Fprintln(w.out, formatEvent(event))
+	}
+	return CmdResult{Success: true, Message: fmt.Sprintf("received: %d, dropped: %d", received, dropped)}, nil
+}
+
+// parseWatchArgs фильтры вида key=value, count и for ограничивают просмотр на стороне CLI.
+func parseWatchArgs(args []string) (*pb.WatchReq, uint64, time.Duration, error) {
+	var (
+		req      = &pb.WatchReq{}
+		count    uint64
+		duration time.Duration
+		err      error
+	)
+	for _, arg := range args {
+		key, value, _ := strings.Cut(arg, "=")
+		switch key {
+		case "type":
+			req.Types = strings.Split(value, ",")
+		case "net":
+			req.IPNet = value
+		case "denied":
+			req.DeniedOnly = true
+		case "count":
+			if count, err = strconv.ParseUint(value, 10, 64); err != nil {
+				return nil, 0, 0, fmt.Errorf("count '%s': %w", value, err)
+			}
+		case "for":
+			if duration, err = time.ParseDuration(value); err != nil {
+				return nil, 0, 0, fmt.Errorf("for '%s': %w", value, err)
+			}
+		default:
+			return nil, 0, 0, fmt.Errorf("'%s': %w", arg, ErrWatchArg)
+		}
+	}
+	return req, count, duration, nil
+}
+
+func formatEvent(event *pb.Event) string {
+	prefix := event.GetTime().AsTime().Local().Format(time.RFC3339) + "  " + event.GetType() + "  "
+	switch {
+	case event.GetDecision() != nil:
+		d := event.GetDecision()
+		result := "allow"
+		if !d.GetSuccess() {
+			result = "deny"
+		}
+		return prefix + fmt.Sprintf("%s login=%s ip=%s reason=%s", result, d.GetLogin(), d.GetIP(), d.GetReason())
+	case event.GetRule() != nil:
+		r := event.GetRule()
+		return prefix + fmt.Sprintf("%s %s list=%s actor=%s %s",
+			r.GetAction(), r.GetIPNet(), eventListName(r), r.GetActor(), eventChange(r))
+	case event.GetLimitReset() != nil:
+		r := event.GetLimitReset()
+		return prefix + fmt.Sprintf("%s=%s actor=%s", r.GetParam(), r.GetValue(), r.GetActor())
+	}
+	return prefix
+}
+
+func NewWatch(client pb.PermitClient, out io.Writer) Command {
+	return &Watch{client: client, out: out}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -58,16 +59,24 @@ func (cs Commands) Execute(ctx context.Context, args []string) (CmdResult, error
 }
 
 func (cs Commands) Help() string {
+	// ширина колонки имени команды, не меньше чем у quit.
+	names := make([]string, 0, len(cs))
+	width := len("quit")
+	for name := range cs {
+		names = append(names, name)
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	sort.Strings(names)
+
 	res := strings.Builder{}
 	res.WriteString("Available Commands:\n")
-	for _, command := range cs {
-		res.WriteString(fmt.Sprintf(
-			" - %s%s%s\n",
-			command.GetName(), strings.Repeat(" ", 7-len(command.GetName())), command.GetDesc()),
-		)
+	for _, name := range names {
+		res.WriteString(fmt.Sprintf(" - %-*s  %s\n", width, name, cs[name].GetDesc()))
 	}
 
-	res.WriteString(" - quit   Exit\n")
+	res.WriteString(fmt.Sprintf(" - %-*s  %s\n", width, "quit", "Exit"))
 	return res.String()
 }
 
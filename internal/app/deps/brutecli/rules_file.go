@@ -0,0 +1,213 @@
+package brutecli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+	"github.com/vitermakov/otusgo-final/pkg/utils/netlist"
+)
+
+/*
+Форматы файлов для import/export, определяются по расширению:
+  - .csv  - колонки network,type,comment,source,created_by,priority (заголовок необязателен,
+    source и created_by при импорте игнорируются);
+  - .json - массив объектов {"network": "...", "type": "white|black", "comment": "...", "priority": 0},
+    при экспорте добавляются поля source и createdBy;
+  - остальные - одна сеть на строку, тип списка задается параметром команды.
+*/
+
+const (
+	formatPlain = "plain"
+	formatCSV   = "csv"
+	formatJSON  = "json"
+)
+
+var ErrNoListType = errors.New("list type (white|black) must be specified for plain format")
+
+var csvHeader = []string{"network", "type", "comment", "source", "created_by", "priority"}
+
+// ruleRecord правило в файле. Source и CreatedBy только экспортируются,
+// при импорте правила получают источник import и автора-пользователя API.
+type ruleRecord struct {
+	Network   string `json:"network"`
+	Type      string `json:"type"`
+	Comment   string `json:"comment,omitempty"`
+	Source    string `json:"source,omitempty"`
+	CreatedBy string `json:"createdBy,omitempty"`
+	Priority  int    `json:"priority,omitempty"`
+}
+
+func fileFormat(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return formatCSV
+	case ".json":
+		return formatJSON
+	}
+	return formatPlain
+}
+
+// readRules читает правила, defType используется, если тип не указан в самом файле.
+func readRules(r io.Reader, format, defType string) ([]ruleRecord, error) {
+	var (
+		records []ruleRecord
+		err     error
+	)
+	switch format {
+	case formatCSV:
+		records, err = readRulesCSV(r)
+	case formatJSON:
+		err = json.NewDecoder(r).Decode(&records)
+	default:
+		records, err = readRulesPlain(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		records[i].Source, records[i].CreatedBy = "", ""
+		if records[i].Type == "" {
+			records[i].Type = defType
+		}
+		if err := records[i].normalize(); err != nil {
+			return nil, fmt.Errorf("rule #%d: %w", i+1, err)
+		}
+	}
+	return records, nil
+}
+
+func readRulesPlain(r io.Reader) ([]ruleRecord, error) {
+	entries, err := netlist.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]ruleRecord, 0, len(entries))
+	for _, entry := range entries {
+		records = append(records, ruleRecord{Network: entry.IPNet.String(), Comment: entry.Comment})
+	}
+	return records, nil
+}
+
+func readRulesCSV(r io.Reader) ([]ruleRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]ruleRecord, 0, len(rows))
+	for i, row := range rows {
+		if i == 0 && strings.EqualFold(row[0], csvHeader[0]) {
+			continue
+		}
+		record := ruleRecord{Network: row[0]}
+		if len(row) > 1 {
+			record.Type = row[1]
+		}
+		if len(row) > 2 {
+			record.Comment = row[2]
+		}
+		if len(row) > 5 && row[5] != "" {
+			if record.Priority, err = strconv.Atoi(row[5]); err != nil {
+				return nil, fmt.Errorf("row #%d: wrong priority: %w", i+1, err)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func writeRules(w io.Writer, format string, records []ruleRecord) error {
+	switch format {
+	case formatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, record := range records {
+			row := []string{
+				record.Network, record.Type, record.Comment, record.Source, record.CreatedBy,
+				strconv.Itoa(record.Priority),
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+	entries := make([]netlist.Entry, 0, len(records))
+	for _, record := range records {
+		ipNet, err := netlist.ParseNet(record.Network)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, netlist.Entry{IPNet: ipNet, Comment: record.Comment})
+	}
+	return netlist.Write(w, entries)
+}
+
+// normalize проверяет правило и приводит сеть к виду CIDR.
+func (rr *ruleRecord) normalize() error {
+	if rr.Type == "" {
+		return ErrNoListType
+	}
+	if _, err := listTypeFromName(rr.Type); err != nil {
+		return err
+	}
+	ipNet, err := netlist.ParseNet(rr.Network)
+	if err != nil {
+		return err
+	}
+	rr.Network = ipNet.String()
+	return nil
+}
+
+func (rr ruleRecord) toPb() *pb.Rule {
+	typ, _ := listTypeFromName(rr.Type)
+	return &pb.Rule{Type: typ, IPNet: rr.Network, Comment: rr.Comment, Priority: int32(rr.Priority)}
+}
+
+func ruleRecordFromPb(rule *pb.Rule) ruleRecord {
+	return ruleRecord{
+		Network:   rule.GetIPNet(),
+		Type:      listTypeName(rule.GetType()),
+		Comment:   rule.GetComment(),
+		Source:    rule.GetSource(),
+		CreatedBy: rule.GetCreatedBy(),
+		Priority:  int(rule.GetPriority()),
+	}
+}
+
+func listTypeFromName(name string) (pb.ListType, error) {
+	switch name {
+	case typeListWhite:
+		return pb.ListType_ListWhite, nil
+	case typeListBlack:
+		return pb.ListType_ListBlack, nil
+	}
+	return pb.ListType_ListNone, fmt.Errorf("type must be %s or %s", typeListWhite, typeListBlack)
+}
+
+func listTypeName(typ pb.ListType) string {
+	switch typ { //nolint:exhaustive // и не должно быть
+	case pb.ListType_ListWhite:
+		return typeListWhite
+	case pb.ListType_ListBlack:
+		return typeListBlack
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package brutecli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulesFile(t *testing.T) {
+	expected := []ruleRecord{
+		{Network: "10.0.0.0/8", Type: typeListWhite, Comment: "office"},
+		{Network: "192.168.1.10/32", Type: typeListWhite},
+	}
+
+	t.Run("format by extension", func(t *testing.T) {
+		require.Equal(t, formatCSV, fileFormat("rules.CSV"))
+		require.Equal(t, formatJSON, fileFormat("/tmp/rules.json"))
+		require.Equal(t, formatPlain, fileFormat("drop.txt"))
+		require.Equal(t, formatPlain, fileFormat("firehol_level1.netset"))
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		src := "# office networks\n10.0.0.0/8 # office\n192.168.1.10\n"
+		_, err := readRules(strings.NewReader(src), formatPlain, "")
+		require.ErrorIs(t, err, ErrNoListType)
+
+		records, err := readRules(strings.NewReader(src), formatPlain, typeListWhite)
+		require.NoError(t, err)
+		require.Equal(t, expected, records)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		src := "network,type,comment\n10.0.0.0/8,white,office\n192.168.1.10,white\n"
+		records, err := readRules(strings.NewReader(src), formatCSV, "")
+		require.NoError(t, err)
+		require.Equal(t, expected, records)
+
+		_, err = readRules(strings.NewReader("10.0.0.0/8,grey\n"), formatCSV, "")
+		require.Error(t, err)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		src := `[{"network": "10.0.0.0/8", "type": "white", "comment": "office"}, {"network": "192.168.1.10"}]`
+		records, err := readRules(strings.NewReader(src), formatJSON, typeListWhite)
+		require.NoError(t, err)
+		require.Equal(t, expected, records)
+	})
+
+	t.Run("write and read back", func(t *testing.T) {
+		for _, format := range []string{formatPlain, formatCSV, formatJSON} {
+			var buf bytes.Buffer
+			require.NoError(t, writeRules(&buf, format, expected), format)
+			records, err := readRules(&buf, format, typeListWhite)
+			require.NoError(t, err, format)
+			require.Equal(t, expected, records, format)
+		}
+	})
+}
@@ -2,28 +2,46 @@ package brutefp
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/benbjohnson/clock"
 	common "github.com/vitermakov/otusgo-final/internal/app/config"
 	"github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/events"
+	"github.com/vitermakov/otusgo-final/internal/metrics"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/notify"
 	"github.com/vitermakov/otusgo-final/internal/ratelimit"
 	"github.com/vitermakov/otusgo-final/internal/repository"
 	"github.com/vitermakov/otusgo-final/internal/repository/memory"
 	"github.com/vitermakov/otusgo-final/internal/repository/pgsql"
+	"github.com/vitermakov/otusgo-final/internal/repository/sqlite"
+	"github.com/vitermakov/otusgo-final/internal/repository/traced"
 	"github.com/vitermakov/otusgo-final/internal/service"
+	"github.com/vitermakov/otusgo-final/migrations"
 	"github.com/vitermakov/otusgo-final/pkg/logger"
+	grpcServ "github.com/vitermakov/otusgo-final/pkg/servers/grpc"
+	"github.com/vitermakov/otusgo-final/pkg/utils/migrate"
 )
 
 const (
 	StoreTypeInMemory = "memory"
 	StoreTypeInPgsql  = "pgsql"
+	StoreTypeInSqlite = "sqlite"
 )
 
 // Repos регистр репозиториев.
 type Repos struct {
-	IPRule repository.IPRule
+	IPRule      repository.IPRule
+	IPRuleEvent repository.IPRuleEvent
+	APIKey      repository.APIKey
+	// NotificationOutbox очередь вебхуков, для memory не переживает перезапуск
+	NotificationOutbox repository.NotificationOutbox
+	// Tx транзакции, общие для всех репозиториев хранилища
+	Tx repository.Transactor
 }
 
 func NewRepos(store common.Storage, dbPool *sql.DB) (*Repos, error) {
@@ -34,11 +52,27 @@ func NewRepos(store common.Storage, dbPool *sql.DB) (*Repos, error) {
 	switch store.Type {
 	case StoreTypeInMemory:
 		repos = &Repos{
-			IPRule: memory.NewIPRuleRepo(),
+			IPRule:             memory.NewIPRuleRepo(),
+			IPRuleEvent:        memory.NewIPRuleEventRepo(),
+			APIKey:             memory.NewAPIKeyRepo(),
+			NotificationOutbox: memory.NewNotificationOutboxRepo(),
+			Tx:                 memory.NewTransactor(),
 		}
 	case StoreTypeInPgsql:
 		repos = &Repos{
-			IPRule: pgsql.NewIPRuleRepo(dbPool),
+			IPRule:             pgsql.NewIPRuleRepo(dbPool),
+			IPRuleEvent:        pgsql.NewIPRuleEventRepo(dbPool),
+			APIKey:             pgsql.NewAPIKeyRepo(dbPool),
+			NotificationOutbox: pgsql.NewNotificationOutboxRepo(dbPool),
+			Tx:                 pgsql.NewTransactor(dbPool),
+		}
+	case StoreTypeInSqlite:
+		repos = &Repos{
+			IPRule:             sqlite.NewIPRuleRepo(dbPool),
+			IPRuleEvent:        sqlite.NewIPRuleEventRepo(dbPool),
+			APIKey:             sqlite.NewAPIKeyRepo(dbPool),
+			NotificationOutbox: sqlite.NewNotificationOutboxRepo(dbPool),
+			Tx:                 sqlite.NewTransactor(dbPool),
 		}
 	default:
 		err = fmt.Errorf("unknown storage type '%s", store.Type)
@@ -46,7 +80,38 @@ func NewRepos(store common.Storage, dbPool *sql.DB) (*Repos, error) {
 	if err != nil {
 		return nil, err
 	}
-	return repos, nil
+	return traceRepos(repos, store.Type), nil
+}
+
+// traceRepos спаны на вызовы репозиториев, без настроенной трассировки почти ничего не стоят.
+func traceRepos(repos *Repos, storeType string) *Repos {
+	system := storeType
+	if storeType == StoreTypeInPgsql {
+		system = "postgresql"
+	}
+	return &Repos{
+		IPRule:             traced.NewIPRuleRepo(repos.IPRule, system),
+		IPRuleEvent:        traced.NewIPRuleEventRepo(repos.IPRuleEvent, system),
+		APIKey:             traced.NewAPIKeyRepo(repos.APIKey, system),
+		NotificationOutbox: traced.NewNotificationOutboxRepo(repos.NotificationOutbox, system),
+		Tx:                 traced.NewTransactor(repos.Tx, system),
+	}
+}
+
+// ErrStoreNoSchema хранилищу не нужны миграции.
+var ErrStoreNoSchema = errors.New("storage type has no schema to migrate")
+
+// NewMigrator миграции схемы хранилища, встроенные в бинарник.
+func NewMigrator(store common.Storage, db *sql.DB) (*migrate.Migrator, error) {
+	switch store.Type {
+	case StoreTypeInPgsql:
+		return migrate.New(db, migrations.FS, migrate.Postgres), nil
+	case StoreTypeInSqlite:
+		return migrate.New(db, sqlite.Migrations(), migrate.SQLite), nil
+	case StoreTypeInMemory:
+		return nil, fmt.Errorf("'%s': %w", store.Type, ErrStoreNoSchema)
+	}
+	return nil, fmt.Errorf("unknown storage type '%s'", store.Type)
 }
 
 // Deps зависимости.
@@ -55,19 +120,65 @@ type Deps struct {
 	Logger      logger.Logger
 	RateLimiter ratelimit.RateLimiter
 	Clock       clock.Clock
+	// Health статусы для grpc.health.v1.Health, nil - сервис не регистрируется
+	Health *grpcServ.HealthChecker
+	// Metrics nil, если метрики не отдаются
+	Metrics *metrics.Metrics
+	// CheckStream ограничения потоков проверок, нулевые значения - без ограничений
+	CheckStream brutefp.CheckStream
+	// Notifier nil, если вебхуки не настроены
+	Notifier *notify.Notifier
 }
 
 // Services регистр сервисов.
 type Services struct {
 	IPRule        service.IPRule
 	PermitChecker service.PermitChecker
+	// Auth nil, если авторизация API отключена
+	Auth service.Auth
+	// Events решения, изменения правил и сбросы лимитов для WatchEvents
+	Events *events.Bus
 }
 
 func NewServices(deps *Deps, cfg brutefp.Config) *Services {
 	repos := deps.Repos
-	ipRule := service.NewIPRuleSrv(repos.IPRule)
-	return &Services{
+	// значения проверены при чтении конфигурации, пустые - значения по умолчанию.
+	precedence, _ := model.ParsePrecedence(cfg.Rules.Precedence)
+	onConflict, _ := model.ParseConflictPolicy(cfg.Rules.OnConflict)
+	bus := events.NewBus(cfg.Events.Buffer)
+	ruleEvents, tx := repos.IPRuleEvent, repos.Tx
+	if deps.Notifier != nil {
+		ruleEvents, tx = deps.Notifier.RuleEventRepo(ruleEvents), deps.Notifier.Transactor(tx)
+	}
+	ipRule := service.NewIPRuleSrv(
+		repos.IPRule, events.IPRuleEventRepo(ruleEvents, bus), events.Transactor(tx, bus),
+		precedence, onConflict,
+	)
+	services := &Services{
 		IPRule:        ipRule,
 		PermitChecker: service.NewPermitCheckerSrv(ipRule, deps.RateLimiter, time.Minute, cfg.Limits),
+		Events:        bus,
+	}
+	services.PermitChecker = events.PermitChecker(services.PermitChecker, bus)
+	if deps.Metrics != nil {
+		services.PermitChecker = deps.Metrics.PermitChecker(services.PermitChecker)
+	}
+	if cfg.Auth.Enabled {
+		services.Auth = NewAuth(repos, cfg.Auth)
+	}
+	return services
+}
+
+// NewAuth ключи API из хранилища и конфигурации, значения конфигурации проверены при ее чтении.
+func NewAuth(repos *Repos, cfg brutefp.Auth) service.Auth {
+	keys := make([]model.APIKey, 0, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		role, _ := model.ParseRole(key.Role)
+		keys = append(keys, model.APIKey{Name: key.Name, Hash: strings.ToLower(key.Hash), Role: role})
+	}
+	certs := make(map[string]model.Role, len(cfg.Certs))
+	for _, cert := range cfg.Certs {
+		certs[cert.CommonName], _ = model.ParseRole(cert.Role)
 	}
+	return service.NewAuthSrv(repos.APIKey, keys, certs)
 }
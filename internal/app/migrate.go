@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
+	"github.com/vitermakov/otusgo-final/pkg/utils/closer"
+)
+
+var ErrMigrateCommand = errors.New("unknown migrate command, expected up, down or status")
+
+// Migrate выполнение команды "brutefp migrate up|down|status" над хранилищем из конфигурации.
+func Migrate(ctx context.Context, config config.Config, command string, out io.Writer) error {
+	if command != "up" && command != "down" && command != "status" {
+		return fmt.Errorf("'%s': %w", command, ErrMigrateCommand)
+	}
+	logs, err := newLogger(config)
+	if err != nil {
+		return err
+	}
+	closes := closer.NewCloser()
+	defer closes.Close(ctx, logs)
+
+	db, err := openStorage(ctx, config, logs, closes)
+	if err != nil {
+		return err
+	}
+	migrator, err := deps.NewMigrator(config.Storage, db)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(out, "OK   %s\n", migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(out, "no migrations to run")
+		}
+		return err
+	case "down":
+		migration, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "OK   %s\n", migration.Name)
+		return nil
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "APPLIED AT\tMIGRATION")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.Applied {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", appliedAt, status.Name)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,128 @@
+// Package events шина событий brutefp для подписчиков WatchEvents.
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vitermakov/otusgo-final/internal/model"
+)
+
+// DefaultBuffer размер буфера подписчика по умолчанию.
+const DefaultBuffer = 1024
+
+var ErrBusClosed = errors.New("event bus is closed")
+
+// Bus шина событий в памяти. Публикация не блокируется: если буфер подписчика заполнен,
+// событие ему не доставляется и учитывается как потерянное.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	buffer int
+	closed bool
+
+	published atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+func NewBus(buffer int) *Bus {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Bus{subs: make(map[*Subscription]struct{}), buffer: buffer}
+}
+
+// Publish рассылка события подписчикам, чей фильтр оно проходит.
+func (b *Bus) Publish(event model.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	b.published.Add(1)
+	for sub := range b.subs {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe подписка с отдельным буфером, после использования нужно вызвать Close.
+func (b *Bus) Subscribe(filter model.EventFilter) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+	sub := &Subscription{bus: b, filter: filter, events: make(chan model.Event, b.buffer)}
+	b.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Close закрытие каналов всех подписок, чтобы потоки WatchEvents завершились до остановки серверов.
+func (b *Bus) Close(context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		b.unsubscribe(sub)
+	}
+	return nil
+}
+
+// Published число опубликованных событий.
+func (b *Bus) Published() uint64 {
+	return b.published.Load()
+}
+
+// Dropped число событий, не доставленных подписчикам из-за переполнения буфера.
+func (b *Bus) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// Subscribers число активных подписок.
+func (b *Bus) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+func (b *Bus) unsubscribe(sub *Subscription) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	close(sub.events)
+}
+
+// Subscription подписка на события, проходящие фильтр.
+type Subscription struct {
+	bus     *Bus
+	filter  model.EventFilter
+	events  chan model.Event
+	dropped atomic.Uint64
+}
+
+// Events канал событий, закрывается при Close подписки или шины.
+func (s *Subscription) Events() <-chan model.Event {
+	return s.events
+}
+
+// Dropped число событий, потерянных этой подпиской.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.unsubscribe(s)
+}
@@ -0,0 +1,158 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository/memory"
+	"github.com/vitermakov/otusgo-final/internal/service"
+)
+
+func TestBus(t *testing.T) {
+	bus := NewBus(2)
+	_, office, _ := net.ParseCIDR("10.0.0.0/8")
+	denied, err := bus.Subscribe(model.EventFilter{
+		Types: []model.EventType{model.EventTypeDecision}, IPNet: office, DeniedOnly: true,
+	})
+	require.NoError(t, err)
+	all, err := bus.Subscribe(model.EventFilter{})
+	require.NoError(t, err)
+	require.Equal(t, 2, bus.Subscribers())
+
+	decision := func(ip string, success bool) model.Event {
+		return model.NewDecisionEvent(
+			model.PermitQuery{Login: "admin", Password: "secret", IP: net.ParseIP(ip)},
+			model.PermitResult{Success: success},
+		)
+	}
+	bus.Publish(decision("10.1.1.1", true))
+	bus.Publish(decision("192.168.1.1", false))
+	bus.Publish(decision("10.1.1.2", false))
+	bus.Publish(model.NewResetEvent(model.LimitBucket{Param: model.LimitParamNameLogin, Value: "admin"}, "ops"))
+
+	// в буфер all помещаются два события, остальные потеряны только для него.
+	event := <-denied.Events()
+	require.Equal(t, "10.1.1.2", event.Decision.IP.String())
+	require.Equal(t, "a***", event.Decision.Login)
+	require.Zero(t, denied.Dropped())
+	require.Len(t, all.Events(), 2)
+	require.Equal(t, uint64(2), all.Dropped())
+	require.Equal(t, uint64(2), bus.Dropped())
+	require.Equal(t, uint64(4), bus.Published())
+
+	denied.Close()
+	denied.Close()
+	require.Equal(t, 1, bus.Subscribers())
+
+	require.NoError(t, bus.Close(context.Background()))
+	<-all.Events()
+	<-all.Events()
+	_, ok := <-all.Events()
+	require.False(t, ok)
+	all.Close()
+	_, err = bus.Subscribe(model.EventFilter{})
+	require.ErrorIs(t, err, ErrBusClosed)
+	bus.Publish(decision("10.1.1.1", true))
+}
+
+func TestRuleEvent(t *testing.T) {
+	_, ipNet, _ := net.ParseCIDR("192.168.0.0/24")
+	rule := model.IPRule{Type: model.RuleTypeDeny, IPNet: *ipNet, Source: model.RuleSourceAutoBan}
+	event := model.NewRuleEvent(model.NewIPRuleEvent(model.EventActionAdd, "limits", nil, &rule))
+	require.Equal(t, model.EventTypeAutoBan, event.Type)
+	require.False(t, event.Rule.CreatedAt.IsZero())
+
+	_, host, _ := net.ParseCIDR("192.168.0.7/32")
+	_, other, _ := net.ParseCIDR("172.16.0.0/12")
+	require.True(t, model.EventFilter{IPNet: host}.Match(event))
+	require.False(t, model.EventFilter{IPNet: other}.Match(event))
+	require.False(t, model.EventFilter{Types: []model.EventType{model.EventTypeRuleAdd}}.Match(event))
+}
+
+func TestTransactorPublishesAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus(4)
+	sub, err := bus.Subscribe(model.EventFilter{})
+	require.NoError(t, err)
+	repo := IPRuleEventRepo(memory.NewIPRuleEventRepo(), bus)
+	tx := Transactor(memory.NewTransactor(), bus)
+
+	_, ipNet, _ := net.ParseCIDR("192.168.0.0/24")
+	rule := model.IPRule{Type: model.RuleTypeDeny, IPNet: *ipNet}
+	add := func(ctx context.Context) error {
+		return repo.Add(ctx, model.NewIPRuleEvent(model.EventActionAdd, "ops", nil, &rule))
+	}
+	errRollback := errors.New("rollback")
+	err = tx.InTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, add(ctx))
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+	require.Empty(t, sub.Events())
+
+	require.NoError(t, tx.InTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, add(ctx))
+		require.NoError(t, tx.InTx(ctx, add))
+		require.Empty(t, sub.Events())
+		return nil
+	}))
+	require.Len(t, sub.Events(), 2)
+
+	require.NoError(t, add(ctx))
+	require.Len(t, sub.Events(), 3)
+}
+
+// failingChecker проверка с ошибкой сервиса для одного из IP.
+type failingChecker struct {
+	service.PermitChecker
+	failIP string
+}
+
+func (f failingChecker) Check(_ context.Context, query model.PermitQuery) (model.PermitResult, error) {
+	if query.IP.String() == f.failIP {
+		internal := model.PermitResult{Err: model.ErrDeniedInternal, Reason: model.PermitReasonInternal}
+		return internal, errors.New("limiter is down")
+	}
+	return model.PermitResult{Success: true, Reason: model.PermitReasonWithinLimits}, nil
+}
+
+func (f failingChecker) CheckBatch(ctx context.Context, batch model.PermitBatch) ([]model.PermitResult, error) {
+	results := make([]model.PermitResult, len(batch.Queries))
+	for i, query := range batch.Queries {
+		results[i], _ = f.Check(ctx, query)
+	}
+	if batch.Atomic {
+		return nil, errors.New("limiter is down")
+	}
+	return results, nil
+}
+
+func TestPermitCheckerSkipsFailedChecks(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus(4)
+	sub, err := bus.Subscribe(model.EventFilter{})
+	require.NoError(t, err)
+	checker := PermitChecker(failingChecker{failIP: "10.0.0.2"}, bus)
+	ok := model.PermitQuery{Login: "user", IP: net.ParseIP("10.0.0.1")}
+	failed := model.PermitQuery{Login: "user", IP: net.ParseIP("10.0.0.2")}
+
+	_, err = checker.Check(ctx, failed)
+	require.Error(t, err)
+	require.Empty(t, sub.Events())
+	_, err = checker.Check(ctx, ok)
+	require.NoError(t, err)
+	require.Len(t, sub.Events(), 1)
+
+	// результаты с внутренней ошибкой и наборы с ошибкой не публикуются.
+	results, err := checker.CheckBatch(ctx, model.PermitBatch{Queries: []model.PermitQuery{ok, failed}})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Len(t, sub.Events(), 2)
+	_, err = checker.CheckBatch(ctx, model.PermitBatch{Queries: []model.PermitQuery{ok}, Atomic: true})
+	require.Error(t, err)
+	require.Len(t, sub.Events(), 2)
+}
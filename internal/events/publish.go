@@ -0,0 +1,110 @@
+package events
+
+import (
+	"context"
+
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/internal/service"
+)
+
+// PermitChecker публикация решений и сбросов лимитов поверх сервиса проверки.
+func PermitChecker(next service.PermitChecker, bus *Bus) service.PermitChecker {
+	return &permitChecker{PermitChecker: next, bus: bus}
+}
+
+type permitChecker struct {
+	service.PermitChecker
+	bus *Bus
+}
+
+func (p *permitChecker) Check(ctx context.Context, query model.PermitQuery) (model.PermitResult, error) {
+	res, err := p.PermitChecker.Check(ctx, query)
+	if err == nil {
+		p.publishDecision(query, res)
+	}
+	return res, err
+}
+
+func (p *permitChecker) CheckBatch(ctx context.Context, batch model.PermitBatch) ([]model.PermitResult, error) {
+	results, err := p.PermitChecker.CheckBatch(ctx, batch)
+	if err != nil {
+		return results, err
+	}
+	for i, res := range results {
+		p.publishDecision(batch.Queries[i], res)
+	}
+	return results, nil
+}
+
+// publishDecision при внутренней ошибке решения не было: такой запрос не попадает к подписчикам.
+func (p *permitChecker) publishDecision(query model.PermitQuery, res model.PermitResult) {
+	if res.Reason != model.PermitReasonInternal {
+		p.bus.Publish(model.NewDecisionEvent(query, res))
+	}
+}
+
+func (p *permitChecker) Reset(ctx context.Context, bucket model.LimitBucket) (bool, error) {
+	ok, err := p.PermitChecker.Reset(ctx, bucket)
+	if err == nil {
+		p.bus.Publish(model.NewResetEvent(bucket, model.ActorFromContext(ctx)))
+	}
+	return ok, err
+}
+
+// IPRuleEventRepo публикация изменений правил после их записи в журнал: в журнал попадает
+// каждое изменение, откуда бы оно ни пришло (API, фиды, импорт, автобан). Внутри транзакции
+// Transactor события публикуются после ее фиксации.
+func IPRuleEventRepo(repo repository.IPRuleEvent, bus *Bus) repository.IPRuleEvent {
+	return &ipRuleEventRepo{IPRuleEvent: repo, bus: bus}
+}
+
+type ipRuleEventRepo struct {
+	repository.IPRuleEvent
+	bus *Bus
+}
+
+func (r *ipRuleEventRepo) Add(ctx context.Context, event model.IPRuleEvent) error {
+	if err := r.IPRuleEvent.Add(ctx, event); err != nil {
+		return err
+	}
+	if p, ok := ctx.Value(pendingKey{}).(*pending); ok {
+		p.events = append(p.events, model.NewRuleEvent(event))
+		return nil
+	}
+	r.bus.Publish(model.NewRuleEvent(event))
+	return nil
+}
+
+// Transactor события изменений правил, записанные в транзакции, публикуются только после ее фиксации:
+// подписчики не увидят изменение, которое откатилось.
+func Transactor(tx repository.Transactor, bus *Bus) repository.Transactor {
+	return &transactor{Transactor: tx, bus: bus}
+}
+
+// pendingKey события транзакции, ожидающие публикации.
+type pendingKey struct{}
+
+type pending struct {
+	events []model.Event
+}
+
+type transactor struct {
+	repository.Transactor
+	bus *Bus
+}
+
+func (t *transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(pendingKey{}).(*pending); ok {
+		return t.Transactor.InTx(ctx, fn)
+	}
+	p := &pending{}
+	err := t.Transactor.InTx(context.WithValue(ctx, pendingKey{}, p), fn)
+	if err != nil {
+		return err
+	}
+	for _, event := range p.events {
+		t.bus.Publish(event)
+	}
+	return nil
+}
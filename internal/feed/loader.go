@@ -0,0 +1,58 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/vitermakov/otusgo-final/pkg/utils/netlist"
+)
+
+// Loader загрузка содержимого фида из файла или по HTTP.
+type Loader struct {
+	client *http.Client
+}
+
+func NewLoader(client *http.Client) Loader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return Loader{client: client}
+}
+
+// Load source - путь к файлу (допускается префикс file://) или http(s) URL.
+func (l Loader) Load(ctx context.Context, source string) ([]netlist.Entry, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return l.loadHTTP(ctx, source)
+	}
+	file, err := os.Open(strings.TrimPrefix(source, "file://"))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return netlist.Parse(file)
+}
+
+func (l Loader) loadHTTP(ctx context.Context, url string) ([]netlist.Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrBadStatus, resp.Status)
+	}
+	return netlist.Parse(resp.Body)
+}
@@ -0,0 +1,163 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/service"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+)
+
+var (
+	ErrBadStatus     = errors.New("unexpected response status")
+	ErrFeedName      = errors.New("feed name is empty or duplicated")
+	ErrFeedSource    = errors.New("feed source is empty")
+	ErrFeedDurations = errors.New("feed interval and timeout must be positive")
+)
+
+// Feed настройки одного фида, проверенные и приведенные к внутренним типам.
+type Feed struct {
+	Name     string
+	Source   string
+	RuleType model.RuleType
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// Syncer периодически загружает фиды и сверяет их с правилами.
+type Syncer struct {
+	feeds  []Feed
+	ipRule service.IPRule
+	loader Loader
+	logger logger.Logger
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewSyncer(cfg []config.Feed, ipRule service.IPRule, loader Loader, logger logger.Logger) (*Syncer, error) {
+	feeds := make([]Feed, 0, len(cfg))
+	names := make(map[string]struct{}, len(cfg))
+	for _, item := range cfg {
+		feed, err := parseFeed(item)
+		if err != nil {
+			return nil, fmt.Errorf("feed '%s': %w", item.Name, err)
+		}
+		if _, ok := names[feed.Name]; ok {
+			return nil, fmt.Errorf("feed '%s': %w", item.Name, ErrFeedName)
+		}
+		names[feed.Name] = struct{}{}
+		feeds = append(feeds, feed)
+	}
+	return &Syncer{feeds: feeds, ipRule: ipRule, loader: loader, logger: logger}, nil
+}
+
+// Start запускает по рутине на фид, первая загрузка - сразу.
+func (s *Syncer) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, feed := range s.feeds {
+		s.wg.Add(1)
+		go func(feed Feed) {
+			defer s.wg.Done()
+			ticker := time.NewTicker(feed.Interval)
+			defer ticker.Stop()
+			for {
+				s.syncAndLog(ctx, feed)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(feed)
+	}
+}
+
+// Stop останавливает загрузку фидов и ждет завершения текущих синхронизаций.
+func (s *Syncer) Stop(ctx context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sync однократная загрузка и сверка фида.
+func (s *Syncer) Sync(ctx context.Context, feed Feed) (model.FeedSyncResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, feed.Timeout)
+	defer cancel()
+
+	entries, err := s.loader.Load(ctx, feed.Source)
+	if err != nil {
+		return model.FeedSyncResult{}, fmt.Errorf("error loading %s: %w", feed.Source, err)
+	}
+	nets := make([]net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		nets = append(nets, entry.IPNet)
+	}
+	return s.ipRule.SyncFeed(ctx, model.FeedSync{Feed: feed.Name, Type: feed.RuleType, Nets: nets})
+}
+
+// Feeds настроенные фиды.
+func (s *Syncer) Feeds() []Feed {
+	return s.feeds
+}
+
+func (s *Syncer) syncAndLog(ctx context.Context, feed Feed) {
+	res, err := s.Sync(ctx, feed)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			s.logger.Error("feed %s: sync failed: %s", feed.Name, err.Error())
+		}
+		return
+	}
+	s.logger.Info(
+		"feed %s: synced, added %d, removed %d, unchanged %d, skipped %d",
+		feed.Name, res.Added, res.Removed, res.Unchanged, res.Skipped,
+	)
+}
+
+func parseFeed(cfg config.Feed) (Feed, error) {
+	if cfg.Name == "" {
+		return Feed{}, ErrFeedName
+	}
+	if cfg.Source == "" {
+		return Feed{}, ErrFeedSource
+	}
+	ruleType, err := model.ParseRuleType(cfg.RuleType)
+	if err != nil {
+		return Feed{}, err
+	}
+	interval, err := cfg.Interval.AsDuration()
+	if err != nil {
+		return Feed{}, err
+	}
+	timeout, err := cfg.Timeout.AsDuration()
+	if err != nil {
+		return Feed{}, err
+	}
+	if interval <= 0 || timeout <= 0 {
+		return Feed{}, ErrFeedDurations
+	}
+	return Feed{
+		Name:     cfg.Name,
+		Source:   cfg.Source,
+		RuleType: ruleType,
+		Interval: interval,
+		Timeout:  timeout,
+	}, nil
+}
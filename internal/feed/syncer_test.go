@@ -0,0 +1,164 @@
+package feed
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository/memory"
+	"github.com/vitermakov/otusgo-final/internal/service"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	"github.com/vitermakov/otusgo-final/pkg/utils/jsonx"
+)
+
+// feedStandIn локальная замена внешнего источника фида.
+type feedStandIn struct {
+	mu     sync.Mutex
+	body   string
+	status int
+}
+
+func (f *feedStandIn) set(status int, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status, f.body = status, body
+}
+
+func (f *feedStandIn) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.WriteHeader(f.status)
+	_, _ = w.Write([]byte(f.body))
+}
+
+func TestSyncer(t *testing.T) {
+	ctx := context.Background()
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+
+	standIn := &feedStandIn{}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	newSyncer := func(t *testing.T, source string) (*Syncer, service.IPRule) {
+		t.Helper()
+		ipRule := service.NewIPRuleSrv(
+			memory.NewIPRuleRepo(), memory.NewIPRuleEventRepo(), memory.NewTransactor(),
+			model.PrecedenceAllowWins, model.ConflictWarn,
+		)
+		syncer, err := NewSyncer([]config.Feed{{
+			Name:     "drop",
+			Source:   source,
+			RuleType: "deny",
+			Interval: jsonx.NewDuration(1, 'h'),
+			Timeout:  jsonx.NewDuration(5, 's'),
+		}}, ipRule, NewLoader(server.Client()), log)
+		require.NoError(t, err)
+		return syncer, ipRule
+	}
+	listNets := func(t *testing.T, ipRule service.IPRule, source model.RuleSource) []string {
+		t.Helper()
+		rules, err := ipRule.GetList(ctx, model.IPRuleSearch{Source: &source})
+		require.NoError(t, err)
+		result := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			result = append(result, rule.IPNet.String())
+		}
+		return result
+	}
+
+	t.Run("reconcile keeps manual rules", func(t *testing.T) {
+		syncer, ipRule := newSyncer(t, server.URL)
+		feed := syncer.Feeds()[0]
+
+		_, manual, _ := net.ParseCIDR("1.10.16.0/20")
+		_, err := ipRule.Add(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: *manual}, "")
+		require.NoError(t, err)
+
+		standIn.set(http.StatusOK, "; Spamhaus DROP List\n1.10.16.0/20 ; SBL256894\n5.0.0.0/8\n6.0.0.0/8\n")
+		res, err := syncer.Sync(ctx, feed)
+		require.NoError(t, err)
+		require.Equal(t, model.FeedSyncResult{Added: 2, Skipped: 1}, res)
+		require.ElementsMatch(t, []string{"5.0.0.0/8", "6.0.0.0/8"}, listNets(t, ipRule, model.RuleSourceFeed))
+
+		standIn.set(http.StatusOK, "5.0.0.0/8\n7.0.0.0/8\n7.0.0.0/8\n1.10.16.0/20\n")
+		res, err = syncer.Sync(ctx, feed)
+		require.NoError(t, err)
+		require.Equal(t, model.FeedSyncResult{Added: 1, Removed: 1, Unchanged: 1, Skipped: 1}, res)
+		require.ElementsMatch(t, []string{"5.0.0.0/8", "7.0.0.0/8"}, listNets(t, ipRule, model.RuleSourceFeed))
+
+		// сбой источника или пустой список не трогает правила.
+		standIn.set(http.StatusInternalServerError, "")
+		_, err = syncer.Sync(ctx, feed)
+		require.ErrorIs(t, err, ErrBadStatus)
+		standIn.set(http.StatusOK, "# empty\n")
+		_, err = syncer.Sync(ctx, feed)
+		require.ErrorContains(t, err, model.ErrFeedEmpty.Error())
+
+		require.ElementsMatch(t, []string{"5.0.0.0/8", "7.0.0.0/8"}, listNets(t, ipRule, model.RuleSourceFeed))
+		require.ElementsMatch(t, []string{"1.10.16.0/20"}, listNets(t, ipRule, model.RuleSourceManual))
+
+		// изменения фида попадают в историю от имени фида.
+		events, err := ipRule.GetHistory(ctx, model.IPRuleEventSearch{})
+		require.NoError(t, err)
+		require.Len(t, events, 5)
+		for _, event := range events[:4] {
+			require.Equal(t, "feed:drop", event.Actor)
+		}
+		require.Equal(t, model.EventActionDelete, events[1].Action)
+		require.Equal(t, "6.0.0.0/8", events[1].IPNet.String())
+	})
+
+	t.Run("file source", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "firehol_level1.netset")
+		require.NoError(t, os.WriteFile(fileName, []byte("# FireHOL\n8.8.0.0/16\n9.9.9.9\n"), 0o600))
+
+		syncer, ipRule := newSyncer(t, "file://"+fileName)
+		res, err := syncer.Sync(ctx, syncer.Feeds()[0])
+		require.NoError(t, err)
+		require.Equal(t, 2, res.Added)
+
+		ruleType, err := ipRule.GetRuleTypeForIP(ctx, net.ParseIP("9.9.9.9"))
+		require.NoError(t, err)
+		require.Equal(t, model.RuleTypeDeny, ruleType)
+	})
+
+	t.Run("start and stop", func(t *testing.T) {
+		standIn.set(http.StatusOK, "10.0.0.0/8\n")
+		syncer, ipRule := newSyncer(t, server.URL)
+		syncer.Start(ctx)
+		require.Eventually(t, func() bool {
+			return len(listNets(t, ipRule, model.RuleSourceFeed)) == 1
+		}, time.Second, time.Millisecond*10)
+
+		stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		require.NoError(t, syncer.Stop(stopCtx))
+	})
+
+	t.Run("bad config", func(t *testing.T) {
+		ipRule := service.NewIPRuleSrv(
+			memory.NewIPRuleRepo(), memory.NewIPRuleEventRepo(), memory.NewTransactor(),
+			model.PrecedenceAllowWins, model.ConflictWarn,
+		)
+		feed := config.Feed{
+			Name: "drop", Source: server.URL, RuleType: "deny",
+			Interval: jsonx.NewDuration(1, 'h'), Timeout: jsonx.NewDuration(5, 's'),
+		}
+		_, err := NewSyncer([]config.Feed{feed, feed}, ipRule, NewLoader(nil), log)
+		require.ErrorIs(t, err, ErrFeedName)
+
+		feed.RuleType = "block"
+		_, err = NewSyncer([]config.Feed{feed}, ipRule, NewLoader(nil), log)
+		require.ErrorIs(t, err, model.ErrRuleTypeUnk)
+	})
+}
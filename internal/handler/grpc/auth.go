@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/servers"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// methodRoles минимальная роль для каждого метода API, методы без роли запрещены.
+var methodRoles = servers.MethodRoles{
+	method(pb.Permit_ServiceDesc, "CheckQuery"):      model.RolesFrom(model.RoleChecker),
+	method(pb.Permit_ServiceDesc, "CheckQueryBatch"): model.RolesFrom(model.RoleChecker),
+	method(pb.Permit_ServiceDesc, "CheckStream"):     model.RolesFrom(model.RoleChecker),
+	method(pb.Permit_ServiceDesc, "ResetLogin"):      model.RolesFrom(model.RoleOperator),
+	method(pb.Permit_ServiceDesc, "ResetIP"):         model.RolesFrom(model.RoleOperator),
+	// WatchEvents отдает IP и маскированные логины всех проверок.
+	method(pb.Permit_ServiceDesc, "WatchEvents"): model.RolesFrom(model.RoleOperator),
+
+	method(pb.IPRule_ServiceDesc, "AddToWhiteList"):      model.RolesFrom(model.RoleOperator),
+	method(pb.IPRule_ServiceDesc, "AddToBlackList"):      model.RolesFrom(model.RoleOperator),
+	method(pb.IPRule_ServiceDesc, "DeleteFromWhiteList"): model.RolesFrom(model.RoleOperator),
+	method(pb.IPRule_ServiceDesc, "DeleteFromBlackList"): model.RolesFrom(model.RoleOperator),
+	method(pb.IPRule_ServiceDesc, "UpdateRule"):          model.RolesFrom(model.RoleOperator),
+	method(pb.IPRule_ServiceDesc, "ExportRules"):         model.RolesFrom(model.RoleOperator),
+	method(pb.IPRule_ServiceDesc, "GetRuleHistory"):      model.RolesFrom(model.RoleOperator),
+	method(pb.IPRule_ServiceDesc, "ImportRules"):         model.RolesFrom(model.RoleAdmin),
+	method(pb.IPRule_ServiceDesc, "CompactRules"):        model.RolesFrom(model.RoleAdmin),
+
+	// пробы балансировщика и оркестратора приходят без ключа.
+	method(healthpb.Health_ServiceDesc, "Check"): {servers.RoleAnonymous},
+	method(healthpb.Health_ServiceDesc, "Watch"): {servers.RoleAnonymous},
+	// reflection включается настройкой, схема API доступна любому клиенту с ключом.
+	method(reflectionpb.ServerReflection_ServiceDesc, "ServerReflectionInfo"): model.RolesFrom(model.RoleChecker),
+}
+
+// method полное имя метода, как в grpc.UnaryServerInfo.FullMethod.
+func method(desc grpc.ServiceDesc, name string) string {
+	return "/" + desc.ServiceName + "/" + name
+}
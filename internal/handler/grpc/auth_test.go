@@ -0,0 +1,179 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	common "github.com/vitermakov/otusgo-final/internal/app/config"
+	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	grpcServ "github.com/vitermakov/otusgo-final/pkg/servers/grpc"
+	"github.com/vitermakov/otusgo-final/pkg/utils/closer"
+	"github.com/vitermakov/otusgo-final/pkg/utils/tlsx"
+	"github.com/vitermakov/otusgo-final/pkg/utils/tlsx/tlsxtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TestMethodRoles у каждого метода API есть роль, иначе он будет недоступен при включенной авторизации.
+func TestMethodRoles(t *testing.T) {
+	for _, desc := range []grpc.ServiceDesc{pb.Permit_ServiceDesc, pb.IPRule_ServiceDesc} {
+		for _, m := range desc.Methods {
+			require.NotEmpty(t, methodRoles[method(desc, m.MethodName)], m.MethodName)
+		}
+		for _, s := range desc.Streams {
+			require.NotEmpty(t, methodRoles[method(desc, s.StreamName)], s.StreamName)
+		}
+	}
+}
+
+func TestAuth(t *testing.T) {
+	cfg := getCfgAPI(t)
+	cfg.API.Port = 50052
+	cfg.Auth = config.Auth{Enabled: true}
+
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+	closes := closer.NewCloser()
+	defer closes.Close(context.Background(), log)
+
+	rateLimiter, closeFn, err := ratelimit.NewRateLimiter(cfg.Limits)
+	require.NoError(t, err)
+	closes.Register("Rate Limiter", closeFn)
+	repos, err := deps.NewRepos(cfg.Storage, nil)
+	require.NoError(t, err)
+	depends := &deps.Deps{Repos: repos, Logger: log, RateLimiter: rateLimiter}
+	services := deps.NewServices(depends, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	checker, _, err := services.Auth.CreateKey(ctx, "php-login", model.RoleChecker)
+	require.NoError(t, err)
+	operator, _, err := services.Auth.CreateKey(ctx, "ops", model.RoleOperator)
+	require.NoError(t, err)
+
+	server, closeFn, err := NewHandledServer(cfg.API, services, depends)
+	require.NoError(t, err)
+	closes.Register("GRPC Server", closeFn)
+	go func() {
+		_ = server.Start()
+	}()
+	conn, err := getConn(t, cfg.API)
+	require.NoError(t, err)
+	defer conn.Close()
+	permit, rules := pb.NewPermitClient(conn), pb.NewIPRuleClient(conn)
+
+	withKey := func(key string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+key)
+	}
+	req := &pb.PermitReq{Login: "login", Password: "password", IP: "192.168.0.1"}
+
+	_, err = permit.CheckQuery(ctx, req)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = permit.CheckQuery(withKey("bfp_unknown"), req)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	res, err := permit.CheckQuery(withKey(checker), req)
+	require.NoError(t, err)
+	require.True(t, res.GetSuccess())
+
+	_, err = rules.AddToWhiteList(withKey(checker), &pb.IPNet{IPNet: "192.168.0.0/24"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	added, err := rules.AddToWhiteList(withKey(operator), &pb.IPNet{IPNet: "192.168.0.0/24"})
+	require.NoError(t, err)
+	require.Equal(t, "ops", added.GetRule().GetCreatedBy())
+
+	// потоковые методы проверяются так же.
+	export, err := rules.ExportRules(withKey(checker), &pb.ExportReq{})
+	require.NoError(t, err)
+	_, err = export.Recv()
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = rules.CompactRules(withKey(operator), &pb.CompactReq{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+// TestAuthMTLS клиент без ключа авторизуется сертификатом, ключ имеет приоритет над сертификатом.
+func TestAuthMTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := tlsxtest.NewCA(t, dir, "ca")
+	serverPair := ca.Server(t, dir, "brutefp")
+	checkerPair := ca.Client(t, dir, "php-login")
+	strangerPair := ca.Client(t, dir, "stranger")
+
+	cfg := getCfgAPI(t)
+	cfg.API.Port = 50053
+	cfg.API.TLS = common.TLS{
+		Enabled: true, CertFile: serverPair.CertFile, KeyFile: serverPair.KeyFile, CAFile: ca.File,
+	}
+	cfg.Auth = config.Auth{
+		Enabled: true,
+		Certs:   []config.CertRole{{CommonName: "php-login", Role: string(model.RoleChecker)}},
+	}
+
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+	closes := closer.NewCloser()
+	defer closes.Close(context.Background(), log)
+
+	rateLimiter, closeFn, err := ratelimit.NewRateLimiter(cfg.Limits)
+	require.NoError(t, err)
+	closes.Register("Rate Limiter", closeFn)
+	repos, err := deps.NewRepos(cfg.Storage, nil)
+	require.NoError(t, err)
+	depends := &deps.Deps{Repos: repos, Logger: log, RateLimiter: rateLimiter}
+	services := deps.NewServices(depends, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	operator, _, err := services.Auth.CreateKey(ctx, "ops", model.RoleOperator)
+	require.NoError(t, err)
+
+	server, closeFn, err := NewHandledServer(cfg.API, services, depends)
+	require.NoError(t, err)
+	closes.Register("GRPC Server", closeFn)
+	go func() {
+		_ = server.Start()
+	}()
+
+	dial := func(pair tlsxtest.Pair, opts ...grpc.DialOption) *grpc.ClientConn {
+		clientTLS, err := tlsx.ClientConfig(tlsx.Files{CertFile: pair.CertFile, KeyFile: pair.KeyFile, CAFile: ca.File})
+		require.NoError(t, err)
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(clientTLS)))
+		conn, err := grpc.Dial(net.JoinHostPort(cfg.API.Host, strconv.Itoa(cfg.API.Port)), opts...)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+		return conn
+	}
+	req := &pb.PermitReq{Login: "login", Password: "password", IP: "192.168.0.1"}
+
+	checker := dial(checkerPair)
+	// сервер запускается не сразу
+	res, err := pb.NewPermitClient(checker).CheckQuery(ctx, req, grpc.WaitForReady(true))
+	require.NoError(t, err)
+	require.True(t, res.GetSuccess())
+	_, err = pb.NewIPRuleClient(checker).AddToWhiteList(ctx, &pb.IPNet{IPNet: "192.168.0.0/24"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = pb.NewPermitClient(dial(strangerPair)).CheckQuery(ctx, req)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	withKey := dial(strangerPair, grpc.WithPerRPCCredentials(grpcServ.NewTokenCredentials(operator, true)))
+	added, err := pb.NewIPRuleClient(withKey).AddToWhiteList(ctx, &pb.IPNet{IPNet: "192.168.0.0/24"})
+	require.NoError(t, err)
+	require.Equal(t, "ops", added.GetRule().GetCreatedBy())
+}
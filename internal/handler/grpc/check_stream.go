@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/dto"
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+	"github.com/vitermakov/otusgo-final/internal/metrics"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defStreamMaxInFlight если ограничение не задано в зависимостях (тесты).
+const defStreamMaxInFlight = 64
+
+// streamLimits ограничения потока CheckStream, нулевые длительности - без ограничения.
+type streamLimits struct {
+	maxInFlight    int
+	timeout        time.Duration
+	requestTimeout time.Duration
+	metrics        *metrics.CheckStream
+}
+
+func newStreamLimits(deps *deps.Deps) streamLimits {
+	limits := streamLimits{maxInFlight: deps.CheckStream.MaxInFlight}
+	if limits.maxInFlight <= 0 {
+		limits.maxInFlight = defStreamMaxInFlight
+	}
+	// длительности проверены при чтении конфигурации, незаданные - без ограничения.
+	limits.timeout, _ = deps.CheckStream.Timeout.AsDuration()
+	limits.requestTimeout, _ = deps.CheckStream.RequestTimeout.AsDuration()
+	if deps.Metrics != nil {
+		limits.metrics = deps.Metrics.CheckStream()
+	}
+	return limits
+}
+
+// CheckStream запросы проверяются параллельно, не больше maxInFlight одновременно. Место освобождается
+// после отправки ответа, поэтому пока все места заняты, поток не читается и клиента притормаживает
+// flow control HTTP/2. Ошибка отдельного запроса возвращается в его ответе и поток не прерывает.
+func (p PermitHandlerImpl) CheckStream(stream pb.Permit_CheckStreamServer) error {
+	defer p.stream.metrics.StreamOpened()()
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	if p.stream.timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, p.stream.timeout)
+		defer cancelTimeout()
+	}
+
+	var (
+		slots    = make(chan struct{}, p.stream.maxInFlight)
+		requests = make(chan *pb.CheckStreamReq)
+		results  = make(chan *pb.CheckStreamRes, p.stream.maxInFlight)
+		recvErr  error
+		sent     = make(chan error, 1)
+		workers  sync.WaitGroup
+	)
+	// чтение: место занимается до Recv, запрос передается на проверку.
+	go func() {
+		defer close(requests)
+		for {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			req, err := stream.Recv()
+			if err != nil {
+				recvErr = err
+				return
+			}
+			select {
+			case requests <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	// отправка из одной горутины: Send нельзя вызывать параллельно. После ошибки или окончания
+	// потока ответы только вычитываются, чтобы освободить места.
+	go func() {
+		var err error
+		for res := range results {
+			if err == nil && ctx.Err() == nil {
+				err = stream.Send(res)
+			}
+			<-slots
+		}
+		sent <- err
+	}()
+
+	// closed клиент закрыл поток или чтение завершилось ошибкой, recvErr заполнена.
+	closed := false
+	for !closed && ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+		case req, ok := <-requests:
+			if !ok {
+				closed = true
+				break
+			}
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				results <- p.checkStreamItem(ctx, req)
+			}()
+		}
+	}
+	workers.Wait()
+	close(results)
+	sendErr := <-sent
+
+	switch {
+	case stream.Context().Err() != nil:
+		return status.FromContextError(stream.Context().Err()).Err()
+	case ctx.Err() != nil:
+		return status.Error(codes.DeadlineExceeded, "stream timeout exceeded, reconnect to continue")
+	case sendErr != nil:
+		return sendErr
+	case closed && !errors.Is(recvErr, io.EOF):
+		return recvErr
+	}
+	return nil
+}
+
+func (p PermitHandlerImpl) checkStreamItem(ctx context.Context, req *pb.CheckStreamReq) *pb.CheckStreamRes {
+	observe := p.stream.metrics.RequestReceived()
+	res := &pb.CheckStreamRes{ID: req.GetID()}
+
+	query, err := dto.PermitModel(req.GetQuery())
+	if err != nil {
+		res.Error = fmt.Sprintf("wrong check-query request: %s", err.Error())
+		observe(metrics.StreamResultInvalid)
+		return res
+	}
+	if p.stream.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.stream.requestTimeout)
+		defer cancel()
+	}
+	result, err := p.services.PermitChecker.Check(ctx, query)
+	switch {
+	case ctx.Err() != nil:
+		res.Error = "check timeout exceeded"
+		observe(metrics.StreamResultTimeout)
+	case err != nil:
+		p.logger.Error("stream internal error: %s", err.Error())
+		res.Error = "internal error"
+		observe(metrics.StreamResultError)
+	default:
+		p.logger.Debug("%s", model.CheckLogMessage(query, result))
+		res.Result = dto.FromPermitResultModel(result)
+		observe(metrics.StreamResultOK)
+	}
+	return res
+}
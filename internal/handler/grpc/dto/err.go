@@ -1,8 +1,16 @@
 package dto
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vitermakov/otusgo-final/internal/model"
+)
 
 var (
-	ErrRequestEmpty = errors.New("empty query")
-	ErrBadIP        = errors.New("ip address is not well-formed")
+	ErrRequestEmpty    = errors.New("empty query")
+	ErrBadIP           = errors.New("ip address is not well-formed")
+	ErrImportNoOptions = errors.New("import options expected in the first message")
+	ErrBadLimit        = errors.New("limit must not be negative")
+	ErrBatchSize       = fmt.Errorf("batch must contain at most %d queries", model.PermitBatchMaxSize)
 )
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/utils/netlist"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func EventFilterModel(req *pb.WatchReq) (model.EventFilter, error) {
+	filter := model.EventFilter{DeniedOnly: req.GetDeniedOnly()}
+	for _, typ := range req.GetTypes() {
+		eventType, err := model.ParseEventType(typ)
+		if err != nil {
+			return filter, fmt.Errorf("'%s': %w", typ, err)
+		}
+		filter.Types = append(filter.Types, eventType)
+	}
+	if req.GetIPNet() != "" {
+		ipNet, err := netlist.ParseNet(req.GetIPNet())
+		if err != nil {
+			return filter, err
+		}
+		filter.IPNet = &ipNet
+	}
+	return filter, nil
+}
+
+// FromEventModel dropped - число потерянных подписчиком событий на момент отправки.
+func FromEventModel(event model.Event, dropped uint64) *pb.Event {
+	result := &pb.Event{
+		Type:    string(event.Type),
+		Time:    timestamppb.New(event.Time),
+		Dropped: dropped,
+	}
+	switch {
+	case event.Decision != nil:
+		result.Payload = &pb.Event_Decision{Decision: &pb.DecisionEvent{
+			Login:   event.Decision.Login,
+			IP:      event.Decision.IP.String(),
+			Success: event.Decision.Success,
+			Reason:  string(event.Decision.Reason),
+		}}
+	case event.Rule != nil:
+		result.Payload = &pb.Event_Rule{Rule: FromIPRuleEventModel(*event.Rule)}
+	case event.Reset != nil:
+		result.Payload = &pb.Event_LimitReset{LimitReset: &pb.ResetEvent{
+			Param: event.Reset.Param,
+			Value: event.Reset.Value,
+			Actor: event.Reset.Actor,
+		}}
+	}
+	return result
+}
@@ -2,14 +2,225 @@ package dto
 
 import (
 	"net"
+	"strings"
 
 	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/utils/netlist"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-func IPNetModel(req *pb.IPNet) (net.IPNet, error) {
-	_, mask, err := net.ParseCIDR(req.IPNet)
+// IPNetModel сеть CIDR или диапазон start-end, см. netlist.ParseNetOrRange.
+func IPNetModel(req *pb.IPNet) (net.IPNet, string, error) {
+	return netlist.ParseNetOrRange(req.GetIPNet())
+}
+
+func RuleTypeModel(typ pb.ListType) model.RuleType {
+	switch typ { //nolint:exhaustive // ListNone - отсутствие типа
+	case pb.ListType_ListWhite:
+		return model.RuleTypeAllow
+	case pb.ListType_ListBlack:
+		return model.RuleTypeDeny
+	}
+	return model.RuleTypeNone
+}
+
+func FromRuleTypeModel(typ model.RuleType) pb.ListType {
+	switch typ { //nolint:exhaustive // и не должно быть
+	case model.RuleTypeAllow:
+		return pb.ListType_ListWhite
+	case model.RuleTypeDeny:
+		return pb.ListType_ListBlack
+	}
+	return pb.ListType_ListNone
+}
+
+func IPRuleInputModel(req *pb.Rule) (model.IPRuleInput, error) {
+	if req == nil {
+		return model.IPRuleInput{}, ErrRequestEmpty
+	}
+	_, ipNet, err := net.ParseCIDR(req.GetIPNet())
 	if err != nil {
-		return net.IPNet{}, err
+		return model.IPRuleInput{}, err
+	}
+	return model.IPRuleInput{
+		Type:     RuleTypeModel(req.GetType()),
+		IPNet:    *ipNet,
+		Comment:  req.GetComment(),
+		Priority: int(req.GetPriority()),
+	}, nil
+}
+
+func FromIPRuleModel(rule model.IPRule) *pb.Rule {
+	return &pb.Rule{
+		ID:        rule.ID.String(),
+		Type:      FromRuleTypeModel(rule.Type),
+		IPNet:     rule.IPNet.String(),
+		Comment:   rule.Comment,
+		UpdatedAt: timestamppb.New(rule.UpdatedAt),
+		Source:    string(rule.Source),
+		CreatedBy: rule.CreatedBy,
+		Priority:  int32(rule.Priority),
+		Range:     rule.Range,
+	}
+}
+
+func ImportOptionsModel(req *pb.ImportRuleReq) (model.IPRuleImport, error) {
+	opts := req.GetOptions()
+	if opts == nil {
+		return model.IPRuleImport{}, ErrImportNoOptions
+	}
+	return model.IPRuleImport{Atomic: opts.GetAtomic(), DryRun: opts.GetDryRun()}, nil
+}
+
+func FromImportResultModel(res model.IPRuleImportResult) *pb.ImportResult {
+	result := &pb.ImportResult{
+		Applied: res.Applied,
+		Added:   int32(res.Added),
+		Skipped: int32(res.Skipped),
+		Failed:  int32(res.Failed),
+		Items:   make([]*pb.ImportItem, 0, len(res.Items)),
+	}
+	for _, item := range res.Items {
+		pbItem := &pb.ImportItem{
+			Rule: &pb.Rule{
+				Type:     FromRuleTypeModel(item.Input.Type),
+				IPNet:    item.Input.IPNet.String(),
+				Comment:  item.Input.Comment,
+				Priority: int32(item.Input.Priority),
+			},
+			// значения pb.ImportAction совпадают с model.ImportAction.
+			Action: pb.ImportAction(item.Action),
+		}
+		if item.Err != nil {
+			pbItem.Error = item.Err.Error()
+		}
+		result.Items = append(result.Items, pbItem)
+	}
+	return result
+}
+
+// HistorySearchModel IPNet в запросе может быть CIDR (точное совпадение) или IP (все содержащие его сети).
+func HistorySearchModel(req *pb.HistoryReq) (model.IPRuleEventSearch, error) {
+	search := model.IPRuleEventSearch{Limit: int(req.GetLimit())}
+	if search.Limit < 0 {
+		return search, ErrBadLimit
+	}
+	if req.GetIPNet() == "" {
+		return search, nil
+	}
+	ipNet, err := netlist.ParseNet(req.GetIPNet())
+	if err != nil {
+		return search, err
+	}
+	search.IPNet = &ipNet
+	search.IPNetExact = strings.Contains(req.GetIPNet(), "/")
+	return search, nil
+}
+
+func FromIPRuleEventsModel(events []model.IPRuleEvent) *pb.HistoryResult {
+	result := &pb.HistoryResult{Events: make([]*pb.RuleEvent, 0, len(events))}
+	for _, event := range events {
+		result.Events = append(result.Events, FromIPRuleEventModel(event))
+	}
+	return result
+}
+
+func FromIPRuleEventModel(event model.IPRuleEvent) *pb.RuleEvent {
+	result := &pb.RuleEvent{
+		ID:        event.ID.String(),
+		RuleID:    event.RuleID.String(),
+		Action:    string(event.Action),
+		Actor:     event.Actor,
+		IPNet:     event.IPNet.String(),
+		CreatedAt: timestamppb.New(event.CreatedAt),
+	}
+	if event.Before != nil {
+		result.Before = FromIPRuleModel(*event.Before)
+	}
+	if event.After != nil {
+		result.After = FromIPRuleModel(*event.After)
+	}
+	return result
+}
+
+func ConflictPolicyModel(policy pb.ConflictPolicy) model.ConflictPolicy {
+	switch policy { //nolint:exhaustive // ConflictDefault - политика сервиса
+	case pb.ConflictPolicy_ConflictReject:
+		return model.ConflictReject
+	case pb.ConflictPolicy_ConflictWarn:
+		return model.ConflictWarn
+	case pb.ConflictPolicy_ConflictMerge:
+		return model.ConflictMerge
+	}
+	return ""
+}
+
+func FromAddResultModel(res model.IPRuleAddResult) *pb.AddResult {
+	result := &pb.AddResult{
+		Skipped:   res.Skipped,
+		Conflicts: make([]*pb.RuleConflict, 0, len(res.Conflicts)),
+		Merged:    make([]*pb.Rule, 0, len(res.Merged)),
+	}
+	if res.Rule != nil {
+		result.Rule = FromIPRuleModel(*res.Rule)
+	}
+	for _, conflict := range res.Conflicts {
+		result.Conflicts = append(result.Conflicts, &pb.RuleConflict{
+			Rule:        FromIPRuleModel(conflict.Rule),
+			Relation:    conflict.Relation.String(),
+			Contradicts: conflict.Contradicts,
+		})
+	}
+	for _, rule := range res.Merged {
+		result.Merged = append(result.Merged, FromIPRuleModel(rule))
+	}
+	for _, rule := range res.Rules {
+		result.Rules = append(result.Rules, FromIPRuleModel(rule))
+	}
+	return result
+}
+
+func IPRuleUpdateModel(req *pb.UpdateRuleReq) (model.IPRuleUpdate, error) {
+	if req == nil {
+		return model.IPRuleUpdate{}, ErrRequestEmpty
+	}
+	_, ipNet, err := net.ParseCIDR(req.GetIPNet())
+	if err != nil {
+		return model.IPRuleUpdate{}, err
+	}
+	update := model.IPRuleUpdate{IPNet: *ipNet, Comment: req.Comment}
+	if req.GetType() != pb.ListType_ListNone {
+		typ := RuleTypeModel(req.GetType())
+		update.Type = &typ
+	}
+	if req.Priority != nil {
+		priority := int(req.GetPriority())
+		update.Priority = &priority
+	}
+	return update, nil
+}
+
+func CompactModel(req *pb.CompactReq) model.IPRuleCompact {
+	return model.IPRuleCompact{Type: RuleTypeModel(req.GetType()), DryRun: req.GetDryRun()}
+}
+
+func FromCompactResultModel(res model.IPRuleCompactResult) *pb.CompactResult {
+	result := &pb.CompactResult{
+		Applied: res.Applied,
+		Removed: make([]*pb.Rule, 0, len(res.Removed)),
+		Added:   make([]*pb.Rule, 0, len(res.Added)),
+	}
+	for _, rule := range res.Removed {
+		result.Removed = append(result.Removed, FromIPRuleModel(rule))
+	}
+	for _, rule := range res.Added {
+		pbRule := FromIPRuleModel(rule)
+		if !res.Applied {
+			// при предварительном расчете правила еще не созданы.
+			pbRule.ID, pbRule.UpdatedAt = "", nil
+		}
+		result.Added = append(result.Added, pbRule)
 	}
-	return *mask, nil
+	return result
 }
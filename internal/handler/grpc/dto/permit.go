@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"fmt"
 	"net"
 
 	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
@@ -24,6 +25,24 @@ func PermitModel(req *pb.PermitReq) (model.PermitQuery, error) {
 	return input, nil
 }
 
+func PermitBatchModel(req *pb.PermitBatchReq) (model.PermitBatch, error) {
+	if req == nil || len(req.GetItems()) == 0 {
+		return model.PermitBatch{}, ErrRequestEmpty
+	}
+	if len(req.GetItems()) > model.PermitBatchMaxSize {
+		return model.PermitBatch{}, ErrBatchSize
+	}
+	batch := model.PermitBatch{Queries: make([]model.PermitQuery, len(req.GetItems())), Atomic: req.GetAtomic()}
+	for i, item := range req.GetItems() {
+		query, err := PermitModel(item)
+		if err != nil {
+			return model.PermitBatch{}, fmt.Errorf("item %d: %w", i, err)
+		}
+		batch.Queries[i] = query
+	}
+	return batch, nil
+}
+
 func ResetLoginModel(req *pb.RstLoginReq) (model.LimitBucket, error) {
 	return model.LimitBucket{Param: model.LimitParamNameLogin, Value: req.GetLogin()}, nil
 }
@@ -43,3 +62,11 @@ func FromPermitResultModel(res model.PermitResult) *pb.PermitResult {
 	}
 	return result
 }
+
+func FromPermitBatchResultModel(results []model.PermitResult) *pb.PermitBatchResult {
+	items := make([]*pb.PermitResult, len(results))
+	for i, res := range results {
+		items[i] = FromPermitResultModel(res)
+	}
+	return &pb.PermitBatchResult{Items: items}
+}
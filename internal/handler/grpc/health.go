@@ -0,0 +1,37 @@
+package grpc
+
+import (
+	"database/sql"
+
+	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	grpcServ "github.com/vitermakov/otusgo-final/pkg/servers/grpc"
+)
+
+// Имена зависимостей в grpc.health.v1.Health.
+const (
+	HealthStorage = "storage"
+	HealthLimiter = "limiter"
+)
+
+// NewHealthChecker проверка ограничителя и базы хранилища, при хранилище в памяти db = nil.
+func NewHealthChecker(
+	cfg config.Health, db *sql.DB, limiter ratelimit.RateLimiter, logger logger.Logger,
+) (*grpcServ.HealthChecker, error) {
+	interval, err := cfg.Interval.AsDuration()
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := cfg.Timeout.AsDuration()
+	if err != nil {
+		return nil, err
+	}
+	checks := map[string]grpcServ.HealthCheck{HealthLimiter: limiter.Ping}
+	if db != nil {
+		checks[HealthStorage] = db.PingContext
+	}
+	services := []string{pb.Permit_ServiceDesc.ServiceName, pb.IPRule_ServiceDesc.ServiceName}
+	return grpcServ.NewHealthChecker(checks, services, interval, timeout, logger), nil
+}
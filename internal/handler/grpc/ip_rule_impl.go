@@ -2,13 +2,16 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 
 	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
 	"github.com/vitermakov/otusgo-final/internal/handler/grpc/dto"
 	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
 	"github.com/vitermakov/otusgo-final/internal/model"
 	"github.com/vitermakov/otusgo-final/pkg/logger"
+	"github.com/vitermakov/otusgo-final/pkg/servers"
 	"github.com/vitermakov/otusgo-final/pkg/servers/grpc/rqres"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -28,11 +31,11 @@ type IPRuleHandlerImpl struct {
 	logger   logger.Logger
 }
 
-func (ir IPRuleHandlerImpl) AddToWhiteList(ctx context.Context, req *pb.IPNet) (*emptypb.Empty, error) {
+func (ir IPRuleHandlerImpl) AddToWhiteList(ctx context.Context, req *pb.IPNet) (*pb.AddResult, error) {
 	return ir.addToList(ctx, req, model.RuleTypeAllow)
 }
 
-func (ir IPRuleHandlerImpl) AddToBlackList(ctx context.Context, req *pb.IPNet) (*emptypb.Empty, error) {
+func (ir IPRuleHandlerImpl) AddToBlackList(ctx context.Context, req *pb.IPNet) (*pb.AddResult, error) {
 	return ir.addToList(ctx, req, model.RuleTypeDeny)
 }
 
@@ -44,29 +47,142 @@ func (ir IPRuleHandlerImpl) DeleteFromBlackList(ctx context.Context, req *pb.IPN
 	return ir.removeFromList(ctx, req, model.RuleTypeDeny)
 }
 
+func (ir IPRuleHandlerImpl) UpdateRule(ctx context.Context, req *pb.UpdateRuleReq) (*pb.Rule, error) {
+	ctx = withActor(ctx)
+	update, err := dto.IPRuleUpdateModel(req)
+	if err != nil {
+		return nil, ir.handleError(fmt.Errorf("specified network is wrong: %w", err))
+	}
+	rule, err := ir.services.IPRule.Update(ctx, update)
+	if err != nil {
+		return nil, ir.handleError(fmt.Errorf("error updating rule: %w", err))
+	}
+	ir.logger.Info("rule updated successfully, now in %s", ruleNames[rule.Type])
+
+	return dto.FromIPRuleModel(*rule), nil
+}
+
+func (ir IPRuleHandlerImpl) ImportRules(stream pb.IPRule_ImportRulesServer) error {
+	ctx := withActor(stream.Context())
+	req, err := stream.Recv()
+	if err != nil {
+		return ir.handleError(fmt.Errorf("error receiving import options: %w", err))
+	}
+	imp, err := dto.ImportOptionsModel(req)
+	if err != nil {
+		return ir.handleError(fmt.Errorf("wrong import request: %w", err))
+	}
+	for num := 1; ; num++ {
+		req, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return ir.handleError(fmt.Errorf("error receiving rule #%d: %w", num, err))
+		}
+		input, err := dto.IPRuleInputModel(req.GetRule())
+		if err != nil {
+			return ir.handleError(fmt.Errorf("rule #%d is wrong: %w", num, err))
+		}
+		input.Source = model.RuleSourceImport
+		input.CreatedBy = model.ActorFromContext(ctx)
+		imp.Rules = append(imp.Rules, input)
+	}
+	res, err := ir.services.IPRule.Import(ctx, imp)
+	if err != nil {
+		return ir.handleError(fmt.Errorf("error importing rules: %w", err))
+	}
+	if res.Applied {
+		ir.logger.Info("rules imported: added %d, skipped %d, failed %d", res.Added, res.Skipped, res.Failed)
+	}
+	return stream.SendAndClose(dto.FromImportResultModel(res))
+}
+
+func (ir IPRuleHandlerImpl) ExportRules(req *pb.ExportReq, stream pb.IPRule_ExportRulesServer) error {
+	search := model.IPRuleSearch{}
+	if typ := dto.RuleTypeModel(req.GetType()); typ.Valid() {
+		search.Type = &typ
+	}
+	rules, err := ir.services.IPRule.GetList(stream.Context(), search)
+	if err != nil {
+		return ir.handleError(fmt.Errorf("error exporting rules: %w", err))
+	}
+	for _, rule := range rules {
+		if err := stream.Send(dto.FromIPRuleModel(rule)); err != nil {
+			return ir.handleError(fmt.Errorf("error sending rule: %w", err))
+		}
+	}
+	return nil
+}
+
+func (ir IPRuleHandlerImpl) GetRuleHistory(ctx context.Context, req *pb.HistoryReq) (*pb.HistoryResult, error) {
+	search, err := dto.HistorySearchModel(req)
+	if err != nil {
+		return nil, ir.handleError(fmt.Errorf("wrong history request: %w", err))
+	}
+	events, err := ir.services.IPRule.GetHistory(ctx, search)
+	if err != nil {
+		return nil, ir.handleError(fmt.Errorf("error getting rule history: %w", err))
+	}
+	return dto.FromIPRuleEventsModel(events), nil
+}
+
+func (ir IPRuleHandlerImpl) CompactRules(ctx context.Context, req *pb.CompactReq) (*pb.CompactResult, error) {
+	ctx = withActor(ctx)
+	result, err := ir.services.IPRule.Compact(ctx, dto.CompactModel(req))
+	if err != nil {
+		return nil, ir.handleError(fmt.Errorf("error compacting rules: %w", err))
+	}
+	if result.Applied {
+		ir.logger.Info("rules compacted: %d removed, %d added", len(result.Removed), len(result.Added))
+	}
+	return dto.FromCompactResultModel(result), nil
+}
+
 func (ir IPRuleHandlerImpl) addToList(
 	ctx context.Context, req *pb.IPNet, ruleType model.RuleType,
-) (*emptypb.Empty, error) {
-	ipNet, err := dto.IPNetModel(req)
+) (*pb.AddResult, error) {
+	ctx = withActor(ctx)
+	ipNet, rng, err := dto.IPNetModel(req)
 	if err != nil {
 		return nil, ir.handleError(fmt.Errorf("specified network is wrong: %w", err))
 	}
-	_, err = ir.services.IPRule.Add(ctx, model.IPRuleInput{IPNet: ipNet, Type: ruleType})
+	result, err := ir.services.IPRule.Add(ctx, model.IPRuleInput{
+		IPNet:     ipNet,
+		Range:     rng,
+		Type:      ruleType,
+		Source:    model.RuleSourceManual,
+		Comment:   req.GetComment(),
+		CreatedBy: model.ActorFromContext(ctx),
+		Priority:  int(req.GetPriority()),
+	}, dto.ConflictPolicyModel(req.GetOnConflict()))
 	if err != nil {
 		return nil, ir.handleError(fmt.Errorf("error adding network in %s: %w", ruleNames[ruleType], err))
 	}
-	ir.logger.Info("network added successfully in %s", ruleNames[ruleType])
+	if result.Skipped {
+		ir.logger.Info("network already covered in %s", ruleNames[ruleType])
+	} else {
+		ir.logger.Info("network added successfully in %s", ruleNames[ruleType])
+	}
 
-	return &emptypb.Empty{}, nil
+	return dto.FromAddResultModel(result), nil
 }
 
 func (ir IPRuleHandlerImpl) removeFromList(
 	ctx context.Context, req *pb.IPNet, ruleType model.RuleType,
 ) (*emptypb.Empty, error) {
-	ipNet, err := dto.IPNetModel(req)
+	ctx = withActor(ctx)
+	ipNet, rng, err := dto.IPNetModel(req)
 	if err != nil {
 		return nil, ir.handleError(fmt.Errorf("specified network is wrong: %w", err))
 	}
+	if rng != "" {
+		if err := ir.services.IPRule.DeleteRange(ctx, ruleType, rng); err != nil {
+			return nil, ir.handleError(fmt.Errorf("error removing range from %s: %w", ruleNames[ruleType], err))
+		}
+		ir.logger.Info("range removed successfully from %s", ruleNames[ruleType])
+		return &emptypb.Empty{}, nil
+	}
 	rule, err := ir.services.IPRule.GetByIPNet(ctx, ruleType, ipNet)
 	if err != nil {
 		ir.logger.Error(err.Error())
@@ -84,6 +200,15 @@ func (ir IPRuleHandlerImpl) removeFromList(
 	return &emptypb.Empty{}, nil
 }
 
+// withActor логин авторизованного пользователя API - автор изменений запроса,
+// без авторизации автор не задается.
+func withActor(ctx context.Context) context.Context {
+	if user, ok := servers.UserFromContext(ctx); ok {
+		return model.ContextWithActor(ctx, user.Login)
+	}
+	return ctx
+}
+
 func (ir IPRuleHandlerImpl) handleError(err error) error {
 	ir.logger.Error(err.Error())
 	s := rqres.FromError(err)
@@ -2,6 +2,8 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net"
 	"strconv"
 	"testing"
@@ -61,7 +63,8 @@ func (is *IPRuleSuiteTest) SetupTest() {
 
 	services := deps.NewServices(depends, cfg)
 
-	grpcServer, closeFn := NewHandledServer(cfg.API, services, depends)
+	grpcServer, closeFn, err := NewHandledServer(cfg.API, services, depends)
+	is.Suite.Require().NoError(err)
 	is.closer.Register("GRPC Server", closeFn)
 
 	go func() {
@@ -91,7 +94,19 @@ func (is *IPRuleSuiteTest) TearDownTest() {
 }
 
 func (is *IPRuleSuiteTest) TestComplex() {
-	type actionFn func(ctx context.Context, in *pb.IPNet, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	type actionFn func(ctx context.Context, in *pb.IPNet) error
+	add := func(fn func(context.Context, *pb.IPNet, ...grpc.CallOption) (*pb.AddResult, error)) actionFn {
+		return func(ctx context.Context, in *pb.IPNet) error {
+			_, err := fn(ctx, in)
+			return err
+		}
+	}
+	remove := func(fn func(context.Context, *pb.IPNet, ...grpc.CallOption) (*emptypb.Empty, error)) actionFn {
+		return func(ctx context.Context, in *pb.IPNet) error {
+			_, err := fn(ctx, in)
+			return err
+		}
+	}
 
 	badNet := &pb.IPNet{IPNet: "292.168.1.0/24"}
 	goodNet := &pb.IPNet{IPNet: "192.168.1.0/24"}
@@ -105,52 +120,52 @@ func (is *IPRuleSuiteTest) TestComplex() {
 		{
 			name:         "white wrong net",
 			expectedCode: codes.InvalidArgument,
-			actionFn:     is.client.AddToWhiteList,
+			actionFn:     add(is.client.AddToWhiteList),
 			arg:          badNet,
 		}, {
 			name:         "white ok",
 			expectedCode: codes.OK,
-			actionFn:     is.client.AddToWhiteList,
+			actionFn:     add(is.client.AddToWhiteList),
 			arg:          goodNet,
 		}, {
 			name:         "white duplicate",
 			expectedCode: codes.InvalidArgument,
-			actionFn:     is.client.AddToWhiteList,
+			actionFn:     add(is.client.AddToWhiteList),
 			arg:          goodNet,
 		}, {
 			name:         "white remove ok",
 			expectedCode: codes.OK,
-			actionFn:     is.client.DeleteFromWhiteList,
+			actionFn:     remove(is.client.DeleteFromWhiteList),
 			arg:          goodNet,
 		}, {
 			name:         "white remove not found",
 			expectedCode: codes.NotFound,
-			actionFn:     is.client.DeleteFromWhiteList,
+			actionFn:     remove(is.client.DeleteFromWhiteList),
 			arg:          goodNet,
 		}, {
 			name:         "black wrong net",
 			expectedCode: codes.InvalidArgument,
-			actionFn:     is.client.AddToBlackList,
+			actionFn:     add(is.client.AddToBlackList),
 			arg:          badNet,
 		}, {
 			name:         "black ok",
 			expectedCode: codes.OK,
-			actionFn:     is.client.AddToBlackList,
+			actionFn:     add(is.client.AddToBlackList),
 			arg:          goodNet,
 		}, {
 			name:         "black duplicate",
 			expectedCode: codes.InvalidArgument,
-			actionFn:     is.client.AddToBlackList,
+			actionFn:     add(is.client.AddToBlackList),
 			arg:          goodNet,
 		}, {
 			name:         "black remove ok",
 			expectedCode: codes.OK,
-			actionFn:     is.client.DeleteFromBlackList,
+			actionFn:     remove(is.client.DeleteFromBlackList),
 			arg:          goodNet,
 		}, {
 			name:         "black remove not found",
 			expectedCode: codes.NotFound,
-			actionFn:     is.client.DeleteFromBlackList,
+			actionFn:     remove(is.client.DeleteFromBlackList),
 			arg:          goodNet,
 		},
 	}
@@ -161,7 +176,7 @@ func (is *IPRuleSuiteTest) TestComplex() {
 			defer cancel()
 
 			// неверный адрес сети
-			_, err := tc.actionFn(ctx, tc.arg)
+			err := tc.actionFn(ctx, tc.arg)
 			e, ok := status.FromError(err)
 
 			is.Suite.True(ok, "error is not status")
@@ -170,6 +185,230 @@ func (is *IPRuleSuiteTest) TestComplex() {
 	}
 }
 
+func (is *IPRuleSuiteTest) TestImportExport() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	_, err := is.client.AddToWhiteList(ctx, &pb.IPNet{IPNet: "10.10.0.0/16", Comment: "office"})
+	is.Suite.Require().NoError(err)
+
+	importRules := func(opts *pb.ImportOptions, rules ...*pb.Rule) *pb.ImportResult {
+		stream, err := is.client.ImportRules(ctx)
+		is.Suite.Require().NoError(err)
+		is.Suite.Require().NoError(stream.Send(&pb.ImportRuleReq{Payload: &pb.ImportRuleReq_Options{Options: opts}}))
+		for _, rule := range rules {
+			is.Suite.Require().NoError(stream.Send(&pb.ImportRuleReq{Payload: &pb.ImportRuleReq_Rule{Rule: rule}}))
+		}
+		res, err := stream.CloseAndRecv()
+		is.Suite.Require().NoError(err)
+		return res
+	}
+	exportRules := func(typ pb.ListType) []*pb.Rule {
+		stream, err := is.client.ExportRules(ctx, &pb.ExportReq{Type: typ})
+		is.Suite.Require().NoError(err)
+		var rules []*pb.Rule
+		for {
+			rule, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			is.Suite.Require().NoError(err)
+			rules = append(rules, rule)
+		}
+		return rules
+	}
+
+	rules := []*pb.Rule{
+		{Type: pb.ListType_ListWhite, IPNet: "10.10.0.0/16"},
+		{Type: pb.ListType_ListBlack, IPNet: "10.20.0.0/16", Comment: "scanner"},
+		{Type: pb.ListType_ListBlack, IPNet: "10.30.0.0/16"},
+	}
+	res := importRules(&pb.ImportOptions{DryRun: true}, rules...)
+	is.Suite.Require().False(res.Applied)
+	is.Suite.Require().Equal(int32(2), res.Added)
+	is.Suite.Require().Equal(int32(1), res.Skipped)
+	is.Suite.Require().Empty(exportRules(pb.ListType_ListBlack))
+
+	// сеть уже в white-листе: в режиме atomic не добавляется ничего.
+	conflict := &pb.Rule{Type: pb.ListType_ListBlack, IPNet: "10.10.0.0/16"}
+	res = importRules(&pb.ImportOptions{Atomic: true}, append(rules, conflict)...)
+	is.Suite.Require().False(res.Applied)
+	is.Suite.Require().Equal(int32(1), res.Failed)
+	is.Suite.Require().Equal(pb.ImportAction_ImportError, res.Items[3].Action)
+	is.Suite.Require().Empty(exportRules(pb.ListType_ListBlack))
+
+	res = importRules(&pb.ImportOptions{Atomic: true}, rules...)
+	is.Suite.Require().True(res.Applied)
+	is.Suite.Require().Len(exportRules(pb.ListType_ListBlack), 2)
+	is.Suite.Require().Len(exportRules(pb.ListType_ListNone), 3)
+
+	// метаданные правил.
+	for _, rule := range exportRules(pb.ListType_ListNone) {
+		switch rule.IPNet {
+		case "10.10.0.0/16":
+			is.Suite.Require().Equal("manual", rule.Source)
+			is.Suite.Require().Equal("office", rule.Comment)
+		case "10.20.0.0/16":
+			is.Suite.Require().Equal("import", rule.Source)
+			is.Suite.Require().Equal("scanner", rule.Comment)
+		default:
+			is.Suite.Require().Equal("import", rule.Source)
+			is.Suite.Require().Empty(rule.Comment)
+		}
+	}
+
+	// без atomic ошибочные правила пропускаются, остальные добавляются.
+	res = importRules(&pb.ImportOptions{}, conflict, &pb.Rule{Type: pb.ListType_ListWhite, IPNet: "10.40.0.0/16"})
+	is.Suite.Require().True(res.Applied)
+	is.Suite.Require().Equal(int32(1), res.Added)
+	is.Suite.Require().Equal(int32(1), res.Failed)
+	is.Suite.Require().Len(exportRules(pb.ListType_ListWhite), 2)
+
+	// первым сообщением должны идти параметры.
+	stream, err := is.client.ImportRules(ctx)
+	is.Suite.Require().NoError(err)
+	is.Suite.Require().NoError(stream.Send(&pb.ImportRuleReq{Payload: &pb.ImportRuleReq_Rule{Rule: rules[0]}}))
+	_, err = stream.CloseAndRecv()
+	is.Suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func (is *IPRuleSuiteTest) TestHistory() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	_, err := is.client.AddToWhiteList(ctx, &pb.IPNet{IPNet: "10.50.0.0/16", Comment: "partner"})
+	is.Suite.Require().NoError(err)
+	_, err = is.client.AddToBlackList(ctx, &pb.IPNet{IPNet: "10.60.0.0/16"})
+	is.Suite.Require().NoError(err)
+	_, err = is.client.DeleteFromWhiteList(ctx, &pb.IPNet{IPNet: "10.50.0.0/16"})
+	is.Suite.Require().NoError(err)
+
+	res, err := is.client.GetRuleHistory(ctx, &pb.HistoryReq{IPNet: "10.50.0.0/16"})
+	is.Suite.Require().NoError(err)
+	is.Suite.Require().Len(res.Events, 2)
+	// от новых к старым.
+	is.Suite.Require().Equal("delete", res.Events[0].Action)
+	is.Suite.Require().Nil(res.Events[0].After)
+	is.Suite.Require().Equal("partner", res.Events[0].Before.Comment)
+	is.Suite.Require().Equal("add", res.Events[1].Action)
+	is.Suite.Require().Nil(res.Events[1].Before)
+	is.Suite.Require().Equal(pb.ListType_ListWhite, res.Events[1].After.Type)
+	is.Suite.Require().Equal(res.Events[0].RuleID, res.Events[1].RuleID)
+
+	// по IP - история всех содержащих его сетей.
+	res, err = is.client.GetRuleHistory(ctx, &pb.HistoryReq{IPNet: "10.60.1.1"})
+	is.Suite.Require().NoError(err)
+	is.Suite.Require().Len(res.Events, 1)
+	is.Suite.Require().Equal("10.60.0.0/16", res.Events[0].IPNet)
+
+	res, err = is.client.GetRuleHistory(ctx, &pb.HistoryReq{Limit: 1})
+	is.Suite.Require().NoError(err)
+	is.Suite.Require().Len(res.Events, 1)
+	is.Suite.Require().Equal("delete", res.Events[0].Action)
+
+	_, err = is.client.GetRuleHistory(ctx, &pb.HistoryReq{IPNet: "10.60.1"})
+	is.Suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func (is *IPRuleSuiteTest) TestConflicts() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	_, err := is.client.AddToWhiteList(ctx, &pb.IPNet{IPNet: "172.16.1.0/24"})
+	is.Suite.Require().NoError(err)
+
+	// противоречие: black покрывает white.
+	_, err = is.client.AddToBlackList(ctx, &pb.IPNet{
+		IPNet: "172.16.0.0/16", OnConflict: pb.ConflictPolicy_ConflictReject,
+	})
+	is.Suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+	is.Suite.Require().Contains(status.Convert(err).Message(), "covers allow rule 172.16.1.0/24 (contradicts)")
+
+	res, err := is.client.AddToBlackList(ctx, &pb.IPNet{IPNet: "172.16.0.0/16"})
+	is.Suite.Require().NoError(err)
+	is.Suite.Require().Len(res.Conflicts, 1)
+	is.Suite.Require().Equal("covered", res.Conflicts[0].Relation)
+	is.Suite.Require().True(res.Conflicts[0].Contradicts)
+
+	// избыточное правило того же списка при merge не добавляется.
+	res, err = is.client.AddToBlackList(ctx, &pb.IPNet{
+		IPNet: "172.16.200.0/24", OnConflict: pb.ConflictPolicy_ConflictMerge,
+	})
+	is.Suite.Require().NoError(err)
+	is.Suite.Require().True(res.Skipped)
+	is.Suite.Require().Equal("172.16.0.0/16", res.Rule.IPNet)
+
+	// покрываемые правила того же списка при merge удаляются.
+	res, err = is.client.AddToWhiteList(ctx, &pb.IPNet{
+		IPNet: "172.16.1.0/23", OnConflict: pb.ConflictPolicy_ConflictMerge,
+	})
+	is.Suite.Require().NoError(err)
+	is.Suite.Require().False(res.Skipped)
+	is.Suite.Require().Len(res.Merged, 1)
+	is.Suite.Require().Equal("172.16.1.0/24", res.Merged[0].IPNet)
+	is.Suite.Require().Len(res.Conflicts, 2)
+}
+
+func (is *IPRuleSuiteTest) TestUpdateRule() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	_, err := is.client.AddToWhiteList(ctx, &pb.IPNet{IPNet: "10.70.0.0/16", Comment: "partner", Priority: 3})
+	is.Suite.Require().NoError(err)
+
+	rule, err := is.client.UpdateRule(ctx, &pb.UpdateRuleReq{IPNet: "10.70.0.0/16", Type: pb.ListType_ListBlack})
+	is.Suite.Require().NoError(err)
+	is.Suite.Require().Equal(pb.ListType_ListBlack, rule.Type)
+	// не переданные поля сохраняются.
+	is.Suite.Require().Equal("partner", rule.Comment)
+	is.Suite.Require().Equal(int32(3), rule.Priority)
+
+	history, err := is.client.GetRuleHistory(ctx, &pb.HistoryReq{IPNet: "10.70.0.0/16"})
+	is.Suite.Require().NoError(err)
+	is.Suite.Require().Len(history.Events, 2)
+	is.Suite.Require().Equal("update", history.Events[0].Action)
+	is.Suite.Require().Equal(pb.ListType_ListWhite, history.Events[0].Before.Type)
+	is.Suite.Require().Equal(pb.ListType_ListBlack, history.Events[0].After.Type)
+
+	_, err = is.client.UpdateRule(ctx, &pb.UpdateRuleReq{IPNet: "10.71.0.0/16", Type: pb.ListType_ListBlack})
+	is.Suite.Require().Equal(codes.NotFound, status.Code(err))
+	_, err = is.client.UpdateRule(ctx, &pb.UpdateRuleReq{IPNet: "10.70.0.0/16"})
+	is.Suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func (is *IPRuleSuiteTest) TestRanges() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	res, err := is.client.AddToWhiteList(ctx, &pb.IPNet{IPNet: "203.0.113.10-203.0.113.77", Comment: "vendor"})
+	is.Suite.Require().NoError(err)
+	is.Suite.Require().Nil(res.Rule)
+	is.Suite.Require().Len(res.Rules, 7)
+	for _, rule := range res.Rules {
+		is.Suite.Require().Equal("203.0.113.10-203.0.113.77", rule.Range)
+		is.Suite.Require().Equal("vendor", rule.Comment)
+	}
+
+	// повторное добавление того же диапазона и сети из него - дубликаты.
+	_, err = is.client.AddToWhiteList(ctx, &pb.IPNet{IPNet: "203.0.113.10 - 203.0.113.77"})
+	is.Suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+	_, err = is.client.AddToBlackList(ctx, &pb.IPNet{IPNet: "203.0.113.16/28"})
+	is.Suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+
+	// диапазон, совпадающий с сетью, хранится как обычная сеть.
+	res, err = is.client.AddToBlackList(ctx, &pb.IPNet{IPNet: "198.51.100.0-198.51.100.255"})
+	is.Suite.Require().NoError(err)
+	is.Suite.Require().Equal("198.51.100.0/24", res.Rule.IPNet)
+	is.Suite.Require().Empty(res.Rule.Range)
+
+	_, err = is.client.DeleteFromWhiteList(ctx, &pb.IPNet{IPNet: "203.0.113.10-203.0.113.77"})
+	is.Suite.Require().NoError(err)
+	_, err = is.client.DeleteFromWhiteList(ctx, &pb.IPNet{IPNet: "203.0.113.10-203.0.113.77"})
+	is.Suite.Require().Equal(codes.NotFound, status.Code(err))
+	_, err = is.client.AddToWhiteList(ctx, &pb.IPNet{IPNet: "203.0.113.77-203.0.113.10"})
+	is.Suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+}
+
 func TestIPRuleApi(t *testing.T) {
 	suite.Run(t, new(IPRuleSuiteTest))
 }
@@ -13,6 +13,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
@@ -22,31 +23,1038 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ListType int32
+
+const (
+	ListType_ListNone  ListType = 0
+	ListType_ListWhite ListType = 1
+	ListType_ListBlack ListType = 2
+)
+
+// Enum value maps for ListType.
+var (
+	ListType_name = map[int32]string{
+		0: "ListNone",
+		1: "ListWhite",
+		2: "ListBlack",
+	}
+	ListType_value = map[string]int32{
+		"ListNone":  0,
+		"ListWhite": 1,
+		"ListBlack": 2,
+	}
+)
+
+func (x ListType) Enum() *ListType {
+	p := new(ListType)
+	*p = x
+	return p
+}
+
+func (x ListType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ListType) Descriptor() protoreflect.EnumDescriptor {
+	return file_IPRuleService_proto_enumTypes[0].Descriptor()
+}
+
+func (ListType) Type() protoreflect.EnumType {
+	return &file_IPRuleService_proto_enumTypes[0]
+}
+
+func (x ListType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ListType.Descriptor instead.
+func (ListType) EnumDescriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{0}
+}
+
+type ConflictPolicy int32
+
+const (
+	ConflictPolicy_ConflictDefault ConflictPolicy = 0
+	ConflictPolicy_ConflictReject  ConflictPolicy = 1
+	ConflictPolicy_ConflictWarn    ConflictPolicy = 2
+	ConflictPolicy_ConflictMerge   ConflictPolicy = 3
+)
+
+// Enum value maps for ConflictPolicy.
+var (
+	ConflictPolicy_name = map[int32]string{
+		0: "ConflictDefault",
+		1: "ConflictReject",
+		2: "ConflictWarn",
+		3: "ConflictMerge",
+	}
+	ConflictPolicy_value = map[string]int32{
+		"ConflictDefault": 0,
+		"ConflictReject":  1,
+		"ConflictWarn":    2,
+		"ConflictMerge":   3,
+	}
+)
+
+func (x ConflictPolicy) Enum() *ConflictPolicy {
+	p := new(ConflictPolicy)
+	*p = x
+	return p
+}
+
+func (x ConflictPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ConflictPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_IPRuleService_proto_enumTypes[1].Descriptor()
+}
+
+func (ConflictPolicy) Type() protoreflect.EnumType {
+	return &file_IPRuleService_proto_enumTypes[1]
+}
+
+func (x ConflictPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ConflictPolicy.Descriptor instead.
+func (ConflictPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{1}
+}
+
+type ImportAction int32
+
+const (
+	ImportAction_ImportAdd    ImportAction = 0
+	ImportAction_ImportExists ImportAction = 1
+	ImportAction_ImportError  ImportAction = 2
+)
+
+// Enum value maps for ImportAction.
+var (
+	ImportAction_name = map[int32]string{
+		0: "ImportAdd",
+		1: "ImportExists",
+		2: "ImportError",
+	}
+	ImportAction_value = map[string]int32{
+		"ImportAdd":    0,
+		"ImportExists": 1,
+		"ImportError":  2,
+	}
+)
+
+func (x ImportAction) Enum() *ImportAction {
+	p := new(ImportAction)
+	*p = x
+	return p
+}
+
+func (x ImportAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImportAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_IPRuleService_proto_enumTypes[2].Descriptor()
+}
+
+func (ImportAction) Type() protoreflect.EnumType {
+	return &file_IPRuleService_proto_enumTypes[2]
+}
+
+func (x ImportAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImportAction.Descriptor instead.
+func (ImportAction) EnumDescriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{2}
+}
+
 type IPNet struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
+	// IPNet сеть CIDR или диапазон start-end, например 203.0.113.10-203.0.113.77.
+	IPNet   string `protobuf:"bytes,1,opt,name=IPNet,proto3" json:"IPNet,omitempty"`
+	Comment string `protobuf:"bytes,2,opt,name=Comment,proto3" json:"Comment,omitempty"`
+	// Priority учитывается при порядке применения правил priority, больше - важнее.
+	Priority int32 `protobuf:"varint,3,opt,name=Priority,proto3" json:"Priority,omitempty"`
+	// OnConflict только для добавления, ConflictDefault - политика из конфигурации сервиса.
+	OnConflict ConflictPolicy `protobuf:"varint,4,opt,name=OnConflict,proto3,enum=api.ConflictPolicy" json:"OnConflict,omitempty"`
+}
+
+func (x *IPNet) Reset() {
+	*x = IPNet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IPNet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IPNet) ProtoMessage() {}
+
+func (x *IPNet) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IPNet.ProtoReflect.Descriptor instead.
+func (*IPNet) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IPNet) GetIPNet() string {
+	if x != nil {
+		return x.IPNet
+	}
+	return ""
+}
+
+func (x *IPNet) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *IPNet) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *IPNet) GetOnConflict() ConflictPolicy {
+	if x != nil {
+		return x.OnConflict
+	}
+	return ConflictPolicy_ConflictDefault
+}
+
+type RuleConflict struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rule *Rule `protobuf:"bytes,1,opt,name=Rule,proto3" json:"Rule,omitempty"`
+	// Relation covering - правило покрывает добавляемую сеть, covered - покрывается ею.
+	Relation string `protobuf:"bytes,2,opt,name=Relation,proto3" json:"Relation,omitempty"`
+	// Contradicts правило из другого списка.
+	Contradicts bool `protobuf:"varint,3,opt,name=Contradicts,proto3" json:"Contradicts,omitempty"`
+}
+
+func (x *RuleConflict) Reset() {
+	*x = RuleConflict{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleConflict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleConflict) ProtoMessage() {}
+
+func (x *RuleConflict) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleConflict.ProtoReflect.Descriptor instead.
+func (*RuleConflict) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RuleConflict) GetRule() *Rule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+func (x *RuleConflict) GetRelation() string {
+	if x != nil {
+		return x.Relation
+	}
+	return ""
+}
+
+func (x *RuleConflict) GetContradicts() bool {
+	if x != nil {
+		return x.Contradicts
+	}
+	return false
+}
+
+type AddResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Rule добавленное правило, при Skipped - уже покрывающее его правило того же списка.
+	Rule      *Rule           `protobuf:"bytes,1,opt,name=Rule,proto3" json:"Rule,omitempty"`
+	Conflicts []*RuleConflict `protobuf:"bytes,2,rep,name=Conflicts,proto3" json:"Conflicts,omitempty"`
+	// Merged правила, удаленные как покрываемые добавленным (ConflictMerge).
+	Merged  []*Rule `protobuf:"bytes,3,rep,name=Merged,proto3" json:"Merged,omitempty"`
+	Skipped bool    `protobuf:"varint,4,opt,name=Skipped,proto3" json:"Skipped,omitempty"`
+	// Rules сети, на которые разбит добавленный диапазон.
+	Rules []*Rule `protobuf:"bytes,5,rep,name=Rules,proto3" json:"Rules,omitempty"`
+}
+
+func (x *AddResult) Reset() {
+	*x = AddResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddResult) ProtoMessage() {}
+
+func (x *AddResult) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddResult.ProtoReflect.Descriptor instead.
+func (*AddResult) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AddResult) GetRule() *Rule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+func (x *AddResult) GetConflicts() []*RuleConflict {
+	if x != nil {
+		return x.Conflicts
+	}
+	return nil
+}
+
+func (x *AddResult) GetMerged() []*Rule {
+	if x != nil {
+		return x.Merged
+	}
+	return nil
+}
+
+func (x *AddResult) GetSkipped() bool {
+	if x != nil {
+		return x.Skipped
+	}
+	return false
+}
+
+func (x *AddResult) GetRules() []*Rule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type Rule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID        string                 `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Type      ListType               `protobuf:"varint,2,opt,name=Type,proto3,enum=api.ListType" json:"Type,omitempty"`
+	IPNet     string                 `protobuf:"bytes,3,opt,name=IPNet,proto3" json:"IPNet,omitempty"`
+	Comment   string                 `protobuf:"bytes,4,opt,name=Comment,proto3" json:"Comment,omitempty"`
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=UpdatedAt,proto3" json:"UpdatedAt,omitempty"`
+	// Source manual, feed, auto-ban или import
+	Source    string `protobuf:"bytes,6,opt,name=Source,proto3" json:"Source,omitempty"`
+	CreatedBy string `protobuf:"bytes,7,opt,name=CreatedBy,proto3" json:"CreatedBy,omitempty"`
+	Priority  int32  `protobuf:"varint,8,opt,name=Priority,proto3" json:"Priority,omitempty"`
+	// Range исходный диапазон, если правило получено его разбиением на CIDR.
+	Range string `protobuf:"bytes,9,opt,name=Range,proto3" json:"Range,omitempty"`
+}
+
+func (x *Rule) Reset() {
+	*x = Rule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Rule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rule) ProtoMessage() {}
+
+func (x *Rule) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rule.ProtoReflect.Descriptor instead.
+func (*Rule) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Rule) GetID() string {
+	if x != nil {
+		return x.ID
+	}
+	return ""
+}
+
+func (x *Rule) GetType() ListType {
+	if x != nil {
+		return x.Type
+	}
+	return ListType_ListNone
+}
+
+func (x *Rule) GetIPNet() string {
+	if x != nil {
+		return x.IPNet
+	}
+	return ""
+}
+
+func (x *Rule) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *Rule) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Rule) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Rule) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *Rule) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Rule) GetRange() string {
+	if x != nil {
+		return x.Range
+	}
+	return ""
+}
+
+type UpdateRuleReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// IPNet сеть существующего правила (точное совпадение).
+	IPNet string `protobuf:"bytes,1,opt,name=IPNet,proto3" json:"IPNet,omitempty"`
+	// Type новый список, ListNone - не менять.
+	Type     ListType `protobuf:"varint,2,opt,name=Type,proto3,enum=api.ListType" json:"Type,omitempty"`
+	Comment  *string  `protobuf:"bytes,3,opt,name=Comment,proto3,oneof" json:"Comment,omitempty"`
+	Priority *int32   `protobuf:"varint,4,opt,name=Priority,proto3,oneof" json:"Priority,omitempty"`
+}
+
+func (x *UpdateRuleReq) Reset() {
+	*x = UpdateRuleReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateRuleReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRuleReq) ProtoMessage() {}
+
+func (x *UpdateRuleReq) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRuleReq.ProtoReflect.Descriptor instead.
+func (*UpdateRuleReq) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateRuleReq) GetIPNet() string {
+	if x != nil {
+		return x.IPNet
+	}
+	return ""
+}
+
+func (x *UpdateRuleReq) GetType() ListType {
+	if x != nil {
+		return x.Type
+	}
+	return ListType_ListNone
+}
+
+func (x *UpdateRuleReq) GetComment() string {
+	if x != nil && x.Comment != nil {
+		return *x.Comment
+	}
+	return ""
+}
+
+func (x *UpdateRuleReq) GetPriority() int32 {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return 0
+}
+
+type ImportOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Atomic все или ничего: при хотя бы одной ошибке ни одно правило не добавляется.
+	Atomic bool `protobuf:"varint,1,opt,name=Atomic,proto3" json:"Atomic,omitempty"`
+	// DryRun только расчет изменений, без записи.
+	DryRun bool `protobuf:"varint,2,opt,name=DryRun,proto3" json:"DryRun,omitempty"`
+}
+
+func (x *ImportOptions) Reset() {
+	*x = ImportOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportOptions) ProtoMessage() {}
+
+func (x *ImportOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportOptions.ProtoReflect.Descriptor instead.
+func (*ImportOptions) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ImportOptions) GetAtomic() bool {
+	if x != nil {
+		return x.Atomic
+	}
+	return false
+}
+
+func (x *ImportOptions) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type ImportRuleReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Payload:
+	//	*ImportRuleReq_Options
+	//	*ImportRuleReq_Rule
+	Payload isImportRuleReq_Payload `protobuf_oneof:"Payload"`
+}
+
+func (x *ImportRuleReq) Reset() {
+	*x = ImportRuleReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportRuleReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRuleReq) ProtoMessage() {}
+
+func (x *ImportRuleReq) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRuleReq.ProtoReflect.Descriptor instead.
+func (*ImportRuleReq) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{6}
+}
+
+func (m *ImportRuleReq) GetPayload() isImportRuleReq_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *ImportRuleReq) GetOptions() *ImportOptions {
+	if x, ok := x.GetPayload().(*ImportRuleReq_Options); ok {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ImportRuleReq) GetRule() *Rule {
+	if x, ok := x.GetPayload().(*ImportRuleReq_Rule); ok {
+		return x.Rule
+	}
+	return nil
+}
+
+type isImportRuleReq_Payload interface {
+	isImportRuleReq_Payload()
+}
+
+type ImportRuleReq_Options struct {
+	Options *ImportOptions `protobuf:"bytes,1,opt,name=Options,proto3,oneof"`
+}
+
+type ImportRuleReq_Rule struct {
+	Rule *Rule `protobuf:"bytes,2,opt,name=Rule,proto3,oneof"`
+}
+
+func (*ImportRuleReq_Options) isImportRuleReq_Payload() {}
+
+func (*ImportRuleReq_Rule) isImportRuleReq_Payload() {}
+
+type ImportItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rule   *Rule        `protobuf:"bytes,1,opt,name=Rule,proto3" json:"Rule,omitempty"`
+	Action ImportAction `protobuf:"varint,2,opt,name=Action,proto3,enum=api.ImportAction" json:"Action,omitempty"`
+	Error  string       `protobuf:"bytes,3,opt,name=Error,proto3" json:"Error,omitempty"`
+}
+
+func (x *ImportItem) Reset() {
+	*x = ImportItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportItem) ProtoMessage() {}
+
+func (x *ImportItem) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportItem.ProtoReflect.Descriptor instead.
+func (*ImportItem) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ImportItem) GetRule() *Rule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+func (x *ImportItem) GetAction() ImportAction {
+	if x != nil {
+		return x.Action
+	}
+	return ImportAction_ImportAdd
+}
+
+func (x *ImportItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ImportResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Applied bool          `protobuf:"varint,1,opt,name=Applied,proto3" json:"Applied,omitempty"`
+	Added   int32         `protobuf:"varint,2,opt,name=Added,proto3" json:"Added,omitempty"`
+	Skipped int32         `protobuf:"varint,3,opt,name=Skipped,proto3" json:"Skipped,omitempty"`
+	Failed  int32         `protobuf:"varint,4,opt,name=Failed,proto3" json:"Failed,omitempty"`
+	Items   []*ImportItem `protobuf:"bytes,5,rep,name=Items,proto3" json:"Items,omitempty"`
+}
+
+func (x *ImportResult) Reset() {
+	*x = ImportResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportResult) ProtoMessage() {}
+
+func (x *ImportResult) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportResult.ProtoReflect.Descriptor instead.
+func (*ImportResult) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ImportResult) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+func (x *ImportResult) GetAdded() int32 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *ImportResult) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *ImportResult) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *ImportResult) GetItems() []*ImportItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type ExportReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Type ListNone - оба списка.
+	Type ListType `protobuf:"varint,1,opt,name=Type,proto3,enum=api.ListType" json:"Type,omitempty"`
+}
+
+func (x *ExportReq) Reset() {
+	*x = ExportReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportReq) ProtoMessage() {}
+
+func (x *ExportReq) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportReq.ProtoReflect.Descriptor instead.
+func (*ExportReq) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ExportReq) GetType() ListType {
+	if x != nil {
+		return x.Type
+	}
+	return ListType_ListNone
+}
+
+type CompactReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Type ListNone - оба списка.
+	Type ListType `protobuf:"varint,1,opt,name=Type,proto3,enum=api.ListType" json:"Type,omitempty"`
+	// DryRun только расчет изменений, без записи.
+	DryRun bool `protobuf:"varint,2,opt,name=DryRun,proto3" json:"DryRun,omitempty"`
+}
+
+func (x *CompactReq) Reset() {
+	*x = CompactReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompactReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompactReq) ProtoMessage() {}
+
+func (x *CompactReq) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompactReq.ProtoReflect.Descriptor instead.
+func (*CompactReq) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CompactReq) GetType() ListType {
+	if x != nil {
+		return x.Type
+	}
+	return ListType_ListNone
+}
+
+func (x *CompactReq) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type CompactResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Applied bool    `protobuf:"varint,1,opt,name=Applied,proto3" json:"Applied,omitempty"`
+	Removed []*Rule `protobuf:"bytes,2,rep,name=Removed,proto3" json:"Removed,omitempty"`
+	Added   []*Rule `protobuf:"bytes,3,rep,name=Added,proto3" json:"Added,omitempty"`
+}
+
+func (x *CompactResult) Reset() {
+	*x = CompactResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompactResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompactResult) ProtoMessage() {}
+
+func (x *CompactResult) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompactResult.ProtoReflect.Descriptor instead.
+func (*CompactResult) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CompactResult) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+func (x *CompactResult) GetRemoved() []*Rule {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *CompactResult) GetAdded() []*Rule {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+type HistoryReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// IPNet CIDR - история этой сети, IP - всех сетей, его содержащих, пусто - вся история.
 	IPNet string `protobuf:"bytes,1,opt,name=IPNet,proto3" json:"IPNet,omitempty"`
+	// Limit количество последних записей, 0 - все.
+	Limit int32 `protobuf:"varint,2,opt,name=Limit,proto3" json:"Limit,omitempty"`
 }
 
-func (x *IPNet) Reset() {
-	*x = IPNet{}
+func (x *HistoryReq) Reset() {
+	*x = HistoryReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_IPRuleService_proto_msgTypes[0]
+		mi := &file_IPRuleService_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *IPNet) String() string {
+func (x *HistoryReq) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IPNet) ProtoMessage() {}
+func (*HistoryReq) ProtoMessage() {}
 
-func (x *IPNet) ProtoReflect() protoreflect.Message {
-	mi := &file_IPRuleService_proto_msgTypes[0]
+func (x *HistoryReq) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -57,44 +1065,351 @@ func (x *IPNet) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IPNet.ProtoReflect.Descriptor instead.
-func (*IPNet) Descriptor() ([]byte, []int) {
-	return file_IPRuleService_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use HistoryReq.ProtoReflect.Descriptor instead.
+func (*HistoryReq) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *IPNet) GetIPNet() string {
+func (x *HistoryReq) GetIPNet() string {
+	if x != nil {
+		return x.IPNet
+	}
+	return ""
+}
+
+func (x *HistoryReq) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type RuleEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID     string `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	RuleID string `protobuf:"bytes,2,opt,name=RuleID,proto3" json:"RuleID,omitempty"`
+	// Action add, delete или update
+	Action    string                 `protobuf:"bytes,3,opt,name=Action,proto3" json:"Action,omitempty"`
+	Actor     string                 `protobuf:"bytes,4,opt,name=Actor,proto3" json:"Actor,omitempty"`
+	IPNet     string                 `protobuf:"bytes,5,opt,name=IPNet,proto3" json:"IPNet,omitempty"`
+	Before    *Rule                  `protobuf:"bytes,6,opt,name=Before,proto3" json:"Before,omitempty"`
+	After     *Rule                  `protobuf:"bytes,7,opt,name=After,proto3" json:"After,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=CreatedAt,proto3" json:"CreatedAt,omitempty"`
+}
+
+func (x *RuleEvent) Reset() {
+	*x = RuleEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleEvent) ProtoMessage() {}
+
+func (x *RuleEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleEvent.ProtoReflect.Descriptor instead.
+func (*RuleEvent) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RuleEvent) GetID() string {
+	if x != nil {
+		return x.ID
+	}
+	return ""
+}
+
+func (x *RuleEvent) GetRuleID() string {
+	if x != nil {
+		return x.RuleID
+	}
+	return ""
+}
+
+func (x *RuleEvent) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *RuleEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *RuleEvent) GetIPNet() string {
 	if x != nil {
 		return x.IPNet
 	}
 	return ""
 }
 
+func (x *RuleEvent) GetBefore() *Rule {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
+
+func (x *RuleEvent) GetAfter() *Rule {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+func (x *RuleEvent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type HistoryResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Events []*RuleEvent `protobuf:"bytes,1,rep,name=Events,proto3" json:"Events,omitempty"`
+}
+
+func (x *HistoryResult) Reset() {
+	*x = HistoryResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_IPRuleService_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HistoryResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryResult) ProtoMessage() {}
+
+func (x *HistoryResult) ProtoReflect() protoreflect.Message {
+	mi := &file_IPRuleService_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryResult.ProtoReflect.Descriptor instead.
+func (*HistoryResult) Descriptor() ([]byte, []int) {
+	return file_IPRuleService_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *HistoryResult) GetEvents() []*RuleEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
 var File_IPRuleService_proto protoreflect.FileDescriptor
 
 var file_IPRuleService_proto_rawDesc = []byte{
 	0x0a, 0x13, 0x49, 0x50, 0x52, 0x75, 0x6c, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x61, 0x70, 0x69, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67,
 	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74,
-	0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x1d, 0x0a, 0x05, 0x49, 0x50, 0x4e, 0x65, 0x74,
-	0x12, 0x14, 0x0a, 0x05, 0x49, 0x50, 0x4e, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x05, 0x49, 0x50, 0x4e, 0x65, 0x74, 0x32, 0xf2, 0x01, 0x0a, 0x06, 0x49, 0x50, 0x52, 0x75, 0x6c,
-	0x65, 0x12, 0x36, 0x0a, 0x0e, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x57, 0x68, 0x69, 0x74, 0x65, 0x4c,
-	0x69, 0x73, 0x74, 0x12, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x50, 0x4e, 0x65, 0x74, 0x1a,
-	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x36, 0x0a, 0x0e, 0x41, 0x64, 0x64,
-	0x54, 0x6f, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x0a, 0x2e, 0x61, 0x70,
-	0x69, 0x2e, 0x49, 0x50, 0x4e, 0x65, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22,
-	0x00, 0x12, 0x3b, 0x0a, 0x13, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x57,
-	0x68, 0x69, 0x74, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49,
-	0x50, 0x4e, 0x65, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x3b,
-	0x0a, 0x13, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x42, 0x6c, 0x61, 0x63,
-	0x6b, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x50, 0x4e, 0x65,
+	0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x88, 0x01, 0x0a, 0x05, 0x49, 0x50, 0x4e,
+	0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x49, 0x50, 0x4e, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x49, 0x50, 0x4e, 0x65, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d,
+	0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x65,
+	0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x33,
+	0x0a, 0x0a, 0x4f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63,
+	0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x0a, 0x4f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x6c,
+	0x69, 0x63, 0x74, 0x22, 0x6b, 0x0a, 0x0c, 0x52, 0x75, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x6c,
+	0x69, 0x63, 0x74, 0x12, 0x1d, 0x0a, 0x04, 0x52, 0x75, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x04, 0x52, 0x75,
+	0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20,
+	0x0a, 0x0b, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x64, 0x69, 0x63, 0x74, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0b, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x64, 0x69, 0x63, 0x74, 0x73,
+	0x22, 0xb9, 0x01, 0x0a, 0x09, 0x41, 0x64, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1d,
+	0x0a, 0x04, 0x52, 0x75, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x04, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x2f, 0x0a,
+	0x09, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x6c,
+	0x69, 0x63, 0x74, 0x52, 0x09, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x73, 0x12, 0x21,
+	0x0a, 0x06, 0x4d, 0x65, 0x72, 0x67, 0x65, 0x64, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x06, 0x4d, 0x65, 0x72, 0x67, 0x65,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x53, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x12, 0x1f, 0x0a, 0x05, 0x52,
+	0x75, 0x6c, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x05, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x22, 0x8b, 0x02, 0x0a,
+	0x04, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x21, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x79,
+	0x70, 0x65, 0x52, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x49, 0x50, 0x4e, 0x65,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x49, 0x50, 0x4e, 0x65, 0x74, 0x12, 0x18,
+	0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x38, 0x0a, 0x09, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64,
+	0x41, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x42, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x42, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x50, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x22, 0xa1, 0x01, 0x0a, 0x0d, 0x55,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x12, 0x14, 0x0a, 0x05,
+	0x49, 0x50, 0x4e, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x49, 0x50, 0x4e,
+	0x65, 0x74, 0x12, 0x21, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52,
+	0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e,
+	0x74, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x08, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69,
+	0x74, 0x79, 0x88, 0x01, 0x01, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e,
+	0x74, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x22, 0x3f,
+	0x0a, 0x0d, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x41, 0x74, 0x6f, 0x6d, 0x69, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x06, 0x41, 0x74, 0x6f, 0x6d, 0x69, 0x63, 0x12, 0x16, 0x0a, 0x06, 0x44, 0x72, 0x79, 0x52, 0x75,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x44, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x22,
+	0x6b, 0x0a, 0x0d, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71,
+	0x12, 0x2e, 0x0a, 0x07, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x48, 0x00, 0x52, 0x07, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x1f, 0x0a, 0x04, 0x52, 0x75, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x48, 0x00, 0x52, 0x04, 0x52, 0x75, 0x6c,
+	0x65, 0x42, 0x09, 0x0a, 0x07, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x6c, 0x0a, 0x0a,
+	0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x1d, 0x0a, 0x04, 0x52, 0x75,
+	0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52,
+	0x75, 0x6c, 0x65, 0x52, 0x04, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x29, 0x0a, 0x06, 0x41, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x41, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x97, 0x01, 0x0a, 0x0c, 0x49,
+	0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x41,
+	0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x41, 0x70,
+	0x70, 0x6c, 0x69, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x41, 0x64, 0x64, 0x65, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x41, 0x64, 0x64, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x53,
+	0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x53, 0x6b,
+	0x69, 0x70, 0x70, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x25, 0x0a,
+	0x05, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x49,
+	0x74, 0x65, 0x6d, 0x73, 0x22, 0x2e, 0x0a, 0x09, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65,
+	0x71, 0x12, 0x21, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04,
+	0x54, 0x79, 0x70, 0x65, 0x22, 0x47, 0x0a, 0x0a, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x52,
+	0x65, 0x71, 0x12, 0x21, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52,
+	0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x44, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x44, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x22, 0x6f, 0x0a,
+	0x0d, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x18,
+	0x0a, 0x07, 0x41, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x41, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x12, 0x23, 0x0a, 0x07, 0x52, 0x65, 0x6d, 0x6f,
+	0x76, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x52, 0x75, 0x6c, 0x65, 0x52, 0x07, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x12, 0x1f, 0x0a,
+	0x05, 0x41, 0x64, 0x64, 0x65, 0x64, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x05, 0x41, 0x64, 0x64, 0x65, 0x64, 0x22, 0x38,
+	0x0a, 0x0a, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x12, 0x14, 0x0a, 0x05,
+	0x49, 0x50, 0x4e, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x49, 0x50, 0x4e,
+	0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x05, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0xf5, 0x01, 0x0a, 0x09, 0x52, 0x75, 0x6c,
+	0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x12, 0x16,
+	0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05,
+	0x49, 0x50, 0x4e, 0x65, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x49, 0x50, 0x4e,
+	0x65, 0x74, 0x12, 0x21, 0x0a, 0x06, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x06, 0x42,
+	0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x1f, 0x0a, 0x05, 0x41, 0x66, 0x74, 0x65, 0x72, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52,
+	0x05, 0x41, 0x66, 0x74, 0x65, 0x72, 0x12, 0x38, 0x0a, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x22, 0x37, 0x0a, 0x0d, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x26, 0x0a, 0x06, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x52, 0x06, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2a, 0x36, 0x0a, 0x08, 0x4c, 0x69, 0x73,
+	0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0c, 0x0a, 0x08, 0x4c, 0x69, 0x73, 0x74, 0x4e, 0x6f, 0x6e,
+	0x65, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x57, 0x68, 0x69, 0x74, 0x65,
+	0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x10,
+	0x02, 0x2a, 0x5e, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x12, 0x13, 0x0a, 0x0f, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x44,
+	0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x66,
+	0x6c, 0x69, 0x63, 0x74, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c,
+	0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x57, 0x61, 0x72, 0x6e, 0x10, 0x02, 0x12, 0x11,
+	0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x4d, 0x65, 0x72, 0x67, 0x65, 0x10,
+	0x03, 0x2a, 0x40, 0x0a, 0x0c, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x0d, 0x0a, 0x09, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x41, 0x64, 0x64, 0x10, 0x00,
+	0x12, 0x10, 0x0a, 0x0c, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x45, 0x78, 0x69, 0x73, 0x74, 0x73,
+	0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x10, 0x02, 0x32, 0xe9, 0x03, 0x0a, 0x06, 0x49, 0x50, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x2e,
+	0x0a, 0x0e, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x57, 0x68, 0x69, 0x74, 0x65, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x50, 0x4e, 0x65, 0x74, 0x1a, 0x0e, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x41, 0x64, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x2e,
+	0x0a, 0x0e, 0x41, 0x64, 0x64, 0x54, 0x6f, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x50, 0x4e, 0x65, 0x74, 0x1a, 0x0e, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x41, 0x64, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x3b,
+	0x0a, 0x13, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x57, 0x68, 0x69, 0x74,
+	0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x50, 0x4e, 0x65,
 	0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x42, 0x1a, 0x5a, 0x18, 0x69,
-	0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x72, 0x2f,
-	0x67, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x3b, 0x0a, 0x13, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x4c, 0x69,
+	0x73, 0x74, 0x12, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x50, 0x4e, 0x65, 0x74, 0x1a, 0x16,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x2d, 0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x12, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x09, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x52, 0x75, 0x6c, 0x65, 0x22, 0x00, 0x12, 0x38, 0x0a, 0x0b, 0x49, 0x6d, 0x70, 0x6f, 0x72,
+	0x74, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x12, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6d, 0x70,
+	0x6f, 0x72, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x11, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x28,
+	0x01, 0x12, 0x2c, 0x0a, 0x0b, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x73,
+	0x12, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71,
+	0x1a, 0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12,
+	0x37, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72,
+	0x79, 0x12, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52,
+	0x65, 0x71, 0x1a, 0x12, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x35, 0x0a, 0x0c, 0x43, 0x6f, 0x6d, 0x70,
+	0x61, 0x63, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x43,
+	0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x12, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x42,
+	0x1a, 0x5a, 0x18, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x68, 0x61, 0x6e, 0x64,
+	0x6c, 0x65, 0x72, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -109,25 +1424,76 @@ func file_IPRuleService_proto_rawDescGZIP() []byte {
 	return file_IPRuleService_proto_rawDescData
 }
 
-var file_IPRuleService_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_IPRuleService_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_IPRuleService_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_IPRuleService_proto_goTypes = []interface{}{
-	(*IPNet)(nil),         // 0: api.IPNet
-	(*emptypb.Empty)(nil), // 1: google.protobuf.Empty
+	(ListType)(0),                 // 0: api.ListType
+	(ConflictPolicy)(0),           // 1: api.ConflictPolicy
+	(ImportAction)(0),             // 2: api.ImportAction
+	(*IPNet)(nil),                 // 3: api.IPNet
+	(*RuleConflict)(nil),          // 4: api.RuleConflict
+	(*AddResult)(nil),             // 5: api.AddResult
+	(*Rule)(nil),                  // 6: api.Rule
+	(*UpdateRuleReq)(nil),         // 7: api.UpdateRuleReq
+	(*ImportOptions)(nil),         // 8: api.ImportOptions
+	(*ImportRuleReq)(nil),         // 9: api.ImportRuleReq
+	(*ImportItem)(nil),            // 10: api.ImportItem
+	(*ImportResult)(nil),          // 11: api.ImportResult
+	(*ExportReq)(nil),             // 12: api.ExportReq
+	(*CompactReq)(nil),            // 13: api.CompactReq
+	(*CompactResult)(nil),         // 14: api.CompactResult
+	(*HistoryReq)(nil),            // 15: api.HistoryReq
+	(*RuleEvent)(nil),             // 16: api.RuleEvent
+	(*HistoryResult)(nil),         // 17: api.HistoryResult
+	(*timestamppb.Timestamp)(nil), // 18: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 19: google.protobuf.Empty
 }
 var file_IPRuleService_proto_depIdxs = []int32{
-	0, // 0: api.IPRule.AddToWhiteList:input_type -> api.IPNet
-	0, // 1: api.IPRule.AddToBlackList:input_type -> api.IPNet
-	0, // 2: api.IPRule.DeleteFromWhiteList:input_type -> api.IPNet
-	0, // 3: api.IPRule.DeleteFromBlackList:input_type -> api.IPNet
-	1, // 4: api.IPRule.AddToWhiteList:output_type -> google.protobuf.Empty
-	1, // 5: api.IPRule.AddToBlackList:output_type -> google.protobuf.Empty
-	1, // 6: api.IPRule.DeleteFromWhiteList:output_type -> google.protobuf.Empty
-	1, // 7: api.IPRule.DeleteFromBlackList:output_type -> google.protobuf.Empty
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1,  // 0: api.IPNet.OnConflict:type_name -> api.ConflictPolicy
+	6,  // 1: api.RuleConflict.Rule:type_name -> api.Rule
+	6,  // 2: api.AddResult.Rule:type_name -> api.Rule
+	4,  // 3: api.AddResult.Conflicts:type_name -> api.RuleConflict
+	6,  // 4: api.AddResult.Merged:type_name -> api.Rule
+	6,  // 5: api.AddResult.Rules:type_name -> api.Rule
+	0,  // 6: api.Rule.Type:type_name -> api.ListType
+	18, // 7: api.Rule.UpdatedAt:type_name -> google.protobuf.Timestamp
+	0,  // 8: api.UpdateRuleReq.Type:type_name -> api.ListType
+	8,  // 9: api.ImportRuleReq.Options:type_name -> api.ImportOptions
+	6,  // 10: api.ImportRuleReq.Rule:type_name -> api.Rule
+	6,  // 11: api.ImportItem.Rule:type_name -> api.Rule
+	2,  // 12: api.ImportItem.Action:type_name -> api.ImportAction
+	10, // 13: api.ImportResult.Items:type_name -> api.ImportItem
+	0,  // 14: api.ExportReq.Type:type_name -> api.ListType
+	0,  // 15: api.CompactReq.Type:type_name -> api.ListType
+	6,  // 16: api.CompactResult.Removed:type_name -> api.Rule
+	6,  // 17: api.CompactResult.Added:type_name -> api.Rule
+	6,  // 18: api.RuleEvent.Before:type_name -> api.Rule
+	6,  // 19: api.RuleEvent.After:type_name -> api.Rule
+	18, // 20: api.RuleEvent.CreatedAt:type_name -> google.protobuf.Timestamp
+	16, // 21: api.HistoryResult.Events:type_name -> api.RuleEvent
+	3,  // 22: api.IPRule.AddToWhiteList:input_type -> api.IPNet
+	3,  // 23: api.IPRule.AddToBlackList:input_type -> api.IPNet
+	3,  // 24: api.IPRule.DeleteFromWhiteList:input_type -> api.IPNet
+	3,  // 25: api.IPRule.DeleteFromBlackList:input_type -> api.IPNet
+	7,  // 26: api.IPRule.UpdateRule:input_type -> api.UpdateRuleReq
+	9,  // 27: api.IPRule.ImportRules:input_type -> api.ImportRuleReq
+	12, // 28: api.IPRule.ExportRules:input_type -> api.ExportReq
+	15, // 29: api.IPRule.GetRuleHistory:input_type -> api.HistoryReq
+	13, // 30: api.IPRule.CompactRules:input_type -> api.CompactReq
+	5,  // 31: api.IPRule.AddToWhiteList:output_type -> api.AddResult
+	5,  // 32: api.IPRule.AddToBlackList:output_type -> api.AddResult
+	19, // 33: api.IPRule.DeleteFromWhiteList:output_type -> google.protobuf.Empty
+	19, // 34: api.IPRule.DeleteFromBlackList:output_type -> google.protobuf.Empty
+	6,  // 35: api.IPRule.UpdateRule:output_type -> api.Rule
+	11, // 36: api.IPRule.ImportRules:output_type -> api.ImportResult
+	6,  // 37: api.IPRule.ExportRules:output_type -> api.Rule
+	17, // 38: api.IPRule.GetRuleHistory:output_type -> api.HistoryResult
+	14, // 39: api.IPRule.CompactRules:output_type -> api.CompactResult
+	31, // [31:40] is the sub-list for method output_type
+	22, // [22:31] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_IPRuleService_proto_init() }
@@ -148,19 +1514,193 @@ func file_IPRuleService_proto_init() {
 				return nil
 			}
 		}
+		file_IPRuleService_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleConflict); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Rule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateRuleReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImportOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImportRuleReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImportItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImportResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompactReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompactResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HistoryReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_IPRuleService_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HistoryResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_IPRuleService_proto_msgTypes[4].OneofWrappers = []interface{}{}
+	file_IPRuleService_proto_msgTypes[6].OneofWrappers = []interface{}{
+		(*ImportRuleReq_Options)(nil),
+		(*ImportRuleReq_Rule)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_IPRuleService_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   1,
+			NumEnums:      3,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_IPRuleService_proto_goTypes,
 		DependencyIndexes: file_IPRuleService_proto_depIdxs,
+		EnumInfos:         file_IPRuleService_proto_enumTypes,
 		MessageInfos:      file_IPRuleService_proto_msgTypes,
 	}.Build()
 	File_IPRuleService_proto = out.File
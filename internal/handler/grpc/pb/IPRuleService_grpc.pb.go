@@ -24,10 +24,18 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type IPRuleClient interface {
-	AddToWhiteList(ctx context.Context, in *IPNet, opts ...grpc.CallOption) (*emptypb.Empty, error)
-	AddToBlackList(ctx context.Context, in *IPNet, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	AddToWhiteList(ctx context.Context, in *IPNet, opts ...grpc.CallOption) (*AddResult, error)
+	AddToBlackList(ctx context.Context, in *IPNet, opts ...grpc.CallOption) (*AddResult, error)
 	DeleteFromWhiteList(ctx context.Context, in *IPNet, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	DeleteFromBlackList(ctx context.Context, in *IPNet, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// UpdateRule перенос сети в другой список и/или изменение метаданных одной операцией.
+	UpdateRule(ctx context.Context, in *UpdateRuleReq, opts ...grpc.CallOption) (*Rule, error)
+	// ImportRules первое сообщение потока - параметры импорта, далее - правила.
+	ImportRules(ctx context.Context, opts ...grpc.CallOption) (IPRule_ImportRulesClient, error)
+	ExportRules(ctx context.Context, in *ExportReq, opts ...grpc.CallOption) (IPRule_ExportRulesClient, error)
+	GetRuleHistory(ctx context.Context, in *HistoryReq, opts ...grpc.CallOption) (*HistoryResult, error)
+	// CompactRules замена смежных и вложенных сетей минимальным покрытием.
+	CompactRules(ctx context.Context, in *CompactReq, opts ...grpc.CallOption) (*CompactResult, error)
 }
 
 type iPRuleClient struct {
@@ -38,8 +46,8 @@ func NewIPRuleClient(cc grpc.ClientConnInterface) IPRuleClient {
 	return &iPRuleClient{cc}
 }
 
-func (c *iPRuleClient) AddToWhiteList(ctx context.Context, in *IPNet, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	out := new(emptypb.Empty)
+func (c *iPRuleClient) AddToWhiteList(ctx context.Context, in *IPNet, opts ...grpc.CallOption) (*AddResult, error) {
+	out := new(AddResult)
 	err := c.cc.Invoke(ctx, "/api.IPRule/AddToWhiteList", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -47,8 +55,8 @@ func (c *iPRuleClient) AddToWhiteList(ctx context.Context, in *IPNet, opts ...gr
 	return out, nil
 }
 
-func (c *iPRuleClient) AddToBlackList(ctx context.Context, in *IPNet, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	out := new(emptypb.Empty)
+func (c *iPRuleClient) AddToBlackList(ctx context.Context, in *IPNet, opts ...grpc.CallOption) (*AddResult, error) {
+	out := new(AddResult)
 	err := c.cc.Invoke(ctx, "/api.IPRule/AddToBlackList", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -74,14 +82,115 @@ func (c *iPRuleClient) DeleteFromBlackList(ctx context.Context, in *IPNet, opts
 	return out, nil
 }
 
+func (c *iPRuleClient) UpdateRule(ctx context.Context, in *UpdateRuleReq, opts ...grpc.CallOption) (*Rule, error) {
+	out := new(Rule)
+	err := c.cc.Invoke(ctx, "/api.IPRule/UpdateRule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPRuleClient) ImportRules(ctx context.Context, opts ...grpc.CallOption) (IPRule_ImportRulesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &IPRule_ServiceDesc.Streams[0], "/api.IPRule/ImportRules", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &iPRuleImportRulesClient{stream}
+	return x, nil
+}
+
+type IPRule_ImportRulesClient interface {
+	Send(*ImportRuleReq) error
+	CloseAndRecv() (*ImportResult, error)
+	grpc.ClientStream
+}
+
+type iPRuleImportRulesClient struct {
+	grpc.ClientStream
+}
+
+func (x *iPRuleImportRulesClient) Send(m *ImportRuleReq) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *iPRuleImportRulesClient) CloseAndRecv() (*ImportResult, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *iPRuleClient) ExportRules(ctx context.Context, in *ExportReq, opts ...grpc.CallOption) (IPRule_ExportRulesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &IPRule_ServiceDesc.Streams[1], "/api.IPRule/ExportRules", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &iPRuleExportRulesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type IPRule_ExportRulesClient interface {
+	Recv() (*Rule, error)
+	grpc.ClientStream
+}
+
+type iPRuleExportRulesClient struct {
+	grpc.ClientStream
+}
+
+func (x *iPRuleExportRulesClient) Recv() (*Rule, error) {
+	m := new(Rule)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *iPRuleClient) GetRuleHistory(ctx context.Context, in *HistoryReq, opts ...grpc.CallOption) (*HistoryResult, error) {
+	out := new(HistoryResult)
+	err := c.cc.Invoke(ctx, "/api.IPRule/GetRuleHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPRuleClient) CompactRules(ctx context.Context, in *CompactReq, opts ...grpc.CallOption) (*CompactResult, error) {
+	out := new(CompactResult)
+	err := c.cc.Invoke(ctx, "/api.IPRule/CompactRules", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // IPRuleServer is the server API for IPRule service.
 // All implementations must embed UnimplementedIPRuleServer
 // for forward compatibility
 type IPRuleServer interface {
-	AddToWhiteList(context.Context, *IPNet) (*emptypb.Empty, error)
-	AddToBlackList(context.Context, *IPNet) (*emptypb.Empty, error)
+	AddToWhiteList(context.Context, *IPNet) (*AddResult, error)
+	AddToBlackList(context.Context, *IPNet) (*AddResult, error)
 	DeleteFromWhiteList(context.Context, *IPNet) (*emptypb.Empty, error)
 	DeleteFromBlackList(context.Context, *IPNet) (*emptypb.Empty, error)
+	// UpdateRule перенос сети в другой список и/или изменение метаданных одной операцией.
+	UpdateRule(context.Context, *UpdateRuleReq) (*Rule, error)
+	// ImportRules первое сообщение потока - параметры импорта, далее - правила.
+	ImportRules(IPRule_ImportRulesServer) error
+	ExportRules(*ExportReq, IPRule_ExportRulesServer) error
+	GetRuleHistory(context.Context, *HistoryReq) (*HistoryResult, error)
+	// CompactRules замена смежных и вложенных сетей минимальным покрытием.
+	CompactRules(context.Context, *CompactReq) (*CompactResult, error)
 	mustEmbedUnimplementedIPRuleServer()
 }
 
@@ -89,10 +198,10 @@ type IPRuleServer interface {
 type UnimplementedIPRuleServer struct {
 }
 
-func (UnimplementedIPRuleServer) AddToWhiteList(context.Context, *IPNet) (*emptypb.Empty, error) {
+func (UnimplementedIPRuleServer) AddToWhiteList(context.Context, *IPNet) (*AddResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddToWhiteList not implemented")
 }
-func (UnimplementedIPRuleServer) AddToBlackList(context.Context, *IPNet) (*emptypb.Empty, error) {
+func (UnimplementedIPRuleServer) AddToBlackList(context.Context, *IPNet) (*AddResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddToBlackList not implemented")
 }
 func (UnimplementedIPRuleServer) DeleteFromWhiteList(context.Context, *IPNet) (*emptypb.Empty, error) {
@@ -101,6 +210,21 @@ func (UnimplementedIPRuleServer) DeleteFromWhiteList(context.Context, *IPNet) (*
 func (UnimplementedIPRuleServer) DeleteFromBlackList(context.Context, *IPNet) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteFromBlackList not implemented")
 }
+func (UnimplementedIPRuleServer) UpdateRule(context.Context, *UpdateRuleReq) (*Rule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRule not implemented")
+}
+func (UnimplementedIPRuleServer) ImportRules(IPRule_ImportRulesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportRules not implemented")
+}
+func (UnimplementedIPRuleServer) ExportRules(*ExportReq, IPRule_ExportRulesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportRules not implemented")
+}
+func (UnimplementedIPRuleServer) GetRuleHistory(context.Context, *HistoryReq) (*HistoryResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRuleHistory not implemented")
+}
+func (UnimplementedIPRuleServer) CompactRules(context.Context, *CompactReq) (*CompactResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompactRules not implemented")
+}
 func (UnimplementedIPRuleServer) mustEmbedUnimplementedIPRuleServer() {}
 
 // UnsafeIPRuleServer may be embedded to opt out of forward compatibility for this service.
@@ -186,6 +310,107 @@ func _IPRule_DeleteFromBlackList_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _IPRule_UpdateRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRuleReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPRuleServer).UpdateRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.IPRule/UpdateRule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPRuleServer).UpdateRule(ctx, req.(*UpdateRuleReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPRule_ImportRules_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IPRuleServer).ImportRules(&iPRuleImportRulesServer{stream})
+}
+
+type IPRule_ImportRulesServer interface {
+	SendAndClose(*ImportResult) error
+	Recv() (*ImportRuleReq, error)
+	grpc.ServerStream
+}
+
+type iPRuleImportRulesServer struct {
+	grpc.ServerStream
+}
+
+func (x *iPRuleImportRulesServer) SendAndClose(m *ImportResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *iPRuleImportRulesServer) Recv() (*ImportRuleReq, error) {
+	m := new(ImportRuleReq)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _IPRule_ExportRules_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IPRuleServer).ExportRules(m, &iPRuleExportRulesServer{stream})
+}
+
+type IPRule_ExportRulesServer interface {
+	Send(*Rule) error
+	grpc.ServerStream
+}
+
+type iPRuleExportRulesServer struct {
+	grpc.ServerStream
+}
+
+func (x *iPRuleExportRulesServer) Send(m *Rule) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _IPRule_GetRuleHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPRuleServer).GetRuleHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.IPRule/GetRuleHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPRuleServer).GetRuleHistory(ctx, req.(*HistoryReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPRule_CompactRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPRuleServer).CompactRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.IPRule/CompactRules",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPRuleServer).CompactRules(ctx, req.(*CompactReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // IPRule_ServiceDesc is the grpc.ServiceDesc for IPRule service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -209,7 +434,30 @@ var IPRule_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteFromBlackList",
 			Handler:    _IPRule_DeleteFromBlackList_Handler,
 		},
+		{
+			MethodName: "UpdateRule",
+			Handler:    _IPRule_UpdateRule_Handler,
+		},
+		{
+			MethodName: "GetRuleHistory",
+			Handler:    _IPRule_GetRuleHistory_Handler,
+		},
+		{
+			MethodName: "CompactRules",
+			Handler:    _IPRule_CompactRules_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportRules",
+			Handler:       _IPRule_ImportRules_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportRules",
+			Handler:       _IPRule_ExportRules_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "IPRuleService.proto",
 }
@@ -13,6 +13,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
@@ -140,6 +141,231 @@ func (x *PermitResult) GetReason() string {
 	return ""
 }
 
+type PermitBatchReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Items не больше 100 запросов.
+	Items []*PermitReq `protobuf:"bytes,1,rep,name=Items,proto3" json:"Items,omitempty"`
+	// Atomic попытки учитываются в лимитах, только если разрешены все запросы; иначе не учитывается ни одна,
+	// а запросы, прошедшие бы проверку, запрещаются.
+	Atomic bool `protobuf:"varint,2,opt,name=Atomic,proto3" json:"Atomic,omitempty"`
+}
+
+func (x *PermitBatchReq) Reset() {
+	*x = PermitBatchReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_PermitService_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PermitBatchReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PermitBatchReq) ProtoMessage() {}
+
+func (x *PermitBatchReq) ProtoReflect() protoreflect.Message {
+	mi := &file_PermitService_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PermitBatchReq.ProtoReflect.Descriptor instead.
+func (*PermitBatchReq) Descriptor() ([]byte, []int) {
+	return file_PermitService_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PermitBatchReq) GetItems() []*PermitReq {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *PermitBatchReq) GetAtomic() bool {
+	if x != nil {
+		return x.Atomic
+	}
+	return false
+}
+
+type PermitBatchResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items []*PermitResult `protobuf:"bytes,1,rep,name=Items,proto3" json:"Items,omitempty"`
+}
+
+func (x *PermitBatchResult) Reset() {
+	*x = PermitBatchResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_PermitService_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PermitBatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PermitBatchResult) ProtoMessage() {}
+
+func (x *PermitBatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_PermitService_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PermitBatchResult.ProtoReflect.Descriptor instead.
+func (*PermitBatchResult) Descriptor() ([]byte, []int) {
+	return file_PermitService_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PermitBatchResult) GetItems() []*PermitResult {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type CheckStreamReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID выбирается клиентом и возвращается в ответе.
+	ID    uint64     `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Query *PermitReq `protobuf:"bytes,2,opt,name=Query,proto3" json:"Query,omitempty"`
+}
+
+func (x *CheckStreamReq) Reset() {
+	*x = CheckStreamReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_PermitService_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckStreamReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckStreamReq) ProtoMessage() {}
+
+func (x *CheckStreamReq) ProtoReflect() protoreflect.Message {
+	mi := &file_PermitService_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckStreamReq.ProtoReflect.Descriptor instead.
+func (*CheckStreamReq) Descriptor() ([]byte, []int) {
+	return file_PermitService_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CheckStreamReq) GetID() uint64 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *CheckStreamReq) GetQuery() *PermitReq {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+type CheckStreamRes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID     uint64        `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Result *PermitResult `protobuf:"bytes,2,opt,name=Result,proto3" json:"Result,omitempty"`
+	// Error запрос не проверен: неверный запрос, превышено время проверки или внутренняя ошибка.
+	Error string `protobuf:"bytes,3,opt,name=Error,proto3" json:"Error,omitempty"`
+}
+
+func (x *CheckStreamRes) Reset() {
+	*x = CheckStreamRes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_PermitService_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckStreamRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckStreamRes) ProtoMessage() {}
+
+func (x *CheckStreamRes) ProtoReflect() protoreflect.Message {
+	mi := &file_PermitService_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckStreamRes.ProtoReflect.Descriptor instead.
+func (*CheckStreamRes) Descriptor() ([]byte, []int) {
+	return file_PermitService_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CheckStreamRes) GetID() uint64 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *CheckStreamRes) GetResult() *PermitResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *CheckStreamRes) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 type RstIPReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -151,7 +377,7 @@ type RstIPReq struct {
 func (x *RstIPReq) Reset() {
 	*x = RstIPReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_PermitService_proto_msgTypes[2]
+		mi := &file_PermitService_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -164,7 +390,7 @@ func (x *RstIPReq) String() string {
 func (*RstIPReq) ProtoMessage() {}
 
 func (x *RstIPReq) ProtoReflect() protoreflect.Message {
-	mi := &file_PermitService_proto_msgTypes[2]
+	mi := &file_PermitService_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -177,7 +403,7 @@ func (x *RstIPReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RstIPReq.ProtoReflect.Descriptor instead.
 func (*RstIPReq) Descriptor() ([]byte, []int) {
-	return file_PermitService_proto_rawDescGZIP(), []int{2}
+	return file_PermitService_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *RstIPReq) GetIP() string {
@@ -198,7 +424,7 @@ type RstLoginReq struct {
 func (x *RstLoginReq) Reset() {
 	*x = RstLoginReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_PermitService_proto_msgTypes[3]
+		mi := &file_PermitService_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -211,7 +437,7 @@ func (x *RstLoginReq) String() string {
 func (*RstLoginReq) ProtoMessage() {}
 
 func (x *RstLoginReq) ProtoReflect() protoreflect.Message {
-	mi := &file_PermitService_proto_msgTypes[3]
+	mi := &file_PermitService_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -224,7 +450,7 @@ func (x *RstLoginReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RstLoginReq.ProtoReflect.Descriptor instead.
 func (*RstLoginReq) Descriptor() ([]byte, []int) {
-	return file_PermitService_proto_rawDescGZIP(), []int{3}
+	return file_PermitService_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *RstLoginReq) GetLogin() string {
@@ -234,39 +460,427 @@ func (x *RstLoginReq) GetLogin() string {
 	return ""
 }
 
+type WatchReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types decision, rule_add, rule_delete, rule_update, reset, auto_ban; пусто - все.
+	Types []string `protobuf:"bytes,1,rep,name=Types,proto3" json:"Types,omitempty"`
+	// IPNet CIDR или IP: решения и сбросы по адресам из сети, изменения пересекающихся с ней правил.
+	IPNet string `protobuf:"bytes,2,opt,name=IPNet,proto3" json:"IPNet,omitempty"`
+	// DeniedOnly из решений только запреты.
+	DeniedOnly bool `protobuf:"varint,3,opt,name=DeniedOnly,proto3" json:"DeniedOnly,omitempty"`
+}
+
+func (x *WatchReq) Reset() {
+	*x = WatchReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_PermitService_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchReq) ProtoMessage() {}
+
+func (x *WatchReq) ProtoReflect() protoreflect.Message {
+	mi := &file_PermitService_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchReq.ProtoReflect.Descriptor instead.
+func (*WatchReq) Descriptor() ([]byte, []int) {
+	return file_PermitService_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchReq) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *WatchReq) GetIPNet() string {
+	if x != nil {
+		return x.IPNet
+	}
+	return ""
+}
+
+func (x *WatchReq) GetDeniedOnly() bool {
+	if x != nil {
+		return x.DeniedOnly
+	}
+	return false
+}
+
+type DecisionEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Login первый символ логина, остальное скрыто; пароль не передается.
+	Login   string `protobuf:"bytes,1,opt,name=Login,proto3" json:"Login,omitempty"`
+	IP      string `protobuf:"bytes,2,opt,name=IP,proto3" json:"IP,omitempty"`
+	Success bool   `protobuf:"varint,3,opt,name=Success,proto3" json:"Success,omitempty"`
+	Reason  string `protobuf:"bytes,4,opt,name=Reason,proto3" json:"Reason,omitempty"`
+}
+
+func (x *DecisionEvent) Reset() {
+	*x = DecisionEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_PermitService_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DecisionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecisionEvent) ProtoMessage() {}
+
+func (x *DecisionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_PermitService_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecisionEvent.ProtoReflect.Descriptor instead.
+func (*DecisionEvent) Descriptor() ([]byte, []int) {
+	return file_PermitService_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DecisionEvent) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *DecisionEvent) GetIP() string {
+	if x != nil {
+		return x.IP
+	}
+	return ""
+}
+
+func (x *DecisionEvent) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DecisionEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ResetEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Param string `protobuf:"bytes,1,opt,name=Param,proto3" json:"Param,omitempty"`
+	// Value IP или замаскированный логин.
+	Value string `protobuf:"bytes,2,opt,name=Value,proto3" json:"Value,omitempty"`
+	Actor string `protobuf:"bytes,3,opt,name=Actor,proto3" json:"Actor,omitempty"`
+}
+
+func (x *ResetEvent) Reset() {
+	*x = ResetEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_PermitService_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResetEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetEvent) ProtoMessage() {}
+
+func (x *ResetEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_PermitService_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetEvent.ProtoReflect.Descriptor instead.
+func (*ResetEvent) Descriptor() ([]byte, []int) {
+	return file_PermitService_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ResetEvent) GetParam() string {
+	if x != nil {
+		return x.Param
+	}
+	return ""
+}
+
+func (x *ResetEvent) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *ResetEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type string                 `protobuf:"bytes,1,opt,name=Type,proto3" json:"Type,omitempty"`
+	Time *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=Time,proto3" json:"Time,omitempty"`
+	// Types that are assignable to Payload:
+	//	*Event_Decision
+	//	*Event_Rule
+	//	*Event_LimitReset
+	Payload isEvent_Payload `protobuf_oneof:"Payload"`
+	// Dropped событий, не доставленных подписчику из-за переполнения буфера, с начала подписки.
+	Dropped uint64 `protobuf:"varint,6,opt,name=Dropped,proto3" json:"Dropped,omitempty"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_PermitService_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_PermitService_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_PermitService_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (m *Event) GetPayload() isEvent_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *Event) GetDecision() *DecisionEvent {
+	if x, ok := x.GetPayload().(*Event_Decision); ok {
+		return x.Decision
+	}
+	return nil
+}
+
+func (x *Event) GetRule() *RuleEvent {
+	if x, ok := x.GetPayload().(*Event_Rule); ok {
+		return x.Rule
+	}
+	return nil
+}
+
+func (x *Event) GetLimitReset() *ResetEvent {
+	if x, ok := x.GetPayload().(*Event_LimitReset); ok {
+		return x.LimitReset
+	}
+	return nil
+}
+
+func (x *Event) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_Decision struct {
+	Decision *DecisionEvent `protobuf:"bytes,3,opt,name=Decision,proto3,oneof"`
+}
+
+type Event_Rule struct {
+	Rule *RuleEvent `protobuf:"bytes,4,opt,name=Rule,proto3,oneof"`
+}
+
+type Event_LimitReset struct {
+	LimitReset *ResetEvent `protobuf:"bytes,5,opt,name=LimitReset,proto3,oneof"`
+}
+
+func (*Event_Decision) isEvent_Payload() {}
+
+func (*Event_Rule) isEvent_Payload() {}
+
+func (*Event_LimitReset) isEvent_Payload() {}
+
 var File_PermitService_proto protoreflect.FileDescriptor
 
 var file_PermitService_proto_rawDesc = []byte{
 	0x0a, 0x13, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x61, 0x70, 0x69, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67,
 	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74,
-	0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x4d, 0x0a, 0x09, 0x50, 0x65, 0x72, 0x6d, 0x69,
-	0x74, 0x52, 0x65, 0x71, 0x12, 0x14, 0x0a, 0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x61,
-	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x50, 0x61,
-	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x50, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x02, 0x49, 0x50, 0x22, 0x40, 0x0a, 0x0c, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74,
-	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73,
-	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73,
-	0x12, 0x16, 0x0a, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x1a, 0x0a, 0x08, 0x52, 0x73, 0x74, 0x49,
-	0x50, 0x52, 0x65, 0x71, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x50, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x02, 0x49, 0x50, 0x22, 0x23, 0x0a, 0x0b, 0x52, 0x73, 0x74, 0x4c, 0x6f, 0x67, 0x69, 0x6e,
-	0x52, 0x65, 0x71, 0x12, 0x14, 0x0a, 0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x32, 0xa9, 0x01, 0x0a, 0x06, 0x50, 0x65,
-	0x72, 0x6d, 0x69, 0x74, 0x12, 0x31, 0x0a, 0x0a, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x51, 0x75, 0x65,
-	0x72, 0x79, 0x12, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x52,
-	0x65, 0x71, 0x1a, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x52,
-	0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x32, 0x0a, 0x07, 0x52, 0x65, 0x73, 0x65, 0x74,
-	0x49, 0x50, 0x12, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x73, 0x74, 0x49, 0x50, 0x52, 0x65,
-	0x71, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x38, 0x0a, 0x0a, 0x52,
-	0x65, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x10, 0x2e, 0x61, 0x70, 0x69, 0x2e,
-	0x52, 0x73, 0x74, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x1a, 0x16, 0x2e, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
-	0x70, 0x74, 0x79, 0x22, 0x00, 0x42, 0x1a, 0x5a, 0x18, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61,
-	0x6c, 0x2f, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x72, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x70,
-	0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x13, 0x49, 0x50, 0x52, 0x75, 0x6c, 0x65,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x4d, 0x0a,
+	0x09, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x71, 0x12, 0x14, 0x0a, 0x05, 0x4c, 0x6f,
+	0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e,
+	0x12, 0x1a, 0x0a, 0x08, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x0e, 0x0a, 0x02,
+	0x49, 0x50, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x50, 0x22, 0x40, 0x0a, 0x0c,
+	0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x18, 0x0a, 0x07,
+	0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x53,
+	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x4e,
+	0x0a, 0x0e, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71,
+	0x12, 0x24, 0x0a, 0x05, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x71, 0x52,
+	0x05, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x74, 0x6f, 0x6d, 0x69, 0x63,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x41, 0x74, 0x6f, 0x6d, 0x69, 0x63, 0x22, 0x3c,
+	0x0a, 0x11, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x12, 0x27, 0x0a, 0x05, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x05, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x22, 0x46, 0x0a, 0x0e,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x12, 0x0e,
+	0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12, 0x24,
+	0x0a, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x71, 0x52, 0x05, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x22, 0x61, 0x0a, 0x0e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x12, 0x29, 0x0a, 0x06, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x65, 0x72,
+	0x6d, 0x69, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x06, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x1a, 0x0a, 0x08, 0x52, 0x73, 0x74, 0x49, 0x50,
+	0x52, 0x65, 0x71, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x50, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x49, 0x50, 0x22, 0x23, 0x0a, 0x0b, 0x52, 0x73, 0x74, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52,
+	0x65, 0x71, 0x12, 0x14, 0x0a, 0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x22, 0x56, 0x0a, 0x08, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x71, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x79, 0x70, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x05, 0x54, 0x79, 0x70, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x49, 0x50,
+	0x4e, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x49, 0x50, 0x4e, 0x65, 0x74,
+	0x12, 0x1e, 0x0a, 0x0a, 0x44, 0x65, 0x6e, 0x69, 0x65, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x44, 0x65, 0x6e, 0x69, 0x65, 0x64, 0x4f, 0x6e, 0x6c, 0x79,
+	0x22, 0x67, 0x0a, 0x0d, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x50, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x50, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x75, 0x63, 0x63, 0x65,
+	0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x4e, 0x0a, 0x0a, 0x52, 0x65, 0x73,
+	0x65, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x50, 0x61, 0x72, 0x61, 0x6d,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x12, 0x14, 0x0a,
+	0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x22, 0xfb, 0x01, 0x0a, 0x05, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x2e, 0x0a, 0x04, 0x54, 0x69, 0x6d, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x04, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x30, 0x0a, 0x08, 0x44, 0x65, 0x63, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52,
+	0x08, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x24, 0x0a, 0x04, 0x52, 0x75, 0x6c,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x75,
+	0x6c, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x04, 0x52, 0x75, 0x6c, 0x65, 0x12,
+	0x31, 0x0a, 0x0a, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x73, 0x65, 0x74, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73, 0x65, 0x74, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x0a, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x73,
+	0x65, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x44, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x07, 0x44, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x42, 0x09, 0x0a, 0x07,
+	0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x32, 0xd8, 0x02, 0x0a, 0x06, 0x50, 0x65, 0x72, 0x6d,
+	0x69, 0x74, 0x12, 0x31, 0x0a, 0x0a, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x12, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x71,
+	0x1a, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x0f, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50,
+	0x65, 0x72, 0x6d, 0x69, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x1a, 0x16, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x3d, 0x0a, 0x0b, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x1a, 0x13, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73,
+	0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x32, 0x0a, 0x07, 0x52, 0x65, 0x73, 0x65, 0x74, 0x49,
+	0x50, 0x12, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x73, 0x74, 0x49, 0x50, 0x52, 0x65, 0x71,
+	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x38, 0x0a, 0x0a, 0x52, 0x65,
+	0x73, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x10, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52,
+	0x73, 0x74, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x22, 0x00, 0x12, 0x2c, 0x0a, 0x0b, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x73, 0x12, 0x0d, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52,
+	0x65, 0x71, 0x1a, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x00,
+	0x30, 0x01, 0x42, 0x1a, 0x5a, 0x18, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x68,
+	0x61, 0x6e, 0x64, 0x6c, 0x65, 0x72, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -281,26 +895,50 @@ func file_PermitService_proto_rawDescGZIP() []byte {
 	return file_PermitService_proto_rawDescData
 }
 
-var file_PermitService_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_PermitService_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_PermitService_proto_goTypes = []interface{}{
-	(*PermitReq)(nil),     // 0: api.PermitReq
-	(*PermitResult)(nil),  // 1: api.PermitResult
-	(*RstIPReq)(nil),      // 2: api.RstIPReq
-	(*RstLoginReq)(nil),   // 3: api.RstLoginReq
-	(*emptypb.Empty)(nil), // 4: google.protobuf.Empty
+	(*PermitReq)(nil),             // 0: api.PermitReq
+	(*PermitResult)(nil),          // 1: api.PermitResult
+	(*PermitBatchReq)(nil),        // 2: api.PermitBatchReq
+	(*PermitBatchResult)(nil),     // 3: api.PermitBatchResult
+	(*CheckStreamReq)(nil),        // 4: api.CheckStreamReq
+	(*CheckStreamRes)(nil),        // 5: api.CheckStreamRes
+	(*RstIPReq)(nil),              // 6: api.RstIPReq
+	(*RstLoginReq)(nil),           // 7: api.RstLoginReq
+	(*WatchReq)(nil),              // 8: api.WatchReq
+	(*DecisionEvent)(nil),         // 9: api.DecisionEvent
+	(*ResetEvent)(nil),            // 10: api.ResetEvent
+	(*Event)(nil),                 // 11: api.Event
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+	(*RuleEvent)(nil),             // 13: api.RuleEvent
+	(*emptypb.Empty)(nil),         // 14: google.protobuf.Empty
 }
 var file_PermitService_proto_depIdxs = []int32{
-	0, // 0: api.Permit.CheckQuery:input_type -> api.PermitReq
-	2, // 1: api.Permit.ResetIP:input_type -> api.RstIPReq
-	3, // 2: api.Permit.ResetLogin:input_type -> api.RstLoginReq
-	1, // 3: api.Permit.CheckQuery:output_type -> api.PermitResult
-	4, // 4: api.Permit.ResetIP:output_type -> google.protobuf.Empty
-	4, // 5: api.Permit.ResetLogin:output_type -> google.protobuf.Empty
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: api.PermitBatchReq.Items:type_name -> api.PermitReq
+	1,  // 1: api.PermitBatchResult.Items:type_name -> api.PermitResult
+	0,  // 2: api.CheckStreamReq.Query:type_name -> api.PermitReq
+	1,  // 3: api.CheckStreamRes.Result:type_name -> api.PermitResult
+	12, // 4: api.Event.Time:type_name -> google.protobuf.Timestamp
+	9,  // 5: api.Event.Decision:type_name -> api.DecisionEvent
+	13, // 6: api.Event.Rule:type_name -> api.RuleEvent
+	10, // 7: api.Event.LimitReset:type_name -> api.ResetEvent
+	0,  // 8: api.Permit.CheckQuery:input_type -> api.PermitReq
+	2,  // 9: api.Permit.CheckQueryBatch:input_type -> api.PermitBatchReq
+	4,  // 10: api.Permit.CheckStream:input_type -> api.CheckStreamReq
+	6,  // 11: api.Permit.ResetIP:input_type -> api.RstIPReq
+	7,  // 12: api.Permit.ResetLogin:input_type -> api.RstLoginReq
+	8,  // 13: api.Permit.WatchEvents:input_type -> api.WatchReq
+	1,  // 14: api.Permit.CheckQuery:output_type -> api.PermitResult
+	3,  // 15: api.Permit.CheckQueryBatch:output_type -> api.PermitBatchResult
+	5,  // 16: api.Permit.CheckStream:output_type -> api.CheckStreamRes
+	14, // 17: api.Permit.ResetIP:output_type -> google.protobuf.Empty
+	14, // 18: api.Permit.ResetLogin:output_type -> google.protobuf.Empty
+	11, // 19: api.Permit.WatchEvents:output_type -> api.Event
+	14, // [14:20] is the sub-list for method output_type
+	8,  // [8:14] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_PermitService_proto_init() }
@@ -308,6 +946,7 @@ func file_PermitService_proto_init() {
 	if File_PermitService_proto != nil {
 		return
 	}
+	file_IPRuleService_proto_init()
 	if !protoimpl.UnsafeEnabled {
 		file_PermitService_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PermitReq); i {
@@ -334,7 +973,7 @@ func file_PermitService_proto_init() {
 			}
 		}
 		file_PermitService_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RstIPReq); i {
+			switch v := v.(*PermitBatchReq); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -346,6 +985,54 @@ func file_PermitService_proto_init() {
 			}
 		}
 		file_PermitService_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PermitBatchResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_PermitService_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckStreamReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_PermitService_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckStreamRes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_PermitService_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RstIPReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_PermitService_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RstLoginReq); i {
 			case 0:
 				return &v.state
@@ -357,6 +1044,59 @@ func file_PermitService_proto_init() {
 				return nil
 			}
 		}
+		file_PermitService_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_PermitService_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DecisionEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_PermitService_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResetEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_PermitService_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Event); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_PermitService_proto_msgTypes[11].OneofWrappers = []interface{}{
+		(*Event_Decision)(nil),
+		(*Event_Rule)(nil),
+		(*Event_LimitReset)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -364,7 +1104,7 @@ func file_PermitService_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_PermitService_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -25,8 +25,15 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type PermitClient interface {
 	CheckQuery(ctx context.Context, in *PermitReq, opts ...grpc.CallOption) (*PermitResult, error)
+	// CheckQueryBatch проверка набора попыток одним вызовом, результаты в порядке запросов.
+	CheckQueryBatch(ctx context.Context, in *PermitBatchReq, opts ...grpc.CallOption) (*PermitBatchResult, error)
+	// CheckStream поток проверок: ответы отправляются по готовности, не в порядке запросов,
+	// и сопоставляются с запросами по ID.
+	CheckStream(ctx context.Context, opts ...grpc.CallOption) (Permit_CheckStreamClient, error)
 	ResetIP(ctx context.Context, in *RstIPReq, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	ResetLogin(ctx context.Context, in *RstLoginReq, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// WatchEvents решения, изменения правил и сбросы лимитов в реальном времени.
+	WatchEvents(ctx context.Context, in *WatchReq, opts ...grpc.CallOption) (Permit_WatchEventsClient, error)
 }
 
 type permitClient struct {
@@ -46,6 +53,46 @@ func (c *permitClient) CheckQuery(ctx context.Context, in *PermitReq, opts ...gr
 	return out, nil
 }
 
+func (c *permitClient) CheckQueryBatch(ctx context.Context, in *PermitBatchReq, opts ...grpc.CallOption) (*PermitBatchResult, error) {
+	out := new(PermitBatchResult)
+	err := c.cc.Invoke(ctx, "/api.Permit/CheckQueryBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permitClient) CheckStream(ctx context.Context, opts ...grpc.CallOption) (Permit_CheckStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Permit_ServiceDesc.Streams[0], "/api.Permit/CheckStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &permitCheckStreamClient{stream}
+	return x, nil
+}
+
+type Permit_CheckStreamClient interface {
+	Send(*CheckStreamReq) error
+	Recv() (*CheckStreamRes, error)
+	grpc.ClientStream
+}
+
+type permitCheckStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *permitCheckStreamClient) Send(m *CheckStreamReq) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *permitCheckStreamClient) Recv() (*CheckStreamRes, error) {
+	m := new(CheckStreamRes)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *permitClient) ResetIP(ctx context.Context, in *RstIPReq, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, "/api.Permit/ResetIP", in, out, opts...)
@@ -64,13 +111,52 @@ func (c *permitClient) ResetLogin(ctx context.Context, in *RstLoginReq, opts ...
 	return out, nil
 }
 
+func (c *permitClient) WatchEvents(ctx context.Context, in *WatchReq, opts ...grpc.CallOption) (Permit_WatchEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Permit_ServiceDesc.Streams[1], "/api.Permit/WatchEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &permitWatchEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Permit_WatchEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type permitWatchEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *permitWatchEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // PermitServer is the server API for Permit service.
 // All implementations must embed UnimplementedPermitServer
 // for forward compatibility
 type PermitServer interface {
 	CheckQuery(context.Context, *PermitReq) (*PermitResult, error)
+	// CheckQueryBatch проверка набора попыток одним вызовом, результаты в порядке запросов.
+	CheckQueryBatch(context.Context, *PermitBatchReq) (*PermitBatchResult, error)
+	// CheckStream поток проверок: ответы отправляются по готовности, не в порядке запросов,
+	// и сопоставляются с запросами по ID.
+	CheckStream(Permit_CheckStreamServer) error
 	ResetIP(context.Context, *RstIPReq) (*emptypb.Empty, error)
 	ResetLogin(context.Context, *RstLoginReq) (*emptypb.Empty, error)
+	// WatchEvents решения, изменения правил и сбросы лимитов в реальном времени.
+	WatchEvents(*WatchReq, Permit_WatchEventsServer) error
 	mustEmbedUnimplementedPermitServer()
 }
 
@@ -81,12 +167,21 @@ type UnimplementedPermitServer struct {
 func (UnimplementedPermitServer) CheckQuery(context.Context, *PermitReq) (*PermitResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CheckQuery not implemented")
 }
+func (UnimplementedPermitServer) CheckQueryBatch(context.Context, *PermitBatchReq) (*PermitBatchResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckQueryBatch not implemented")
+}
+func (UnimplementedPermitServer) CheckStream(Permit_CheckStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method CheckStream not implemented")
+}
 func (UnimplementedPermitServer) ResetIP(context.Context, *RstIPReq) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ResetIP not implemented")
 }
 func (UnimplementedPermitServer) ResetLogin(context.Context, *RstLoginReq) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ResetLogin not implemented")
 }
+func (UnimplementedPermitServer) WatchEvents(*WatchReq, Permit_WatchEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
 func (UnimplementedPermitServer) mustEmbedUnimplementedPermitServer() {}
 
 // UnsafePermitServer may be embedded to opt out of forward compatibility for this service.
@@ -118,6 +213,50 @@ func _Permit_CheckQuery_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Permit_CheckQueryBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PermitBatchReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PermitServer).CheckQueryBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Permit/CheckQueryBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PermitServer).CheckQueryBatch(ctx, req.(*PermitBatchReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Permit_CheckStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PermitServer).CheckStream(&permitCheckStreamServer{stream})
+}
+
+type Permit_CheckStreamServer interface {
+	Send(*CheckStreamRes) error
+	Recv() (*CheckStreamReq, error)
+	grpc.ServerStream
+}
+
+type permitCheckStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *permitCheckStreamServer) Send(m *CheckStreamRes) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *permitCheckStreamServer) Recv() (*CheckStreamReq, error) {
+	m := new(CheckStreamReq)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Permit_ResetIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RstIPReq)
 	if err := dec(in); err != nil {
@@ -154,6 +293,27 @@ func _Permit_ResetLogin_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Permit_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PermitServer).WatchEvents(m, &permitWatchEventsServer{stream})
+}
+
+type Permit_WatchEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type permitWatchEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *permitWatchEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Permit_ServiceDesc is the grpc.ServiceDesc for Permit service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -165,6 +325,10 @@ var Permit_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CheckQuery",
 			Handler:    _Permit_CheckQuery_Handler,
 		},
+		{
+			MethodName: "CheckQueryBatch",
+			Handler:    _Permit_CheckQueryBatch_Handler,
+		},
 		{
 			MethodName: "ResetIP",
 			Handler:    _Permit_ResetIP_Handler,
@@ -174,6 +338,18 @@ var Permit_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _Permit_ResetLogin_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CheckStream",
+			Handler:       _Permit_CheckStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _Permit_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "PermitService.proto",
 }
@@ -3,7 +3,6 @@ package grpc
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
 	"github.com/vitermakov/otusgo-final/internal/handler/grpc/dto"
@@ -11,6 +10,7 @@ import (
 	"github.com/vitermakov/otusgo-final/internal/model"
 	"github.com/vitermakov/otusgo-final/pkg/logger"
 	"github.com/vitermakov/otusgo-final/pkg/servers/grpc/rqres"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
@@ -20,6 +20,11 @@ type PermitHandlerImpl struct {
 	pb.UnimplementedPermitServer
 	services *deps.Services
 	logger   logger.Logger
+	stream   streamLimits
+}
+
+func newPermitHandler(services *deps.Services, deps *deps.Deps) PermitHandlerImpl {
+	return PermitHandlerImpl{services: services, logger: deps.Logger, stream: newStreamLimits(deps)}
 }
 
 func (p PermitHandlerImpl) CheckQuery(ctx context.Context, req *pb.PermitReq) (*pb.PermitResult, error) {
@@ -31,11 +36,29 @@ func (p PermitHandlerImpl) CheckQuery(ctx context.Context, req *pb.PermitReq) (*
 	if err != nil {
 		return nil, p.handleError(fmt.Errorf("internal error: %w", err))
 	}
-	p.logCheckQuery(query, res)
+	p.logger.Info("%s", model.CheckLogMessage(query, res))
 
 	return dto.FromPermitResultModel(res), nil
 }
 
+// CheckQueryBatch ошибка в любом из запросов отклоняет весь набор, до проверки лимитов.
+func (p PermitHandlerImpl) CheckQueryBatch(ctx context.Context, req *pb.PermitBatchReq) (*pb.PermitBatchResult, error) {
+	batch, err := dto.PermitBatchModel(req)
+	if err != nil {
+		return nil, p.handleError(fmt.Errorf("wrong check-query-batch request: %w", err))
+	}
+	results, err := p.services.PermitChecker.CheckBatch(ctx, batch)
+	if err != nil {
+		return nil, p.handleError(fmt.Errorf("internal error: %w", err))
+	}
+	// запросов в наборе много, поэтому каждый только в отладочном журнале.
+	for i, res := range results {
+		p.logger.Debug("%s", model.CheckLogMessage(batch.Queries[i], res))
+	}
+
+	return dto.FromPermitBatchResultModel(results), nil
+}
+
 func (p PermitHandlerImpl) ResetLogin(ctx context.Context, req *pb.RstLoginReq) (*emptypb.Empty, error) {
 	query, err := dto.ResetLoginModel(req)
 	if err != nil {
@@ -53,7 +76,7 @@ func (p PermitHandlerImpl) ResetIP(ctx context.Context, req *pb.RstIPReq) (*empt
 }
 
 func (p PermitHandlerImpl) reset(ctx context.Context, bucket model.LimitBucket) (*emptypb.Empty, error) {
-	_, err := p.services.PermitChecker.Reset(ctx, bucket)
+	_, err := p.services.PermitChecker.Reset(withActor(ctx), bucket)
 	if err != nil {
 		return nil, p.handleError(fmt.Errorf("reset error %s=%s: %w", bucket.Param, bucket.Value, err))
 	}
@@ -62,15 +85,32 @@ func (p PermitHandlerImpl) reset(ctx context.Context, bucket model.LimitBucket)
 	return &emptypb.Empty{}, nil
 }
 
-func (p PermitHandlerImpl) logCheckQuery(query model.PermitQuery, res model.PermitResult) {
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("запрос (login=%s, password=%s, ip=%s) -> ", query.Login, query.Password, query.IP))
-	if res.Success {
-		sb.WriteString("разрешено")
-	} else {
-		sb.WriteString("запрещено (" + res.Err.Error() + ")")
+// WatchEvents события до отключения клиента или остановки сервиса. Медленному клиенту часть событий
+// не доставляется, их число передается в Dropped каждого следующего события.
+func (p PermitHandlerImpl) WatchEvents(req *pb.WatchReq, stream pb.Permit_WatchEventsServer) error {
+	filter, err := dto.EventFilterModel(req)
+	if err != nil {
+		return p.handleError(fmt.Errorf("wrong watch request: %w", err))
+	}
+	sub, err := p.services.Events.Subscribe(filter)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-sub.Events():
+			if !ok {
+				return status.Error(codes.Unavailable, "service is shutting down")
+			}
+			if err := stream.Send(dto.FromEventModel(event, sub.Dropped())); err != nil {
+				return err
+			}
+		}
 	}
-	p.logger.Info(sb.String())
 }
 
 func (p PermitHandlerImpl) handleError(err error) error {
@@ -2,7 +2,11 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -14,6 +18,8 @@ import (
 	"github.com/vitermakov/otusgo-final/pkg/logger"
 	"github.com/vitermakov/otusgo-final/pkg/utils/closer"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type PermitSuiteTest struct {
@@ -24,6 +30,7 @@ type PermitSuiteTest struct {
 	irClient pb.IPRuleClient
 	pmClient pb.PermitClient
 	logger   logger.Logger
+	logFile  string
 	services *deps.Services
 }
 
@@ -34,9 +41,10 @@ func (ps *PermitSuiteTest) SetupTest() {
 	logLevel, err := logger.ParseLevel(cfg.Logger.Level)
 	ps.Suite.Require().NoError(err)
 
+	ps.logFile = filepath.Join(ps.T().TempDir(), "brutefp.log")
 	log, err := logger.NewLogrus(logger.Config{
 		Level:    logLevel,
-		FileName: cfg.Logger.FileName,
+		FileName: ps.logFile,
 	})
 	ps.Suite.Require().NoError(err)
 	ps.logger = log
@@ -63,7 +71,8 @@ func (ps *PermitSuiteTest) SetupTest() {
 
 	ps.services = deps.NewServices(depends, cfg)
 
-	grpcServer, closeFn := NewHandledServer(cfg.API, ps.services, depends)
+	grpcServer, closeFn, err := NewHandledServer(cfg.API, ps.services, depends)
+	ps.Suite.Require().NoError(err)
 	ps.closer.Register("GRPC Server", closeFn)
 
 	go func() {
@@ -149,6 +158,33 @@ func (ps *PermitSuiteTest) TestOutOfLimits() {
 	}
 }
 
+// TestPasswordNotLogged отказ по лимиту пароля не раскрывает пароль ни в журнале, ни в ответе.
+func (ps *PermitSuiteTest) TestPasswordNotLogged() {
+	cfg := getCfgAPI(ps.T())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	const password = "s3cret-passw0rd"
+	var res *pb.PermitResult
+	for i := 0; i <= cfg.Limits.PasswordPerMin; i++ {
+		var err error
+		res, err = ps.pmClient.CheckQuery(ctx, &pb.PermitReq{
+			Login:    fmt.Sprintf("login_%d", i),
+			Password: password,
+			IP:       fmt.Sprintf("192.168.2.%d", i+1),
+		})
+		ps.Suite.Require().NoError(err)
+	}
+	ps.Suite.Require().False(res.Success)
+	ps.Suite.Require().Contains(res.GetReason(), model.ErrDeniedByPasswordLimit.Error())
+	ps.Suite.Require().NotContains(res.GetReason(), password)
+
+	logs, err := os.ReadFile(ps.logFile)
+	ps.Suite.Require().NoError(err)
+	ps.Suite.Require().Contains(string(logs), string(model.PermitReasonPasswordLimit))
+	ps.Suite.Require().NotContains(string(logs), password)
+}
+
 // TestBlackList если добавить IP в black-list, то не разрешен ни один запрос.
 func (ps *PermitSuiteTest) TestBlackList() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
@@ -266,6 +302,133 @@ func (ps *PermitSuiteTest) TestAutoReset() {
 	ps.Suite.Require().True(res.Success)
 }
 
+// TestCheckQueryBatch результаты по каждому запросу; в атомарном наборе попытки учитываются,
+// только если разрешены все.
+func (ps *PermitSuiteTest) TestCheckQueryBatch() {
+	cfg := getCfgAPI(ps.T())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := ps.irClient.AddToBlackList(ctx, &pb.IPNet{IPNet: "192.168.6.0/24"})
+	ps.Suite.Require().NoError(err)
+	_, err = ps.irClient.AddToWhiteList(ctx, &pb.IPNet{IPNet: "192.168.7.0/24"})
+	ps.Suite.Require().NoError(err)
+
+	res, err := ps.pmClient.CheckQueryBatch(ctx, &pb.PermitBatchReq{Items: []*pb.PermitReq{
+		{Login: "batch", Password: "password", IP: "192.168.8.1"},
+		{Login: "batch", Password: "password", IP: "192.168.6.1"},
+		{Login: "batch", Password: "password", IP: "192.168.7.1"},
+	}})
+	ps.Suite.Require().NoError(err)
+	ps.Suite.Require().Len(res.GetItems(), 3)
+	ps.Suite.Require().True(res.GetItems()[0].GetSuccess())
+	ps.Suite.Require().False(res.GetItems()[1].GetSuccess())
+	ps.Suite.Require().Contains(res.GetItems()[1].GetReason(), model.ErrDeniedByRule.Error())
+	ps.Suite.Require().True(res.GetItems()[2].GetSuccess())
+
+	atomic := func(login string, count int) *pb.PermitBatchReq {
+		req := &pb.PermitBatchReq{Atomic: true}
+		for i := 1; i <= count; i++ {
+			req.Items = append(req.Items, &pb.PermitReq{
+				Login:    login,
+				Password: fmt.Sprintf("password_%d", i),
+				IP:       fmt.Sprintf("192.168.9.%d", i),
+			})
+		}
+		return req
+	}
+	// последний запрос превышает лимит по логину: набор отклонен целиком и не учтен.
+	res, err = ps.pmClient.CheckQueryBatch(ctx, atomic("atomic", cfg.Limits.LoginPerMin+1))
+	ps.Suite.Require().NoError(err)
+	for i, item := range res.GetItems() {
+		ps.Suite.Require().False(item.GetSuccess())
+		if i < cfg.Limits.LoginPerMin {
+			ps.Suite.Require().Contains(item.GetReason(), model.ErrDeniedByBatch.Error())
+		} else {
+			ps.Suite.Require().Contains(item.GetReason(), model.ErrDeniedByLoginLimit.Error())
+		}
+	}
+	res, err = ps.pmClient.CheckQueryBatch(ctx, atomic("atomic", cfg.Limits.LoginPerMin))
+	ps.Suite.Require().NoError(err)
+	for _, item := range res.GetItems() {
+		ps.Suite.Require().True(item.GetSuccess())
+	}
+	single, err := ps.pmClient.CheckQuery(ctx, &pb.PermitReq{Login: "atomic", Password: "password", IP: "192.168.9.100"})
+	ps.Suite.Require().NoError(err)
+	ps.Suite.Require().False(single.GetSuccess())
+
+	// запрет по black списку отклоняет атомарный набор без проверки лимитов.
+	req := atomic("atomic_rule", 1)
+	req.Items = append(req.Items, &pb.PermitReq{Login: "atomic_rule", Password: "password", IP: "192.168.6.1"})
+	res, err = ps.pmClient.CheckQueryBatch(ctx, req)
+	ps.Suite.Require().NoError(err)
+	ps.Suite.Require().Contains(res.GetItems()[0].GetReason(), model.ErrDeniedByBatch.Error())
+	ps.Suite.Require().Contains(res.GetItems()[1].GetReason(), model.ErrDeniedByRule.Error())
+
+	_, err = ps.pmClient.CheckQueryBatch(ctx, &pb.PermitBatchReq{})
+	ps.Suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+	_, err = ps.pmClient.CheckQueryBatch(ctx, &pb.PermitBatchReq{Items: []*pb.PermitReq{{Login: "batch", IP: "bad"}}})
+	ps.Suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+	_, err = ps.pmClient.CheckQueryBatch(ctx, atomic("atomic", model.PermitBatchMaxSize+1))
+	ps.Suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+}
+
+// TestCheckStream ответы сопоставляются по ID, ошибочный запрос не прерывает поток,
+// лимиты действуют как для CheckQuery.
+func (ps *PermitSuiteTest) TestCheckStream() {
+	cfg := getCfgAPI(ps.T())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := ps.irClient.AddToBlackList(ctx, &pb.IPNet{IPNet: "192.168.6.0/24"})
+	ps.Suite.Require().NoError(err)
+
+	stream, err := ps.pmClient.CheckStream(ctx)
+	ps.Suite.Require().NoError(err)
+	count := cfg.Limits.LoginPerMin + 1
+	for i := 1; i <= count; i++ {
+		err = stream.Send(&pb.CheckStreamReq{ID: uint64(i), Query: &pb.PermitReq{
+			Login:    "stream",
+			Password: fmt.Sprintf("password_%d", i),
+			IP:       fmt.Sprintf("192.168.10.%d", i),
+		}})
+		ps.Suite.Require().NoError(err)
+	}
+	ps.Suite.Require().NoError(stream.Send(&pb.CheckStreamReq{ID: 100, Query: &pb.PermitReq{Login: "stream", IP: "bad"}}))
+	ps.Suite.Require().NoError(stream.Send(&pb.CheckStreamReq{ID: 101, Query: &pb.PermitReq{
+		Login: "stream_rule", Password: "password", IP: "192.168.6.1",
+	}}))
+	ps.Suite.Require().NoError(stream.CloseSend())
+
+	results := make(map[uint64]*pb.CheckStreamRes)
+	for {
+		res, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		ps.Suite.Require().NoError(err)
+		ps.Suite.Require().NotContains(results, res.GetID())
+		results[res.GetID()] = res
+	}
+	ps.Suite.Require().Len(results, count+2)
+
+	// запросы проверяются параллельно, поэтому отклонен любой один из превысивших лимит по логину.
+	denied := 0
+	for i := 1; i <= count; i++ {
+		res := results[uint64(i)]
+		ps.Suite.Require().Empty(res.GetError())
+		if !res.GetResult().GetSuccess() {
+			ps.Suite.Require().Contains(res.GetResult().GetReason(), model.ErrDeniedByLoginLimit.Error())
+			denied++
+		}
+	}
+	ps.Suite.Require().Equal(1, denied)
+	ps.Suite.Require().Contains(results[100].GetError(), "wrong check-query request")
+	ps.Suite.Require().Nil(results[100].GetResult())
+	ps.Suite.Require().False(results[101].GetResult().GetSuccess())
+	ps.Suite.Require().Contains(results[101].GetResult().GetReason(), model.ErrDeniedByRule.Error())
+}
+
 func TestPermitApi(t *testing.T) {
 	suite.Run(t, new(PermitSuiteTest))
 }
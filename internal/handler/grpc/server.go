@@ -10,24 +10,103 @@ import (
 	grpcServ "github.com/vitermakov/otusgo-final/pkg/servers/grpc"
 	"github.com/vitermakov/otusgo-final/pkg/utils/closer"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
+// NewHandledServer все методы API на одном адресе.
 func NewHandledServer(
 	config config.Server, services *deps.Services, deps *deps.Deps,
-) (*grpcServ.Server, closer.CloseFunc) {
-	server := grpcServ.NewServer(servers.NewConfig(
-		config.Host,
-		config.Port,
-		false,
-	), nil, deps.Logger)
-
-	server.RegisterHandler(func(s *grpc.Server) {
+) (*grpcServ.Server, closer.CloseFunc, error) {
+	return newServer(config, services, deps, func(s *grpc.Server) {
 		pb.RegisterIPRuleServer(s, IPRuleHandlerImpl{services: services, logger: deps.Logger})
-		pb.RegisterPermitServer(s, PermitHandlerImpl{services: services, logger: deps.Logger})
+		pb.RegisterPermitServer(s, newPermitHandler(services, deps))
 	})
+}
+
+// NewCheckServer только проверки Permit для серверов приложений, остальные методы - Unimplemented.
+func NewCheckServer(
+	config config.Server, services *deps.Services, deps *deps.Deps,
+) (*grpcServ.Server, closer.CloseFunc, error) {
+	return newServer(config, services, deps, func(s *grpc.Server) {
+		pb.RegisterPermitServer(s, checkPermit{impl: newPermitHandler(services, deps)})
+	})
+}
+
+// NewAdminServer управление списками и сброс лимитов, без проверок.
+func NewAdminServer(
+	config config.Server, services *deps.Services, deps *deps.Deps,
+) (*grpcServ.Server, closer.CloseFunc, error) {
+	return newServer(config, services, deps, func(s *grpc.Server) {
+		pb.RegisterIPRuleServer(s, IPRuleHandlerImpl{services: services, logger: deps.Logger})
+		pb.RegisterPermitServer(s, adminPermit{newPermitHandler(services, deps)})
+	})
+}
+
+func newServer(
+	config config.Server, services *deps.Services, deps *deps.Deps, register grpcServ.RegisterHandlerFunc,
+) (*grpcServ.Server, closer.CloseFunc, error) {
+	srvConfig, err := config.Servers(deps.Logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	var authSrv servers.AuthService
+	if services.Auth != nil && !config.NoAuth {
+		authSrv = services.Auth
+	}
+	var metrics *grpcServ.MetricsInterceptor
+	if deps.Metrics != nil {
+		metrics = deps.Metrics.GRPC()
+	}
+	server := grpcServ.NewServer(srvConfig, authSrv, methodRoles, metrics, deps.Logger)
+	server.RegisterHandler(register)
+	if deps.Health != nil {
+		server.RegisterHandler(deps.Health.Register)
+	}
+	if config.Reflection {
+		server.RegisterHandler(func(s *grpc.Server) {
+			reflection.Register(s)
+		})
+	}
 
 	return server, func(_ context.Context) error {
 		server.Stop()
 		return nil
-	}
+	}, nil
+}
+
+// checkPermit из Permit доступны только проверки.
+type checkPermit struct {
+	pb.UnimplementedPermitServer
+	impl PermitHandlerImpl
+}
+
+func (c checkPermit) CheckQuery(ctx context.Context, req *pb.PermitReq) (*pb.PermitResult, error) {
+	return c.impl.CheckQuery(ctx, req)
+}
+
+func (c checkPermit) CheckQueryBatch(ctx context.Context, req *pb.PermitBatchReq) (*pb.PermitBatchResult, error) {
+	return c.impl.CheckQueryBatch(ctx, req)
+}
+
+func (c checkPermit) CheckStream(stream pb.Permit_CheckStreamServer) error {
+	return c.impl.CheckStream(stream)
+}
+
+// adminPermit из Permit доступны только сбросы лимитов.
+type adminPermit struct {
+	PermitHandlerImpl
+}
+
+func (adminPermit) CheckQuery(context.Context, *pb.PermitReq) (*pb.PermitResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckQuery is served on the api address")
+}
+
+func (adminPermit) CheckQueryBatch(context.Context, *pb.PermitBatchReq) (*pb.PermitBatchResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckQueryBatch is served on the api address")
+}
+
+func (adminPermit) CheckStream(pb.Permit_CheckStreamServer) error {
+	return status.Error(codes.Unimplemented, "method CheckStream is served on the api address")
 }
@@ -0,0 +1,426 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	common "github.com/vitermakov/otusgo-final/internal/app/config"
+	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/app/deps/brutecli"
+	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/handler/grpc/pb"
+	"github.com/vitermakov/otusgo-final/internal/metrics"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	"github.com/vitermakov/otusgo-final/pkg/tracing"
+	"github.com/vitermakov/otusgo-final/pkg/utils/closer"
+	"github.com/vitermakov/otusgo-final/pkg/utils/jsonx"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+// TestSeparateServers CheckQuery на TCP с авторизацией, управление на unix-сокете без нее.
+func TestSeparateServers(t *testing.T) {
+	cfg := getCfgAPI(t)
+	cfg.API.Port = 50054
+	cfg.API.Quiet = true
+	cfg.Admin = common.Server{Socket: filepath.Join(t.TempDir(), "admin.sock"), NoAuth: true}
+	cfg.Auth = config.Auth{Enabled: true}
+
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+	closes := closer.NewCloser()
+	defer closes.Close(context.Background(), log)
+
+	rateLimiter, closeFn, err := ratelimit.NewRateLimiter(cfg.Limits)
+	require.NoError(t, err)
+	closes.Register("Rate Limiter", closeFn)
+	repos, err := deps.NewRepos(cfg.Storage, nil)
+	require.NoError(t, err)
+	depends := &deps.Deps{Repos: repos, Logger: log, RateLimiter: rateLimiter}
+	services := deps.NewServices(depends, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	key, _, err := services.Auth.CreateKey(ctx, "php-login", model.RoleAdmin)
+	require.NoError(t, err)
+
+	checkServer, closeFn, err := NewCheckServer(cfg.API, services, depends)
+	require.NoError(t, err)
+	closes.Register("GRPC Check Server", closeFn)
+	adminServer, closeFn, err := NewAdminServer(cfg.Admin, services, depends)
+	require.NoError(t, err)
+	closes.Register("GRPC Admin Server", closeFn)
+	go func() {
+		_ = checkServer.Start()
+	}()
+	go func() {
+		_ = adminServer.Start()
+	}()
+
+	checkConn, err := getConn(t, cfg.API)
+	require.NoError(t, err)
+	defer checkConn.Close()
+	adminConn, err := grpc.Dial("unix:"+cfg.Admin.Socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer adminConn.Close()
+
+	withKey := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+key)
+	req := &pb.PermitReq{Login: "login", Password: "password", IP: "192.168.0.1"}
+
+	// на адресе проверок: только проверки и только с ключом.
+	_, err = pb.NewPermitClient(checkConn).CheckQuery(ctx, req, grpc.WaitForReady(true))
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	res, err := pb.NewPermitClient(checkConn).CheckQuery(withKey, req)
+	require.NoError(t, err)
+	require.True(t, res.GetSuccess())
+	batch, err := pb.NewPermitClient(checkConn).CheckQueryBatch(withKey, &pb.PermitBatchReq{Items: []*pb.PermitReq{req}})
+	require.NoError(t, err)
+	require.True(t, batch.GetItems()[0].GetSuccess())
+	_, err = pb.NewPermitClient(checkConn).ResetLogin(withKey, &pb.RstLoginReq{Login: "login"})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+	_, err = pb.NewIPRuleClient(checkConn).AddToBlackList(withKey, &pb.IPNet{IPNet: "192.168.0.0/24"})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// на сокете: управление без ключа, но без проверок.
+	rules := pb.NewIPRuleClient(adminConn)
+	_, err = rules.AddToBlackList(ctx, &pb.IPNet{IPNet: "192.168.0.0/24"}, grpc.WaitForReady(true))
+	require.NoError(t, err)
+	_, err = pb.NewPermitClient(adminConn).ResetLogin(ctx, &pb.RstLoginReq{Login: "login"})
+	require.NoError(t, err)
+	_, err = pb.NewPermitClient(adminConn).CheckQuery(ctx, req)
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+	_, err = pb.NewPermitClient(adminConn).CheckQueryBatch(ctx, &pb.PermitBatchReq{Items: []*pb.PermitReq{req}})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+	stream, err := pb.NewPermitClient(adminConn).CheckStream(ctx)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	res, err = pb.NewPermitClient(checkConn).CheckQuery(withKey, req)
+	require.NoError(t, err)
+	require.False(t, res.GetSuccess())
+}
+
+// TestHealthAndReflection health-check доступен без ключа, reflection - с любым ключом.
+func TestHealthAndReflection(t *testing.T) {
+	cfg := getCfgAPI(t)
+	cfg.API.Port = 50055
+	cfg.API.Reflection = true
+	cfg.Auth = config.Auth{Enabled: true}
+
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+	closes := closer.NewCloser()
+	defer closes.Close(context.Background(), log)
+
+	rateLimiter, closeFn, err := ratelimit.NewRateLimiter(cfg.Limits)
+	require.NoError(t, err)
+	closes.Register("Rate Limiter", closeFn)
+	repos, err := deps.NewRepos(cfg.Storage, nil)
+	require.NoError(t, err)
+	health, err := NewHealthChecker(config.Health{
+		Interval: jsonx.NewDuration(1, 'h'), Timeout: jsonx.NewDuration(1, 's'),
+	}, nil, rateLimiter, log)
+	require.NoError(t, err)
+	depends := &deps.Deps{Repos: repos, Logger: log, RateLimiter: rateLimiter, Health: health}
+	services := deps.NewServices(depends, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	key, _, err := services.Auth.CreateKey(ctx, "php-login", model.RoleChecker)
+	require.NoError(t, err)
+
+	server, closeFn, err := NewHandledServer(cfg.API, services, depends)
+	require.NoError(t, err)
+	closes.Register("GRPC Server", closeFn)
+	go func() {
+		_ = server.Start()
+	}()
+	conn, err := getConn(t, cfg.API)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	healthClient := healthpb.NewHealthClient(conn)
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		res, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{Service: service}, grpc.WaitForReady(true))
+		require.NoError(t, err)
+		return res.GetStatus()
+	}
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(""))
+	health.Start(ctx)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, check(""))
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, check(HealthLimiter))
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, check(pb.Permit_ServiceDesc.ServiceName))
+	_, err = healthClient.Check(ctx, &healthpb.HealthCheckRequest{Service: HealthStorage})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	listServices := func(ctx context.Context) ([]string, error) {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		err = stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		})
+		if err != nil {
+			return nil, err
+		}
+		res, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0)
+		for _, service := range res.GetListServicesResponse().GetService() {
+			names = append(names, service.GetName())
+		}
+		return names, nil
+	}
+	_, err = listServices(ctx)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	names, err := listServices(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+key))
+	require.NoError(t, err)
+	require.Contains(t, names, pb.Permit_ServiceDesc.ServiceName)
+	require.Contains(t, names, healthpb.Health_ServiceDesc.ServiceName)
+
+	require.NoError(t, health.Shutdown(ctx))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(""))
+}
+
+// TestTracing спаны проверки продолжают трассу клиента из traceparent.
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(tracing.Propagator)
+	defer func() {
+		_ = provider.Shutdown(context.Background())
+	}()
+
+	cfg := getCfgAPI(t)
+	cfg.API.Port = 50056
+	cfg.API.Quiet = true
+
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+	closes := closer.NewCloser()
+	defer closes.Close(context.Background(), log)
+
+	rateLimiter, closeFn, err := ratelimit.NewRateLimiter(cfg.Limits)
+	require.NoError(t, err)
+	closes.Register("Rate Limiter", closeFn)
+	repos, err := deps.NewRepos(cfg.Storage, nil)
+	require.NoError(t, err)
+	depends := &deps.Deps{Repos: repos, Logger: log, RateLimiter: rateLimiter}
+	services := deps.NewServices(depends, cfg)
+
+	server, closeFn, err := NewHandledServer(cfg.API, services, depends)
+	require.NoError(t, err)
+	closes.Register("GRPC Server", closeFn)
+	go func() {
+		_ = server.Start()
+	}()
+	conn, err := getConn(t, cfg.API)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	ctx = metadata.AppendToOutgoingContext(ctx, "traceparent", "00-"+traceID+"-"+spanID+"-01")
+	req := &pb.PermitReq{Login: "login", Password: "password", IP: "192.168.0.1"}
+	_, err = pb.NewPermitClient(conn).CheckQuery(ctx, req, grpc.WaitForReady(true))
+	require.NoError(t, err)
+
+	spans := make(map[string]tracetest.SpanStub)
+	limits := make([]string, 0)
+	for _, span := range exporter.GetSpans() {
+		require.Equal(t, traceID, span.SpanContext.TraceID().String())
+		spans[span.Name] = span
+		if span.Name == "PermitChecker.Limit" {
+			for _, attr := range span.Attributes {
+				if attr.Key == "brutefp.limit.param" {
+					limits = append(limits, attr.Value.AsString())
+				}
+			}
+		}
+	}
+	root := spans[pb.Permit_ServiceDesc.ServiceName+"/CheckQuery"]
+	require.Equal(t, spanID, root.Parent.SpanID().String())
+	require.Equal(t, trace.SpanKindServer, root.SpanKind)
+	check := spans["PermitChecker.Check"]
+	require.Equal(t, root.SpanContext.SpanID(), check.Parent.SpanID())
+	require.Equal(t, check.SpanContext.SpanID(), spans["IPRule.GetRuleTypeForIP"].Parent.SpanID())
+	require.Equal(t, spans["IPRule.GetRuleTypeForIP"].SpanContext.SpanID(), spans["IPRuleRepo.GetList"].Parent.SpanID())
+	require.ElementsMatch(t, []string{
+		model.LimitParamNameLogin, model.LimitParamNamePassword, model.LimitParamNameIP,
+	}, limits)
+}
+
+// TestWatchEvents команда watch получает решения, изменения правил и сбросы без учетных данных.
+func TestWatchEvents(t *testing.T) {
+	cfg := getCfgAPI(t)
+	cfg.API.Port = 50057
+	cfg.API.Quiet = true
+
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+	closes := closer.NewCloser()
+	defer closes.Close(context.Background(), log)
+
+	rateLimiter, closeFn, err := ratelimit.NewRateLimiter(cfg.Limits)
+	require.NoError(t, err)
+	closes.Register("Rate Limiter", closeFn)
+	repos, err := deps.NewRepos(cfg.Storage, nil)
+	require.NoError(t, err)
+	depends := &deps.Deps{Repos: repos, Logger: log, RateLimiter: rateLimiter}
+	services := deps.NewServices(depends, cfg)
+	closes.RegisterFirst("Events", services.Events.Close)
+
+	server, closeFn, err := NewHandledServer(cfg.API, services, depends)
+	require.NoError(t, err)
+	closes.Register("GRPC Server", closeFn)
+	go func() {
+		_ = server.Start()
+	}()
+	conn, err := getConn(t, cfg.API)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	permit := pb.NewPermitClient(conn)
+	_, err = permit.CheckQuery(ctx, &pb.PermitReq{Login: "admin", Password: "secret", IP: "10.0.0.1"},
+		grpc.WaitForReady(true))
+	require.NoError(t, err)
+
+	var out strings.Builder
+	results := make(chan brutecli.CmdResult, 1)
+	go func() {
+		res, _ := brutecli.NewWatch(permit, &out).Execute(ctx, []string{"count=4"})
+		results <- res
+	}()
+	require.Eventually(t, func() bool {
+		return services.Events.Subscribers() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = permit.CheckQuery(ctx, &pb.PermitReq{Login: "admin", Password: "secret", IP: "10.0.0.1"})
+	require.NoError(t, err)
+	_, err = pb.NewIPRuleClient(conn).AddToBlackList(ctx, &pb.IPNet{IPNet: "192.168.0.0/24"})
+	require.NoError(t, err)
+	_, err = permit.ResetLogin(ctx, &pb.RstLoginReq{Login: "admin"})
+	require.NoError(t, err)
+	_, err = permit.CheckQuery(ctx, &pb.PermitReq{Login: "admin", Password: "secret", IP: "192.168.0.5"})
+	require.NoError(t, err)
+
+	res := <-results
+	require.True(t, res.Success)
+	require.Equal(t, "received: 4, dropped: 0", res.Message)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Contains(t, lines[0], "decision  allow login=a*** ip=10.0.0.1 reason=within_limits")
+	require.Contains(t, lines[1], "rule_add  add 192.168.0.0/24 list=black")
+	require.Contains(t, lines[2], "reset  login=a***")
+	require.Contains(t, lines[3], "decision  deny login=a*** ip=192.168.0.5 reason=black_list")
+	require.NotContains(t, out.String(), "secret")
+	require.NotContains(t, out.String(), "admin")
+	// после count поток закрывается клиентом, подписка снимается.
+	require.Eventually(t, func() bool {
+		return services.Events.Subscribers() == 0
+	}, time.Second, 10*time.Millisecond)
+
+	res, err = brutecli.NewWatch(permit, &out).Execute(ctx, []string{"type=unknown"})
+	require.NoError(t, err)
+	require.False(t, res.Success)
+	require.Equal(t, int(codes.InvalidArgument), res.Code)
+}
+
+// TestCheckStreamLimits один запрос в обработке - ответы в порядке запросов; поток закрывается
+// по истечении Timeout, метрики учитывают потоки и запросы.
+func TestCheckStreamLimits(t *testing.T) {
+	cfg := getCfgAPI(t)
+	cfg.API.Port = 50058
+	cfg.API.Quiet = true
+
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+	closes := closer.NewCloser()
+	defer closes.Close(context.Background(), log)
+
+	rateLimiter, closeFn, err := ratelimit.NewRateLimiter(cfg.Limits)
+	require.NoError(t, err)
+	closes.Register("Rate Limiter", closeFn)
+	repos, err := deps.NewRepos(cfg.Storage, nil)
+	require.NoError(t, err)
+	m, err := metrics.New()
+	require.NoError(t, err)
+	depends := &deps.Deps{
+		Repos: repos, Logger: log, RateLimiter: rateLimiter, Metrics: m,
+		CheckStream: config.CheckStream{MaxInFlight: 1, Timeout: jsonx.NewDuration(1, 's')},
+	}
+	services := deps.NewServices(depends, cfg)
+
+	server, closeFn, err := NewHandledServer(cfg.API, services, depends)
+	require.NoError(t, err)
+	closes.Register("GRPC Server", closeFn)
+	go func() {
+		_ = server.Start()
+	}()
+	conn, err := getConn(t, cfg.API)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := pb.NewPermitClient(conn).CheckStream(ctx, grpc.WaitForReady(true))
+	require.NoError(t, err)
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, stream.Send(&pb.CheckStreamReq{ID: uint64(i), Query: &pb.PermitReq{
+			Login: fmt.Sprintf("login_%d", i), Password: "password", IP: "10.0.0.1",
+		}}))
+	}
+	for i := 1; i <= 5; i++ {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, uint64(i), res.GetID())
+		require.True(t, res.GetResult().GetSuccess())
+	}
+	require.Contains(t, metricsBody(t, m), "brutefp_check_streams 1")
+
+	// клиент не закрывает поток: сервер закрывает его сам по истечении Timeout.
+	_, err = stream.Recv()
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	require.NoError(t, ctx.Err())
+	require.Eventually(t, func() bool {
+		return !strings.Contains(metricsBody(t, m), "brutefp_check_streams 1")
+	}, time.Second, 10*time.Millisecond)
+	require.Contains(t, metricsBody(t, m), `brutefp_check_stream_requests_total{result="ok"} 5`)
+}
+
+func metricsBody(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rec.Body.String()
+}
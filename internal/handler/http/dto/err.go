@@ -0,0 +1,10 @@
+package dto
+
+import "errors"
+
+var (
+	ErrRequestEmpty = errors.New("empty query")
+	ErrBadIP        = errors.New("ip address is not well-formed")
+	ErrBadList      = errors.New("unknown list, 'white' or 'black' expected")
+	ErrBadID        = errors.New("rule id is not well-formed")
+)
@@ -0,0 +1,188 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/utils/netlist"
+)
+
+// Названия списков в API, как и в GRPC: white - allow, black - deny.
+const (
+	ListWhite = "white"
+	ListBlack = "black"
+)
+
+type Rule struct {
+	ID        string    `json:"id"`
+	List      string    `json:"list"`
+	Network   string    `json:"network"`
+	Comment   string    `json:"comment,omitempty"`
+	Priority  int       `json:"priority"`
+	Source    string    `json:"source"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	Range     string    `json:"range,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// AddRuleReq Network - сеть CIDR или диапазон start-end.
+type AddRuleReq struct {
+	List       string `json:"list"`
+	Network    string `json:"network"`
+	Comment    string `json:"comment"`
+	Priority   int    `json:"priority"`
+	OnConflict string `json:"onConflict"`
+}
+
+// UpdateRuleReq изменяются только переданные поля.
+type UpdateRuleReq struct {
+	List     *string `json:"list"`
+	Comment  *string `json:"comment"`
+	Priority *int    `json:"priority"`
+}
+
+type RuleConflict struct {
+	Rule        Rule   `json:"rule"`
+	Relation    string `json:"relation"`
+	Contradicts bool   `json:"contradicts"`
+}
+
+type AddResult struct {
+	Rule      *Rule          `json:"rule,omitempty"`
+	Skipped   bool           `json:"skipped"`
+	Conflicts []RuleConflict `json:"conflicts"`
+	Merged    []Rule         `json:"merged"`
+	Rules     []Rule         `json:"rules,omitempty"`
+}
+
+func RuleTypeModel(list string) (model.RuleType, error) {
+	switch list {
+	case ListWhite:
+		return model.RuleTypeAllow, nil
+	case ListBlack:
+		return model.RuleTypeDeny, nil
+	}
+	return model.RuleTypeNone, ErrBadList
+}
+
+func FromRuleTypeModel(typ model.RuleType) string {
+	switch typ { //nolint:exhaustive // и не должно быть
+	case model.RuleTypeAllow:
+		return ListWhite
+	case model.RuleTypeDeny:
+		return ListBlack
+	}
+	return ""
+}
+
+func RuleIDModel(id string) (uuid.UUID, error) {
+	guid, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, ErrBadID
+	}
+	return guid, nil
+}
+
+// RuleSearchModel пустой list - правила обоих списков.
+func RuleSearchModel(list string) (model.IPRuleSearch, error) {
+	search := model.IPRuleSearch{}
+	if list == "" {
+		return search, nil
+	}
+	typ, err := RuleTypeModel(list)
+	if err != nil {
+		return search, err
+	}
+	search.Type = &typ
+	return search, nil
+}
+
+func ConflictPolicyModel(policy string) (model.ConflictPolicy, error) {
+	if policy == "" {
+		// политика сервиса
+		return "", nil
+	}
+	return model.ParseConflictPolicy(policy)
+}
+
+func IPRuleInputModel(req AddRuleReq) (model.IPRuleInput, model.ConflictPolicy, error) {
+	typ, err := RuleTypeModel(req.List)
+	if err != nil {
+		return model.IPRuleInput{}, "", err
+	}
+	ipNet, rng, err := netlist.ParseNetOrRange(req.Network)
+	if err != nil {
+		return model.IPRuleInput{}, "", err
+	}
+	policy, err := ConflictPolicyModel(req.OnConflict)
+	if err != nil {
+		return model.IPRuleInput{}, "", err
+	}
+	return model.IPRuleInput{
+		Type:     typ,
+		IPNet:    ipNet,
+		Range:    rng,
+		Source:   model.RuleSourceManual,
+		Comment:  req.Comment,
+		Priority: req.Priority,
+	}, policy, nil
+}
+
+// IPRuleUpdateModel изменение найденного по ID правила rule.
+func IPRuleUpdateModel(rule model.IPRule, req UpdateRuleReq) (model.IPRuleUpdate, error) {
+	update := model.IPRuleUpdate{IPNet: rule.IPNet, Comment: req.Comment, Priority: req.Priority}
+	if req.List != nil {
+		typ, err := RuleTypeModel(*req.List)
+		if err != nil {
+			return update, err
+		}
+		update.Type = &typ
+	}
+	return update, nil
+}
+
+func FromIPRuleModel(rule model.IPRule) Rule {
+	return Rule{
+		ID:        rule.ID.String(),
+		List:      FromRuleTypeModel(rule.Type),
+		Network:   rule.IPNet.String(),
+		Comment:   rule.Comment,
+		Priority:  rule.Priority,
+		Source:    string(rule.Source),
+		CreatedBy: rule.CreatedBy,
+		Range:     rule.Range,
+		UpdatedAt: rule.UpdatedAt,
+	}
+}
+
+func FromIPRulesModel(rules []model.IPRule) []Rule {
+	result := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, FromIPRuleModel(rule))
+	}
+	return result
+}
+
+func FromAddResultModel(res model.IPRuleAddResult) AddResult {
+	result := AddResult{
+		Skipped:   res.Skipped,
+		Conflicts: make([]RuleConflict, 0, len(res.Conflicts)),
+		Merged:    FromIPRulesModel(res.Merged),
+	}
+	if res.Rule != nil {
+		rule := FromIPRuleModel(*res.Rule)
+		result.Rule = &rule
+	}
+	for _, conflict := range res.Conflicts {
+		result.Conflicts = append(result.Conflicts, RuleConflict{
+			Rule:        FromIPRuleModel(conflict.Rule),
+			Relation:    conflict.Relation.String(),
+			Contradicts: conflict.Contradicts,
+		})
+	}
+	if len(res.Rules) > 0 {
+		result.Rules = FromIPRulesModel(res.Rules)
+	}
+	return result
+}
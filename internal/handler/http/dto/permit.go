@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"net"
+
+	"github.com/vitermakov/otusgo-final/internal/model"
+)
+
+type PermitReq struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+	IP       string `json:"ip"`
+}
+
+type PermitResult struct {
+	Success bool   `json:"success"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type RstLoginReq struct {
+	Login string `json:"login"`
+}
+
+type RstIPReq struct {
+	IP string `json:"ip"`
+}
+
+func PermitModel(req PermitReq) (model.PermitQuery, error) {
+	ip := net.ParseIP(req.IP)
+	if ip == nil {
+		return model.PermitQuery{}, ErrBadIP
+	}
+	return model.PermitQuery{Login: req.Login, Password: req.Password, IP: ip}, nil
+}
+
+func ResetLoginModel(req RstLoginReq) (model.LimitBucket, error) {
+	return model.LimitBucket{Param: model.LimitParamNameLogin, Value: req.Login}, nil
+}
+
+func ResetIPModel(req RstIPReq) (model.LimitBucket, error) {
+	ip := net.ParseIP(req.IP)
+	if ip == nil {
+		return model.LimitBucket{}, ErrBadIP
+	}
+	return model.LimitBucket{Param: model.LimitParamNameIP, Value: ip.String()}, nil
+}
+
+func FromPermitResultModel(res model.PermitResult) PermitResult {
+	result := PermitResult{Success: res.Success}
+	if !result.Success {
+		result.Reason = res.Err.Error()
+	}
+	return result
+}
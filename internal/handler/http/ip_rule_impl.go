@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/handler/http/dto"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	"github.com/vitermakov/otusgo-final/pkg/servers/http/rqres"
+	"github.com/vitermakov/otusgo-final/pkg/utils/errx"
+)
+
+const rulesPrefix = "/v1/rules/"
+
+// ruleNames название типа правила для клиента.
+var ruleNames = map[model.RuleType]string{
+	model.RuleTypeDeny:  "black-list",
+	model.RuleTypeAllow: "white-list",
+}
+
+// IPRuleHandlerImpl CRUD white/black списков через REST.
+type IPRuleHandlerImpl struct {
+	services *deps.Services
+	logger   logger.Logger
+}
+
+// Collection /v1/rules: GET - список, POST - добавление.
+func (ir IPRuleHandlerImpl) Collection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ir.list(w, r)
+	case http.MethodPost:
+		ir.add(w, r)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// Item /v1/rules/{id}: GET, PATCH, DELETE.
+func (ir IPRuleHandlerImpl) Item(w http.ResponseWriter, r *http.Request) {
+	id, err := dto.RuleIDModel(strings.TrimPrefix(r.URL.Path, rulesPrefix))
+	if err != nil {
+		ir.handleError(w, errx.NotFoundNew(err, map[string]interface{}{"id": r.URL.Path}))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodPatch, http.MethodDelete:
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPatch, http.MethodDelete)
+		return
+	}
+	rule, err := ir.getByID(r.Context(), id)
+	if err != nil {
+		ir.handleError(w, err)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		rqres.WriteJSON(w, http.StatusOK, dto.FromIPRuleModel(*rule))
+	case http.MethodPatch:
+		ir.update(w, r, *rule)
+	case http.MethodDelete:
+		ir.delete(w, r, *rule)
+	}
+}
+
+func (ir IPRuleHandlerImpl) list(w http.ResponseWriter, r *http.Request) {
+	search, err := dto.RuleSearchModel(r.URL.Query().Get("list"))
+	if err != nil {
+		ir.handleError(w, fmt.Errorf("wrong rules request: %w", err))
+		return
+	}
+	rules, err := ir.services.IPRule.GetList(r.Context(), search)
+	if err != nil {
+		ir.handleError(w, fmt.Errorf("error getting rules: %w", err))
+		return
+	}
+	rqres.WriteJSON(w, http.StatusOK, dto.FromIPRulesModel(rules))
+}
+
+func (ir IPRuleHandlerImpl) add(w http.ResponseWriter, r *http.Request) {
+	var req dto.AddRuleReq
+	if err := decodeBody(w, r, &req); err != nil {
+		ir.handleError(w, fmt.Errorf("wrong add rule request: %w", err))
+		return
+	}
+	input, policy, err := dto.IPRuleInputModel(req)
+	if err != nil {
+		ir.handleError(w, fmt.Errorf("specified network is wrong: %w", err))
+		return
+	}
+	input.CreatedBy = model.ActorFromContext(r.Context())
+	result, err := ir.services.IPRule.Add(r.Context(), input, policy)
+	if err != nil {
+		ir.handleError(w, fmt.Errorf("error adding network in %s: %w", ruleNames[input.Type], err))
+		return
+	}
+	if result.Skipped {
+		ir.logger.Info("network already covered in %s", ruleNames[input.Type])
+		rqres.WriteJSON(w, http.StatusOK, dto.FromAddResultModel(result))
+		return
+	}
+	ir.logger.Info("network added successfully in %s", ruleNames[input.Type])
+	rqres.WriteJSON(w, http.StatusCreated, dto.FromAddResultModel(result))
+}
+
+func (ir IPRuleHandlerImpl) update(w http.ResponseWriter, r *http.Request, rule model.IPRule) {
+	var req dto.UpdateRuleReq
+	if err := decodeBody(w, r, &req); err != nil {
+		ir.handleError(w, fmt.Errorf("wrong update rule request: %w", err))
+		return
+	}
+	update, err := dto.IPRuleUpdateModel(rule, req)
+	if err != nil {
+		ir.handleError(w, fmt.Errorf("wrong update rule request: %w", err))
+		return
+	}
+	updated, err := ir.services.IPRule.Update(r.Context(), update)
+	if err != nil {
+		ir.handleError(w, fmt.Errorf("error updating rule: %w", err))
+		return
+	}
+	ir.logger.Info("rule updated successfully, now in %s", ruleNames[updated.Type])
+
+	rqres.WriteJSON(w, http.StatusOK, dto.FromIPRuleModel(*updated))
+}
+
+// delete правило, полученное разбиением диапазона, удаляется вместе со всем диапазоном.
+func (ir IPRuleHandlerImpl) delete(w http.ResponseWriter, r *http.Request, rule model.IPRule) {
+	if rule.Range != "" {
+		if err := ir.services.IPRule.DeleteRange(r.Context(), rule.Type, rule.Range); err != nil {
+			ir.handleError(w, fmt.Errorf("error removing range from %s: %w", ruleNames[rule.Type], err))
+			return
+		}
+		ir.logger.Info("range removed successfully from %s", ruleNames[rule.Type])
+		rqres.WriteJSON(w, http.StatusNoContent, nil)
+		return
+	}
+	if err := ir.services.IPRule.Delete(r.Context(), rule); err != nil {
+		ir.handleError(w, fmt.Errorf("error removing network from %s: %w", ruleNames[rule.Type], err))
+		return
+	}
+	ir.logger.Info("network removed successfully from %s", ruleNames[rule.Type])
+	rqres.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+func (ir IPRuleHandlerImpl) getByID(ctx context.Context, id uuid.UUID) (*model.IPRule, error) {
+	rules, err := ir.services.IPRule.GetList(ctx, model.IPRuleSearch{ID: &id})
+	if err != nil {
+		return nil, fmt.Errorf("error getting rule: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil, errx.NotFoundNew(model.ErrRuleNotFound, map[string]interface{}{"id": id.String()})
+	}
+	return &rules[0], nil
+}
+
+func (ir IPRuleHandlerImpl) handleError(w http.ResponseWriter, err error) {
+	ir.logger.Error(err.Error())
+	rqres.WriteError(w, err)
+}
@@ -0,0 +1,23 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/vitermakov/otusgo-final/internal/app/config"
+	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
+	httpServ "github.com/vitermakov/otusgo-final/pkg/servers/http"
+	"github.com/vitermakov/otusgo-final/pkg/utils/closer"
+)
+
+// NewMetricsServer страница /metrics на отдельном адресе, закрытом от клиентов API.
+func NewMetricsServer(config config.Server, deps *deps.Deps) (*httpServ.Server, closer.CloseFunc, error) {
+	srvConfig, err := config.Servers(deps.Logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", allowMethod(http.MethodGet, deps.Metrics.Handler().ServeHTTP))
+	server := httpServ.NewServer(srvConfig, mux, deps.Logger)
+
+	return server, server.Stop, nil
+}
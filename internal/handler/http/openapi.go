@@ -0,0 +1,16 @@
+package http
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPI описание REST API, при изменении маршрутов и dto обновляется вручную.
+//
+//go:embed openapi.json
+var openAPI []byte
+
+func serveOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPI)
+}
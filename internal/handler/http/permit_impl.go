@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/handler/http/dto"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	"github.com/vitermakov/otusgo-final/pkg/servers/http/rqres"
+)
+
+// PermitHandlerImpl проверка разрешения на запросы и сброс бакетов через REST.
+type PermitHandlerImpl struct {
+	services *deps.Services
+	logger   logger.Logger
+}
+
+func (p PermitHandlerImpl) CheckQuery(w http.ResponseWriter, r *http.Request) {
+	var req dto.PermitReq
+	if err := decodeBody(w, r, &req); err != nil {
+		p.handleError(w, fmt.Errorf("wrong check-query request: %w", err))
+		return
+	}
+	query, err := dto.PermitModel(req)
+	if err != nil {
+		p.handleError(w, fmt.Errorf("wrong check-query request: %w", err))
+		return
+	}
+	res, err := p.services.PermitChecker.Check(r.Context(), query)
+	if err != nil {
+		p.handleError(w, fmt.Errorf("internal error: %w", err))
+		return
+	}
+	p.logger.Info("%s", model.CheckLogMessage(query, res))
+
+	rqres.WriteJSON(w, http.StatusOK, dto.FromPermitResultModel(res))
+}
+
+func (p PermitHandlerImpl) ResetLogin(w http.ResponseWriter, r *http.Request) {
+	var req dto.RstLoginReq
+	if err := decodeBody(w, r, &req); err != nil {
+		p.handleError(w, fmt.Errorf("wrong reset login request: %w", err))
+		return
+	}
+	bucket, err := dto.ResetLoginModel(req)
+	if err != nil {
+		p.handleError(w, fmt.Errorf("wrong reset login request: %w", err))
+		return
+	}
+	p.reset(r.Context(), w, bucket)
+}
+
+func (p PermitHandlerImpl) ResetIP(w http.ResponseWriter, r *http.Request) {
+	var req dto.RstIPReq
+	if err := decodeBody(w, r, &req); err != nil {
+		p.handleError(w, fmt.Errorf("wrong reset ip request: %w", err))
+		return
+	}
+	bucket, err := dto.ResetIPModel(req)
+	if err != nil {
+		p.handleError(w, fmt.Errorf("wrong reset ip request: %w", err))
+		return
+	}
+	p.reset(r.Context(), w, bucket)
+}
+
+func (p PermitHandlerImpl) reset(ctx context.Context, w http.ResponseWriter, bucket model.LimitBucket) {
+	if _, err := p.services.PermitChecker.Reset(ctx, bucket); err != nil {
+		p.handleError(w, fmt.Errorf("reset error %s=%s: %w", bucket.Param, bucket.Value, err))
+		return
+	}
+	p.logger.Info("limit reset %s", bucket.String())
+
+	rqres.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+func (p PermitHandlerImpl) handleError(w http.ResponseWriter, err error) {
+	p.logger.Error(err.Error())
+	rqres.WriteError(w, err)
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/vitermakov/otusgo-final/internal/app/config"
+	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	"github.com/vitermakov/otusgo-final/pkg/servers"
+	httpServ "github.com/vitermakov/otusgo-final/pkg/servers/http"
+	"github.com/vitermakov/otusgo-final/pkg/servers/http/rqres"
+	"github.com/vitermakov/otusgo-final/pkg/utils/closer"
+)
+
+var ErrMethodNotAllowed = errors.New("method not allowed")
+
+func NewHandledServer(
+	config config.Server, services *deps.Services, deps *deps.Deps,
+) (*httpServ.Server, closer.CloseFunc, error) {
+	srvConfig, err := config.Servers(deps.Logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	if config.NoAuth {
+		withoutAuth := *services
+		withoutAuth.Auth = nil
+		services = &withoutAuth
+	}
+	server := httpServ.NewServer(srvConfig, NewHandler(services, deps.Logger), deps.Logger)
+
+	return server, server.Stop, nil
+}
+
+// NewHandler маршруты REST API поверх тех же сервисов, что и GRPC.
+func NewHandler(services *deps.Services, logger logger.Logger) http.Handler {
+	permit := PermitHandlerImpl{services: services, logger: logger}
+	rules := IPRuleHandlerImpl{services: services, logger: logger}
+
+	// при отключенной авторизации роли не проверяются.
+	require := func(_ model.Role, handler http.Handler) http.Handler {
+		return handler
+	}
+	if services.Auth != nil {
+		auth := httpServ.NewAuthMiddleware(services.Auth)
+		require = func(role model.Role, handler http.Handler) http.Handler {
+			return auth.Require(model.RolesFrom(role), withActor(handler))
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/v1/check", require(model.RoleChecker, allowMethod(http.MethodPost, permit.CheckQuery)))
+	mux.Handle("/v1/reset/login", require(model.RoleOperator, allowMethod(http.MethodPost, permit.ResetLogin)))
+	mux.Handle("/v1/reset/ip", require(model.RoleOperator, allowMethod(http.MethodPost, permit.ResetIP)))
+	mux.Handle("/v1/rules", require(model.RoleOperator, http.HandlerFunc(rules.Collection)))
+	mux.Handle("/v1/rules/", require(model.RoleOperator, http.HandlerFunc(rules.Item)))
+	mux.Handle("/openapi.json", allowMethod(http.MethodGet, serveOpenAPI))
+	return mux
+}
+
+func allowMethod(method string, handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			methodNotAllowed(w, method)
+			return
+		}
+		handler(w, r)
+	})
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	for _, method := range allowed {
+		w.Header().Add("Allow", method)
+	}
+	rqres.WriteJSON(w, http.StatusMethodNotAllowed, rqres.ErrorBody{Message: ErrMethodNotAllowed.Error()})
+}
+
+// maxBodySize предел тела запроса: правило или проверка занимают сотни байт.
+const maxBodySize = 64 << 10
+
+// decodeBody тело запроса в JSON, пустое тело, лишние поля и тело больше maxBodySize - ошибка.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("request body is not valid JSON: %w", err)
+	}
+	return nil
+}
+
+// withActor логин авторизованного пользователя API - автор изменений запроса.
+func withActor(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, ok := servers.UserFromContext(r.Context()); ok {
+			r = r.WithContext(model.ContextWithActor(r.Context(), user.Login))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,284 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	common "github.com/vitermakov/otusgo-final/internal/app/config"
+	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	deps "github.com/vitermakov/otusgo-final/internal/app/deps/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/handler/http/dto"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	"github.com/vitermakov/otusgo-final/pkg/servers/http/rqres"
+	"github.com/vitermakov/otusgo-final/pkg/utils/closer"
+	"github.com/vitermakov/otusgo-final/pkg/utils/jsonx"
+)
+
+type HandlerSuiteTest struct {
+	suite.Suite
+	closer *closer.Closer
+	server *httptest.Server
+	logger logger.Logger
+}
+
+func (hs *HandlerSuiteTest) SetupTest() {
+	cfg := getCfgAPI(hs.T())
+
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	hs.Suite.Require().NoError(err)
+	hs.logger = log
+
+	hs.closer = closer.NewCloser()
+
+	rateLimiter, closeFn, err := ratelimit.NewRateLimiter(cfg.Limits)
+	hs.Suite.Require().NoError(err)
+	hs.closer.Register("Rate Limiter", closeFn)
+
+	repos, err := deps.NewRepos(cfg.Storage, nil)
+	hs.Suite.Require().NoError(err)
+
+	depends := &deps.Deps{
+		Repos:       repos,
+		Logger:      log,
+		RateLimiter: rateLimiter,
+	}
+	hs.server = httptest.NewServer(NewHandler(deps.NewServices(depends, cfg), log))
+}
+
+func (hs *HandlerSuiteTest) TearDownTest() {
+	hs.server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	hs.closer.Close(ctx, hs.logger)
+}
+
+// TestCheckAndReset исчерпание лимита логина и его сброс.
+func (hs *HandlerSuiteTest) TestCheckAndReset() {
+	cfg := getCfgAPI(hs.T())
+	var res dto.PermitResult
+	for i := 1; i <= cfg.Limits.LoginPerMin+1; i++ {
+		status := hs.do(http.MethodPost, "/v1/check", dto.PermitReq{
+			Login:    "login",
+			Password: fmt.Sprintf("password_%d", i),
+			IP:       fmt.Sprintf("192.168.0.%d", i),
+		}, &res)
+		hs.Require().Equal(http.StatusOK, status)
+		hs.Require().Equal(i <= cfg.Limits.LoginPerMin, res.Success, i)
+	}
+	hs.Require().Contains(res.Reason, model.ErrDeniedByLoginLimit.Error())
+
+	status := hs.do(http.MethodPost, "/v1/reset/login", dto.RstLoginReq{Login: "login"}, nil)
+	hs.Require().Equal(http.StatusNoContent, status)
+
+	status = hs.do(http.MethodPost, "/v1/check", dto.PermitReq{
+		Login: "login", Password: "password", IP: "192.168.1.1",
+	}, &res)
+	hs.Require().Equal(http.StatusOK, status)
+	hs.Require().True(res.Success)
+}
+
+func (hs *HandlerSuiteTest) TestBadRequests() {
+	var errBody rqres.ErrorBody
+	status := hs.do(http.MethodPost, "/v1/check", dto.PermitReq{Login: "login", IP: "bad"}, &errBody)
+	hs.Require().Equal(http.StatusBadRequest, status)
+	hs.Require().Contains(errBody.Message, dto.ErrBadIP.Error())
+
+	status = hs.do(http.MethodPost, "/v1/reset/ip", dto.RstIPReq{IP: "192.168.1"}, &errBody)
+	hs.Require().Equal(http.StatusBadRequest, status)
+
+	status = hs.do(http.MethodGet, "/v1/check", nil, &errBody)
+	hs.Require().Equal(http.StatusMethodNotAllowed, status)
+
+	status = hs.do(http.MethodGet, "/v1/rules?list=grey", nil, &errBody)
+	hs.Require().Equal(http.StatusBadRequest, status)
+	hs.Require().Contains(errBody.Message, dto.ErrBadList.Error())
+
+	status = hs.do(http.MethodGet, "/v1/rules/not-uuid", nil, &errBody)
+	hs.Require().Equal(http.StatusNotFound, status)
+
+	status = hs.do(http.MethodPost, "/v1/reset/login", map[string]string{"login": "login", "ip": "10.0.0.1"}, &errBody)
+	hs.Require().Equal(http.StatusBadRequest, status)
+	hs.Require().Contains(errBody.Message, `unknown field "ip"`)
+
+	status = hs.do(http.MethodPost, "/v1/reset/login", dto.RstLoginReq{Login: strings.Repeat("a", maxBodySize)}, &errBody)
+	hs.Require().Equal(http.StatusBadRequest, status)
+	hs.Require().Contains(errBody.Message, "request body too large")
+}
+
+// TestRulesCRUD добавление, чтение, изменение и удаление правила по ID.
+func (hs *HandlerSuiteTest) TestRulesCRUD() {
+	var added dto.AddResult
+	status := hs.do(http.MethodPost, "/v1/rules", dto.AddRuleReq{
+		List: dto.ListBlack, Network: "192.168.3.0/24", Comment: "scanner",
+	}, &added)
+	hs.Require().Equal(http.StatusCreated, status)
+	hs.Require().NotNil(added.Rule)
+	hs.Require().Equal("192.168.3.0/24", added.Rule.Network)
+	hs.Require().Equal(dto.ListBlack, added.Rule.List)
+
+	// повторное добавление той же сети - логическая ошибка с кодом
+	var errBody rqres.ErrorBody
+	status = hs.do(http.MethodPost, "/v1/rules", dto.AddRuleReq{
+		List: dto.ListBlack, Network: "192.168.3.0/24", OnConflict: "reject",
+	}, &errBody)
+	hs.Require().Equal(http.StatusBadRequest, status)
+	hs.Require().NotZero(errBody.Code)
+
+	var res dto.PermitResult
+	hs.do(http.MethodPost, "/v1/check", dto.PermitReq{Login: "l", Password: "p", IP: "192.168.3.7"}, &res)
+	hs.Require().False(res.Success)
+	hs.Require().Contains(res.Reason, model.ErrDeniedByRule.Error())
+
+	var rule dto.Rule
+	status = hs.do(http.MethodGet, "/v1/rules/"+added.Rule.ID, nil, &rule)
+	hs.Require().Equal(http.StatusOK, status)
+	hs.Require().Equal("scanner", rule.Comment)
+
+	list, priority := dto.ListWhite, 5
+	status = hs.do(http.MethodPatch, "/v1/rules/"+added.Rule.ID, dto.UpdateRuleReq{
+		List: &list, Priority: &priority,
+	}, &rule)
+	hs.Require().Equal(http.StatusOK, status)
+	hs.Require().Equal(dto.ListWhite, rule.List)
+	hs.Require().Equal(5, rule.Priority)
+
+	var rules []dto.Rule
+	status = hs.do(http.MethodGet, "/v1/rules?list=white", nil, &rules)
+	hs.Require().Equal(http.StatusOK, status)
+	hs.Require().Len(rules, 1)
+
+	status = hs.do(http.MethodDelete, "/v1/rules/"+added.Rule.ID, nil, nil)
+	hs.Require().Equal(http.StatusNoContent, status)
+
+	status = hs.do(http.MethodGet, "/v1/rules/"+added.Rule.ID, nil, &errBody)
+	hs.Require().Equal(http.StatusNotFound, status)
+	hs.Require().Equal(model.ErrRuleNotFound.Error(), errBody.Message)
+}
+
+// TestRangeRule правило из диапазона удаляется вместе со всем диапазоном.
+func (hs *HandlerSuiteTest) TestRangeRule() {
+	var added dto.AddResult
+	status := hs.do(http.MethodPost, "/v1/rules", dto.AddRuleReq{
+		List: dto.ListBlack, Network: "203.0.113.10-203.0.113.77",
+	}, &added)
+	hs.Require().Equal(http.StatusCreated, status)
+	hs.Require().Len(added.Rules, 7)
+
+	status = hs.do(http.MethodDelete, "/v1/rules/"+added.Rules[3].ID, nil, nil)
+	hs.Require().Equal(http.StatusNoContent, status)
+
+	var rules []dto.Rule
+	hs.do(http.MethodGet, "/v1/rules", nil, &rules)
+	hs.Require().Empty(rules)
+}
+
+func (hs *HandlerSuiteTest) TestOpenAPI() {
+	var doc map[string]interface{}
+	status := hs.do(http.MethodGet, "/openapi.json", nil, &doc)
+	hs.Require().Equal(http.StatusOK, status)
+	hs.Require().Equal("3.0.3", doc["openapi"])
+	paths, ok := doc["paths"].(map[string]interface{})
+	hs.Require().True(ok)
+	for _, path := range []string{"/v1/check", "/v1/reset/login", "/v1/reset/ip", "/v1/rules", "/v1/rules/{id}"} {
+		hs.Require().Contains(paths, path)
+	}
+}
+
+// do запрос к тестовому серверу, ответ декодируется в out, если он задан.
+func (hs *HandlerSuiteTest) do(method, path string, body, out interface{}) int {
+	var reader io.Reader
+	if body != nil {
+		bs, err := json.Marshal(body)
+		hs.Require().NoError(err)
+		reader = bytes.NewReader(bs)
+	}
+	req, err := http.NewRequestWithContext(context.Background(), method, hs.server.URL+path, reader)
+	hs.Require().NoError(err)
+	resp, err := hs.server.Client().Do(req)
+	hs.Require().NoError(err)
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		hs.Require().NoError(json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+func TestHandlerSuite(t *testing.T) {
+	suite.Run(t, new(HandlerSuiteTest))
+}
+
+func TestAuth(t *testing.T) {
+	cfg := getCfgAPI(t)
+	cfg.Auth = config.Auth{Enabled: true}
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+	repos, err := deps.NewRepos(cfg.Storage, nil)
+	require.NoError(t, err)
+	rateLimiter, closeFn, err := ratelimit.NewRateLimiter(cfg.Limits)
+	require.NoError(t, err)
+	defer func() {
+		_ = closeFn(context.Background())
+	}()
+	services := deps.NewServices(&deps.Deps{Repos: repos, Logger: log, RateLimiter: rateLimiter}, cfg)
+	checker, _, err := services.Auth.CreateKey(context.Background(), "php-login", model.RoleChecker)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewHandler(services, log))
+	defer server.Close()
+
+	post := func(path, key string, body interface{}) int {
+		bs, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL+path, bytes.NewReader(bs))
+		require.NoError(t, err)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	check := dto.PermitReq{Login: "login", Password: "password", IP: "192.168.0.1"}
+	require.Equal(t, http.StatusUnauthorized, post("/v1/check", "", check))
+	require.Equal(t, http.StatusUnauthorized, post("/v1/check", "bfp_unknown", check))
+	require.Equal(t, http.StatusOK, post("/v1/check", checker, check))
+	require.Equal(t, http.StatusForbidden, post("/v1/rules", checker, dto.AddRuleReq{
+		List: dto.ListWhite, Network: "192.168.0.0/24",
+	}))
+
+	// описание API доступно без ключа.
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/openapi.json", nil)
+	require.NoError(t, err)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func getCfgAPI(t *testing.T) config.Config {
+	t.Helper()
+	return config.Config{
+		Limits: config.Limits{
+			Method:         "fixed_memory",
+			Store:          "memory",
+			LoginPerMin:    10,
+			PasswordPerMin: 20,
+			IPPerMin:       30,
+			BaseDuration:   jsonx.NewDuration(2, 's'),
+		},
+		Storage: common.Storage{Type: "memory"},
+	}
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Результаты запросов потока CheckStream.
+const (
+	StreamResultOK      = "ok"
+	StreamResultInvalid = "invalid"
+	StreamResultError   = "error"
+	StreamResultTimeout = "timeout"
+)
+
+// CheckStream потоки CheckStream: открытые потоки, запросы в обработке, результаты и время ответа.
+// Решения по запросам учитываются в check_decisions_total, как и для CheckQuery.
+// Методы nil допускают: метрики не собираются.
+type CheckStream struct {
+	streams  prometheus.Gauge
+	inFlight prometheus.Gauge
+	requests *prometheus.CounterVec
+	duration prometheus.Histogram
+}
+
+func newCheckStream() *CheckStream {
+	return &CheckStream{
+		streams: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "check_streams",
+			Help:      "Open CheckStream streams.",
+		}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "check_stream_in_flight",
+			Help:      "CheckStream requests being checked.",
+		}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "check_stream_requests_total",
+			Help:      "CheckStream requests by result (ok, invalid, error, timeout).",
+		}, []string{"result"}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "check_stream_request_seconds",
+			Help:      "Time from receiving a CheckStream request to its result being ready.",
+			Buckets:   []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, 1},
+		}),
+	}
+}
+
+// Describe реализация prometheus.Collector.
+func (c *CheckStream) Describe(ch chan<- *prometheus.Desc) {
+	c.streams.Describe(ch)
+	c.inFlight.Describe(ch)
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+}
+
+// Collect реализация prometheus.Collector.
+func (c *CheckStream) Collect(ch chan<- prometheus.Metric) {
+	c.streams.Collect(ch)
+	c.inFlight.Collect(ch)
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+}
+
+// StreamOpened возвращает функцию закрытия потока.
+func (c *CheckStream) StreamOpened() func() {
+	if c == nil {
+		return func() {}
+	}
+	c.streams.Inc()
+	return c.streams.Dec
+}
+
+// RequestReceived запрос принят в обработку, возвращает функцию учета ответа с результатом.
+func (c *CheckStream) RequestReceived() func(result string) {
+	if c == nil {
+		return func(string) {}
+	}
+	start := time.Now()
+	c.inFlight.Inc()
+	return func(result string) {
+		c.inFlight.Dec()
+		c.requests.WithLabelValues(result).Inc()
+		c.duration.Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,193 @@
+// Package metrics метрики brutefp для Prometheus.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/vitermakov/otusgo-final/internal/events"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+	"github.com/vitermakov/otusgo-final/internal/service"
+	grpcServ "github.com/vitermakov/otusgo-final/pkg/servers/grpc"
+)
+
+const (
+	namespace = "brutefp"
+	// rulesTimeout подсчет правил не должен задерживать сбор остальных метрик.
+	rulesTimeout = 5 * time.Second
+)
+
+// Metrics собственный реестр, а не глобальный: в тестах поднимается несколько экземпляров.
+type Metrics struct {
+	registry    *prometheus.Registry
+	decisions   *prometheus.CounterVec
+	grpc        *grpcServ.MetricsInterceptor
+	checkStream *CheckStream
+}
+
+func New() (*Metrics, error) {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		decisions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "check_decisions_total",
+			Help:      "Check decisions by result (allow, deny) and reason.",
+		}, []string{"decision", "reason"}),
+		grpc:        grpcServ.NewMetricsInterceptor(),
+		checkStream: newCheckStream(),
+	}
+	for _, collector := range []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.decisions,
+		m.grpc,
+		m.checkStream,
+	} {
+		if err := m.registry.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// GRPC interceptor для всех GRPC серверов приложения.
+func (m *Metrics) GRPC() *grpcServ.MetricsInterceptor {
+	return m.grpc
+}
+
+// CheckStream метрики потоков CheckStream.
+func (m *Metrics) CheckStream() *CheckStream {
+	return m.checkStream
+}
+
+// Handler страница /metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveDecision учет решения по запросу проверки.
+func (m *Metrics) ObserveDecision(res model.PermitResult) {
+	decision := "deny"
+	if res.Success {
+		decision = "allow"
+	}
+	m.decisions.WithLabelValues(decision, string(res.Reason)).Inc()
+}
+
+// RegisterLimiter число активных бакетов, если ограничитель умеет его считать.
+func (m *Metrics) RegisterLimiter(name string, limiter ratelimit.RateLimiter) error {
+	counter, ok := limiter.(ratelimit.BucketCounter)
+	if !ok {
+		return nil
+	}
+	return m.registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "limiter_buckets",
+		Help:        "Active rate limiter buckets.",
+		ConstLabels: prometheus.Labels{"limiter": name},
+	}, func() float64 {
+		return float64(counter.Capacity())
+	}))
+}
+
+// RegisterRules число правил в white и black списках, считается запросом Count при каждом сборе метрик.
+func (m *Metrics) RegisterRules(ipRule service.IPRule) error {
+	return m.registry.Register(&rulesCollector{
+		ipRule: ipRule,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "rules"),
+			"Rules in the white and black lists.",
+			[]string{"list"}, nil,
+		),
+	})
+}
+
+// RegisterEvents число опубликованных и потерянных событий WatchEvents и активных подписок.
+func (m *Metrics) RegisterEvents(bus *events.Bus) error {
+	for _, collector := range []prometheus.Collector{
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_published_total",
+			Help:      "Events published to the watch bus.",
+		}, func() float64 {
+			return float64(bus.Published())
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_dropped_total",
+			Help:      "Events not delivered to slow watch subscribers.",
+		}, func() float64 {
+			return float64(bus.Dropped())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "events_subscribers",
+			Help:      "Active watch subscribers.",
+		}, func() float64 {
+			return float64(bus.Subscribers())
+		}),
+	} {
+		if err := m.registry.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterDB статистика пула соединений с базой хранилища.
+func (m *Metrics) RegisterDB(name string, db *sql.DB) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// PermitChecker учет решений поверх сервиса проверки.
+func (m *Metrics) PermitChecker(next service.PermitChecker) service.PermitChecker {
+	return &permitChecker{PermitChecker: next, metrics: m}
+}
+
+type permitChecker struct {
+	service.PermitChecker
+	metrics *Metrics
+}
+
+func (p *permitChecker) Check(ctx context.Context, query model.PermitQuery) (model.PermitResult, error) {
+	res, err := p.PermitChecker.Check(ctx, query)
+	p.metrics.ObserveDecision(res)
+	return res, err
+}
+
+func (p *permitChecker) CheckBatch(ctx context.Context, batch model.PermitBatch) ([]model.PermitResult, error) {
+	results, err := p.PermitChecker.CheckBatch(ctx, batch)
+	for _, res := range results {
+		p.metrics.ObserveDecision(res)
+	}
+	return results, err
+}
+
+type rulesCollector struct {
+	ipRule service.IPRule
+	desc   *prometheus.Desc
+}
+
+func (c *rulesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *rulesCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), rulesTimeout)
+	defer cancel()
+	// один запрос с группировкой по спискам, сами правила не читаются.
+	counts, err := c.ipRule.Count(ctx, model.IPRuleSearch{})
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for list, ruleType := range map[string]model.RuleType{"white": model.RuleTypeAllow, "black": model.RuleTypeDeny} {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(counts[ruleType]), list)
+	}
+}
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/events"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+	"github.com/vitermakov/otusgo-final/internal/repository/memory"
+	"github.com/vitermakov/otusgo-final/internal/repository/sqlite"
+	"github.com/vitermakov/otusgo-final/internal/service"
+)
+
+// fakeChecker отвечает заранее заданными результатами по очереди.
+type fakeChecker struct {
+	service.PermitChecker
+	results []model.PermitResult
+}
+
+func (f *fakeChecker) Check(context.Context, model.PermitQuery) (model.PermitResult, error) {
+	res := f.results[0]
+	f.results = f.results[1:]
+	return res, nil
+}
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	m, err := New()
+	require.NoError(t, err)
+
+	checker := m.PermitChecker(&fakeChecker{results: []model.PermitResult{
+		{Success: true, Reason: model.PermitReasonWithinLimits},
+		{Success: true, Reason: model.PermitReasonWithinLimits},
+		{Err: model.ErrDeniedByIPLimit, Reason: model.PermitReasonIPLimit},
+	}})
+	for i := 0; i < 3; i++ {
+		_, err := checker.Check(ctx, model.PermitQuery{})
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2.0, testutil.ToFloat64(m.decisions.WithLabelValues("allow", "within_limits")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.decisions.WithLabelValues("deny", "ip_limit")))
+
+	limiter := ratelimit.NewFixedMemory()
+	defer limiter.Destroy()
+	require.NoError(t, m.RegisterLimiter("fixed_memory", limiter))
+	for _, bucket := range []string{"login_a", "login_b", "ip_10.0.0.1"} {
+		_, err := limiter.ExceedLimit(bucket, ratelimit.Limits{Period: time.Minute, Limit: 1})
+		require.NoError(t, err)
+	}
+
+	ipRule := service.NewIPRuleSrv(
+		memory.NewIPRuleRepo(), memory.NewIPRuleEventRepo(), memory.NewTransactor(),
+		model.PrecedenceAllowWins, model.ConflictWarn,
+	)
+	for _, rule := range []struct {
+		typ  model.RuleType
+		cidr string
+	}{
+		{model.RuleTypeAllow, "10.0.0.0/8"},
+		{model.RuleTypeDeny, "192.168.0.0/16"},
+		{model.RuleTypeDeny, "172.16.0.0/12"},
+	} {
+		_, ipNet, _ := net.ParseCIDR(rule.cidr)
+		_, err := ipRule.Add(ctx, model.IPRuleInput{Type: rule.typ, IPNet: *ipNet}, "")
+		require.NoError(t, err)
+	}
+	require.NoError(t, m.RegisterRules(ipRule))
+	bus := events.NewBus(1)
+	require.NoError(t, m.RegisterEvents(bus))
+	sub, err := bus.Subscribe(model.EventFilter{})
+	require.NoError(t, err)
+	defer sub.Close()
+	for i := 0; i < 3; i++ {
+		bus.Publish(model.NewResetEvent(model.LimitBucket{Param: model.LimitParamNameIP, Value: "10.0.0.1"}, ""))
+	}
+
+	db, closeFn, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "brutefp.db"))
+	require.NoError(t, err)
+	defer closeFn(ctx)
+	require.NoError(t, m.RegisterDB("sqlite", db))
+
+	closeStream := m.CheckStream().StreamOpened()
+	m.CheckStream().RequestReceived()(StreamResultOK)
+	m.CheckStream().RequestReceived()(StreamResultOK)
+	m.CheckStream().RequestReceived()(StreamResultInvalid)
+	m.CheckStream().RequestReceived()
+
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	res, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		`brutefp_check_decisions_total{decision="allow",reason="within_limits"} 2`,
+		`brutefp_limiter_buckets{limiter="fixed_memory"} 3`,
+		`brutefp_rules{list="white"} 1`,
+		`brutefp_rules{list="black"} 2`,
+		`go_sql_max_open_connections{db_name="sqlite"}`,
+		`brutefp_events_published_total 3`,
+		`brutefp_events_dropped_total 2`,
+		`brutefp_events_subscribers 1`,
+		`brutefp_check_streams 1`,
+		`brutefp_check_stream_in_flight 1`,
+		`brutefp_check_stream_requests_total{result="ok"} 2`,
+		`brutefp_check_stream_requests_total{result="invalid"} 1`,
+		`brutefp_check_stream_request_seconds_count 3`,
+		`go_goroutines`,
+	} {
+		require.Contains(t, string(body), line)
+	}
+	closeStream()
+	require.Equal(t, 0.0, testutil.ToFloat64(m.CheckStream().streams))
+
+	// без метрик учет не ведется.
+	var none *CheckStream
+	none.StreamOpened()()
+	none.RequestReceived()(StreamResultError)
+}
@@ -0,0 +1,18 @@
+package model
+
+import "context"
+
+// actorKey автор изменений в контексте запроса.
+type actorKey struct{}
+
+// ContextWithActor автор изменений (логин пользователя API), попадает в журнал изменений правил
+// и события сброса лимитов. Задается обработчиками API после авторизации.
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext автор изменений, пустой - если не задан.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role роль клиента API, каждая следующая включает права предыдущих.
+type Role string
+
+const (
+	// RoleChecker только проверка попыток авторизации.
+	RoleChecker Role = "checker"
+	// RoleOperator сброс лимитов и ведение white/black списков.
+	RoleOperator Role = "operator"
+	// RoleAdmin массовые операции над списками: импорт, компактизация.
+	RoleAdmin Role = "admin"
+)
+
+// roleOrder роли по возрастанию прав.
+var roleOrder = []Role{RoleChecker, RoleOperator, RoleAdmin}
+
+func ParseRole(value string) (Role, error) {
+	for _, role := range roleOrder {
+		if Role(value) == role {
+			return role, nil
+		}
+	}
+	return "", ErrRoleUnk
+}
+
+func (r Role) level() int {
+	for i, role := range roleOrder {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+// Allows есть ли у роли права required.
+func (r Role) Allows(required Role) bool {
+	return r.level() >= 0 && r.level() >= required.level()
+}
+
+// RolesFrom роли, у которых есть права required, в виде строк для servers.MethodRoles.
+func RolesFrom(required Role) []string {
+	roles := make([]string, 0, len(roleOrder))
+	for _, role := range roleOrder {
+		if role.Allows(required) {
+			roles = append(roles, string(role))
+		}
+	}
+	return roles
+}
+
+// APIKey ключ клиента API, сам ключ не хранится - только его хэш.
+type APIKey struct {
+	ID        uuid.UUID
+	Name      string
+	Hash      string
+	Role      Role
+	CreatedAt time.Time
+}
+
+type APIKeyInput struct {
+	Name string
+	Hash string
+	Role Role
+}
+
+// HashAPIKey хэш ключа для хранения и поиска. Ключи генерируются случайно
+// с достаточной энтропией, поэтому соль и медленный хэш не нужны.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
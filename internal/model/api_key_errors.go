@@ -0,0 +1,13 @@
+package model
+
+import "errors"
+
+var (
+	ErrRoleUnk = errors.New("unknown role (checker/operator/admin)")
+	// ErrAPIKeyNotFound ключа с таким хэшем или именем нет.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrAPIKeyDuplicate ключ с таким именем или хэшем уже есть.
+	ErrAPIKeyDuplicate = errors.New("api key with the same name already exists")
+	ErrAPIKeyNameEmpty = errors.New("api key name must not be empty")
+	ErrAPIKeyHashBad   = errors.New("api key hash must be sha256 in hex")
+)
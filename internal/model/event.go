@@ -0,0 +1,151 @@
+package model
+
+import (
+	"net"
+	"time"
+	"unicode/utf8"
+)
+
+// EventType вид события в потоке WatchEvents.
+type EventType string
+
+const (
+	EventTypeDecision   EventType = "decision"
+	EventTypeRuleAdd    EventType = "rule_add"
+	EventTypeRuleDelete EventType = "rule_delete"
+	EventTypeRuleUpdate EventType = "rule_update"
+	EventTypeReset      EventType = "reset"
+	// EventTypeAutoBan добавление правила с источником RuleSourceAutoBan.
+	EventTypeAutoBan EventType = "auto_ban"
+)
+
+func ParseEventType(typ string) (EventType, error) {
+	switch lit := EventType(typ); lit {
+	case EventTypeDecision, EventTypeRuleAdd, EventTypeRuleDelete, EventTypeRuleUpdate, EventTypeReset, EventTypeAutoBan:
+		return lit, nil
+	}
+	return "", ErrEventTypeUnk
+}
+
+// Event событие для внешних потребителей (SIEM). Заполнено только поле, соответствующее Type.
+// Пароль в события не попадает, логин маскируется.
+type Event struct {
+	Type     EventType
+	Time     time.Time
+	Decision *DecisionEvent
+	Rule     *IPRuleEvent
+	Reset    *ResetEvent
+}
+
+// DecisionEvent решение по запросу проверки.
+type DecisionEvent struct {
+	// Login замаскированный логин, см. RedactLogin
+	Login   string
+	IP      net.IP
+	Success bool
+	Reason  PermitReason
+}
+
+// ResetEvent сброс бакета, значение логина маскируется.
+type ResetEvent struct {
+	Param string
+	Value string
+	Actor string
+}
+
+// EventFilter отбор событий подписчика, пустые поля не ограничивают.
+type EventFilter struct {
+	Types []EventType
+	// IPNet решения и сбросы по IP из сети, изменения пересекающихся с ней правил
+	IPNet *net.IPNet
+	// DeniedOnly из решений только запреты
+	DeniedOnly bool
+}
+
+// NewDecisionEvent событие решения без пароля и с замаскированным логином.
+func NewDecisionEvent(query PermitQuery, res PermitResult) Event {
+	return Event{
+		Type: EventTypeDecision,
+		Time: time.Now(),
+		Decision: &DecisionEvent{
+			Login:   RedactLogin(query.Login),
+			IP:      query.IP,
+			Success: res.Success,
+			Reason:  res.Reason,
+		},
+	}
+}
+
+// NewResetEvent событие сброса бакета пользователем actor.
+func NewResetEvent(bucket LimitBucket, actor string) Event {
+	value := bucket.Value
+	if bucket.Param != LimitParamNameIP {
+		value = RedactLogin(value)
+	}
+	return Event{
+		Type:  EventTypeReset,
+		Time:  time.Now(),
+		Reset: &ResetEvent{Param: bucket.Param, Value: value, Actor: actor},
+	}
+}
+
+// NewRuleEvent событие изменения правила по записи журнала.
+func NewRuleEvent(event IPRuleEvent) Event {
+	typ := EventTypeRuleUpdate
+	switch event.Action {
+	case EventActionAdd:
+		typ = EventTypeRuleAdd
+		if event.After != nil && event.After.Source == RuleSourceAutoBan {
+			typ = EventTypeAutoBan
+		}
+	case EventActionDelete:
+		typ = EventTypeRuleDelete
+	case EventActionUpdate:
+	}
+	// время записи журнала выставляет хранилище, в переданную ему копию оно не попадает.
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	return Event{Type: typ, Time: event.CreatedAt, Rule: &event}
+}
+
+// RedactLogin первый символ логина, остальное скрыто: по событиям можно сопоставить
+// всплески, но не восстановить учетные данные.
+func RedactLogin(login string) string {
+	if login == "" {
+		return ""
+	}
+	r, _ := utf8.DecodeRuneInString(login)
+	return string(r) + "***"
+}
+
+// Match событие проходит фильтр.
+func (f EventFilter) Match(event Event) bool {
+	if len(f.Types) > 0 && !f.hasType(event.Type) {
+		return false
+	}
+	switch {
+	case event.Decision != nil:
+		if f.DeniedOnly && event.Decision.Success {
+			return false
+		}
+		return f.IPNet == nil || f.IPNet.Contains(event.Decision.IP)
+	case event.Reset != nil:
+		if f.IPNet == nil {
+			return true
+		}
+		return event.Reset.Param == LimitParamNameIP && f.IPNet.Contains(net.ParseIP(event.Reset.Value))
+	case event.Rule != nil:
+		return f.IPNet == nil || f.IPNet.Contains(event.Rule.IPNet.IP) || event.Rule.IPNet.Contains(f.IPNet.IP)
+	}
+	return true
+}
+
+func (f EventFilter) hasType(typ EventType) bool {
+	for _, t := range f.Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,5 @@
+package model
+
+import "errors"
+
+var ErrEventTypeUnk = errors.New("unknown event type (decision/rule_add/rule_delete/rule_update/reset/auto_ban)")
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"errors"
+	"net"
+)
+
+var ErrFeedEmpty = errors.New("feed is empty, rules are left untouched")
+
+const ErrFeedEmptyCode = 2101
+
+// FeedSync содержимое внешнего списка для сверки с правилами фида.
+type FeedSync struct {
+	Feed string
+	Type RuleType
+	Nets []net.IPNet
+}
+
+// FeedSyncResult итог сверки: Skipped - сети, уже заданные вручную или другим фидом.
+type FeedSyncResult struct {
+	Added     int
+	Removed   int
+	Unchanged int
+	Skipped   int
+}
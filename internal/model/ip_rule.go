@@ -41,19 +41,69 @@ func ParseRuleType(ruleType string) (RuleType, error) {
 	return RuleTypeNone, ErrRuleTypeUnk
 }
 
+// RuleSource откуда появилось правило.
+type RuleSource string
+
+const (
+	// RuleSourceManual правило добавлено администратором.
+	RuleSourceManual RuleSource = "manual"
+	// RuleSourceFeed правило загружено из внешнего списка (фида).
+	RuleSourceFeed RuleSource = "feed"
+	// RuleSourceAutoBan правило добавлено автоматически при срабатывании ограничений.
+	RuleSourceAutoBan RuleSource = "auto-ban"
+	// RuleSourceImport правило добавлено массовым импортом.
+	RuleSourceImport RuleSource = "import"
+)
+
+// RuleCommentMaxLen максимальная длина комментария к правилу.
+const RuleCommentMaxLen = 1024
+
+func (lit RuleSource) Valid() bool {
+	switch lit {
+	case RuleSourceManual, RuleSourceFeed, RuleSourceAutoBan, RuleSourceImport:
+		return true
+	}
+	return false
+}
+
 // IPRule сущность элемента white/black листов.
-// На данный момент мы не предусматриваем порядок применения правил.
+// Порядок применения пересекающихся правил задается model.Precedence.
 type IPRule struct {
-	ID        uuid.UUID
-	Type      RuleType
-	IPNet     net.IPNet
+	ID     uuid.UUID
+	Type   RuleType
+	IPNet  net.IPNet
+	Source RuleSource
+	// Feed имя фида для Source = RuleSourceFeed
+	Feed    string
+	Comment string
+	// CreatedBy логин пользователя API, добавившего правило
+	CreatedBy string
+	// Priority приоритет правила, учитывается при PrecedencePriority
+	Priority int
+	// Range исходный диапазон start-end, если правило получено его разбиением на CIDR
+	Range     string
 	UpdatedAt time.Time
 }
 
 // IPRuleInput структура для добавления или удаления подсети из white/black листов.
 type IPRuleInput struct {
-	Type  RuleType
-	IPNet net.IPNet
+	Type      RuleType
+	IPNet     net.IPNet
+	Source    RuleSource
+	Feed      string
+	Comment   string
+	CreatedBy string
+	Priority  int
+	// Range диапазон start-end вместо IPNet, при добавлении разбивается на минимальный набор CIDR
+	Range string
+}
+
+// SourceOrDefault источник правила, по умолчанию - ручное добавление.
+func (rc IPRuleInput) SourceOrDefault() RuleSource {
+	if rc.Source == "" {
+		return RuleSourceManual
+	}
+	return rc.Source
 }
 
 func (rc IPRuleInput) Validate() error {
@@ -64,12 +114,85 @@ func (rc IPRuleInput) Validate() error {
 			Err:   ErrRuleTypeUnk,
 		})
 	}
+	if !rc.SourceOrDefault().Valid() {
+		errs.Add(errx.NamedError{
+			Field: "Source",
+			Err:   ErrRuleSourceUnk,
+		})
+	}
+	if len([]rune(rc.Comment)) > RuleCommentMaxLen {
+		errs.Add(errx.NamedError{
+			Field: "Comment",
+			Err:   ErrRuleCommentTooLong,
+		})
+	}
+	if errs.Empty() {
+		return nil
+	}
+	return errs
+}
+
+// IPRuleUpdate изменение правила, найденного по точному совпадению сети. nil - поле не меняется.
+type IPRuleUpdate struct {
+	IPNet    net.IPNet
+	Type     *RuleType
+	Comment  *string
+	Priority *int
+	Source   *RuleSource
+	Feed     *string
+}
+
+func (ru IPRuleUpdate) Validate() error {
+	var errs errx.NamedErrors
+	if ru.Type == nil && ru.Comment == nil && ru.Priority == nil {
+		errs.Add(errx.NamedError{
+			Field: "Type",
+			Err:   ErrRuleUpdateEmpty,
+		})
+	}
+	if ru.Type != nil && !ru.Type.Valid() {
+		errs.Add(errx.NamedError{
+			Field: "Type",
+			Err:   ErrRuleTypeUnk,
+		})
+	}
+	if ru.Source != nil && !ru.Source.Valid() {
+		errs.Add(errx.NamedError{
+			Field: "Source",
+			Err:   ErrRuleSourceUnk,
+		})
+	}
+	if ru.Comment != nil && len([]rune(*ru.Comment)) > RuleCommentMaxLen {
+		errs.Add(errx.NamedError{
+			Field: "Comment",
+			Err:   ErrRuleCommentTooLong,
+		})
+	}
 	if errs.Empty() {
 		return nil
 	}
 	return errs
 }
 
+// Apply изменяет rule согласно заполненным полям.
+func (ru IPRuleUpdate) Apply(rule *IPRule) {
+	if ru.Type != nil {
+		rule.Type = *ru.Type
+	}
+	if ru.Comment != nil {
+		rule.Comment = *ru.Comment
+	}
+	if ru.Priority != nil {
+		rule.Priority = *ru.Priority
+	}
+	if ru.Source != nil {
+		rule.Source = *ru.Source
+	}
+	if ru.Feed != nil {
+		rule.Feed = *ru.Feed
+	}
+}
+
 // IPRuleSearch структура для поиска правил.
 type IPRuleSearch struct {
 	// UUID правила
@@ -80,4 +203,15 @@ type IPRuleSearch struct {
 	IPNet *net.IPNet
 	// IPNetExact точное соответствие (false - значит IPNet IP/подсеть входит в указанную в правиле)
 	IPNetExact bool
+	// IPNetOverlap правила, пересекающиеся с IPNet: включающие ее или входящие в нее.
+	// Учитывается, если IPNetExact = false
+	IPNetOverlap bool
+	// IPs правила, включающие хотя бы один из адресов
+	IPs []net.IP
+	// Source источник правила
+	Source *RuleSource
+	// Feed имя фида
+	Feed *string
+	// Range правила, полученные разбиением диапазона
+	Range *string
 }
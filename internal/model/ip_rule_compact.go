@@ -0,0 +1,19 @@
+package model
+
+// IPRuleCompact запрос сжатия списков: смежные и вложенные сети заменяются минимальным покрытием.
+type IPRuleCompact struct {
+	// Type список для сжатия, RuleTypeNone - оба
+	Type RuleType
+	// DryRun только расчет изменений без записи в хранилище.
+	DryRun bool
+	// Actor автор изменений для журнала
+	Actor string
+}
+
+// IPRuleCompactResult результат сжатия. Applied = false, если изменения не записывались.
+type IPRuleCompactResult struct {
+	Removed []IPRule
+	// Added новые правила, при DryRun - без ID и времени изменения
+	Added   []IPRule
+	Applied bool
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrConflictPolicyUnk = errors.New("unknown conflict policy (reject/warn/merge)")
+
+// ConflictPolicy поведение при добавлении сети, пересекающейся с существующими правилами.
+type ConflictPolicy string
+
+const (
+	// ConflictReject любое пересечение - ошибка, правило не добавляется.
+	ConflictReject ConflictPolicy = "reject"
+	// ConflictWarn правило добавляется, пересечения возвращаются в ответе.
+	ConflictWarn ConflictPolicy = "warn"
+	// ConflictMerge избыточные правила того же списка объединяются: новое правило не добавляется,
+	// если уже покрыто, а покрываемые им правила удаляются. Противоречия - как при ConflictWarn.
+	ConflictMerge ConflictPolicy = "merge"
+)
+
+// ParseConflictPolicy пустое значение - ConflictWarn, как и до появления проверки.
+func ParseConflictPolicy(value string) (ConflictPolicy, error) {
+	switch ConflictPolicy(value) {
+	case "":
+		return ConflictWarn, nil
+	case ConflictReject, ConflictWarn, ConflictMerge:
+		return ConflictPolicy(value), nil
+	}
+	return ConflictWarn, ErrConflictPolicyUnk
+}
+
+// ConflictRelation как существующее правило относится к добавляемой сети.
+type ConflictRelation int
+
+const (
+	// ConflictCovering существующее правило покрывает добавляемую сеть.
+	ConflictCovering ConflictRelation = iota
+	// ConflictCovered добавляемая сеть покрывает существующее правило.
+	ConflictCovered
+)
+
+func (lit ConflictRelation) String() string {
+	switch lit {
+	case ConflictCovering:
+		return "covering"
+	case ConflictCovered:
+		return "covered"
+	}
+	return ""
+}
+
+// RuleConflict существующее правило, пересекающееся с добавляемым.
+type RuleConflict struct {
+	Rule     IPRule
+	Relation ConflictRelation
+	// Contradicts правило из другого списка
+	Contradicts bool
+}
+
+func (rc RuleConflict) String() string {
+	var verb string
+	switch rc.Relation {
+	case ConflictCovering:
+		verb = "covered by"
+	case ConflictCovered:
+		verb = "covers"
+	}
+	msg := fmt.Sprintf("%s %s rule %s", verb, rc.Rule.Type, rc.Rule.IPNet.String())
+	if rc.Contradicts {
+		msg += " (contradicts)"
+	}
+	return msg
+}
+
+// IPRuleAddResult результат добавления правила.
+type IPRuleAddResult struct {
+	// Rule добавленное правило, при Skipped - покрывающее его существующее
+	Rule *IPRule
+	// Conflicts пересечения с существующими правилами на момент добавления
+	Conflicts []RuleConflict
+	// Merged правила, удаленные как покрываемые новым (ConflictMerge)
+	Merged []IPRule
+	// Rules правила, на которые разбит добавленный диапазон
+	Rules []IPRule
+	// Skipped правило не добавлено, т.к. уже покрыто правилом того же списка (ConflictMerge)
+	Skipped bool
+}
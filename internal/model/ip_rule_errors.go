@@ -5,10 +5,30 @@ import "errors"
 var (
 	ErrRuleTypeUnk  = errors.New("unknown rule type (allow/deny)")
 	ErrRuleNotFound = errors.New("rule fot specified network not found")
+	// ErrRuleDuplicate хранилище уже содержит правило того же типа с той же сетью.
+	ErrRuleDuplicate = errors.New("rule for specified network already exists")
+	// ErrRuleAmbiguous сеть есть в обоих списках, изменение не определяет, какое правило менять.
+	ErrRuleAmbiguous = errors.New("rule for specified network is in both lists")
+
+	ErrRuleSourceUnk      = errors.New("unknown rule source (manual/feed/auto-ban/import)")
+	ErrRuleCommentTooLong = errors.New("rule comment is too long")
+	ErrRuleUpdateEmpty    = errors.New("nothing to update: type, comment or priority must be specified")
 )
 
 const (
 	ErrIPRuleNetDuplicateCode = 2001
+	ErrIPRuleImportNoneCode   = 2002
+	ErrIPRuleConflictCode     = 2003
+	ErrIPRuleNetAmbiguousCode = 2004
+)
+
+var (
+	ErrIPRuleNetDuplicate = errors.New("specified network already exists")
+	ErrIPRuleConflict     = errors.New("specified network overlaps existing rules")
+	ErrIPRuleNetAmbiguous = errors.New("specified network is in both lists, delete one of the rules first")
 )
 
-var ErrIPRuleNetDuplicate = errors.New("specified network already exists")
+var (
+	ErrIPRuleNetInBatch = errors.New("network is duplicated in the imported batch")
+	ErrIPRuleImportNone = errors.New("nothing to import")
+)
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventAction вид изменения правила.
+type EventAction string
+
+const (
+	EventActionAdd    EventAction = "add"
+	EventActionDelete EventAction = "delete"
+	EventActionUpdate EventAction = "update"
+)
+
+// IPRuleEvent запись истории изменений white/black листов. Записи только добавляются.
+type IPRuleEvent struct {
+	ID     uuid.UUID
+	RuleID uuid.UUID
+	Action EventAction
+	// Actor логин пользователя API или служебный источник (например, feed:<имя>)
+	Actor string
+	IPNet net.IPNet
+	// Before состояние правила до изменения, nil для add
+	Before *IPRule
+	// After состояние правила после изменения, nil для delete
+	After     *IPRule
+	CreatedAt time.Time
+}
+
+// IPRuleEventSearch структура для поиска записей истории.
+type IPRuleEventSearch struct {
+	RuleID *uuid.UUID
+	IPNet  *net.IPNet
+	// IPNetExact точное соответствие сети, иначе - все сети, содержащие IPNet
+	IPNetExact bool
+	// Limit ограничение количества последних записей, 0 - без ограничения
+	Limit int
+}
+
+// NewIPRuleEvent событие изменения правила, before или after может быть nil.
+func NewIPRuleEvent(action EventAction, actor string, before, after *IPRule) IPRuleEvent {
+	event := IPRuleEvent{
+		ID:     uuid.New(),
+		Action: action,
+		Actor:  actor,
+		Before: before,
+		After:  after,
+	}
+	for _, rule := range []*IPRule{after, before} {
+		if rule != nil {
+			event.RuleID = rule.ID
+			event.IPNet = rule.IPNet
+			break
+		}
+	}
+	return event
+}
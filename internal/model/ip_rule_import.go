@@ -0,0 +1,63 @@
+package model
+
+// ImportAction что будет (или было) сделано с правилом при импорте.
+type ImportAction int
+
+const (
+	ImportActionAdd ImportAction = iota
+	ImportActionExists
+	ImportActionError
+)
+
+func (lit ImportAction) String() string {
+	switch lit {
+	case ImportActionAdd:
+		return "add"
+	case ImportActionExists:
+		return "exists"
+	case ImportActionError:
+		return "error"
+	}
+	return ""
+}
+
+// IPRuleImport запрос массового добавления правил.
+type IPRuleImport struct {
+	Rules []IPRuleInput
+	// Atomic все или ничего: если хотя бы одно правило не может быть добавлено, не добавляется ни одно.
+	Atomic bool
+	// DryRun только расчет изменений без записи в хранилище.
+	DryRun bool
+}
+
+// IPRuleImportItem результат импорта одного правила.
+type IPRuleImportItem struct {
+	Input  IPRuleInput
+	Action ImportAction
+	Err    error
+}
+
+// IPRuleImportResult результат импорта. Applied = false, если изменения не записывались
+// (DryRun или отказ в режиме Atomic).
+type IPRuleImportResult struct {
+	Items   []IPRuleImportItem
+	Applied bool
+	Added   int
+	Skipped int
+	Failed  int
+}
+
+// Count пересчитывает итоговые счетчики по элементам.
+func (r *IPRuleImportResult) Count() {
+	r.Added, r.Skipped, r.Failed = 0, 0, 0
+	for _, item := range r.Items {
+		switch item.Action {
+		case ImportActionAdd:
+			r.Added++
+		case ImportActionExists:
+			r.Skipped++
+		case ImportActionError:
+			r.Failed++
+		}
+	}
+}
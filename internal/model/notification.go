@@ -0,0 +1,62 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotifyType вид уведомления вебхука.
+type NotifyType string
+
+const (
+	// NotifyTypeIPBanned сеть попала в black список: вручную, импортом или автобаном.
+	// Синхронизация фидов и сжатие списков не оповещают, см. ContextWithoutBanNotify.
+	NotifyTypeIPBanned NotifyType = "ip_banned"
+	// NotifyTypeLoginLocked превышен лимит попыток по логину.
+	NotifyTypeLoginLocked NotifyType = "login_locked"
+	// NotifyTypePasswordLimit превышен лимит попыток по паролю.
+	NotifyTypePasswordLimit NotifyType = "password_limit"
+	// NotifyTypeIPLimit превышен лимит попыток с IP.
+	NotifyTypeIPLimit NotifyType = "ip_limit"
+)
+
+func ParseNotifyType(typ string) (NotifyType, error) {
+	switch lit := NotifyType(typ); lit {
+	case NotifyTypeIPBanned, NotifyTypeLoginLocked, NotifyTypePasswordLimit, NotifyTypeIPLimit:
+		return lit, nil
+	}
+	return "", ErrNotifyTypeUnk
+}
+
+// noBanNotifyKey изменения правил без уведомлений о блокировках.
+type noBanNotifyKey struct{}
+
+// ContextWithoutBanNotify изменения правил в контексте попадают в журнал, но не оповещаются
+// как блокировки. Так меняют списки синхронизация фидов (сотни сетей за раз) и сжатие
+// (заменяет уже заблокированные сети их покрытием).
+func ContextWithoutBanNotify(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noBanNotifyKey{}, true)
+}
+
+// BanNotifyDisabled true - контекст получен из ContextWithoutBanNotify.
+func BanNotifyDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noBanNotifyKey{}).(bool)
+	return disabled
+}
+
+// Notification уведомление в очереди на отправку (outbox). Тело запроса формируется при постановке
+// в очередь, поэтому после перезапуска сервиса отправляется то же самое.
+type Notification struct {
+	ID uuid.UUID
+	// Webhook имя вебхука из конфигурации
+	Webhook string
+	Type    NotifyType
+	Payload []byte
+	// Attempts число неудачных попыток доставки
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+}
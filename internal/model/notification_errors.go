@@ -0,0 +1,9 @@
+package model
+
+import "errors"
+
+var (
+	ErrNotifyTypeUnk = errors.New("unknown notification type (ip_banned/login_locked/password_limit/ip_limit)")
+	// ErrNotificationNotFound уведомления нет в очереди: доставлено или отброшено.
+	ErrNotificationNotFound = errors.New("notification not found")
+)
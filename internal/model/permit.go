@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"net"
 )
 
@@ -17,11 +18,38 @@ type PermitQuery struct {
 	IP       net.IP
 }
 
+// PermitReason причина решения по запросу проверки.
+type PermitReason string
+
+const (
+	PermitReasonWhiteList     PermitReason = "white_list"
+	PermitReasonBlackList     PermitReason = "black_list"
+	PermitReasonWithinLimits  PermitReason = "within_limits"
+	PermitReasonLoginLimit    PermitReason = "login_limit"
+	PermitReasonPasswordLimit PermitReason = "password_limit"
+	PermitReasonIPLimit       PermitReason = "ip_limit"
+	PermitReasonInternal      PermitReason = "internal"
+	// PermitReasonBatch запрос прошел бы проверку, но в наборе "все или ничего" запрещен другой запрос.
+	PermitReasonBatch PermitReason = "batch_denied"
+)
+
+// PermitBatchMaxSize наибольшее число запросов в PermitBatch.
+const PermitBatchMaxSize = 100
+
+// PermitBatch набор запросов проверки одного обращения клиента (например, основная и резервная учетные записи).
+type PermitBatch struct {
+	Queries []PermitQuery
+	// Atomic попытки учитываются в лимитах, только если разрешены все запросы набора.
+	// Иначе не учитывается ни одна, а запросы, прошедшие бы проверку, запрещаются с PermitReasonBatch
+	Atomic bool
+}
+
 // PermitResult результат проверки на bruteforce.
 type PermitResult struct {
 	Success bool
 	Err     error
 	ErrCode int
+	Reason  PermitReason
 }
 
 // LimitBucket запрос на сброс блокировки.
@@ -45,3 +73,13 @@ func (lb LimitBucket) Name() string {
 func (lb LimitBucket) String() string {
 	return lb.Param + ": " + lb.Value
 }
+
+// CheckLogMessage запись журнала о проверке для всех транспортов. Пароль и текст ошибки
+// в нее не попадают: в ошибке превышения лимита есть значение бакета, то есть и пароль.
+func CheckLogMessage(query PermitQuery, res PermitResult) string {
+	decision := "запрещено"
+	if res.Success {
+		decision = "разрешено"
+	}
+	return fmt.Sprintf("запрос (login=%s, ip=%s) -> %s (%s)", query.Login, query.IP, decision, res.Reason)
+}
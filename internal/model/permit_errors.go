@@ -9,6 +9,7 @@ var (
 	ErrDeniedByPasswordLimit = errors.New("the limit of requests has been reached (password)")
 	ErrDeniedByIPLimit       = errors.New("the limit of requests has been reached (ip)")
 	ErrWrongResetName        = errors.New(`unknown bucket reset parameter. 'login' или 'ip' expected`)
+	ErrDeniedByBatch         = errors.New("another query of the atomic batch has been denied")
 )
 
 const (
@@ -18,4 +19,5 @@ const (
 	ErrDeniedByPasswordLimitCode = 3004
 	ErrDeniedByIPLimitCode       = 3005
 	ErrWrongResetNameCode        = 3006
+	ErrDeniedByBatchCode         = 3007
 )
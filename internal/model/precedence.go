@@ -0,0 +1,30 @@
+package model
+
+import "errors"
+
+var ErrPrecedenceUnk = errors.New("unknown rule precedence (allow-wins/deny-wins/most-specific/priority)")
+
+// Precedence порядок выбора решения, если IP подходит под несколько правил.
+type Precedence string
+
+const (
+	// PrecedenceAllowWins любое подходящее правило white-листа разрешает запрос.
+	PrecedenceAllowWins Precedence = "allow-wins"
+	// PrecedenceDenyWins любое подходящее правило black-листа запрещает запрос.
+	PrecedenceDenyWins Precedence = "deny-wins"
+	// PrecedenceMostSpecific решает правило с самым длинным префиксом.
+	PrecedenceMostSpecific Precedence = "most-specific"
+	// PrecedencePriority решает правило с наибольшим приоритетом, при равенстве - с самым длинным префиксом.
+	PrecedencePriority Precedence = "priority"
+)
+
+// ParsePrecedence пустое значение - allow-wins, исторически принятый порядок.
+func ParsePrecedence(value string) (Precedence, error) {
+	switch Precedence(value) {
+	case "":
+		return PrecedenceAllowWins, nil
+	case PrecedenceAllowWins, PrecedenceDenyWins, PrecedenceMostSpecific, PrecedencePriority:
+		return Precedence(value), nil
+	}
+	return PrecedenceAllowWins, ErrPrecedenceUnk
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/model"
+)
+
+// message тело уведомления в формате json. Логин замаскирован так же, как в WatchEvents.
+type message struct {
+	ID   string           `json:"id"`
+	Type model.NotifyType `json:"type"`
+	Time time.Time        `json:"time"`
+	// Text краткое описание для людей, в формате slack передается только оно
+	Text    string `json:"text"`
+	IPNet   string `json:"ipNet,omitempty"`
+	Source  string `json:"source,omitempty"`
+	Actor   string `json:"actor,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	IP      string `json:"ip,omitempty"`
+	Login   string `json:"login,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+
+	// key ограничение частоты: уведомления одного типа об одном IP, сети или логине
+	key string
+}
+
+// newMessage уведомление по событию шины, false - событие не требует оповещения.
+func newMessage(event model.Event) (message, bool) {
+	msg := message{Time: event.Time}
+	switch {
+	case event.Rule != nil:
+		before, after := event.Rule.Before, event.Rule.After
+		// в black список: добавление или перенос из white.
+		if after == nil || after.Type != model.RuleTypeDeny || (before != nil && before.Type == model.RuleTypeDeny) {
+			return msg, false
+		}
+		msg.Type = model.NotifyTypeIPBanned
+		msg.IPNet = after.IPNet.String()
+		msg.Source = string(after.Source)
+		msg.Actor = event.Rule.Actor
+		msg.Comment = after.Comment
+		msg.key = msg.IPNet
+		msg.Text = fmt.Sprintf("%s added to the black list (%s)", msg.IPNet, msg.Source)
+	case event.Decision != nil && !event.Decision.Success:
+		decision := event.Decision
+		msg.IP = decision.IP.String()
+		msg.Reason = string(decision.Reason)
+		msg.key = msg.IP
+		switch decision.Reason { //nolint:exhaustive // остальные причины не оповещаются
+		case model.PermitReasonLoginLimit:
+			msg.Type = model.NotifyTypeLoginLocked
+			msg.Login = decision.Login
+			msg.key = decision.Login + "@" + msg.IP
+			msg.Text = fmt.Sprintf("login %s locked out: too many attempts, last from %s", msg.Login, msg.IP)
+		case model.PermitReasonPasswordLimit:
+			msg.Type = model.NotifyTypePasswordLimit
+			msg.Text = fmt.Sprintf("password attempts limit reached, last from %s", msg.IP)
+		case model.PermitReasonIPLimit:
+			msg.Type = model.NotifyTypeIPLimit
+			msg.Text = fmt.Sprintf("%s exceeded the attempts limit", msg.IP)
+		default:
+			return msg, false
+		}
+	default:
+		return msg, false
+	}
+	return msg, true
+}
+
+// body тело запроса в формате вебхука.
+func (msg message) body(id uuid.UUID, format string) ([]byte, error) {
+	msg.ID = id.String()
+	if format == config.WebhookFormatSlack {
+		return json.Marshal(struct {
+			Text string `json:"text"`
+		}{Text: "brutefp: " + msg.Text})
+	}
+	return json.Marshal(msg)
+}
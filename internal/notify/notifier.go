@@ -0,0 +1,473 @@
+// Package notify уведомления о блокировках на вебхуки. Уведомления сначала сохраняются
+// в очередь (outbox) и только потом отправляются, поэтому переживают перезапуск сервиса
+// и недоступность получателя. Блокировки сетей ставятся в очередь в транзакции изменения
+// правила, превышения лимитов - по событиям шины.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/google/uuid"
+	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/events"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+)
+
+const (
+	// batchSize уведомлений за один проход по очереди.
+	batchSize = 100
+
+	HeaderEvent     = "X-Brutefp-Event"
+	HeaderDelivery  = "X-Brutefp-Delivery"
+	HeaderTimestamp = "X-Brutefp-Timestamp"
+	// HeaderSignature "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+	HeaderSignature = "X-Brutefp-Signature"
+)
+
+var (
+	ErrWebhookName   = errors.New("webhook name is empty or duplicated")
+	ErrWebhookURL    = errors.New("webhook url must be http(s)")
+	ErrWebhookFormat = errors.New("webhook format must be json or slack")
+	ErrBadStatus     = errors.New("unexpected response status")
+)
+
+// webhook настройки одного вебхука, проверенные и приведенные к внутренним типам.
+type webhook struct {
+	config.Webhook
+	// types пусто - все типы
+	types  map[model.NotifyType]struct{}
+	limits ratelimit.Limits
+}
+
+func (w webhook) accepts(typ model.NotifyType) bool {
+	if len(w.types) == 0 {
+		return true
+	}
+	_, ok := w.types[typ]
+	return ok
+}
+
+type Notifier struct {
+	webhooks      []webhook
+	maxAttempts   int
+	retryDelay    time.Duration
+	maxRetryDelay time.Duration
+	pollInterval  time.Duration
+	// lease на сколько выбранные уведомления скрываются от других экземпляров: хватает
+	// на отправку всей выборки, даже если каждый получатель отвечает до таймаута.
+	lease time.Duration
+
+	outbox  repository.NotificationOutbox
+	limiter *ratelimit.FixedMemory
+	client  *http.Client
+	clock   clock.Clock
+	logger  logger.Logger
+
+	// wake отправка сразу после постановки в очередь, не дожидаясь pollInterval
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewNotifier(
+	cfg config.Notify, outbox repository.NotificationOutbox, clk clock.Clock, logger logger.Logger,
+) (*Notifier, error) {
+	n := &Notifier{
+		maxAttempts: cfg.MaxAttempts,
+		outbox:      outbox,
+		limiter:     ratelimit.NewFixedMemoryClock(clk),
+		clock:       clk,
+		logger:      logger,
+		wake:        make(chan struct{}, 1),
+	}
+	var err error
+	if n.retryDelay, err = cfg.RetryDelay.AsDuration(); err != nil {
+		return nil, err
+	}
+	if n.maxRetryDelay, err = cfg.MaxRetryDelay.AsDuration(); err != nil {
+		return nil, err
+	}
+	if n.pollInterval, err = cfg.PollInterval.AsDuration(); err != nil {
+		return nil, err
+	}
+	timeout, err := cfg.Timeout.AsDuration()
+	if err != nil {
+		return nil, err
+	}
+	n.client = &http.Client{Timeout: timeout}
+	n.lease = timeout * batchSize
+
+	names := make(map[string]struct{}, len(cfg.Webhooks))
+	for _, item := range cfg.Webhooks {
+		hook, err := parseWebhook(item)
+		if err != nil {
+			return nil, fmt.Errorf("webhook '%s': %w", item.Name, err)
+		}
+		if _, ok := names[hook.Name]; ok {
+			return nil, fmt.Errorf("webhook '%s': %w", item.Name, ErrWebhookName)
+		}
+		names[hook.Name] = struct{}{}
+		n.webhooks = append(n.webhooks, hook)
+	}
+	return n, nil
+}
+
+// RuleEventRepo блокировки сетей ставятся в очередь при записи изменения правила в журнал,
+// в той же транзакции: изменение не зафиксируется без уведомления, а откаченное не оповещается.
+// Изменения в контексте model.ContextWithoutBanNotify не оповещаются.
+func (n *Notifier) RuleEventRepo(repo repository.IPRuleEvent) repository.IPRuleEvent {
+	return &ruleEventRepo{IPRuleEvent: repo, notifier: n}
+}
+
+type ruleEventRepo struct {
+	repository.IPRuleEvent
+	notifier *Notifier
+}
+
+func (r *ruleEventRepo) Add(ctx context.Context, event model.IPRuleEvent) error {
+	if err := r.IPRuleEvent.Add(ctx, event); err != nil {
+		return err
+	}
+	if model.BanNotifyDisabled(ctx) {
+		return nil
+	}
+	// до фиксации отправитель уведомление не увидит, его будит событие шины после фиксации.
+	_, err := r.notifier.enqueue(ctx, model.NewRuleEvent(event))
+	return err
+}
+
+// Transactor ограничение частоты для уведомлений, поставленных в очередь в транзакции,
+// применяется после ее фиксации: откаченное изменение не расходует лимит. Превысившие
+// лимит уведомления удаляются из очереди сразу после фиксации.
+func (n *Notifier) Transactor(tx repository.Transactor) repository.Transactor {
+	return &transactor{Transactor: tx, notifier: n}
+}
+
+// pendingKey уведомления транзакции, ожидающие проверки лимита частоты.
+type pendingKey struct{}
+
+type pending struct {
+	items []pendingItem
+}
+
+type pendingItem struct {
+	id     uuid.UUID
+	hook   webhook
+	msg    message
+	bucket string
+}
+
+type transactor struct {
+	repository.Transactor
+	notifier *Notifier
+}
+
+func (t *transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(pendingKey{}).(*pending); ok {
+		return t.Transactor.InTx(ctx, fn)
+	}
+	p := &pending{}
+	if err := t.Transactor.InTx(context.WithValue(ctx, pendingKey{}, p), fn); err != nil {
+		return err
+	}
+	t.notifier.charge(ctx, p.items)
+	return nil
+}
+
+// charge расход лимита частоты уведомлениями зафиксированной транзакции. При ошибке лимита
+// уведомление остается в очереди: лучше лишнее уведомление, чем потерянное.
+func (n *Notifier) charge(ctx context.Context, items []pendingItem) {
+	for _, item := range items {
+		exceed, err := n.limiter.ExceedLimit(item.bucket, item.hook.limits)
+		if err != nil {
+			n.logger.Error("notify %s: rate limit: %s", item.hook.Name, err.Error())
+			continue
+		}
+		if !exceed {
+			continue
+		}
+		n.logger.Debug("notify %s: %s about %s suppressed by rate limit", item.hook.Name, item.msg.Type, item.msg.key)
+		if err := n.outbox.Delete(ctx, item.id); err != nil && !errors.Is(err, model.ErrNotificationNotFound) {
+			n.logger.Error("notify: outbox: %s", err.Error())
+		}
+	}
+}
+
+// Start подписка на события и отправка очереди, в том числе оставшейся с прошлого запуска.
+// Из шины в очередь ставятся только запреты по лимитам: потерянное при переполнении буфера
+// или падении событие теряет и уведомление. Изменения правил только будят отправку.
+func (n *Notifier) Start(ctx context.Context, bus *events.Bus) error {
+	sub, err := bus.Subscribe(model.EventFilter{
+		Types: []model.EventType{
+			model.EventTypeDecision, model.EventTypeRuleAdd, model.EventTypeRuleUpdate, model.EventTypeAutoBan,
+		},
+		DeniedOnly: true,
+	})
+	if err != nil {
+		return err
+	}
+	ctx, n.cancel = context.WithCancel(ctx)
+	n.wg.Add(2)
+	go func() {
+		defer n.wg.Done()
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-sub.Events():
+				if !ok {
+					return
+				}
+				if event.Decision == nil {
+					n.wakeUp()
+					continue
+				}
+				if err := n.Enqueue(ctx, event); err != nil {
+					n.logger.Error("notify: enqueue failed: %s", err.Error())
+				}
+			}
+		}
+	}()
+	go func() {
+		defer n.wg.Done()
+		ticker := n.clock.Ticker(n.pollInterval)
+		defer ticker.Stop()
+		for {
+			if err := n.DeliverDue(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				n.logger.Error("notify: %s", err.Error())
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-n.wake:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop остановка и ожидание текущей отправки, недоставленное остается в очереди.
+func (n *Notifier) Stop(ctx context.Context) error {
+	if n.cancel != nil {
+		n.cancel()
+	}
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return n.limiter.Destroy()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Enqueue постановка уведомлений по событию в очередь каждого подходящего вебхука,
+// кроме превысивших ограничение частоты.
+func (n *Notifier) Enqueue(ctx context.Context, event model.Event) error {
+	queued, err := n.enqueue(ctx, event)
+	if queued {
+		n.wakeUp()
+	}
+	return err
+}
+
+// wakeUp отправка сразу, не дожидаясь pollInterval.
+func (n *Notifier) wakeUp() {
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+}
+
+// enqueue true - хотя бы одно уведомление поставлено в очередь. В транзакции Transactor лимит
+// частоты проверяется после фиксации, см. charge.
+func (n *Notifier) enqueue(ctx context.Context, event model.Event) (bool, error) {
+	msg, ok := newMessage(event)
+	if !ok {
+		return false, nil
+	}
+	p, inTx := ctx.Value(pendingKey{}).(*pending)
+	queued := false
+	for _, hook := range n.webhooks {
+		if !hook.accepts(msg.Type) {
+			continue
+		}
+		bucket := hook.Name + "_" + string(msg.Type) + "_" + msg.key
+		if !inTx {
+			exceed, err := n.limiter.ExceedLimit(bucket, hook.limits)
+			if err != nil {
+				return queued, err
+			}
+			if exceed {
+				n.logger.Debug("notify %s: %s about %s suppressed by rate limit", hook.Name, msg.Type, msg.key)
+				continue
+			}
+		}
+		id := uuid.New()
+		payload, err := msg.body(id, hook.Format)
+		if err != nil {
+			return queued, err
+		}
+		err = n.outbox.Add(ctx, model.Notification{
+			ID:            id,
+			Webhook:       hook.Name,
+			Type:          msg.Type,
+			Payload:       payload,
+			NextAttemptAt: n.clock.Now(),
+			CreatedAt:     n.clock.Now(),
+		})
+		if err != nil {
+			return queued, err
+		}
+		if inTx {
+			p.items = append(p.items, pendingItem{id: id, hook: hook, msg: msg, bucket: bucket})
+		}
+		queued = true
+	}
+	return queued, nil
+}
+
+// DeliverDue один проход по уведомлениям, время которых подошло. Неудачные откладываются
+// с удвоением паузы, после maxAttempts попыток отбрасываются.
+func (n *Notifier) DeliverDue(ctx context.Context) error {
+	items, err := n.outbox.GetDue(ctx, n.clock.Now(), n.lease, batchSize)
+	if err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		hook, ok := n.webhook(item.Webhook)
+		if !ok {
+			n.logger.Warn("notify: webhook %s is not configured, notification %s dropped", item.Webhook, item.ID)
+			if err := n.outbox.Delete(ctx, item.ID); err != nil && !errors.Is(err, model.ErrNotificationNotFound) {
+				return fmt.Errorf("outbox: %w", err)
+			}
+			continue
+		}
+		sendErr := n.send(ctx, hook, item)
+		// прерванная остановкой отправка попыткой не считается.
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		switch {
+		case sendErr == nil:
+			err = n.outbox.Delete(ctx, item.ID)
+		case item.Attempts+1 >= n.maxAttempts:
+			n.logger.Error("notify %s: %s dropped after %d attempts: %s",
+				hook.Name, item.ID, item.Attempts+1, sendErr.Error())
+			err = n.outbox.Delete(ctx, item.ID)
+		default:
+			n.logger.Warn("notify %s: %s attempt %d failed: %s", hook.Name, item.ID, item.Attempts+1, sendErr.Error())
+			err = n.outbox.Retry(ctx, item.ID, n.clock.Now().Add(n.backoff(item.Attempts+1)), sendErr.Error())
+		}
+		// уведомления уже нет: его обработал другой экземпляр после истечения lease.
+		if err != nil && !errors.Is(err, model.ErrNotificationNotFound) {
+			return fmt.Errorf("outbox: %w", err)
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) send(ctx context.Context, hook webhook, item model.Notification) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(item.Payload))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(n.clock.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "brutefp-notifier")
+	req.Header.Set(HeaderEvent, string(item.Type))
+	req.Header.Set(HeaderDelivery, item.ID.String())
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if hook.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, item.Payload))
+	}
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1024))
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("%w: %s", ErrBadStatus, res.Status)
+	}
+	return nil
+}
+
+// Sign подпись тела уведомления, получатель сверяет ее со значением заголовка HeaderSignature.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff пауза перед попыткой attempts+1.
+func (n *Notifier) backoff(attempts int) time.Duration {
+	delay := n.retryDelay
+	for i := 1; i < attempts && delay < n.maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > n.maxRetryDelay {
+		delay = n.maxRetryDelay
+	}
+	return delay
+}
+
+func (n *Notifier) webhook(name string) (webhook, bool) {
+	for _, hook := range n.webhooks {
+		if hook.Name == name {
+			return hook, true
+		}
+	}
+	return webhook{}, false
+}
+
+func parseWebhook(cfg config.Webhook) (webhook, error) {
+	if cfg.Name == "" {
+		return webhook{}, ErrWebhookName
+	}
+	if u, err := url.Parse(cfg.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return webhook{}, ErrWebhookURL
+	}
+	if cfg.Format != config.WebhookFormatJSON && cfg.Format != config.WebhookFormatSlack {
+		return webhook{}, ErrWebhookFormat
+	}
+	hook := webhook{Webhook: cfg, types: make(map[model.NotifyType]struct{}, len(cfg.Types))}
+	for _, item := range cfg.Types {
+		typ, err := model.ParseNotifyType(item)
+		if err != nil {
+			return webhook{}, fmt.Errorf("'%s': %w", item, err)
+		}
+		hook.types[typ] = struct{}{}
+	}
+	period, err := cfg.RateLimit.Period.AsDuration()
+	if err != nil {
+		return webhook{}, err
+	}
+	hook.limits = ratelimit.Limits{Period: period, Limit: int64(cfg.RateLimit.Limit)}
+	return hook, hook.limits.Valid()
+}
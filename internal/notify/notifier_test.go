@@ -0,0 +1,353 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/events"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/internal/repository/memory"
+	"github.com/vitermakov/otusgo-final/internal/repository/sqlite"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	"github.com/vitermakov/otusgo-final/pkg/utils/jsonx"
+	"github.com/vitermakov/otusgo-final/pkg/utils/migrate"
+)
+
+// receiver локальный получатель вебхука.
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := r.status
+	if status == 0 {
+		status = http.StatusNoContent
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func notifyConfig(hooks ...config.Webhook) config.Notify {
+	for i := range hooks {
+		if hooks[i].Format == "" {
+			hooks[i].Format = config.WebhookFormatJSON
+		}
+		if hooks[i].RateLimit.Limit == 0 {
+			hooks[i].RateLimit = config.WebhookRateLimit{Limit: 1, Period: jsonx.NewDuration(10, 'm')}
+		}
+	}
+	return config.Notify{
+		Webhooks:      hooks,
+		MaxAttempts:   3,
+		RetryDelay:    jsonx.NewDuration(5, 's'),
+		MaxRetryDelay: jsonx.NewDuration(1, 'm'),
+		PollInterval:  jsonx.NewDuration(5, 's'),
+		Timeout:       jsonx.NewDuration(5, 's'),
+	}
+}
+
+func banEvent(ipNet string) model.Event {
+	_, network, _ := net.ParseCIDR(ipNet)
+	return model.NewRuleEvent(model.IPRuleEvent{
+		Action: model.EventActionAdd,
+		Actor:  "admin",
+		IPNet:  *network,
+		After:  &model.IPRule{Type: model.RuleTypeDeny, IPNet: *network, Source: model.RuleSourceManual},
+	})
+}
+
+func deniedEvent(login, ip string, reason model.PermitReason) model.Event {
+	return model.NewDecisionEvent(
+		model.PermitQuery{Login: login, IP: net.ParseIP(ip)},
+		model.PermitResult{Success: false, Reason: reason},
+	)
+}
+
+// goneOutbox уведомление удаляется до подтверждения, как если бы его обработал другой экземпляр.
+type goneOutbox struct {
+	repository.NotificationOutbox
+}
+
+func (o goneOutbox) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := o.NotificationOutbox.Delete(ctx, id); err != nil {
+		return err
+	}
+	return o.NotificationOutbox.Delete(ctx, id)
+}
+
+func TestNotifier(t *testing.T) {
+	ctx := context.Background()
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+
+	newNotifier := func(t *testing.T, cfg config.Notify) (*Notifier, repository.NotificationOutbox, *clock.Mock) {
+		t.Helper()
+		clk := clock.NewMock()
+		clk.Set(time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC))
+		outbox := memory.NewNotificationOutboxRepo()
+		n, err := NewNotifier(cfg, outbox, clk, log)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, n.Stop(ctx)) })
+		return n, outbox, clk
+	}
+
+	t.Run("signed delivery", func(t *testing.T) {
+		recv := &receiver{}
+		server := httptest.NewServer(recv)
+		defer server.Close()
+		n, outbox, clk := newNotifier(t, notifyConfig(config.Webhook{Name: "siem", URL: server.URL, Secret: "s3cret"}))
+
+		require.NoError(t, n.Enqueue(ctx, banEvent("10.0.0.0/24")))
+		require.NoError(t, n.DeliverDue(ctx))
+		require.Equal(t, 1, recv.count())
+
+		req, body := recv.requests[0], recv.bodies[0]
+		require.Equal(t, string(model.NotifyTypeIPBanned), req.Header.Get(HeaderEvent))
+		require.Equal(t, "1682935200", req.Header.Get(HeaderTimestamp))
+		require.Equal(t, Sign("s3cret", req.Header.Get(HeaderTimestamp), body), req.Header.Get(HeaderSignature))
+
+		var msg map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &msg))
+		require.Equal(t, "ip_banned", msg["type"])
+		require.Equal(t, "10.0.0.0/24", msg["ipNet"])
+		require.Equal(t, "admin", msg["actor"])
+		require.Equal(t, req.Header.Get(HeaderDelivery), msg["id"])
+
+		due, err := outbox.GetDue(ctx, clk.Now().Add(time.Hour), 0, 10)
+		require.NoError(t, err)
+		require.Empty(t, due)
+	})
+
+	t.Run("retry with backoff", func(t *testing.T) {
+		recv := &receiver{status: http.StatusInternalServerError}
+		server := httptest.NewServer(recv)
+		defer server.Close()
+		n, outbox, clk := newNotifier(t, notifyConfig(config.Webhook{Name: "siem", URL: server.URL}))
+
+		require.NoError(t, n.Enqueue(ctx, deniedEvent("admin", "192.168.1.1", model.PermitReasonIPLimit)))
+		require.NoError(t, n.DeliverDue(ctx))
+		require.Equal(t, 1, recv.count())
+
+		// до истечения паузы повторной отправки нет.
+		clk.Add(4 * time.Second)
+		require.NoError(t, n.DeliverDue(ctx))
+		require.Equal(t, 1, recv.count())
+
+		// выборка с нулевым lease не сдвигает время попытки.
+		clk.Add(time.Second)
+		due, err := outbox.GetDue(ctx, clk.Now(), 0, 10)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, 1, due[0].Attempts)
+		require.Contains(t, due[0].LastError, "500")
+
+		recv.setStatus(http.StatusOK)
+		require.NoError(t, n.DeliverDue(ctx))
+		require.Equal(t, 2, recv.count())
+		require.Equal(t, recv.bodies[0], recv.bodies[1])
+		require.Equal(t, recv.requests[0].Header.Get(HeaderDelivery), recv.requests[1].Header.Get(HeaderDelivery))
+
+		due, err = outbox.GetDue(ctx, clk.Now().Add(time.Hour), 0, 10)
+		require.NoError(t, err)
+		require.Empty(t, due)
+	})
+
+	t.Run("delivered by another instance", func(t *testing.T) {
+		recv := &receiver{}
+		server := httptest.NewServer(recv)
+		defer server.Close()
+		n, outbox, _ := newNotifier(t, notifyConfig(config.Webhook{Name: "siem", URL: server.URL}))
+		n.outbox = goneOutbox{outbox}
+
+		require.NoError(t, n.Enqueue(ctx, banEvent("10.0.0.0/24")))
+		require.NoError(t, n.DeliverDue(ctx))
+		require.Equal(t, 1, recv.count())
+	})
+
+	t.Run("dropped after max attempts", func(t *testing.T) {
+		recv := &receiver{status: http.StatusBadGateway}
+		server := httptest.NewServer(recv)
+		defer server.Close()
+		n, outbox, clk := newNotifier(t, notifyConfig(config.Webhook{Name: "siem", URL: server.URL}))
+
+		require.NoError(t, n.Enqueue(ctx, banEvent("10.0.0.0/24")))
+		for i := 0; i < 5; i++ {
+			require.NoError(t, n.DeliverDue(ctx))
+			clk.Add(time.Minute)
+		}
+		require.Equal(t, 3, recv.count())
+		due, err := outbox.GetDue(ctx, clk.Now(), 0, 10)
+		require.NoError(t, err)
+		require.Empty(t, due)
+	})
+
+	t.Run("rate limit and filter", func(t *testing.T) {
+		recv := &receiver{}
+		server := httptest.NewServer(recv)
+		defer server.Close()
+		n, _, clk := newNotifier(t, notifyConfig(
+			config.Webhook{Name: "bans", URL: server.URL, Types: []string{"ip_banned"}},
+			config.Webhook{Name: "chat", URL: server.URL, Format: config.WebhookFormatSlack, Types: []string{"ip_limit"}},
+		))
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, n.Enqueue(ctx, deniedEvent("admin", "192.168.1.1", model.PermitReasonIPLimit)))
+		}
+		require.NoError(t, n.Enqueue(ctx, deniedEvent("admin", "192.168.1.2", model.PermitReasonIPLimit)))
+		// не оповещаются: разрешения, другие причины и типы без подписчиков.
+		require.NoError(t, n.Enqueue(ctx, deniedEvent("admin", "192.168.1.3", model.PermitReasonBlackList)))
+		require.NoError(t, n.Enqueue(ctx, deniedEvent("admin", "192.168.1.3", model.PermitReasonLoginLimit)))
+		require.NoError(t, n.Enqueue(ctx, model.NewDecisionEvent(
+			model.PermitQuery{Login: "admin", IP: net.ParseIP("192.168.1.4")},
+			model.PermitResult{Success: true, Reason: model.PermitReasonWithinLimits},
+		)))
+		require.NoError(t, n.Enqueue(ctx, banEvent("10.0.0.0/24")))
+		require.NoError(t, n.DeliverDue(ctx))
+		require.Equal(t, 3, recv.count())
+
+		var texts []string
+		for i, req := range recv.requests {
+			var msg map[string]interface{}
+			require.NoError(t, json.Unmarshal(recv.bodies[i], &msg))
+			if req.Header.Get(HeaderEvent) == string(model.NotifyTypeIPLimit) {
+				// формат slack: только текст.
+				require.Len(t, msg, 1)
+				texts = append(texts, msg["text"].(string))
+			}
+		}
+		require.ElementsMatch(t, []string{
+			"brutefp: 192.168.1.1 exceeded the attempts limit",
+			"brutefp: 192.168.1.2 exceeded the attempts limit",
+		}, texts)
+
+		// после окончания периода уведомление о том же IP снова отправляется.
+		clk.Add(10 * time.Minute)
+		require.NoError(t, n.Enqueue(ctx, deniedEvent("admin", "192.168.1.1", model.PermitReasonIPLimit)))
+		require.NoError(t, n.DeliverDue(ctx))
+		require.Equal(t, 4, recv.count())
+	})
+
+	t.Run("events from bus", func(t *testing.T) {
+		recv := &receiver{}
+		server := httptest.NewServer(recv)
+		defer server.Close()
+		clk := clock.NewMock()
+		bus := events.NewBus(0)
+		n, err := NewNotifier(notifyConfig(config.Webhook{Name: "siem", URL: server.URL}),
+			memory.NewNotificationOutboxRepo(), clk, log)
+		require.NoError(t, err)
+		require.NoError(t, n.Start(ctx, bus))
+
+		// блокировки ставятся в очередь при записи правила, из шины - только превышения лимитов.
+		bus.Publish(banEvent("10.0.0.0/24"))
+		bus.Publish(deniedEvent("admin", "192.168.1.1", model.PermitReasonIPLimit))
+		require.Eventually(t, func() bool { return recv.count() == 1 }, time.Second, 10*time.Millisecond)
+		require.Equal(t, string(model.NotifyTypeIPLimit), recv.requests[0].Header.Get(HeaderEvent))
+		require.NoError(t, n.Stop(ctx))
+	})
+
+	t.Run("ban in rule transaction", func(t *testing.T) {
+		db, closeFn, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "brutefp.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = closeFn(ctx)
+		})
+		_, err = migrate.New(db, sqlite.Migrations(), migrate.SQLite).Up(ctx)
+		require.NoError(t, err)
+		clk := clock.NewMock()
+		clk.Set(time.Now())
+		outbox := sqlite.NewNotificationOutboxRepo(db)
+		n, err := NewNotifier(notifyConfig(config.Webhook{Name: "siem", URL: "http://localhost"}), outbox, clk, log)
+		require.NoError(t, err)
+		repo, tx := n.RuleEventRepo(sqlite.NewIPRuleEventRepo(db)), n.Transactor(sqlite.NewTransactor(db))
+		ban := func(ctx context.Context, ipNet string) error {
+			_, network, _ := net.ParseCIDR(ipNet)
+			return repo.Add(ctx, model.NewIPRuleEvent(model.EventActionAdd, "admin", nil,
+				&model.IPRule{ID: uuid.New(), Type: model.RuleTypeDeny, IPNet: *network, Source: model.RuleSourceManual}))
+		}
+		requireDue := func(count int) {
+			due, err := outbox.GetDue(ctx, clk.Now(), 0, 10)
+			require.NoError(t, err)
+			require.Len(t, due, count)
+		}
+
+		errRollback := errors.New("rollback")
+		err = tx.InTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, ban(ctx, "10.0.0.0/24"))
+			return errRollback
+		})
+		require.ErrorIs(t, err, errRollback)
+		requireDue(0)
+
+		// откаченная попытка не израсходовала лимит частоты.
+		require.NoError(t, tx.InTx(ctx, func(ctx context.Context) error {
+			return ban(ctx, "10.0.0.0/24")
+		}))
+		requireDue(1)
+
+		// лимит исчерпан: уведомление удалено из очереди после фиксации.
+		require.NoError(t, tx.InTx(ctx, func(ctx context.Context) error {
+			return ban(ctx, "10.0.0.0/24")
+		}))
+		requireDue(1)
+
+		// сжатие списков и синхронизация фидов не оповещают.
+		require.NoError(t, tx.InTx(model.ContextWithoutBanNotify(ctx), func(ctx context.Context) error {
+			return ban(ctx, "10.0.1.0/24")
+		}))
+		requireDue(1)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		outbox := memory.NewNotificationOutboxRepo()
+		cases := []struct {
+			hook config.Webhook
+			err  error
+		}{
+			{hook: config.Webhook{URL: "http://localhost"}, err: ErrWebhookName},
+			{hook: config.Webhook{Name: "a", URL: "ftp://localhost"}, err: ErrWebhookURL},
+			{hook: config.Webhook{Name: "a", URL: "http://localhost", Format: "xml"}, err: ErrWebhookFormat},
+			{hook: config.Webhook{Name: "a", URL: "http://localhost", Types: []string{"x"}}, err: model.ErrNotifyTypeUnk},
+		}
+		for _, tc := range cases {
+			_, err := NewNotifier(notifyConfig(tc.hook), outbox, clock.NewMock(), log)
+			require.ErrorIs(t, err, tc.err)
+		}
+		hook := config.Webhook{Name: "a", URL: "http://localhost"}
+		_, err := NewNotifier(notifyConfig(hook, hook), outbox, clock.NewMock(), log)
+		require.ErrorIs(t, err, ErrWebhookName)
+	})
+}
@@ -0,0 +1,23 @@
+package ratelimit_test
+
+import (
+	"testing"
+
+	"github.com/benbjohnson/clock"
+	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+	"github.com/vitermakov/otusgo-final/internal/ratelimit/ratelimittest"
+)
+
+func newFixedMemory(_ *testing.T, clk clock.Clock) ratelimit.RateLimiter {
+	return ratelimit.NewFixedMemoryClock(clk)
+}
+
+func TestFixedMemoryConformance(t *testing.T) {
+	ratelimittest.Run(t, newFixedMemory)
+}
+
+func TestFixedMemoryModel(t *testing.T) {
+	ratelimittest.CheckModel(t, newFixedMemory, func() ratelimittest.Model {
+		return ratelimittest.NewFixedWindow()
+	})
+}
@@ -1,9 +1,11 @@
 package ratelimit
 
 import (
+	"context"
 	"sync"
-	"sync/atomic"
 	"time"
+
+	"github.com/benbjohnson/clock"
 )
 
 /*
@@ -18,12 +20,19 @@ import (
 // FixedMemory реализация FixedWin, в качестве хранилища использующая ОЗУ компьютера.
 type FixedMemory struct {
 	buckets map[string]*bucket
+	clock   clock.Clock
 	mu      sync.Mutex
 }
 
 func NewFixedMemory() *FixedMemory {
+	return NewFixedMemoryClock(clock.New())
+}
+
+// NewFixedMemoryClock ограничитель с заданными часами, в тестах - clock.Mock.
+func NewFixedMemoryClock(clk clock.Clock) *FixedMemory {
 	return &FixedMemory{
 		buckets: make(map[string]*bucket),
+		clock:   clk,
 	}
 }
 
@@ -41,6 +50,37 @@ func (fm *FixedMemory) ExceedLimit(bucketCode string, limits Limits) (bool, erro
 	return !res, nil
 }
 
+// ExceedLimitAll проверяет набор событий под общей блокировкой, бакеты создаются только при учете.
+func (fm *FixedMemory) ExceedLimitAll(charges []Charge) ([]bool, error) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	for _, charge := range charges {
+		if err := charge.Limits.Valid(); err != nil {
+			return nil, err
+		}
+	}
+	exceeds := make([]bool, len(charges))
+	taken := make(map[string]int64, len(charges))
+	exceeded := false
+	for i, charge := range charges {
+		taken[charge.BucketCode]++
+		counter, limit := int64(0), charge.Limits.Limit
+		if item := fm.getBucket(charge.BucketCode, nil); item != nil {
+			counter, limit = item.count()
+		}
+		exceeds[i] = counter+taken[charge.BucketCode] > limit
+		exceeded = exceeded || exceeds[i]
+	}
+	if exceeded {
+		return exceeds, nil
+	}
+	for i := range charges {
+		fm.getBucket(charges[i].BucketCode, &charges[i].Limits).takeCount()
+	}
+	return exceeds, nil
+}
+
 // ResetBucket сбрасывает бакет с кодом bucketCode.
 func (fm *FixedMemory) ResetBucket(bucketCode string) (bool, error) {
 	fm.mu.Lock()
@@ -83,6 +123,28 @@ func (fm *FixedMemory) Destroy() error {
 	return nil
 }
 
+// pingRetry пауза между попытками Ping получить блокировку.
+const pingRetry = 5 * time.Millisecond
+
+// Ping ограничитель не заблокирован: блокировку удается получить до истечения ctx.
+// Блокировка не ждется в отдельной горутине: при зависшем ограничителе такие горутины
+// копились бы с каждой проверкой.
+func (fm *FixedMemory) Ping(ctx context.Context) error {
+	ticker := time.NewTicker(pingRetry)
+	defer ticker.Stop()
+	for {
+		if fm.mu.TryLock() {
+			fm.mu.Unlock()
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 // Capacity количество бакетов.
 func (fm *FixedMemory) Capacity() int {
 	fm.mu.Lock()
@@ -114,14 +176,30 @@ func (fm *FixedMemory) getBucket(name string, cfg *Limits) *bucket {
 	return item
 }
 
+// expire удаление бакета, простаивающего два периода. Бакет мог быть затронут,
+// пока ждали блокировку, или уже удален вручную - тогда ничего не делаем.
+func (fm *FixedMemory) expire(item *bucket) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.buckets[item.name] != item || !item.idle() {
+		return
+	}
+	item.kill()
+	<-item.done()
+	delete(fm.buckets, item.name)
+}
+
 type bucket struct {
 	owner *FixedMemory
 	// name имя бакета
 	name string
-	// counter счетчик обращений
+	// counter счетчик обращений в текущем окне
 	counter int64
 	// limits параметры ограничений
 	limits Limits
+	// windowStart начало текущего окна, unix nano
+	windowStart int64
 	// touched timestamp последнего
 	touched int64
 
@@ -132,11 +210,12 @@ type bucket struct {
 
 func newBucket(fm *FixedMemory, name string, cfg Limits) *bucket {
 	return &bucket{
-		owner:  fm,
-		name:   name,
-		limits: cfg,
-		doneCh: make(chan struct{}),
-		killCh: make(chan struct{}),
+		owner:       fm,
+		name:        name,
+		limits:      cfg,
+		windowStart: fm.clock.Now().UnixNano(),
+		doneCh:      make(chan struct{}),
+		killCh:      make(chan struct{}),
 	}
 }
 
@@ -144,11 +223,43 @@ func (b *bucket) done() <-chan struct{} {
 	return b.doneCh
 }
 
+// takeCount окна отсчитываются от создания бакета, счетчик обнуляется при первом обращении в новом окне.
 func (b *bucket) takeCount() bool {
-	atomic.AddInt64(&b.counter, 1)
-	atomic.StoreInt64(&b.touched, time.Now().UnixNano())
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.roll()
+	b.counter++
+	b.touched = now
+
+	return b.counter <= b.limits.Limit
+}
+
+// count счетчик текущего окна и лимит бакета без учета нового события.
+func (b *bucket) count() (int64, int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll()
+	return b.counter, b.limits.Limit
+}
+
+// roll переход в текущее окно, вызывается под блокировкой бакета. Возвращает текущее время.
+func (b *bucket) roll() int64 {
+	now := b.owner.clock.Now().UnixNano()
+	period := b.limits.Period.Nanoseconds()
+	if elapsed := now - b.windowStart; elapsed >= period {
+		b.windowStart += elapsed / period * period
+		b.counter = 0
+	}
+	return now
+}
 
-	return atomic.LoadInt64(&b.counter) <= b.limits.Limit
+// idle нет новых сигналов в бакете два периода.
+func (b *bucket) idle() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.owner.clock.Now().UnixNano()-b.touched >= b.limits.Period.Nanoseconds()*2
 }
 
 // kill принудительная остановка.
@@ -157,9 +268,10 @@ func (b *bucket) kill() {
 }
 
 // makeCounting вызывает в рутине и в фоне отслеживает свое состояние и при необходимости удаляется.
+// Тикер создается до запуска рутины, чтобы периоды отсчитывались от создания бакета.
 func (b *bucket) makeCounting() {
+	ticker := b.owner.clock.Ticker(b.limits.Period)
 	go func() {
-		ticker := time.NewTicker(b.limits.Period)
 		defer func() {
 			ticker.Stop()
 			close(b.killCh)
@@ -170,16 +282,9 @@ func (b *bucket) makeCounting() {
 			case <-b.killCh:
 				return
 			case <-ticker.C:
-				b.mu.Lock()
-				atomic.StoreInt64(&b.counter, 0)
 				// если два периода нет новых сигналов в бакете, то удаляем его
-				touched := atomic.LoadInt64(&b.touched)
-				needKill := time.Now().UnixNano()-touched >= b.limits.Period.Nanoseconds()*2
-				b.mu.Unlock()
-				if needKill {
-					go func() {
-						_, _ = b.owner.ResetBucket(b.name)
-					}()
+				if b.idle() {
+					go b.owner.expire(b)
 				}
 			}
 		}
@@ -1,10 +1,13 @@
 package ratelimit
 
 import (
+	"context"
 	"fmt"
+	"runtime"
 	"testing"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -15,6 +18,24 @@ func TestFixedMemory(t *testing.T) {
 		require.Equal(t, []string{}, limiter.BucketNames())
 	})
 
+	t.Run("ping", func(t *testing.T) {
+		limiter := NewFixedMemory()
+		require.NoError(t, limiter.Ping(context.Background()))
+
+		limiter.mu.Lock()
+		goroutines := runtime.NumGoroutine()
+		for i := 0; i < 3; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			require.ErrorIs(t, limiter.Ping(ctx), context.DeadlineExceeded)
+			cancel()
+		}
+		// зависшие проверки не оставляют горутин, ждущих блокировку.
+		require.Less(t, runtime.NumGoroutine(), goroutines+3)
+		limiter.mu.Unlock()
+
+		require.NoError(t, limiter.Ping(context.Background()))
+	})
+
 	t.Run("add_destroy", func(t *testing.T) {
 		var (
 			ok  bool
@@ -43,7 +64,8 @@ func TestFixedMemory(t *testing.T) {
 			ok  bool
 			err error
 		)
-		limiter := NewFixedMemory()
+		clk := clock.NewMock()
+		limiter := NewFixedMemoryClock(clk)
 		for i := 1; i <= 4; i++ {
 			opts := Limits{Period: time.Millisecond * 200 * time.Duration(i), Limit: 10}
 			ok, err = limiter.ExceedLimit(fmt.Sprintf("key%d", i), opts)
@@ -52,10 +74,16 @@ func TestFixedMemory(t *testing.T) {
 		}
 		require.ElementsMatch(t, []string{"key1", "key2", "key3", "key4"}, limiter.BucketNames())
 
-		<-time.After(time.Millisecond * 1000)
+		// бакеты удаляются в фоне после тика часов.
+		clk.Add(time.Millisecond * 1000)
+		require.Eventually(t, func() bool {
+			return limiter.Capacity() == 2
+		}, time.Second, time.Millisecond*10)
 		require.ElementsMatch(t, []string{"key3", "key4"}, limiter.BucketNames())
-		<-time.After(time.Millisecond * 1000)
-		require.ElementsMatch(t, []string{}, limiter.BucketNames())
+		clk.Add(time.Millisecond * 1000)
+		require.Eventually(t, func() bool {
+			return limiter.Capacity() == 0
+		}, time.Second, time.Millisecond*10)
 
 		err = limiter.Destroy()
 		require.NoError(t, err)
@@ -95,7 +123,8 @@ func TestFixedMemory(t *testing.T) {
 			bucketCodeF = "key%d"
 		)
 
-		limiter := NewFixedMemory()
+		clk := clock.NewMock()
+		limiter := NewFixedMemoryClock(clk)
 		opts := Limits{Period: time.Second, Limit: int64(limit)}
 
 		for i := 1; i <= 2; i++ {
@@ -108,7 +137,7 @@ func TestFixedMemory(t *testing.T) {
 				} else {
 					require.False(t, ok)
 				}
-				<-time.After(time.Millisecond * 10)
+				clk.Add(time.Millisecond * 10)
 			}
 		}
 
@@ -27,11 +27,21 @@ func (c Limits) Valid() error {
 	return ErrInvalidLimitOpts
 }
 
+// Charge событие для учета в бакете с кодом BucketCode.
+type Charge struct {
+	BucketCode string
+	Limits     Limits
+}
+
 // Limiter интерфейс ограничителя частоты запросов.
 type Limiter interface {
 	// ExceedLimit метод проверяет превышает ли новое входящее событие ограничение
 	// и учитывает его в бакете с кодом bucketCode
 	ExceedLimit(bucketCode string, config Limits) (bool, error)
+	// ExceedLimitAll учитывает набор событий по принципу "все или ничего": если хоть одно
+	// превышает лимит (с учетом повторов бакета в наборе), ни одно не учитывается.
+	// Возвращает признак превышения для каждого события
+	ExceedLimitAll(charges []Charge) ([]bool, error)
 	// ResetBucket сбрасывает бакет
 	ResetBucket(bucketCode string) (bool, error)
 }
@@ -40,6 +50,13 @@ type Limiter interface {
 type RateLimiter interface {
 	Limiter
 	Destroy() error
+	// Ping проверка, что хранилище бакетов отвечает, для health-check
+	Ping(context.Context) error
+}
+
+// BucketCounter ограничитель, который может сообщить число активных бакетов.
+type BucketCounter interface {
+	Capacity() int
 }
 
 func NewRateLimiter(cfg config.Limits) (RateLimiter, closer.CloseFunc, error) {
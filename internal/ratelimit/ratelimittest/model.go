@@ -0,0 +1,49 @@
+package ratelimittest
+
+import (
+	"time"
+
+	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+)
+
+// Model эталонная реализация алгоритма: без горутин и часов, время передается явно.
+type Model interface {
+	Exceed(bucketCode string, limits ratelimit.Limits, now time.Time) bool
+	Reset(bucketCode string) bool
+}
+
+type fixedWindowBucket struct {
+	limits      ratelimit.Limits
+	windowStart time.Time
+	counter     int64
+}
+
+// FixedWindow фиксированное окно, отсчитываемое от первого события бакета.
+// Параметры ограничений задаются первым событием бакета.
+type FixedWindow struct {
+	buckets map[string]*fixedWindowBucket
+}
+
+func NewFixedWindow() *FixedWindow {
+	return &FixedWindow{buckets: make(map[string]*fixedWindowBucket)}
+}
+
+func (fw *FixedWindow) Exceed(bucketCode string, limits ratelimit.Limits, now time.Time) bool {
+	item, ok := fw.buckets[bucketCode]
+	if !ok {
+		item = &fixedWindowBucket{limits: limits, windowStart: now}
+		fw.buckets[bucketCode] = item
+	}
+	for !now.Before(item.windowStart.Add(item.limits.Period)) {
+		item.windowStart = item.windowStart.Add(item.limits.Period)
+		item.counter = 0
+	}
+	item.counter++
+	return item.counter > item.limits.Limit
+}
+
+func (fw *FixedWindow) Reset(bucketCode string) bool {
+	_, ok := fw.buckets[bucketCode]
+	delete(fw.buckets, bucketCode)
+	return ok
+}
@@ -0,0 +1,249 @@
+// Package ratelimittest общие тесты для всех реализаций ratelimit.RateLimiter.
+package ratelimittest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+)
+
+// Factory создает ограничитель, работающий по переданным часам. Destroy вызывает набор тестов.
+type Factory func(t *testing.T, clk clock.Clock) ratelimit.RateLimiter
+
+// newMock часы не с нулевого времени, чтобы не путать начало окна с незаданным значением.
+func newMock() *clock.Mock {
+	clk := clock.NewMock()
+	clk.Set(time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC))
+	return clk
+}
+
+// Run прогоняет тесты ratelimit.RateLimiter на конкретной реализации.
+func Run(t *testing.T, newLimiter Factory) {
+	t.Helper()
+
+	limits := ratelimit.Limits{Period: time.Minute, Limit: 3}
+	start := func(t *testing.T) (ratelimit.RateLimiter, *clock.Mock) {
+		t.Helper()
+		clk := newMock()
+		limiter := newLimiter(t, clk)
+		t.Cleanup(func() {
+			require.NoError(t, limiter.Destroy())
+		})
+		return limiter, clk
+	}
+	exceed := func(t *testing.T, limiter ratelimit.Limiter, bucketCode string, times int) []bool {
+		t.Helper()
+		result := make([]bool, 0, times)
+		for i := 0; i < times; i++ {
+			ok, err := limiter.ExceedLimit(bucketCode, limits)
+			require.NoError(t, err)
+			result = append(result, ok)
+		}
+		return result
+	}
+
+	t.Run("invalid limits", func(t *testing.T) {
+		limiter, _ := start(t)
+		_, err := limiter.ExceedLimit("key", ratelimit.Limits{Period: time.Minute})
+		require.ErrorIs(t, err, ratelimit.ErrInvalidLimitOpts)
+		_, err = limiter.ExceedLimit("key", ratelimit.Limits{Limit: 1})
+		require.ErrorIs(t, err, ratelimit.ErrInvalidLimitOpts)
+	})
+	t.Run("limit enforcement", func(t *testing.T) {
+		limiter, clk := start(t)
+		require.Equal(t, []bool{false, false, false, true, true}, exceed(t, limiter, "key", 5))
+
+		clk.Add(limits.Period - time.Second)
+		require.Equal(t, []bool{true}, exceed(t, limiter, "key", 1))
+
+		// новое окно.
+		clk.Add(time.Second)
+		require.Equal(t, []bool{false, false, false, true}, exceed(t, limiter, "key", 4))
+	})
+	t.Run("key isolation", func(t *testing.T) {
+		limiter, _ := start(t)
+		require.Equal(t, []bool{false, false, false, true}, exceed(t, limiter, "login:alice", 4))
+		require.Equal(t, []bool{false, false, false, true}, exceed(t, limiter, "login:bob", 4))
+		require.Equal(t, []bool{false}, exceed(t, limiter, "ip:alice", 1))
+	})
+	t.Run("all or nothing", func(t *testing.T) {
+		limiter, _ := start(t)
+		charge := func(codes ...string) []bool {
+			t.Helper()
+			charges := make([]ratelimit.Charge, 0, len(codes))
+			for _, code := range codes {
+				charges = append(charges, ratelimit.Charge{BucketCode: code, Limits: limits})
+			}
+			exceeds, err := limiter.ExceedLimitAll(charges)
+			require.NoError(t, err)
+			return exceeds
+		}
+		require.Equal(t, []bool{false, false}, charge("a", "b"))
+		// повтор бакета в наборе учитывается: третье событие "a" превышает лимит.
+		require.Equal(t, []bool{false, false, false, true}, charge("a", "a", "b", "a"))
+		// отклоненный набор не учтен: в "a" и "b" остается место для двух событий.
+		require.Equal(t, []bool{false, false, false}, charge("a", "a", "b"))
+		require.Equal(t, []bool{false, true}, exceed(t, limiter, "b", 2))
+		require.Equal(t, []bool{true}, exceed(t, limiter, "a", 1))
+
+		_, err := limiter.ExceedLimitAll([]ratelimit.Charge{{BucketCode: "c", Limits: ratelimit.Limits{Limit: 1}}})
+		require.ErrorIs(t, err, ratelimit.ErrInvalidLimitOpts)
+	})
+	t.Run("reset", func(t *testing.T) {
+		limiter, _ := start(t)
+		ok, err := limiter.ResetBucket("key")
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.Equal(t, []bool{false, false, false, true}, exceed(t, limiter, "key", 4))
+		require.Equal(t, []bool{false}, exceed(t, limiter, "other", 1))
+		ok, err = limiter.ResetBucket("key")
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, []bool{false, false, false, true}, exceed(t, limiter, "key", 4))
+		require.Equal(t, []bool{false, false}, exceed(t, limiter, "other", 2))
+	})
+	t.Run("destroy", func(t *testing.T) {
+		clk := newMock()
+		limiter := newLimiter(t, clk)
+		require.NoError(t, limiter.Destroy())
+
+		exceed(t, limiter, "key1", 4)
+		exceed(t, limiter, "key2", 1)
+		require.NoError(t, limiter.Destroy())
+		ok, err := limiter.ResetBucket("key1")
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, []bool{false}, exceed(t, limiter, "key1", 1))
+		require.NoError(t, limiter.Destroy())
+	})
+	t.Run("ping", func(t *testing.T) {
+		limiter, _ := start(t)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, limiter.Ping(ctx))
+
+		exceed(t, limiter, "key", 1)
+		require.NoError(t, limiter.Destroy())
+		require.NoError(t, limiter.Ping(ctx))
+	})
+	t.Run("expiry", func(t *testing.T) {
+		limiter, clk := start(t)
+		exceed(t, limiter, "idle", 1)
+		exceed(t, limiter, "active", 1)
+
+		for i := 0; i < 3; i++ {
+			clk.Add(limits.Period)
+			exceed(t, limiter, "active", 1)
+		}
+		// бакет удаляется в фоне, ждем без продвижения часов.
+		require.Eventually(t, func() bool {
+			ok, err := limiter.ResetBucket("idle")
+			require.NoError(t, err)
+			return !ok
+		}, time.Second, 10*time.Millisecond)
+		ok, err := limiter.ResetBucket("active")
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+	t.Run("concurrent access", func(t *testing.T) {
+		limiter, _ := start(t)
+		const workers, perWorker, keys = 8, 50, 4
+		concurrent := ratelimit.Limits{Period: time.Minute, Limit: 30}
+		var (
+			wg      sync.WaitGroup
+			allowed [keys]int64
+		)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < perWorker; i++ {
+					key := (w + i) % keys
+					ok, err := limiter.ExceedLimit(fmt.Sprintf("key%d", key), concurrent)
+					if err == nil && !ok {
+						atomic.AddInt64(&allowed[key], 1)
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+		// часы стоят, поэтому в каждом бакете пропущено ровно Limit событий.
+		for key := range allowed {
+			require.Equal(t, concurrent.Limit, allowed[key], "key%d", key)
+		}
+	})
+}
+
+// CheckModel сравнивает решения ограничителя с эталонной моделью на случайных сценариях.
+// Часы не продвигаются настолько, чтобы бакет успел устареть, - удаление в фоне не детерминировано.
+func CheckModel(t *testing.T, newLimiter Factory, newModel func() Model) {
+	t.Helper()
+
+	const (
+		seeds = 20
+		steps = 150
+	)
+	keys := []string{"key0", "key1", "key2"}
+	periods := []time.Duration{time.Second, 3 * time.Second, 5 * time.Second}
+	for seed := int64(1); seed <= seeds; seed++ {
+		seed := seed
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(seed)) //nolint:gosec // воспроизводимый сценарий
+			clk := newMock()
+			limiter := newLimiter(t, clk)
+			defer func() {
+				require.NoError(t, limiter.Destroy())
+			}()
+			model := newModel()
+
+			limits := make(map[string]ratelimit.Limits, len(keys))
+			touched := make(map[string]time.Time, len(keys))
+			check := func(step int, key string) {
+				if _, ok := limits[key]; !ok {
+					limits[key] = ratelimit.Limits{
+						Period: periods[rnd.Intn(len(periods))],
+						Limit:  int64(1 + rnd.Intn(5)),
+					}
+				}
+				actual, err := limiter.ExceedLimit(key, limits[key])
+				require.NoError(t, err)
+				expected := model.Exceed(key, limits[key], clk.Now())
+				require.Equal(t, expected, actual, "step %d, key %s, time %s", step, key, clk.Now())
+				touched[key] = clk.Now()
+			}
+
+			for step := 0; step < steps; step++ {
+				key := keys[rnd.Intn(len(keys))]
+				switch op := rnd.Intn(10); {
+				case op == 0:
+					actual, err := limiter.ResetBucket(key)
+					require.NoError(t, err)
+					require.Equal(t, model.Reset(key), actual, "step %d, reset %s", step, key)
+					delete(limits, key)
+					delete(touched, key)
+				case op < 4:
+					advance := time.Duration(rnd.Int63n(int64(periods[0])))
+					// бакет, который простоял бы два периода, сначала трогаем.
+					for touchedKey, at := range touched {
+						if clk.Now().Add(advance).Sub(at) >= 2*limits[touchedKey].Period {
+							check(step, touchedKey)
+						}
+					}
+					clk.Add(advance)
+				default:
+					check(step, key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+type APIKeyRepo struct {
+	mu   sync.RWMutex
+	keys map[string]model.APIKey // по имени
+}
+
+func (kr *APIKeyRepo) Add(_ context.Context, input model.APIKeyInput) (*model.APIKey, error) {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	if _, ok := kr.keys[input.Name]; ok {
+		return nil, model.ErrAPIKeyDuplicate
+	}
+	for _, key := range kr.keys {
+		if key.Hash == input.Hash {
+			return nil, model.ErrAPIKeyDuplicate
+		}
+	}
+	key := model.APIKey{
+		ID:        uuid.New(),
+		Name:      input.Name,
+		Hash:      input.Hash,
+		Role:      input.Role,
+		CreatedAt: time.Now(),
+	}
+	kr.keys[key.Name] = key
+	return &key, nil
+}
+
+func (kr *APIKeyRepo) GetByHash(_ context.Context, hash string) (*model.APIKey, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	for _, key := range kr.keys {
+		if key.Hash == hash {
+			return &key, nil
+		}
+	}
+	return nil, model.ErrAPIKeyNotFound
+}
+
+func (kr *APIKeyRepo) Delete(_ context.Context, name string) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	if _, ok := kr.keys[name]; !ok {
+		return model.ErrAPIKeyNotFound
+	}
+	delete(kr.keys, name)
+	return nil
+}
+
+func (kr *APIKeyRepo) GetList(_ context.Context) ([]model.APIKey, error) {
+	kr.mu.RLock()
+	keys := make([]model.APIKey, 0, len(kr.keys))
+	for _, key := range kr.keys {
+		keys = append(keys, key)
+	}
+	kr.mu.RUnlock()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+	return keys, nil
+}
+
+func NewAPIKeyRepo() repository.APIKey {
+	return &APIKeyRepo{keys: make(map[string]model.APIKey)}
+}
@@ -0,0 +1,15 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/internal/repository/repotest"
+)
+
+func TestAPIKeyMemoryRepo(t *testing.T) {
+	repotest.RunAPIKey(t, func(t *testing.T) repository.APIKey {
+		t.Helper()
+		return NewAPIKeyRepo()
+	})
+}
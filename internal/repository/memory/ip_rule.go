@@ -2,6 +2,8 @@ package memory
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -9,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/vitermakov/otusgo-final/internal/model"
 	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/pkg/utils/netlist"
 )
 
 type IPRuleRepo struct {
@@ -17,30 +20,78 @@ type IPRuleRepo struct {
 }
 
 func (ir *IPRuleRepo) Add(_ context.Context, input model.IPRuleInput) (*model.IPRule, error) {
-	rule := model.IPRule{
-		ID:        uuid.New(),
-		Type:      input.Type,
-		IPNet:     input.IPNet,
-		UpdatedAt: time.Now(),
-	}
+	rule := ir.newRule(input)
 
 	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	if err := checkDuplicates(ir.rules, rule); err != nil {
+		return nil, err
+	}
 	ir.rules = append(ir.rules, rule)
-	ir.mu.Unlock()
 
 	return &rule, nil
 }
 
+// AddBatch атомарность обеспечивается одной блокировкой.
+func (ir *IPRuleRepo) AddBatch(_ context.Context, inputs []model.IPRuleInput) ([]model.IPRule, error) {
+	rules := make([]model.IPRule, 0, len(inputs))
+	for _, input := range inputs {
+		rules = append(rules, ir.newRule(input))
+	}
+
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	if err := checkDuplicates(ir.rules, rules...); err != nil {
+		return nil, err
+	}
+	ir.rules = append(ir.rules, rules...)
+
+	return rules, nil
+}
+
+// Replace удаление и добавление под одной блокировкой.
+func (ir *IPRuleRepo) Replace(
+	_ context.Context, remove []uuid.UUID, inputs []model.IPRuleInput,
+) ([]model.IPRule, error) {
+	removeSet := make(map[uuid.UUID]struct{}, len(remove))
+	for _, id := range remove {
+		removeSet[id] = struct{}{}
+	}
+	added := make([]model.IPRule, 0, len(inputs))
+	for _, input := range inputs {
+		added = append(added, ir.newRule(input))
+	}
+
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	result := make([]model.IPRule, 0, len(ir.rules)-len(remove)+len(added))
+	for _, rule := range ir.rules {
+		if _, ok := removeSet[rule.ID]; !ok {
+			result = append(result, rule)
+		}
+	}
+	if err := checkDuplicates(result, added...); err != nil {
+		return nil, err
+	}
+	ir.rules = append(result, added...)
+	return added, nil
+}
+
 func (ir *IPRuleRepo) Delete(ctx context.Context, input model.IPRuleInput) error {
 	ir.mu.Lock()
 	defer ir.mu.Unlock()
+	search := model.IPRuleSearch{
+		Type:       &input.Type,
+		IPNet:      &input.IPNet,
+		IPNetExact: true,
+	}
+	if input.Source != "" {
+		search.Source = &input.Source
+		search.Feed = &input.Feed
+	}
 	result := make([]model.IPRule, 0)
 	for _, rule := range ir.rules {
-		if !ir.matchSearch(rule, model.IPRuleSearch{
-			Type:       &input.Type,
-			IPNet:      &input.IPNet,
-			IPNetExact: true,
-		}) {
+		if !ir.matchSearch(rule, search) {
 			result = append(result, rule)
 		}
 	}
@@ -48,6 +99,37 @@ func (ir *IPRuleRepo) Delete(ctx context.Context, input model.IPRuleInput) error
 	return nil
 }
 
+// Update поиск и изменение под одной блокировкой.
+func (ir *IPRuleRepo) Update(_ context.Context, update model.IPRuleUpdate) (*model.IPRule, *model.IPRule, error) {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	search := model.IPRuleSearch{IPNet: &update.IPNet, IPNetExact: true}
+	found := -1
+	for i, rule := range ir.rules {
+		if !ir.matchSearch(rule, search) {
+			continue
+		}
+		if found >= 0 {
+			return nil, nil, model.ErrRuleAmbiguous
+		}
+		found = i
+	}
+	if found < 0 {
+		return nil, nil, model.ErrRuleNotFound
+	}
+	rule := ir.rules[found]
+	after := rule
+	update.Apply(&after)
+	after.UpdatedAt = time.Now()
+	if after.Type != rule.Type {
+		if err := checkDuplicates(ir.rules, after); err != nil {
+			return nil, nil, err
+		}
+	}
+	ir.rules[found] = after
+	return &rule, &after, nil
+}
+
 func (ir *IPRuleRepo) GetList(_ context.Context, search model.IPRuleSearch) ([]model.IPRule, error) {
 	var filtered []model.IPRule
 	ir.mu.RLock()
@@ -57,10 +139,40 @@ func (ir *IPRuleRepo) GetList(_ context.Context, search model.IPRuleSearch) ([]m
 		}
 	}
 	ir.mu.RUnlock()
-
+	// правила хранятся в порядке добавления, остается упорядочить по спискам.
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].Type < filtered[j].Type
+	})
 	return filtered, nil
 }
 
+func (ir *IPRuleRepo) Count(_ context.Context, search model.IPRuleSearch) (map[model.RuleType]int, error) {
+	counts := make(map[model.RuleType]int)
+	ir.mu.RLock()
+	for _, rule := range ir.rules {
+		if ir.matchSearch(rule, search) {
+			counts[rule.Type]++
+		}
+	}
+	ir.mu.RUnlock()
+	return counts, nil
+}
+
+func (ir *IPRuleRepo) newRule(input model.IPRuleInput) model.IPRule {
+	return model.IPRule{
+		ID:        uuid.New(),
+		Type:      input.Type,
+		IPNet:     input.IPNet,
+		Source:    input.SourceOrDefault(),
+		Feed:      input.Feed,
+		Comment:   input.Comment,
+		CreatedBy: input.CreatedBy,
+		Priority:  input.Priority,
+		Range:     input.Range,
+		UpdatedAt: time.Now(),
+	}
+}
+
 func (ir *IPRuleRepo) matchSearch(rule model.IPRule, search model.IPRuleSearch) bool {
 	if search.ID != nil {
 		if strings.Compare(rule.ID.String(), search.ID.String()) != 0 {
@@ -72,16 +184,51 @@ func (ir *IPRuleRepo) matchSearch(rule model.IPRule, search model.IPRuleSearch)
 			return false
 		}
 	}
+	if search.Source != nil && rule.Source != *search.Source {
+		return false
+	}
+	if search.Feed != nil && rule.Feed != *search.Feed {
+		return false
+	}
+	if search.Range != nil && rule.Range != *search.Range {
+		return false
+	}
 	if search.IPNet != nil {
-		if search.IPNetExact && strings.Compare(rule.IPNet.String(), search.IPNet.String()) != 0 {
-			return false
-		} else if !search.IPNetExact && !rule.IPNet.Contains(search.IPNet.IP) {
+		var match bool
+		switch {
+		case search.IPNetExact:
+			match = strings.Compare(rule.IPNet.String(), search.IPNet.String()) == 0
+		case search.IPNetOverlap:
+			match = netlist.Overlaps(rule.IPNet, *search.IPNet)
+		default:
+			match = netlist.Contains(rule.IPNet, *search.IPNet)
+		}
+		if !match {
 			return false
 		}
 	}
+	if len(search.IPs) > 0 && !netlist.ContainsAny(rule.IPNet, search.IPs) {
+		return false
+	}
 	return true
 }
 
+// checkDuplicates проверка уникальности пары (тип, сеть) добавляемых правил среди существующих и между собой.
+func checkDuplicates(rules []model.IPRule, added ...model.IPRule) error {
+	keys := make(map[string]struct{}, len(rules)+len(added))
+	for _, rule := range rules {
+		keys[rule.Type.String()+" "+rule.IPNet.String()] = struct{}{}
+	}
+	for _, rule := range added {
+		key := rule.Type.String() + " " + rule.IPNet.String()
+		if _, ok := keys[key]; ok {
+			return fmt.Errorf("%s: %w", rule.IPNet.String(), model.ErrRuleDuplicate)
+		}
+		keys[key] = struct{}{}
+	}
+	return nil
+}
+
 func NewIPRuleRepo() repository.IPRule {
 	return &IPRuleRepo{}
 }
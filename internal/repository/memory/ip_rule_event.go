@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+type IPRuleEventRepo struct {
+	mu     sync.RWMutex
+	events []model.IPRuleEvent
+}
+
+func (er *IPRuleEventRepo) Add(_ context.Context, event model.IPRuleEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	er.mu.Lock()
+	er.events = append(er.events, event)
+	er.mu.Unlock()
+	return nil
+}
+
+func (er *IPRuleEventRepo) GetList(_ context.Context, search model.IPRuleEventSearch) ([]model.IPRuleEvent, error) {
+	var filtered []model.IPRuleEvent
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	// события добавляются в хронологическом порядке, идем с конца.
+	for i := len(er.events) - 1; i >= 0; i-- {
+		if search.Limit > 0 && len(filtered) >= search.Limit {
+			break
+		}
+		if er.matchSearch(er.events[i], search) {
+			filtered = append(filtered, er.events[i])
+		}
+	}
+	return filtered, nil
+}
+
+func (er *IPRuleEventRepo) matchSearch(event model.IPRuleEvent, search model.IPRuleEventSearch) bool {
+	if search.RuleID != nil && event.RuleID != *search.RuleID {
+		return false
+	}
+	if search.IPNet != nil {
+		if search.IPNetExact && event.IPNet.String() != search.IPNet.String() {
+			return false
+		} else if !search.IPNetExact && !event.IPNet.Contains(search.IPNet.IP) {
+			return false
+		}
+	}
+	return true
+}
+
+func NewIPRuleEventRepo() repository.IPRuleEvent {
+	return &IPRuleEventRepo{}
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+// NotificationOutboxRepo очередь в памяти, при перезапуске неотправленные уведомления теряются.
+type NotificationOutboxRepo struct {
+	mu    sync.Mutex
+	items map[uuid.UUID]model.Notification
+}
+
+func (or *NotificationOutboxRepo) Add(_ context.Context, item model.Notification) error {
+	or.mu.Lock()
+	defer or.mu.Unlock()
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	or.items[item.ID] = item
+	return nil
+}
+
+func (or *NotificationOutboxRepo) GetDue(
+	_ context.Context, now time.Time, lease time.Duration, limit int,
+) ([]model.Notification, error) {
+	or.mu.Lock()
+	defer or.mu.Unlock()
+	due := make([]model.Notification, 0)
+	for _, item := range or.items {
+		if !item.NextAttemptAt.After(now) {
+			due = append(due, item)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for i := range due {
+		due[i].NextAttemptAt = now.Add(lease)
+		or.items[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+func (or *NotificationOutboxRepo) Retry(_ context.Context, id uuid.UUID, next time.Time, lastError string) error {
+	or.mu.Lock()
+	defer or.mu.Unlock()
+	item, ok := or.items[id]
+	if !ok {
+		return model.ErrNotificationNotFound
+	}
+	item.Attempts++
+	item.NextAttemptAt = next
+	item.LastError = lastError
+	or.items[id] = item
+	return nil
+}
+
+func (or *NotificationOutboxRepo) Delete(_ context.Context, id uuid.UUID) error {
+	or.mu.Lock()
+	defer or.mu.Unlock()
+	if _, ok := or.items[id]; !ok {
+		return model.ErrNotificationNotFound
+	}
+	delete(or.items, id)
+	return nil
+}
+
+func NewNotificationOutboxRepo() repository.NotificationOutbox {
+	return &NotificationOutboxRepo{items: make(map[uuid.UUID]model.Notification)}
+}
@@ -0,0 +1,20 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+// Transactor репозитории в памяти не откатывают изменения: InTx просто вызывает fn.
+// Сами репозитории после изменения не возвращают ошибок, поэтому частичных изменений не остается,
+// если ошибку не вернул код между вызовами внутри fn.
+type Transactor struct{}
+
+func (Transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func NewTransactor() repository.Transactor {
+	return Transactor{}
+}
@@ -0,0 +1,104 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/leporo/sqlf"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+type APIKeyRepo struct {
+	pool *sql.DB
+}
+
+func (kr APIKeyRepo) Add(ctx context.Context, input model.APIKeyInput) (*model.APIKey, error) {
+	guid := uuid.New()
+	_, err := sqlf.InsertInto("api_keys").
+		Set("id", guid.String()).
+		Set("name", input.Name).
+		Set("key_hash", input.Hash).
+		Set("role", string(input.Role)).
+		ExecAndClose(ctx, executor(ctx, kr.pool))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return nil, fmt.Errorf("%s: %w", pgErr.Detail, model.ErrAPIKeyDuplicate)
+	}
+	if err != nil {
+		return nil, err
+	}
+	keys, err := kr.getList(ctx, sqlf.From("api_keys").Where("id = ?", guid.String()))
+	if err != nil {
+		return nil, err
+	}
+	return &keys[0], nil
+}
+
+func (kr APIKeyRepo) GetByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	keys, err := kr.getList(ctx, sqlf.From("api_keys").Where("key_hash = ?", hash))
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, model.ErrAPIKeyNotFound
+	}
+	return &keys[0], nil
+}
+
+func (kr APIKeyRepo) Delete(ctx context.Context, name string) error {
+	res, err := sqlf.DeleteFrom("api_keys").Where("name = ?", name).ExecAndClose(ctx, executor(ctx, kr.pool))
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (kr APIKeyRepo) GetList(ctx context.Context) ([]model.APIKey, error) {
+	return kr.getList(ctx, sqlf.From("api_keys").OrderBy("name"))
+}
+
+func (kr APIKeyRepo) getList(ctx context.Context, stmt *sqlf.Stmt) ([]model.APIKey, error) {
+	stmt.Select("id, name, key_hash, role, created_at")
+	keys := make([]model.APIKey, 0)
+	rows, err := executor(ctx, kr.pool).QueryContext(ctx, stmt.String(), stmt.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var (
+			id, role string
+			key      model.APIKey
+		)
+		if err := rows.Scan(&id, &key.Name, &key.Hash, &role, &key.CreatedAt); err != nil {
+			return nil, err
+		}
+		if key.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		if key.Role, err = model.ParseRole(role); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func NewAPIKeyRepo(pool *sql.DB) repository.APIKey {
+	return &APIKeyRepo{pool: pool}
+}
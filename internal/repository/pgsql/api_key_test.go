@@ -0,0 +1,33 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/leporo/sqlf"
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/internal/repository/repotest"
+)
+
+func TestAPIKeyPgsqlRepo(t *testing.T) {
+	dsn := os.Getenv(dsnEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", dsnEnv)
+	}
+	sqlf.SetDialect(sqlf.PostgreSQL)
+	pool, err := sql.Open("pgx", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = pool.Close()
+	})
+
+	repotest.RunAPIKey(t, func(t *testing.T) repository.APIKey {
+		t.Helper()
+		_, err := pool.ExecContext(context.Background(), "TRUNCATE api_keys")
+		require.NoError(t, err)
+		return NewAPIKeyRepo(pool)
+	})
+}
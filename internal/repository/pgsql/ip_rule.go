@@ -3,25 +3,29 @@ package pgsql
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"net"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
 	"github.com/leporo/sqlf"
 	"github.com/vitermakov/otusgo-final/internal/model"
 	"github.com/vitermakov/otusgo-final/internal/repository"
 )
 
+const (
+	uniqueViolation = "23505"
+	uniqueTypeIPNet = "ip_rules_type_ip_net_key"
+)
+
 type IPRuleRepo struct {
 	pool *sql.DB
 }
 
 func (ir IPRuleRepo) Add(ctx context.Context, input model.IPRuleInput) (*model.IPRule, error) {
-	guid := uuid.New()
-	stmt := sqlf.InsertInto("ip_rules").
-		Set("id", guid.String()).
-		Set("type", input.Type.String()).
-		Set("ip_net", input.IPNet.String())
-	_, err := stmt.ExecAndClose(ctx, ir.pool)
+	guid, err := ir.insert(ctx, executor(ctx, ir.pool), input)
 	if err != nil {
 		return nil, err
 	}
@@ -32,21 +36,164 @@ func (ir IPRuleRepo) Add(ctx context.Context, input model.IPRuleInput) (*model.I
 	return &rules[0], nil
 }
 
+// AddBatch все правила добавляются в одной транзакции.
+func (ir IPRuleRepo) AddBatch(ctx context.Context, inputs []model.IPRuleInput) ([]model.IPRule, error) {
+	return ir.Replace(ctx, nil, inputs)
+}
+
+func (ir IPRuleRepo) insert(ctx context.Context, db sqlf.Executor, input model.IPRuleInput) (uuid.UUID, error) {
+	guid := uuid.New()
+	stmt := sqlf.InsertInto("ip_rules").
+		Set("id", guid.String()).
+		Set("type", input.Type.String()).
+		Set("ip_net", input.IPNet.String()).
+		Set("source", string(input.SourceOrDefault())).
+		Set("feed", input.Feed).
+		Set("comment", input.Comment).
+		Set("created_by", input.CreatedBy).
+		Set("priority", input.Priority).
+		Set("ip_range", input.Range)
+	_, err := stmt.ExecAndClose(ctx, db)
+	return guid, mapError(err)
+}
+
+// Replace удаление и добавление в одной транзакции.
+func (ir IPRuleRepo) Replace(
+	ctx context.Context, remove []uuid.UUID, inputs []model.IPRuleInput,
+) ([]model.IPRule, error) {
+	rules := make([]model.IPRule, 0, len(inputs))
+	err := inTx(ctx, ir.pool, func(tx sqlf.Executor) error {
+		for _, id := range remove {
+			if _, err := sqlf.DeleteFrom("ip_rules").Where("id = ?", id.String()).ExecAndClose(ctx, tx); err != nil {
+				return err
+			}
+		}
+		for _, input := range inputs {
+			guid, err := ir.insert(ctx, tx, input)
+			if err != nil {
+				return err
+			}
+			found, err := ir.getList(ctx, tx, model.IPRuleSearch{ID: &guid})
+			if err != nil {
+				return err
+			}
+			rules = append(rules, found...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
 func (ir IPRuleRepo) Delete(ctx context.Context, input model.IPRuleInput) error {
 	stmt := sqlf.DeleteFrom("ip_rules").
 		Where("type = ?", input.Type.String()).
 		Where("ip_net = ?", input.IPNet.String())
-	_, err := stmt.ExecAndClose(ctx, ir.pool)
+	if input.Source != "" {
+		stmt.Where("source = ?", string(input.Source)).
+			Where("feed = ?", input.Feed)
+	}
+	_, err := stmt.ExecAndClose(ctx, executor(ctx, ir.pool))
 	return err
 }
 
+// Update строки сети блокируются на время транзакции, параллельные изменения той же сети ждут ее завершения.
+func (ir IPRuleRepo) Update(ctx context.Context, update model.IPRuleUpdate) (*model.IPRule, *model.IPRule, error) {
+	var before, after model.IPRule
+	err := inTx(ctx, ir.pool, func(tx sqlf.Executor) error {
+		id, err := ir.lockIPNet(ctx, tx, update.IPNet)
+		if err != nil {
+			return err
+		}
+		found, err := ir.getList(ctx, tx, model.IPRuleSearch{ID: &id})
+		if err != nil {
+			return err
+		}
+		before = found[0]
+		stmt := sqlf.Update("ip_rules").
+			SetExpr("updated_at", "now()").
+			Where("id = ?", before.ID.String())
+		if update.Type != nil {
+			stmt.Set("type", update.Type.String())
+		}
+		if update.Comment != nil {
+			stmt.Set("comment", *update.Comment)
+		}
+		if update.Priority != nil {
+			stmt.Set("priority", *update.Priority)
+		}
+		if update.Source != nil {
+			stmt.Set("source", string(*update.Source))
+		}
+		if update.Feed != nil {
+			stmt.Set("feed", *update.Feed)
+		}
+		if _, err := stmt.ExecAndClose(ctx, tx); err != nil {
+			return mapError(err)
+		}
+		found, err = ir.getList(ctx, tx, model.IPRuleSearch{ID: &before.ID})
+		if err != nil {
+			return err
+		}
+		after = found[0]
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &before, &after, nil
+}
+
+// lockIPNet блокировка правила с точно совпадающей сетью. Уникален только ключ (type, ip_net),
+// поэтому сеть из обоих списков - ошибка, а не произвольная из двух строк.
+func (ir IPRuleRepo) lockIPNet(ctx context.Context, tx sqlf.Executor, ipNet net.IPNet) (uuid.UUID, error) {
+	stmt := sqlf.From("ip_rules").
+		Select("id").
+		Where("ip_net = ?::inet", ipNet.String()).
+		Clause("FOR UPDATE")
+	defer stmt.Close()
+	rows, err := tx.QueryContext(ctx, stmt.String(), stmt.Args()...)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	ids := make([]string, 0, 1)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return uuid.Nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return uuid.Nil, err
+	}
+	switch len(ids) {
+	case 0:
+		return uuid.Nil, model.ErrRuleNotFound
+	case 1:
+		return uuid.Parse(ids[0])
+	}
+	return uuid.Nil, model.ErrRuleAmbiguous
+}
+
 func (ir IPRuleRepo) GetList(ctx context.Context, search model.IPRuleSearch) ([]model.IPRule, error) {
+	return ir.getList(ctx, executor(ctx, ir.pool), search)
+}
+
+func (ir IPRuleRepo) getList(ctx context.Context, db sqlf.Executor, search model.IPRuleSearch) ([]model.IPRule, error) {
 	stmt := sqlf.From("ip_rules").
-		Select(`id, type, text(ip_net), updated_at`).
-		OrderBy("ip_rules.type asc")
+		Select(`id, type, text(ip_net), source, feed, comment, created_by, priority, ip_range, updated_at`).
+		OrderBy("ip_rules.type asc", "ip_rules.seq asc")
+	defer stmt.Close()
 	ir.applySearch(stmt, search)
 	rules := make([]model.IPRule, 0)
-	rows, err := ir.pool.QueryContext(ctx, stmt.String(), stmt.Args()...)
+	rows, err := db.QueryContext(ctx, stmt.String(), stmt.Args()...)
 	if err != nil {
 		return nil, err
 	}
@@ -61,19 +208,52 @@ func (ir IPRuleRepo) GetList(ctx context.Context, search model.IPRuleSearch) ([]
 		}
 		rules = append(rules, rule)
 	}
-	return rules, nil
+	return rules, rows.Err()
+}
+
+func (ir IPRuleRepo) Count(ctx context.Context, search model.IPRuleSearch) (map[model.RuleType]int, error) {
+	stmt := sqlf.From("ip_rules").
+		Select("text(type), count(*)").
+		GroupBy("ip_rules.type")
+	defer stmt.Close()
+	ir.applySearch(stmt, search)
+	rows, err := executor(ctx, ir.pool).QueryContext(ctx, stmt.String(), stmt.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	counts := make(map[model.RuleType]int)
+	for rows.Next() {
+		var (
+			typ   string
+			count int
+		)
+		if err := rows.Scan(&typ, &count); err != nil {
+			return nil, err
+		}
+		ruleType, err := model.ParseRuleType(typ)
+		if err != nil {
+			return nil, err
+		}
+		counts[ruleType] = count
+	}
+	return counts, rows.Err()
 }
 
 func (ir IPRuleRepo) prepareModel(row *sql.Rows) (model.IPRule, error) {
 	var (
-		id, typ, ipNet sql.NullString
-		rule           model.IPRule
-		err            error
+		id, typ, ipNet, source sql.NullString
+		rule                   model.IPRule
+		err                    error
 	)
-	if err := row.Scan(&id, &typ, &ipNet, &rule.UpdatedAt); err != nil {
-		if err != nil {
-			return rule, err
-		}
+	if err := row.Scan(
+		&id, &typ, &ipNet, &source, &rule.Feed, &rule.Comment, &rule.CreatedBy, &rule.Priority, &rule.Range,
+		&rule.UpdatedAt,
+	); err != nil {
+		return rule, err
 	}
 	if id.Valid {
 		rule.ID, err = uuid.Parse(id.String)
@@ -87,6 +267,9 @@ func (ir IPRuleRepo) prepareModel(row *sql.Rows) (model.IPRule, error) {
 			return rule, err
 		}
 	}
+	if source.Valid {
+		rule.Source = model.RuleSource(source.String)
+	}
 	if ipNet.Valid {
 		_, mask, err := net.ParseCIDR(ipNet.String)
 		if err != nil {
@@ -104,15 +287,45 @@ func (ir IPRuleRepo) applySearch(stmt *sqlf.Stmt, search model.IPRuleSearch) {
 	if search.Type != nil {
 		stmt.Where("ip_rules.type = ?", search.Type.String())
 	}
+	if search.Source != nil {
+		stmt.Where("ip_rules.source = ?", string(*search.Source))
+	}
+	if search.Feed != nil {
+		stmt.Where("ip_rules.feed = ?", *search.Feed)
+	}
+	if search.Range != nil {
+		stmt.Where("ip_rules.ip_range = ?", *search.Range)
+	}
 	if search.IPNet != nil {
-		if search.IPNetExact {
+		switch {
+		case search.IPNetExact:
 			stmt.Where("ip_rules.ip_net = ?::inet", search.IPNet.String())
-		} else {
-			stmt.Where("?::inet << ip_rules.ip_net", search.IPNet.String())
+		case search.IPNetOverlap:
+			stmt.Where("ip_rules.ip_net && ?::inet", search.IPNet.String())
+		default:
+			stmt.Where("?::inet <<= ip_rules.ip_net", search.IPNet.String())
+		}
+	}
+	if len(search.IPs) > 0 {
+		conds := make([]string, len(search.IPs))
+		args := make([]interface{}, len(search.IPs))
+		for i, ip := range search.IPs {
+			conds[i] = "?::inet <<= ip_rules.ip_net"
+			args[i] = ip.String()
 		}
+		stmt.Where("("+strings.Join(conds, " OR ")+")", args...)
 	}
 }
 
+// mapError нарушение ограничения ip_rules_type_ip_net_key переводится в model.ErrRuleDuplicate.
+func mapError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == uniqueTypeIPNet {
+		return fmt.Errorf("%s: %w", pgErr.Detail, model.ErrRuleDuplicate)
+	}
+	return err
+}
+
 func NewIPRuleRepo(pool *sql.DB) repository.IPRule {
 	return &IPRuleRepo{pool: pool}
 }
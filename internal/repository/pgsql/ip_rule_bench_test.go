@@ -0,0 +1,114 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/leporo/sqlf"
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/model"
+)
+
+const (
+	// benchRules сети 1.0.0.0/24, 1.0.1.0/24, ... - по одной на каждые 256 адресов.
+	benchRules = 1_000_000
+	// benchDSNEnv строка подключения к отдельной базе с примененными миграциями: ip_rules в ней
+	// заполняется benchRules правилами, поэтому база тестов dsnEnv для этого не подходит.
+	benchDSNEnv = "BRUTEFP_BENCH_PGSQL_DSN"
+)
+
+// openBenchDB база с benchRules правилами, заполняется один раз и переиспользуется между запусками.
+func openBenchDB(tb testing.TB) *sql.DB {
+	tb.Helper()
+	dsn := os.Getenv(benchDSNEnv)
+	if dsn == "" {
+		tb.Skipf("%s is not set", benchDSNEnv)
+	}
+	sqlf.SetDialect(sqlf.PostgreSQL)
+	pool, err := sql.Open("pgx", dsn)
+	require.NoError(tb, err)
+	tb.Cleanup(func() {
+		_ = pool.Close()
+	})
+
+	ctx := context.Background()
+	var count int
+	require.NoError(tb, pool.QueryRowContext(ctx, "SELECT count(*) FROM ip_rules").Scan(&count))
+	if count == benchRules {
+		return pool
+	}
+	_, err = pool.ExecContext(ctx, "TRUNCATE ip_rules")
+	require.NoError(tb, err)
+	_, err = pool.ExecContext(ctx, `INSERT INTO ip_rules (id, type, ip_net)
+		SELECT md5(i::text)::uuid,
+			CASE WHEN i % 10 = 0 THEN 'allow' ELSE 'deny' END::ip_rules_type,
+			set_masklen('1.0.0.0'::inet + i::bigint * 256, 24)::cidr
+		FROM generate_series(0, $1 - 1) AS i`, benchRules)
+	require.NoError(tb, err)
+	_, err = pool.ExecContext(ctx, "ANALYZE ip_rules")
+	require.NoError(tb, err)
+	return pool
+}
+
+func randomHost(rnd *rand.Rand) *net.IPNet {
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, 1<<24+uint32(rnd.Int63n(benchRules*256)))
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(32, 32)}
+}
+
+func TestContainmentUsesIndex(t *testing.T) {
+	pool := openBenchDB(t)
+	ctx := context.Background()
+
+	for _, query := range []string{
+		"SELECT id FROM ip_rules WHERE '1.2.3.4'::inet <<= ip_rules.ip_net",
+		"SELECT id FROM ip_rules WHERE ip_rules.ip_net && '1.2.0.0/16'::inet",
+	} {
+		rows, err := pool.QueryContext(ctx, "EXPLAIN "+query)
+		require.NoError(t, err)
+		var plan []string
+		for rows.Next() {
+			var line string
+			require.NoError(t, rows.Scan(&line))
+			plan = append(plan, line)
+		}
+		require.NoError(t, rows.Close())
+		require.Contains(t, strings.Join(plan, "\n"), "ip_rules_ip_net_idx", query)
+	}
+}
+
+func BenchmarkGetListContains(b *testing.B) {
+	repo := NewIPRuleRepo(openBenchDB(b))
+	ctx := context.Background()
+	rnd := rand.New(rand.NewSource(1)) //nolint:gosec // воспроизводимая нагрузка
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rules, err := repo.GetList(ctx, model.IPRuleSearch{IPNet: randomHost(rnd)})
+		if err != nil || len(rules) != 1 {
+			b.Fatalf("rules: %d, err: %v", len(rules), err)
+		}
+	}
+}
+
+func BenchmarkGetListExact(b *testing.B) {
+	repo := NewIPRuleRepo(openBenchDB(b))
+	ctx := context.Background()
+	rnd := rand.New(rand.NewSource(1)) //nolint:gosec // воспроизводимая нагрузка
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		host := randomHost(rnd)
+		ipNet := net.IPNet{IP: host.IP.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}
+		rules, err := repo.GetList(ctx, model.IPRuleSearch{IPNet: &ipNet, IPNetExact: true})
+		if err != nil || len(rules) != 1 {
+			b.Fatalf("rules: %d, err: %v", len(rules), err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+	"net"
+
+	"github.com/google/uuid"
+	"github.com/leporo/sqlf"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+type IPRuleEventRepo struct {
+	pool *sql.DB
+}
+
+func (er IPRuleEventRepo) Add(ctx context.Context, event model.IPRuleEvent) error {
+	before, err := repository.MarshalRuleSnapshot(event.Before)
+	if err != nil {
+		return err
+	}
+	after, err := repository.MarshalRuleSnapshot(event.After)
+	if err != nil {
+		return err
+	}
+	stmt := sqlf.InsertInto("ip_rule_events").
+		Set("id", event.ID.String()).
+		Set("rule_id", event.RuleID.String()).
+		Set("action", string(event.Action)).
+		Set("actor", event.Actor).
+		Set("ip_net", event.IPNet.String()).
+		Set("before", before).
+		Set("after", after)
+	if !event.CreatedAt.IsZero() {
+		stmt.Set("created_at", event.CreatedAt)
+	}
+	_, err = stmt.ExecAndClose(ctx, executor(ctx, er.pool))
+	return err
+}
+
+func (er IPRuleEventRepo) GetList(ctx context.Context, search model.IPRuleEventSearch) ([]model.IPRuleEvent, error) {
+	stmt := sqlf.From("ip_rule_events").
+		Select(`id, rule_id, action, actor, text(ip_net), before, after, created_at`).
+		OrderBy("ip_rule_events.created_at desc")
+	if search.RuleID != nil {
+		stmt.Where("ip_rule_events.rule_id = ?", search.RuleID.String())
+	}
+	if search.IPNet != nil {
+		if search.IPNetExact {
+			stmt.Where("ip_rule_events.ip_net = ?::inet", search.IPNet.String())
+		} else {
+			stmt.Where("?::inet <<= ip_rule_events.ip_net", search.IPNet.String())
+		}
+	}
+	if search.Limit > 0 {
+		stmt.Limit(search.Limit)
+	}
+	events := make([]model.IPRuleEvent, 0)
+	rows, err := executor(ctx, er.pool).QueryContext(ctx, stmt.String(), stmt.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		event, err := er.prepareModel(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (er IPRuleEventRepo) prepareModel(row *sql.Rows) (model.IPRuleEvent, error) {
+	var (
+		id, ruleID, action, ipNet sql.NullString
+		before, after             []byte
+		event                     model.IPRuleEvent
+		err                       error
+	)
+	if err := row.Scan(&id, &ruleID, &action, &event.Actor, &ipNet, &before, &after, &event.CreatedAt); err != nil {
+		return event, err
+	}
+	if event.ID, err = uuid.Parse(id.String); err != nil {
+		return event, err
+	}
+	if event.RuleID, err = uuid.Parse(ruleID.String); err != nil {
+		return event, err
+	}
+	event.Action = model.EventAction(action.String)
+	if ipNet.Valid {
+		_, mask, err := net.ParseCIDR(ipNet.String)
+		if err != nil {
+			return event, err
+		}
+		event.IPNet = *mask
+	}
+	if event.Before, err = repository.UnmarshalRuleSnapshot(before); err != nil {
+		return event, err
+	}
+	if event.After, err = repository.UnmarshalRuleSnapshot(after); err != nil {
+		return event, err
+	}
+	return event, nil
+}
+
+func NewIPRuleEventRepo(pool *sql.DB) repository.IPRuleEvent {
+	return &IPRuleEventRepo{pool: pool}
+}
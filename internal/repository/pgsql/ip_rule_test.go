@@ -0,0 +1,37 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/v4/stdlib" //nolint:blank-imports // регистрация драйвера pgx
+	"github.com/leporo/sqlf"
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/internal/repository/repotest"
+)
+
+// dsnEnv строка подключения к тестовой базе с примененными миграциями, таблицы очищаются перед каждым тестом.
+const dsnEnv = "BRUTEFP_TEST_PGSQL_DSN"
+
+func TestIPRulePgsqlRepo(t *testing.T) {
+	dsn := os.Getenv(dsnEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", dsnEnv)
+	}
+	sqlf.SetDialect(sqlf.PostgreSQL)
+	pool, err := sql.Open("pgx", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = pool.Close()
+	})
+
+	repotest.RunIPRule(t, func(t *testing.T) repository.IPRule {
+		t.Helper()
+		_, err := pool.ExecContext(context.Background(), "TRUNCATE ip_rules")
+		require.NoError(t, err)
+		return NewIPRuleRepo(pool)
+	})
+}
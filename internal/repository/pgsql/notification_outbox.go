@@ -0,0 +1,111 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leporo/sqlf"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+type NotificationOutboxRepo struct {
+	pool *sql.DB
+}
+
+func (or NotificationOutboxRepo) Add(ctx context.Context, item model.Notification) error {
+	stmt := sqlf.InsertInto("notification_outbox").
+		Set("id", item.ID.String()).
+		Set("webhook", item.Webhook).
+		Set("type", string(item.Type)).
+		Set("payload", item.Payload).
+		Set("attempts", item.Attempts).
+		Set("next_attempt_at", item.NextAttemptAt).
+		Set("last_error", item.LastError)
+	if !item.CreatedAt.IsZero() {
+		stmt.Set("created_at", item.CreatedAt)
+	}
+	_, err := stmt.ExecAndClose(ctx, executor(ctx, or.pool))
+	return err
+}
+
+// GetDue выбор и продление в одном запросе: строки, занятые другим экземпляром, пропускаются,
+// а выбранные не попадут в его следующую выборку до истечения lease.
+func (or NotificationOutboxRepo) GetDue(
+	ctx context.Context, now time.Time, lease time.Duration, limit int,
+) ([]model.Notification, error) {
+	stmt := sqlf.Update("notification_outbox").
+		Set("next_attempt_at", now.Add(lease)).
+		Where(`id IN (SELECT id FROM notification_outbox WHERE next_attempt_at <= ?
+			ORDER BY created_at LIMIT ? FOR UPDATE SKIP LOCKED)`, now, limit).
+		Returning("id, webhook, type, payload, attempts, next_attempt_at, last_error, created_at")
+	items := make([]model.Notification, 0)
+	rows, err := executor(ctx, or.pool).QueryContext(ctx, stmt.String(), stmt.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var (
+			id, typ string
+			item    model.Notification
+		)
+		err := rows.Scan(
+			&id, &item.Webhook, &typ, &item.Payload, &item.Attempts, &item.NextAttemptAt, &item.LastError, &item.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if item.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		item.Type = model.NotifyType(typ)
+		items = append(items, item)
+	}
+	// RETURNING не сохраняет порядок подзапроса.
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items, rows.Err()
+}
+
+func (or NotificationOutboxRepo) Retry(ctx context.Context, id uuid.UUID, next time.Time, lastError string) error {
+	res, err := sqlf.Update("notification_outbox").
+		SetExpr("attempts", "attempts + 1").
+		Set("next_attempt_at", next).
+		Set("last_error", lastError).
+		Where("id = ?", id.String()).
+		ExecAndClose(ctx, executor(ctx, or.pool))
+	return notFoundIfNone(res, err)
+}
+
+func (or NotificationOutboxRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := sqlf.DeleteFrom("notification_outbox").
+		Where("id = ?", id.String()).
+		ExecAndClose(ctx, executor(ctx, or.pool))
+	return notFoundIfNone(res, err)
+}
+
+func notFoundIfNone(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func NewNotificationOutboxRepo(pool *sql.DB) repository.NotificationOutbox {
+	return &NotificationOutboxRepo{pool: pool}
+}
@@ -0,0 +1,53 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/leporo/sqlf"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+// txKey транзакция Transactor.InTx в контексте, ее подхватывают все репозитории пакета.
+type txKey struct{}
+
+type Transactor struct {
+	pool *sql.DB
+}
+
+func (t Transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+	tx, err := t.pool.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// после Commit вернет sql.ErrTxDone, это нормально.
+		_ = tx.Rollback()
+	}()
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// executor транзакция InTx, если вызов выполняется внутри нее, иначе пул соединений.
+func executor(ctx context.Context, pool *sql.DB) sqlf.Executor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return pool
+}
+
+// inTx fn в транзакции InTx из контекста или в новой.
+func inTx(ctx context.Context, pool *sql.DB, fn func(tx sqlf.Executor) error) error {
+	return Transactor{pool: pool}.InTx(ctx, func(ctx context.Context) error {
+		return fn(executor(ctx, pool))
+	})
+}
+
+func NewTransactor(pool *sql.DB) repository.Transactor {
+	return &Transactor{pool: pool}
+}
@@ -2,13 +2,66 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/vitermakov/otusgo-final/internal/model"
 )
 
+// Transactor транзакция хранилища. Вызовы репозиториев того же хранилища с контекстом fn выполняются
+// в одной транзакции: изменения применяются все вместе, если fn и фиксация прошли без ошибок, иначе
+// откатываются. InTx внутри InTx продолжает внешнюю транзакцию.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // IPRule управление хранилищем white/black списков.
+// Пара (тип, сеть) уникальна: Add, AddBatch и Replace возвращают model.ErrRuleDuplicate, если такое правило уже есть.
 type IPRule interface {
 	Add(context.Context, model.IPRuleInput) (*model.IPRule, error)
+	// AddBatch добавление набора правил по принципу "все или ничего".
+	AddBatch(context.Context, []model.IPRuleInput) ([]model.IPRule, error)
 	Delete(context.Context, model.IPRuleInput) error
+	// Update атомарное изменение правила с точно совпадающей сетью, возвращает состояния до и после.
+	// Если правила нет - model.ErrRuleNotFound, если сеть есть в обоих списках - model.ErrRuleAmbiguous.
+	Update(context.Context, model.IPRuleUpdate) (*model.IPRule, *model.IPRule, error)
+	// Replace удаление правил по ID и добавление новых по принципу "все или ничего".
+	Replace(context.Context, []uuid.UUID, []model.IPRuleInput) ([]model.IPRule, error)
+	// GetList правила по спискам, сначала allow, затем deny, внутри списка в порядке добавления.
+	// Update не меняет место правила.
 	GetList(context.Context, model.IPRuleSearch) ([]model.IPRule, error)
+	// Count число подходящих правил по спискам, без чтения самих правил.
+	Count(context.Context, model.IPRuleSearch) (map[model.RuleType]int, error)
+}
+
+// IPRuleEvent журнал изменений white/black списков, только добавление и чтение.
+type IPRuleEvent interface {
+	Add(context.Context, model.IPRuleEvent) error
+	// GetList записи от новых к старым.
+	GetList(context.Context, model.IPRuleEventSearch) ([]model.IPRuleEvent, error)
+}
+
+// APIKey ключи клиентов API. Имя и хэш ключа уникальны: Add возвращает model.ErrAPIKeyDuplicate.
+type APIKey interface {
+	Add(context.Context, model.APIKeyInput) (*model.APIKey, error)
+	// GetByHash если ключа нет - model.ErrAPIKeyNotFound.
+	GetByHash(context.Context, string) (*model.APIKey, error)
+	// Delete если ключа нет - model.ErrAPIKeyNotFound.
+	Delete(ctx context.Context, name string) error
+	// GetList ключи по имени.
+	GetList(context.Context) ([]model.APIKey, error)
+}
+
+// NotificationOutbox очередь уведомлений вебхуков, переживающая перезапуск сервиса.
+type NotificationOutbox interface {
+	Add(context.Context, model.Notification) error
+	// GetDue уведомления с NextAttemptAt не позже now, от старых к новым, не больше limit.
+	// Выбранным NextAttemptAt атомарно сдвигается на now+lease: другой экземпляр их не получит,
+	// а если отправитель упадет, не удалив и не отложив уведомление, оно снова подойдет после lease.
+	GetDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Notification, error)
+	// Retry учет неудачной попытки: Attempts увеличивается, следующая попытка не раньше next.
+	// Если уведомления нет - model.ErrNotificationNotFound.
+	Retry(ctx context.Context, id uuid.UUID, next time.Time, lastError string) error
+	// Delete уведомление доставлено или отброшено. Если его нет - model.ErrNotificationNotFound.
+	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -0,0 +1,71 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+// APIKeyFactory создает пустое хранилище для каждого подтеста.
+type APIKeyFactory func(t *testing.T) repository.APIKey
+
+// RunAPIKey прогоняет тесты repository.APIKey на конкретной реализации.
+func RunAPIKey(t *testing.T, newRepo APIKeyFactory) {
+	t.Helper()
+
+	t.Run("add and find", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		hash := model.HashAPIKey("secret-1")
+		key, err := repo.Add(ctx, model.APIKeyInput{Name: "php-login", Hash: hash, Role: model.RoleChecker})
+		require.NoError(t, err)
+		require.Equal(t, "php-login", key.Name)
+		require.False(t, key.CreatedAt.IsZero())
+
+		found, err := repo.GetByHash(ctx, hash)
+		require.NoError(t, err)
+		require.Equal(t, key.ID, found.ID)
+		require.Equal(t, model.RoleChecker, found.Role)
+
+		_, err = repo.GetByHash(ctx, model.HashAPIKey("secret-2"))
+		require.ErrorIs(t, err, model.ErrAPIKeyNotFound)
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		_, err := repo.Add(ctx, model.APIKeyInput{Name: "ops", Hash: model.HashAPIKey("a"), Role: model.RoleOperator})
+		require.NoError(t, err)
+		_, err = repo.Add(ctx, model.APIKeyInput{Name: "ops", Hash: model.HashAPIKey("b"), Role: model.RoleOperator})
+		require.ErrorIs(t, err, model.ErrAPIKeyDuplicate)
+		_, err = repo.Add(ctx, model.APIKeyInput{Name: "other", Hash: model.HashAPIKey("a"), Role: model.RoleAdmin})
+		require.ErrorIs(t, err, model.ErrAPIKeyDuplicate)
+	})
+
+	t.Run("list and delete", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		for _, name := range []string{"b", "c", "a"} {
+			_, err := repo.Add(ctx, model.APIKeyInput{Name: name, Hash: model.HashAPIKey(name), Role: model.RoleAdmin})
+			require.NoError(t, err)
+		}
+		keys, err := repo.GetList(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 3)
+		require.Equal(t, []string{"a", "b", "c"}, []string{keys[0].Name, keys[1].Name, keys[2].Name})
+
+		require.NoError(t, repo.Delete(ctx, "b"))
+		require.ErrorIs(t, repo.Delete(ctx, "b"), model.ErrAPIKeyNotFound)
+		_, err = repo.GetByHash(ctx, model.HashAPIKey("b"))
+		require.ErrorIs(t, err, model.ErrAPIKeyNotFound)
+		keys, err = repo.GetList(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+	})
+}
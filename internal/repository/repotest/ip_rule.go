@@ -0,0 +1,520 @@
+// Package repotest общие тесты для всех реализаций интерфейсов пакета repository.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+// IPRuleFactory создает пустое хранилище для каждого подтеста.
+type IPRuleFactory func(t *testing.T) repository.IPRule
+
+// RunIPRule прогоняет тесты repository.IPRule на конкретной реализации.
+func RunIPRule(t *testing.T, newRepo IPRuleFactory) {
+	t.Helper()
+
+	t.Run("complex test", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		_, net1, _ := net.ParseCIDR("10.0.1.0/24")
+		_, net2, _ := net.ParseCIDR("192.168.0.0/16")
+		_, net3, _ := net.ParseCIDR("192.170.0.0/16")
+		_, net4, _ := net.ParseCIDR("192.180.0.0/16")
+
+		ipInNet2 := net.ParseIP("192.168.1.200")
+		ipNotInNets := net.ParseIP("10.0.2.200")
+
+		rules := []model.IPRule{
+			{
+				Type:  model.RuleTypeAllow,
+				IPNet: *net1,
+			}, {
+				Type:  model.RuleTypeAllow,
+				IPNet: *net2,
+			}, {
+				Type:  model.RuleTypeDeny,
+				IPNet: *net3,
+			}, {
+				Type:  model.RuleTypeDeny,
+				IPNet: *net4,
+			},
+		}
+		for i, rule := range rules {
+			input := model.IPRuleInput{
+				Type:  rule.Type,
+				IPNet: rule.IPNet,
+			}
+			rule, err := repo.Add(ctx, input)
+			require.NoError(t, err)
+			require.Equal(t, model.RuleSourceManual, rule.Source)
+
+			rules[i].ID = rule.ID
+			rules[i].Source = rule.Source
+			rules[i].UpdatedAt = rule.UpdatedAt
+		}
+
+		actual, _ := repo.GetList(ctx, model.IPRuleSearch{})
+		require.ElementsMatch(t, rules, actual)
+
+		actual, _ = repo.GetList(ctx, model.IPRuleSearch{ID: &rules[1].ID})
+		require.Equal(t, 1, len(actual))
+
+		rt := model.RuleTypeAllow
+		actual, _ = repo.GetList(ctx, model.IPRuleSearch{Type: &rt})
+		require.Equal(t, 2, len(actual))
+		require.ElementsMatch(t, rules[0:2], actual)
+
+		rt = model.RuleTypeDeny
+		actual, _ = repo.GetList(ctx, model.IPRuleSearch{Type: &rt})
+		require.Equal(t, 2, len(actual))
+		require.ElementsMatch(t, rules[2:4], actual)
+
+		actual, _ = repo.GetList(ctx, model.IPRuleSearch{IPNet: net3, IPNetExact: true})
+		require.Equal(t, 1, len(actual))
+		require.Equal(t, rules[2].ID.String(), actual[0].ID.String())
+
+		actual, _ = repo.GetList(ctx, model.IPRuleSearch{IPNet: &net.IPNet{
+			IP: ipInNet2, Mask: net.CIDRMask(32, 32),
+		}})
+		require.Equal(t, 1, len(actual))
+		require.Equal(t, rules[1].ID.String(), actual[0].ID.String())
+
+		actual, _ = repo.GetList(ctx, model.IPRuleSearch{IPNet: &net.IPNet{
+			IP: ipNotInNets, Mask: net.CIDRMask(32, 32),
+		}, IPNetExact: true})
+		require.Equal(t, 0, len(actual))
+
+		_ = repo.Delete(ctx, model.IPRuleInput{
+			Type:  rules[3].Type,
+			IPNet: rules[3].IPNet,
+		})
+		actual, _ = repo.GetList(ctx, model.IPRuleSearch{})
+		require.Equal(t, 3, len(actual))
+		require.ElementsMatch(t, rules[0:3], actual)
+	})
+	t.Run("ipv6", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		_, wide, _ := net.ParseCIDR("2001:db8::/32")
+		_, narrow, _ := net.ParseCIDR("2001:db8:1::/48")
+		_, other, _ := net.ParseCIDR("2001:db9::/32")
+		for _, ipNet := range []*net.IPNet{wide, narrow, other} {
+			_, err := repo.Add(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: *ipNet})
+			require.NoError(t, err)
+		}
+
+		host := net.IPNet{IP: net.ParseIP("2001:db8:1::1"), Mask: net.CIDRMask(128, 128)}
+		actual, err := repo.GetList(ctx, model.IPRuleSearch{IPNet: &host})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{wide.String(), narrow.String()}, ipNets(actual))
+
+		_, probe, _ := net.ParseCIDR("2001:db8:1:2::/64")
+		actual, err = repo.GetList(ctx, model.IPRuleSearch{IPNet: probe, IPNetOverlap: true})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{wide.String(), narrow.String()}, ipNets(actual))
+
+		// адрес IPv4 не попадает в IPv6 сети.
+		v4 := net.IPNet{IP: net.ParseIP("10.0.0.1").To4(), Mask: net.CIDRMask(32, 32)}
+		actual, err = repo.GetList(ctx, model.IPRuleSearch{IPNet: &v4})
+		require.NoError(t, err)
+		require.Empty(t, actual)
+	})
+	t.Run("search by ips", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		for _, cidr := range []string{"10.0.0.0/8", "10.1.0.0/16", "192.168.0.0/16", "2001:db8::/32", "172.16.0.0/12"} {
+			_, ipNet, _ := net.ParseCIDR(cidr)
+			_, err := repo.Add(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: *ipNet})
+			require.NoError(t, err)
+		}
+		ips := []net.IP{net.ParseIP("10.1.2.3"), net.ParseIP("192.168.5.5"), net.ParseIP("2001:db8::1")}
+		actual, err := repo.GetList(ctx, model.IPRuleSearch{IPs: ips})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"10.0.0.0/8", "10.1.0.0/16", "192.168.0.0/16", "2001:db8::/32"}, ipNets(actual))
+
+		// условие по адресам сочетается с остальными.
+		_, narrow, _ := net.ParseCIDR("10.1.0.0/16")
+		actual, err = repo.GetList(ctx, model.IPRuleSearch{IPs: ips, IPNet: narrow, IPNetExact: true})
+		require.NoError(t, err)
+		require.Equal(t, []string{"10.1.0.0/16"}, ipNets(actual))
+
+		actual, err = repo.GetList(ctx, model.IPRuleSearch{IPs: []net.IP{net.ParseIP("8.8.8.8")}})
+		require.NoError(t, err)
+		require.Empty(t, actual)
+	})
+	t.Run("count", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		counts, err := repo.Count(ctx, model.IPRuleSearch{})
+		require.NoError(t, err)
+		require.Empty(t, counts)
+
+		for cidr, typ := range map[string]model.RuleType{
+			"10.0.0.0/8": model.RuleTypeDeny, "10.1.0.0/16": model.RuleTypeAllow,
+			"192.168.0.0/16": model.RuleTypeDeny, "2001:db8::/32": model.RuleTypeDeny,
+		} {
+			_, ipNet, _ := net.ParseCIDR(cidr)
+			_, err := repo.Add(ctx, model.IPRuleInput{Type: typ, IPNet: *ipNet})
+			require.NoError(t, err)
+		}
+		counts, err = repo.Count(ctx, model.IPRuleSearch{})
+		require.NoError(t, err)
+		require.Equal(t, map[model.RuleType]int{model.RuleTypeDeny: 3, model.RuleTypeAllow: 1}, counts)
+
+		deny := model.RuleTypeDeny
+		counts, err = repo.Count(ctx, model.IPRuleSearch{Type: &deny})
+		require.NoError(t, err)
+		require.Equal(t, map[model.RuleType]int{model.RuleTypeDeny: 3}, counts)
+
+		// условия на сети те же, что у GetList.
+		_, host, _ := net.ParseCIDR("10.1.2.3/32")
+		counts, err = repo.Count(ctx, model.IPRuleSearch{IPNet: host})
+		require.NoError(t, err)
+		require.Equal(t, map[model.RuleType]int{model.RuleTypeDeny: 1, model.RuleTypeAllow: 1}, counts)
+		counts, err = repo.Count(ctx, model.IPRuleSearch{IPs: []net.IP{net.ParseIP("2001:db8::1")}})
+		require.NoError(t, err)
+		require.Equal(t, map[model.RuleType]int{model.RuleTypeDeny: 1}, counts)
+	})
+	t.Run("add batch", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		_, net1, _ := net.ParseCIDR("10.0.1.0/24")
+		_, net2, _ := net.ParseCIDR("192.168.0.0/16")
+		inputs := []model.IPRuleInput{
+			{Type: model.RuleTypeAllow, IPNet: *net1, Source: model.RuleSourceImport, Comment: "office", CreatedBy: "admin"},
+			{Type: model.RuleTypeDeny, IPNet: *net2},
+		}
+		added, err := repo.AddBatch(ctx, inputs)
+		require.NoError(t, err)
+		require.Len(t, added, 2)
+		require.Equal(t, model.RuleSourceImport, added[0].Source)
+		require.Equal(t, "office", added[0].Comment)
+		require.Equal(t, "admin", added[0].CreatedBy)
+		require.Equal(t, model.RuleSourceManual, added[1].Source)
+
+		actual, _ := repo.GetList(ctx, model.IPRuleSearch{})
+		require.ElementsMatch(t, added, actual)
+	})
+	t.Run("order", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		input := func(typ model.RuleType, network string) model.IPRuleInput {
+			_, ipNet, _ := net.ParseCIDR(network)
+			return model.IPRuleInput{Type: typ, IPNet: *ipNet}
+		}
+		_, err := repo.Add(ctx, input(model.RuleTypeDeny, "192.168.0.0/16"))
+		require.NoError(t, err)
+		_, err = repo.Add(ctx, input(model.RuleTypeAllow, "10.0.1.0/24"))
+		require.NoError(t, err)
+		_, err = repo.AddBatch(ctx, []model.IPRuleInput{
+			input(model.RuleTypeDeny, "10.0.0.0/8"), input(model.RuleTypeAllow, "172.16.0.0/12"),
+		})
+		require.NoError(t, err)
+		// изменение не переносит правило в конец списка.
+		comment, first := "changed", input(model.RuleTypeDeny, "192.168.0.0/16")
+		_, _, err = repo.Update(ctx, model.IPRuleUpdate{IPNet: first.IPNet, Comment: &comment})
+		require.NoError(t, err)
+
+		actual, err := repo.GetList(ctx, model.IPRuleSearch{})
+		require.NoError(t, err)
+		nets := make([]string, 0, len(actual))
+		for _, rule := range actual {
+			nets = append(nets, rule.Type.String()+" "+rule.IPNet.String())
+		}
+		require.Equal(t, []string{
+			"allow 10.0.1.0/24", "allow 172.16.0.0/12", "deny 192.168.0.0/16", "deny 10.0.0.0/8",
+		}, nets)
+	})
+	t.Run("update", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		_, net1, _ := net.ParseCIDR("10.0.1.0/24")
+		_, net2, _ := net.ParseCIDR("10.0.2.0/24")
+		added, err := repo.Add(ctx, model.IPRuleInput{Type: model.RuleTypeAllow, IPNet: *net1, Comment: "office"})
+		require.NoError(t, err)
+
+		deny, comment := model.RuleTypeDeny, "compromised"
+		before, after, err := repo.Update(ctx, model.IPRuleUpdate{IPNet: *net1, Type: &deny, Comment: &comment})
+		require.NoError(t, err)
+		require.Equal(t, *added, *before)
+		require.Equal(t, added.ID, after.ID)
+		require.Equal(t, model.RuleTypeDeny, after.Type)
+		require.Equal(t, "compromised", after.Comment)
+
+		actual, _ := repo.GetList(ctx, model.IPRuleSearch{})
+		require.Equal(t, []model.IPRule{*after}, actual)
+
+		_, _, err = repo.Update(ctx, model.IPRuleUpdate{IPNet: *net2, Type: &deny})
+		require.ErrorIs(t, err, model.ErrRuleNotFound)
+	})
+	t.Run("update network in both lists", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		_, net1, _ := net.ParseCIDR("10.0.1.0/24")
+		_, err := repo.Add(ctx, model.IPRuleInput{Type: model.RuleTypeAllow, IPNet: *net1, Comment: "office"})
+		require.NoError(t, err)
+		_, err = repo.Add(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: *net1, Comment: "scanner"})
+		require.NoError(t, err)
+
+		comment := "changed"
+		_, _, err = repo.Update(ctx, model.IPRuleUpdate{IPNet: *net1, Comment: &comment})
+		require.ErrorIs(t, err, model.ErrRuleAmbiguous)
+		actual, err := repo.GetList(ctx, model.IPRuleSearch{})
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+		require.Equal(t, "office", actual[0].Comment)
+		require.Equal(t, "scanner", actual[1].Comment)
+	})
+	t.Run("duplicate", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		_, net1, _ := net.ParseCIDR("10.0.0.0/8")
+		_, net2, _ := net.ParseCIDR("172.16.0.0/12")
+		_, err := repo.Add(ctx, model.IPRuleInput{Type: model.RuleTypeAllow, IPNet: *net1})
+		require.NoError(t, err)
+
+		_, err = repo.Add(ctx, model.IPRuleInput{Type: model.RuleTypeAllow, IPNet: *net1, Comment: "again"})
+		require.ErrorIs(t, err, model.ErrRuleDuplicate)
+		// уникальна пара (тип, сеть), запрет пересечения списков - забота сервиса.
+		_, err = repo.Add(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: *net1})
+		require.NoError(t, err)
+
+		// дубликат в пакете отменяет весь пакет.
+		_, err = repo.AddBatch(ctx, []model.IPRuleInput{
+			{Type: model.RuleTypeDeny, IPNet: *net2},
+			{Type: model.RuleTypeAllow, IPNet: *net1},
+		})
+		require.ErrorIs(t, err, model.ErrRuleDuplicate)
+		_, err = repo.AddBatch(ctx, []model.IPRuleInput{
+			{Type: model.RuleTypeDeny, IPNet: *net2},
+			{Type: model.RuleTypeDeny, IPNet: *net2},
+		})
+		require.ErrorIs(t, err, model.ErrRuleDuplicate)
+
+		actual, err := repo.GetList(ctx, model.IPRuleSearch{})
+		require.NoError(t, err)
+		require.Equal(t, []string{net1.String(), net1.String()}, ipNets(actual))
+	})
+	t.Run("concurrent duplicate", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		_, ipNet, _ := net.ParseCIDR("172.16.0.0/12")
+		const workers = 8
+		var (
+			wg    sync.WaitGroup
+			added int64
+		)
+		errs := make(chan error, workers)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := repo.Add(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: *ipNet})
+				switch {
+				case err == nil:
+					atomic.AddInt64(&added, 1)
+				case !errors.Is(err, model.ErrRuleDuplicate):
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+		require.Equal(t, int64(1), added)
+	})
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		_, net1, _ := net.ParseCIDR("10.0.0.0/8")
+		_, net2, _ := net.ParseCIDR("10.1.0.0/16")
+		_, err := repo.AddBatch(ctx, []model.IPRuleInput{
+			{Type: model.RuleTypeDeny, IPNet: *net1},
+			{Type: model.RuleTypeDeny, IPNet: *net2, Source: model.RuleSourceFeed, Feed: "drop"},
+		})
+		require.NoError(t, err)
+
+		// не совпадают тип, источник или фид - ничего не удаляется, ошибки нет.
+		require.NoError(t, repo.Delete(ctx, model.IPRuleInput{Type: model.RuleTypeAllow, IPNet: *net1}))
+		require.NoError(t, repo.Delete(ctx, model.IPRuleInput{
+			Type: model.RuleTypeDeny, IPNet: *net1, Source: model.RuleSourceFeed, Feed: "drop",
+		}))
+		require.NoError(t, repo.Delete(ctx, model.IPRuleInput{
+			Type: model.RuleTypeDeny, IPNet: *net2, Source: model.RuleSourceFeed, Feed: "edrop",
+		}))
+		actual, err := repo.GetList(ctx, model.IPRuleSearch{})
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+
+		require.NoError(t, repo.Delete(ctx, model.IPRuleInput{
+			Type: model.RuleTypeDeny, IPNet: *net2, Source: model.RuleSourceFeed, Feed: "drop",
+		}))
+		// без источника удаляется правило любого источника.
+		require.NoError(t, repo.Delete(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: *net1}))
+		actual, err = repo.GetList(ctx, model.IPRuleSearch{})
+		require.NoError(t, err)
+		require.Empty(t, actual)
+	})
+	t.Run("exact and containment search", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		_, wide, _ := net.ParseCIDR("10.0.0.0/8")
+		_, middle, _ := net.ParseCIDR("10.1.0.0/16")
+		_, narrow, _ := net.ParseCIDR("10.1.1.0/24")
+		_, other, _ := net.ParseCIDR("192.168.0.0/16")
+		for _, ipNet := range []*net.IPNet{wide, middle, narrow, other} {
+			_, err := repo.Add(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: *ipNet})
+			require.NoError(t, err)
+		}
+
+		host := net.IPNet{IP: net.ParseIP("10.1.1.5").To4(), Mask: net.CIDRMask(32, 32)}
+		actual, err := repo.GetList(ctx, model.IPRuleSearch{IPNet: &host})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{wide.String(), middle.String(), narrow.String()}, ipNets(actual))
+
+		// сеть должна входить в правило целиком.
+		actual, err = repo.GetList(ctx, model.IPRuleSearch{IPNet: middle})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{wide.String(), middle.String()}, ipNets(actual))
+
+		actual, err = repo.GetList(ctx, model.IPRuleSearch{IPNet: middle, IPNetExact: true})
+		require.NoError(t, err)
+		require.Equal(t, []string{middle.String()}, ipNets(actual))
+
+		actual, err = repo.GetList(ctx, model.IPRuleSearch{IPNet: middle, IPNetOverlap: true})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{wide.String(), middle.String(), narrow.String()}, ipNets(actual))
+
+		_, missing, _ := net.ParseCIDR("10.2.0.0/16")
+		actual, err = repo.GetList(ctx, model.IPRuleSearch{IPNet: missing, IPNetExact: true})
+		require.NoError(t, err)
+		require.Empty(t, actual)
+	})
+	t.Run("search by id", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		_, net1, _ := net.ParseCIDR("10.0.0.0/8")
+		_, net2, _ := net.ParseCIDR("172.16.0.0/12")
+		added, err := repo.AddBatch(ctx, []model.IPRuleInput{
+			{Type: model.RuleTypeAllow, IPNet: *net1},
+			{Type: model.RuleTypeDeny, IPNet: *net2},
+		})
+		require.NoError(t, err)
+
+		actual, err := repo.GetList(ctx, model.IPRuleSearch{ID: &added[1].ID})
+		require.NoError(t, err)
+		require.Equal(t, added[1:], actual)
+
+		unknown := uuid.New()
+		actual, err = repo.GetList(ctx, model.IPRuleSearch{ID: &unknown})
+		require.NoError(t, err)
+		require.Empty(t, actual)
+	})
+	t.Run("replace", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		_, net1, _ := net.ParseCIDR("10.0.0.0/24")
+		_, net2, _ := net.ParseCIDR("10.0.1.0/24")
+		_, merged, _ := net.ParseCIDR("10.0.0.0/23")
+		added, err := repo.AddBatch(ctx, []model.IPRuleInput{
+			{Type: model.RuleTypeDeny, IPNet: *net1},
+			{Type: model.RuleTypeDeny, IPNet: *net2},
+		})
+		require.NoError(t, err)
+
+		// ошибка добавления откатывает и удаление.
+		_, err = repo.Replace(ctx, []uuid.UUID{added[0].ID}, []model.IPRuleInput{
+			{Type: model.RuleTypeDeny, IPNet: *net2},
+		})
+		require.ErrorIs(t, err, model.ErrRuleDuplicate)
+		actual, err := repo.GetList(ctx, model.IPRuleSearch{})
+		require.NoError(t, err)
+		require.ElementsMatch(t, added, actual)
+
+		replaced, err := repo.Replace(ctx, []uuid.UUID{added[0].ID, added[1].ID}, []model.IPRuleInput{
+			{Type: model.RuleTypeDeny, IPNet: *merged, Comment: "compacted"},
+		})
+		require.NoError(t, err)
+		require.Len(t, replaced, 1)
+		require.Equal(t, "compacted", replaced[0].Comment)
+		actual, err = repo.GetList(ctx, model.IPRuleSearch{})
+		require.NoError(t, err)
+		require.Equal(t, replaced, actual)
+	})
+	t.Run("concurrent access", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		const workers, perWorker = 8, 20
+		var wg sync.WaitGroup
+		errs := make(chan error, workers*perWorker*2)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < perWorker; i++ {
+					ipNet := net.IPNet{IP: net.IPv4(10, byte(w), byte(i), 0).To4(), Mask: net.CIDRMask(24, 32)}
+					if _, err := repo.Add(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: ipNet}); err != nil {
+						errs <- err
+					}
+					if _, err := repo.GetList(ctx, model.IPRuleSearch{IPNet: &ipNet}); err != nil {
+						errs <- err
+					}
+					// каждое второе правило удаляется.
+					if i%2 == 1 {
+						if err := repo.Delete(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: ipNet}); err != nil {
+							errs <- err
+						}
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		actual, err := repo.GetList(ctx, model.IPRuleSearch{})
+		require.NoError(t, err)
+		require.Len(t, actual, workers*perWorker/2)
+		ids := make(map[uuid.UUID]struct{}, len(actual))
+		for _, rule := range actual {
+			ids[rule.ID] = struct{}{}
+		}
+		require.Len(t, ids, len(actual))
+	})
+}
+
+func ipNets(rules []model.IPRule) []string {
+	nets := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		nets = append(nets, rule.IPNet.String())
+	}
+	return nets
+}
@@ -0,0 +1,116 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+// NotificationOutboxFactory создает пустое хранилище для каждого подтеста.
+type NotificationOutboxFactory func(t *testing.T) repository.NotificationOutbox
+
+// RunNotificationOutbox прогоняет тесты repository.NotificationOutbox на конкретной реализации.
+func RunNotificationOutbox(t *testing.T, newRepo NotificationOutboxFactory) {
+	t.Helper()
+
+	base := time.Now().Truncate(time.Millisecond)
+	notification := func(webhook string, created time.Duration, next time.Duration) model.Notification {
+		return model.Notification{
+			ID:            uuid.New(),
+			Webhook:       webhook,
+			Type:          model.NotifyTypeIPBanned,
+			Payload:       []byte(`{"type":"ip_banned"}`),
+			NextAttemptAt: base.Add(next),
+			CreatedAt:     base.Add(created),
+		}
+	}
+
+	t.Run("due in creation order", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		later := notification("slack", time.Second, 0)
+		first := notification("siem", 0, 0)
+		future := notification("siem", -time.Second, time.Minute)
+		for _, item := range []model.Notification{later, first, future} {
+			require.NoError(t, repo.Add(ctx, item))
+		}
+
+		due, err := repo.GetDue(ctx, base, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, due, 2)
+		require.Equal(t, first.ID, due[0].ID)
+		require.Equal(t, later.ID, due[1].ID)
+		require.Equal(t, "siem", due[0].Webhook)
+		require.Equal(t, model.NotifyTypeIPBanned, due[0].Type)
+		require.Equal(t, first.Payload, due[0].Payload)
+		require.Zero(t, due[0].Attempts)
+
+		due, err = repo.GetDue(ctx, base, 0, 1)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		due, err = repo.GetDue(ctx, base.Add(time.Minute), 0, 10)
+		require.NoError(t, err)
+		require.Len(t, due, 3)
+	})
+
+	t.Run("retry and delete", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		item := notification("siem", 0, 0)
+		require.NoError(t, repo.Add(ctx, item))
+		require.NoError(t, repo.Retry(ctx, item.ID, base.Add(time.Minute), "503 Service Unavailable"))
+		due, err := repo.GetDue(ctx, base, 0, 10)
+		require.NoError(t, err)
+		require.Empty(t, due)
+
+		due, err = repo.GetDue(ctx, base.Add(time.Minute), 0, 10)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, 1, due[0].Attempts)
+		require.Equal(t, "503 Service Unavailable", due[0].LastError)
+		require.True(t, base.Add(time.Minute).Equal(due[0].NextAttemptAt))
+
+		require.NoError(t, repo.Delete(ctx, item.ID))
+		require.ErrorIs(t, repo.Delete(ctx, item.ID), model.ErrNotificationNotFound)
+		require.ErrorIs(t, repo.Retry(ctx, item.ID, base, ""), model.ErrNotificationNotFound)
+	})
+
+	t.Run("claim", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		first := notification("siem", 0, 0)
+		later := notification("siem", time.Second, 0)
+		for _, item := range []model.Notification{first, later} {
+			require.NoError(t, repo.Add(ctx, item))
+		}
+
+		// выбранные откладываются на lease и следующей выборке не достаются.
+		due, err := repo.GetDue(ctx, base, time.Minute, 1)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, first.ID, due[0].ID)
+		require.True(t, base.Add(time.Minute).Equal(due[0].NextAttemptAt))
+		due, err = repo.GetDue(ctx, base, time.Minute, 10)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, later.ID, due[0].ID)
+		due, err = repo.GetDue(ctx, base, time.Minute, 10)
+		require.NoError(t, err)
+		require.Empty(t, due)
+
+		// неотправленные после истечения lease выбираются снова.
+		due, err = repo.GetDue(ctx, base.Add(time.Minute), time.Minute, 10)
+		require.NoError(t, err)
+		require.Len(t, due, 2)
+		require.Equal(t, first.ID, due[0].ID)
+		require.Zero(t, due[0].Attempts)
+	})
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vitermakov/otusgo-final/internal/model"
+)
+
+// ruleSnapshot состояние правила в журнале изменений (колонки before/after), общее для SQL-хранилищ.
+type ruleSnapshot struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	IPNet     string    `json:"ipNet"`
+	Source    string    `json:"source"`
+	Feed      string    `json:"feed,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	Priority  int       `json:"priority,omitempty"`
+	Range     string    `json:"range,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// MarshalRuleSnapshot JSON-снимок правила, nil для отсутствующего правила.
+func MarshalRuleSnapshot(rule *model.IPRule) (interface{}, error) {
+	if rule == nil {
+		return nil, nil
+	}
+	bs, err := json.Marshal(ruleSnapshot{
+		ID:        rule.ID.String(),
+		Type:      rule.Type.String(),
+		IPNet:     rule.IPNet.String(),
+		Source:    string(rule.Source),
+		Feed:      rule.Feed,
+		Comment:   rule.Comment,
+		CreatedBy: rule.CreatedBy,
+		Priority:  rule.Priority,
+		Range:     rule.Range,
+		UpdatedAt: rule.UpdatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return string(bs), nil
+}
+
+// UnmarshalRuleSnapshot правило из JSON-снимка, nil для пустого снимка.
+func UnmarshalRuleSnapshot(data []byte) (*model.IPRule, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var snap ruleSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	rule := &model.IPRule{
+		Source:    model.RuleSource(snap.Source),
+		Feed:      snap.Feed,
+		Comment:   snap.Comment,
+		CreatedBy: snap.CreatedBy,
+		Priority:  snap.Priority,
+		Range:     snap.Range,
+		UpdatedAt: snap.UpdatedAt,
+	}
+	var err error
+	if rule.ID, err = uuid.Parse(snap.ID); err != nil {
+		return nil, err
+	}
+	if rule.Type, err = model.ParseRuleType(snap.Type); err != nil {
+		return nil, err
+	}
+	_, mask, err := net.ParseCIDR(snap.IPNet)
+	if err != nil {
+		return nil, err
+	}
+	rule.IPNet = *mask
+	return rule, nil
+}
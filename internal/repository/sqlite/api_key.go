@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leporo/sqlf"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+type APIKeyRepo struct {
+	db *sql.DB
+}
+
+func (kr APIKeyRepo) Add(ctx context.Context, input model.APIKeyInput) (*model.APIKey, error) {
+	key := model.APIKey{
+		ID:        uuid.New(),
+		Name:      input.Name,
+		Hash:      input.Hash,
+		Role:      input.Role,
+		CreatedAt: time.Now(),
+	}
+	_, err := sqlf.NoDialect.InsertInto("api_keys").
+		Set("id", key.ID.String()).
+		Set("name", key.Name).
+		Set("key_hash", key.Hash).
+		Set("role", string(key.Role)).
+		Set("created_at", key.CreatedAt.UnixNano()).
+		ExecAndClose(ctx, executor(ctx, kr.db))
+	if err != nil {
+		return nil, mapUnique(err, model.ErrAPIKeyDuplicate)
+	}
+	return &key, nil
+}
+
+func (kr APIKeyRepo) GetByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	keys, err := kr.getList(ctx, sqlf.NoDialect.From("api_keys").Where("key_hash = ?", hash))
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, model.ErrAPIKeyNotFound
+	}
+	return &keys[0], nil
+}
+
+func (kr APIKeyRepo) Delete(ctx context.Context, name string) error {
+	res, err := sqlf.NoDialect.DeleteFrom("api_keys").Where("name = ?", name).ExecAndClose(ctx, executor(ctx, kr.db))
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (kr APIKeyRepo) GetList(ctx context.Context) ([]model.APIKey, error) {
+	return kr.getList(ctx, sqlf.NoDialect.From("api_keys").OrderBy("name"))
+}
+
+func (kr APIKeyRepo) getList(ctx context.Context, stmt *sqlf.Stmt) ([]model.APIKey, error) {
+	stmt.Select("id, name, key_hash, role, created_at")
+	keys := make([]model.APIKey, 0)
+	rows, err := executor(ctx, kr.db).QueryContext(ctx, stmt.String(), stmt.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var (
+			id, role  string
+			createdAt int64
+			key       model.APIKey
+		)
+		if err := rows.Scan(&id, &key.Name, &key.Hash, &role, &createdAt); err != nil {
+			return nil, err
+		}
+		if key.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		if key.Role, err = model.ParseRole(role); err != nil {
+			return nil, err
+		}
+		key.CreatedAt = time.Unix(0, createdAt)
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func NewAPIKeyRepo(db *sql.DB) repository.APIKey {
+	return &APIKeyRepo{db: db}
+}
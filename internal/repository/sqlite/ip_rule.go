@@ -0,0 +1,311 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leporo/sqlf"
+	"github.com/mattn/go-sqlite3"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/pkg/utils/netlist"
+)
+
+// IPRuleRepo в SQLite нет типа inet, сети хранятся строками, поэтому проверки вхождения
+// и пересечения сетей (IPv4 и IPv6) выполняются в Go после выборки остальных условий.
+type IPRuleRepo struct {
+	db *sql.DB
+}
+
+func (ir IPRuleRepo) Add(ctx context.Context, input model.IPRuleInput) (*model.IPRule, error) {
+	db := executor(ctx, ir.db)
+	guid, err := ir.insert(ctx, db, input)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := ir.getList(ctx, db, model.IPRuleSearch{ID: &guid})
+	if err != nil {
+		return nil, err
+	}
+	return &rules[0], nil
+}
+
+// AddBatch все правила добавляются в одной транзакции.
+func (ir IPRuleRepo) AddBatch(ctx context.Context, inputs []model.IPRuleInput) ([]model.IPRule, error) {
+	return ir.Replace(ctx, nil, inputs)
+}
+
+func (ir IPRuleRepo) insert(ctx context.Context, db sqlf.Executor, input model.IPRuleInput) (uuid.UUID, error) {
+	guid := uuid.New()
+	stmt := sqlf.NoDialect.InsertInto("ip_rules").
+		Set("id", guid.String()).
+		Set("type", input.Type.String()).
+		Set("ip_net", input.IPNet.String()).
+		Set("source", string(input.SourceOrDefault())).
+		Set("feed", input.Feed).
+		Set("comment", input.Comment).
+		Set("created_by", input.CreatedBy).
+		Set("priority", input.Priority).
+		Set("ip_range", input.Range).
+		Set("updated_at", time.Now().UnixNano())
+	_, err := stmt.ExecAndClose(ctx, db)
+	return guid, mapError(err)
+}
+
+// Replace удаление и добавление в одной транзакции.
+func (ir IPRuleRepo) Replace(
+	ctx context.Context, remove []uuid.UUID, inputs []model.IPRuleInput,
+) ([]model.IPRule, error) {
+	rules := make([]model.IPRule, 0, len(inputs))
+	err := inTx(ctx, ir.db, func(tx sqlf.Executor) error {
+		for _, id := range remove {
+			_, err := sqlf.NoDialect.DeleteFrom("ip_rules").Where("id = ?", id.String()).ExecAndClose(ctx, tx)
+			if err != nil {
+				return err
+			}
+		}
+		for _, input := range inputs {
+			guid, err := ir.insert(ctx, tx, input)
+			if err != nil {
+				return err
+			}
+			found, err := ir.getList(ctx, tx, model.IPRuleSearch{ID: &guid})
+			if err != nil {
+				return err
+			}
+			rules = append(rules, found...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (ir IPRuleRepo) Delete(ctx context.Context, input model.IPRuleInput) error {
+	stmt := sqlf.NoDialect.DeleteFrom("ip_rules").
+		Where("type = ?", input.Type.String()).
+		Where("ip_net = ?", input.IPNet.String())
+	if input.Source != "" {
+		stmt.Where("source = ?", string(input.Source)).
+			Where("feed = ?", input.Feed)
+	}
+	_, err := stmt.ExecAndClose(ctx, executor(ctx, ir.db))
+	return err
+}
+
+// Update транзакция открывается с блокировкой записи (_txlock=immediate), параллельные изменения ждут ее завершения.
+func (ir IPRuleRepo) Update(ctx context.Context, update model.IPRuleUpdate) (*model.IPRule, *model.IPRule, error) {
+	var before, after model.IPRule
+	err := inTx(ctx, ir.db, func(tx sqlf.Executor) error {
+		found, err := ir.getList(ctx, tx, model.IPRuleSearch{IPNet: &update.IPNet, IPNetExact: true})
+		if err != nil {
+			return err
+		}
+		switch len(found) {
+		case 0:
+			return model.ErrRuleNotFound
+		case 1:
+			before = found[0]
+		default:
+			return model.ErrRuleAmbiguous
+		}
+		stmt := sqlf.NoDialect.Update("ip_rules").
+			Set("updated_at", time.Now().UnixNano()).
+			Where("id = ?", before.ID.String())
+		if update.Type != nil {
+			stmt.Set("type", update.Type.String())
+		}
+		if update.Comment != nil {
+			stmt.Set("comment", *update.Comment)
+		}
+		if update.Priority != nil {
+			stmt.Set("priority", *update.Priority)
+		}
+		if update.Source != nil {
+			stmt.Set("source", string(*update.Source))
+		}
+		if update.Feed != nil {
+			stmt.Set("feed", *update.Feed)
+		}
+		if _, err := stmt.ExecAndClose(ctx, tx); err != nil {
+			return mapError(err)
+		}
+		found, err = ir.getList(ctx, tx, model.IPRuleSearch{ID: &before.ID})
+		if err != nil {
+			return err
+		}
+		after = found[0]
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &before, &after, nil
+}
+
+func (ir IPRuleRepo) GetList(ctx context.Context, search model.IPRuleSearch) ([]model.IPRule, error) {
+	return ir.getList(ctx, executor(ctx, ir.db), search)
+}
+
+func (ir IPRuleRepo) getList(ctx context.Context, db sqlf.Executor, search model.IPRuleSearch) ([]model.IPRule, error) {
+	stmt := sqlf.NoDialect.From("ip_rules").
+		Select(`id, type, ip_net, source, feed, comment, created_by, priority, ip_range, updated_at`).
+		OrderBy("ip_rules.type asc", "ip_rules.rowid asc")
+	defer stmt.Close()
+	ir.applySearch(stmt, search)
+	rules := make([]model.IPRule, 0)
+	rows, err := db.QueryContext(ctx, stmt.String(), stmt.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		rule, err := ir.prepareModel(rows)
+		if err != nil {
+			return nil, err
+		}
+		if ir.matchIPNet(rule, search) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, rows.Err()
+}
+
+// Count условия на вхождение и пересечение сетей проверяются в коде (см. matchIPNet),
+// с ними правила читаются целиком, без них считаются в SQL.
+func (ir IPRuleRepo) Count(ctx context.Context, search model.IPRuleSearch) (map[model.RuleType]int, error) {
+	db := executor(ctx, ir.db)
+	if len(search.IPs) > 0 || (search.IPNet != nil && !search.IPNetExact) {
+		rules, err := ir.getList(ctx, db, search)
+		if err != nil {
+			return nil, err
+		}
+		counts := make(map[model.RuleType]int)
+		for _, rule := range rules {
+			counts[rule.Type]++
+		}
+		return counts, nil
+	}
+	stmt := sqlf.NoDialect.From("ip_rules").
+		Select("type, count(*)").
+		GroupBy("ip_rules.type")
+	defer stmt.Close()
+	ir.applySearch(stmt, search)
+	rows, err := db.QueryContext(ctx, stmt.String(), stmt.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	counts := make(map[model.RuleType]int)
+	for rows.Next() {
+		var (
+			typ   string
+			count int
+		)
+		if err := rows.Scan(&typ, &count); err != nil {
+			return nil, err
+		}
+		ruleType, err := model.ParseRuleType(typ)
+		if err != nil {
+			return nil, err
+		}
+		counts[ruleType] = count
+	}
+	return counts, rows.Err()
+}
+
+func (ir IPRuleRepo) prepareModel(row *sql.Rows) (model.IPRule, error) {
+	var (
+		id, typ, ipNet, source string
+		updatedAt              int64
+		rule                   model.IPRule
+		err                    error
+	)
+	if err := row.Scan(
+		&id, &typ, &ipNet, &source, &rule.Feed, &rule.Comment, &rule.CreatedBy, &rule.Priority, &rule.Range,
+		&updatedAt,
+	); err != nil {
+		return rule, err
+	}
+	if rule.ID, err = uuid.Parse(id); err != nil {
+		return rule, err
+	}
+	if rule.Type, err = model.ParseRuleType(typ); err != nil {
+		return rule, err
+	}
+	rule.Source = model.RuleSource(source)
+	_, mask, err := net.ParseCIDR(ipNet)
+	if err != nil {
+		return rule, err
+	}
+	rule.IPNet = *mask
+	rule.UpdatedAt = time.Unix(0, updatedAt)
+	return rule, nil
+}
+
+func (ir IPRuleRepo) applySearch(stmt *sqlf.Stmt, search model.IPRuleSearch) {
+	if search.ID != nil {
+		stmt.Where("ip_rules.id = ?", search.ID.String())
+	}
+	if search.Type != nil {
+		stmt.Where("ip_rules.type = ?", search.Type.String())
+	}
+	if search.Source != nil {
+		stmt.Where("ip_rules.source = ?", string(*search.Source))
+	}
+	if search.Feed != nil {
+		stmt.Where("ip_rules.feed = ?", *search.Feed)
+	}
+	if search.Range != nil {
+		stmt.Where("ip_rules.ip_range = ?", *search.Range)
+	}
+	if search.IPNet != nil && search.IPNetExact {
+		// сети хранятся в каноничном виде net.IPNet.String(), точное совпадение сравнивается строкой.
+		stmt.Where("ip_rules.ip_net = ?", search.IPNet.String())
+	}
+}
+
+// matchIPNet условия на вхождение и пересечение сетей, которые нельзя выразить в SQL.
+func (ir IPRuleRepo) matchIPNet(rule model.IPRule, search model.IPRuleSearch) bool {
+	if len(search.IPs) > 0 && !netlist.ContainsAny(rule.IPNet, search.IPs) {
+		return false
+	}
+	if search.IPNet == nil || search.IPNetExact {
+		return true
+	}
+	if search.IPNetOverlap {
+		return netlist.Overlaps(rule.IPNet, *search.IPNet)
+	}
+	return netlist.Contains(rule.IPNet, *search.IPNet)
+}
+
+// mapError нарушение уникальности (тип, сеть) переводится в model.ErrRuleDuplicate.
+func mapError(err error) error {
+	return mapUnique(err, model.ErrRuleDuplicate)
+}
+
+// mapUnique нарушение уникальности переводится в ошибку модели target.
+func mapUnique(err, target error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return fmt.Errorf("%s: %w", sqliteErr.Error(), target)
+	}
+	return err
+}
+
+func NewIPRuleRepo(db *sql.DB) repository.IPRule {
+	return &IPRuleRepo{db: db}
+}
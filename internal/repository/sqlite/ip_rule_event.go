@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leporo/sqlf"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+type IPRuleEventRepo struct {
+	db *sql.DB
+}
+
+func (er IPRuleEventRepo) Add(ctx context.Context, event model.IPRuleEvent) error {
+	before, err := repository.MarshalRuleSnapshot(event.Before)
+	if err != nil {
+		return err
+	}
+	after, err := repository.MarshalRuleSnapshot(event.After)
+	if err != nil {
+		return err
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	_, err = sqlf.NoDialect.InsertInto("ip_rule_events").
+		Set("id", event.ID.String()).
+		Set("rule_id", event.RuleID.String()).
+		Set("action", string(event.Action)).
+		Set("actor", event.Actor).
+		Set("ip_net", event.IPNet.String()).
+		Set("before", before).
+		Set("after", after).
+		Set("created_at", event.CreatedAt.UnixNano()).
+		ExecAndClose(ctx, executor(ctx, er.db))
+	return err
+}
+
+// GetList вхождение IP в сеть проверяется в Go, поэтому лимит применяется после фильтрации.
+func (er IPRuleEventRepo) GetList(ctx context.Context, search model.IPRuleEventSearch) ([]model.IPRuleEvent, error) {
+	stmt := sqlf.NoDialect.From("ip_rule_events").
+		Select(`id, rule_id, action, actor, ip_net, before, after, created_at`).
+		OrderBy("ip_rule_events.created_at desc", "ip_rule_events.rowid desc")
+	if search.RuleID != nil {
+		stmt.Where("ip_rule_events.rule_id = ?", search.RuleID.String())
+	}
+	if search.IPNet != nil && search.IPNetExact {
+		stmt.Where("ip_rule_events.ip_net = ?", search.IPNet.String())
+	}
+	events := make([]model.IPRuleEvent, 0)
+	rows, err := executor(ctx, er.db).QueryContext(ctx, stmt.String(), stmt.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		if search.Limit > 0 && len(events) >= search.Limit {
+			break
+		}
+		event, err := er.prepareModel(rows)
+		if err != nil {
+			return nil, err
+		}
+		if search.IPNet != nil && !search.IPNetExact && !event.IPNet.Contains(search.IPNet.IP) {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (er IPRuleEventRepo) prepareModel(row *sql.Rows) (model.IPRuleEvent, error) {
+	var (
+		id, ruleID, action, ipNet string
+		before, after             []byte
+		createdAt                 int64
+		event                     model.IPRuleEvent
+		err                       error
+	)
+	if err := row.Scan(&id, &ruleID, &action, &event.Actor, &ipNet, &before, &after, &createdAt); err != nil {
+		return event, err
+	}
+	if event.ID, err = uuid.Parse(id); err != nil {
+		return event, err
+	}
+	if event.RuleID, err = uuid.Parse(ruleID); err != nil {
+		return event, err
+	}
+	event.Action = model.EventAction(action)
+	_, mask, err := net.ParseCIDR(ipNet)
+	if err != nil {
+		return event, err
+	}
+	event.IPNet = *mask
+	event.CreatedAt = time.Unix(0, createdAt)
+	if event.Before, err = repository.UnmarshalRuleSnapshot(before); err != nil {
+		return event, err
+	}
+	if event.After, err = repository.UnmarshalRuleSnapshot(after); err != nil {
+		return event, err
+	}
+	return event, nil
+}
+
+func NewIPRuleEventRepo(db *sql.DB) repository.IPRuleEvent {
+	return &IPRuleEventRepo{db: db}
+}
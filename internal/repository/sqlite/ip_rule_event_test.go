@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/model"
+)
+
+func TestIPRuleEventSqliteRepo(t *testing.T) {
+	ctx := context.Background()
+	repo := NewIPRuleEventRepo(openTestDB(t))
+
+	_, net1, _ := net.ParseCIDR("10.0.0.0/8")
+	_, net2, _ := net.ParseCIDR("2001:db8::/32")
+	rule := model.IPRule{Type: model.RuleTypeDeny, IPNet: *net1, Comment: "spam", Priority: 5}
+	base := time.Unix(1680000000, 0)
+	events := []model.IPRuleEvent{
+		model.NewIPRuleEvent(model.EventActionAdd, "admin", nil, &rule),
+		model.NewIPRuleEvent(model.EventActionDelete, "admin", &rule, nil),
+		model.NewIPRuleEvent(model.EventActionAdd, "", nil, &model.IPRule{Type: model.RuleTypeAllow, IPNet: *net2}),
+	}
+	for i := range events {
+		events[i].CreatedAt = base.Add(time.Duration(i) * time.Second)
+		require.NoError(t, repo.Add(ctx, events[i]))
+	}
+
+	actual, err := repo.GetList(ctx, model.IPRuleEventSearch{})
+	require.NoError(t, err)
+	require.Len(t, actual, 3)
+	require.Equal(t, events[2].ID, actual[0].ID)
+	require.Equal(t, events[0].ID, actual[2].ID)
+	require.Equal(t, "spam", actual[1].Before.Comment)
+	require.Equal(t, 5, actual[1].Before.Priority)
+	require.Nil(t, actual[1].After)
+
+	host := net.IPNet{IP: net.ParseIP("10.1.2.3").To4(), Mask: net.CIDRMask(32, 32)}
+	actual, err = repo.GetList(ctx, model.IPRuleEventSearch{IPNet: &host, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, events[1].ID, actual[0].ID)
+
+	actual, err = repo.GetList(ctx, model.IPRuleEventSearch{IPNet: net2, IPNetExact: true})
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, events[2].ID, actual[0].ID)
+}
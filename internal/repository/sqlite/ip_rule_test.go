@@ -0,0 +1,70 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/internal/repository/repotest"
+	"github.com/vitermakov/otusgo-final/pkg/utils/migrate"
+)
+
+func TestIPRuleSqliteRepo(t *testing.T) {
+	repotest.RunIPRule(t, func(t *testing.T) repository.IPRule {
+		t.Helper()
+		return NewIPRuleRepo(openTestDB(t))
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	db, closeFn, err := Open(ctx, filepath.Join(t.TempDir(), "brutefp.db"))
+	require.NoError(t, err)
+	defer func() {
+		_ = closeFn(ctx)
+	}()
+	migrator := migrate.New(db, Migrations(), migrate.SQLite)
+
+	files, err := fs.Glob(Migrations(), "*.sql")
+	require.NoError(t, err)
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, len(files))
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Empty(t, applied)
+
+	// журнал только пополняется.
+	_, err = db.ExecContext(ctx, `INSERT INTO ip_rule_events (id, rule_id, action, ip_net, created_at)
+		VALUES ('1', '1', 'add', '10.0.0.0/8', 0)`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "DELETE FROM ip_rule_events")
+	require.ErrorContains(t, err, "append-only")
+
+	// все миграции откатываются.
+	for range files {
+		_, err = migrator.Down(ctx)
+		require.NoError(t, err)
+	}
+	_, err = migrator.Down(ctx)
+	require.ErrorIs(t, err, migrate.ErrNothingToDown)
+	_, err = db.ExecContext(ctx, "SELECT count(*) FROM ip_rules")
+	require.ErrorContains(t, err, "no such table")
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	ctx := context.Background()
+	db, closeFn, err := Open(ctx, filepath.Join(t.TempDir(), "brutefp.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = closeFn(ctx)
+	})
+	_, err = migrate.New(db, Migrations(), migrate.SQLite).Up(ctx)
+	require.NoError(t, err)
+	return db
+}
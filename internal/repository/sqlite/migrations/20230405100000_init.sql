@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE ip_rules (
+    id TEXT NOT NULL PRIMARY KEY,
+    type TEXT NOT NULL CHECK (type IN ('allow', 'deny')),
+    ip_net TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT 'manual',
+    feed TEXT NOT NULL DEFAULT '',
+    comment TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    priority INTEGER NOT NULL DEFAULT 0,
+    ip_range TEXT NOT NULL DEFAULT '',
+    -- время в наносекундах unix, чтобы порядок и точность не зависели от формата строк
+    updated_at INTEGER NOT NULL
+);
+CREATE INDEX ip_rules_ip_net_idx ON ip_rules (ip_net);
+CREATE INDEX ip_rules_source_feed_idx ON ip_rules (source, feed);
+CREATE INDEX ip_rules_ip_range_idx ON ip_rules (ip_range) WHERE ip_range <> '';
+
+CREATE TABLE ip_rule_events (
+    id TEXT NOT NULL PRIMARY KEY,
+    rule_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    ip_net TEXT NOT NULL,
+    before TEXT,
+    after TEXT,
+    created_at INTEGER NOT NULL
+);
+CREATE INDEX ip_rule_events_rule_id_idx ON ip_rule_events (rule_id);
+CREATE INDEX ip_rule_events_created_at_idx ON ip_rule_events (created_at);
+
+-- журнал только пополняется: изменение и удаление записей запрещено.
+CREATE TRIGGER ip_rule_events_no_update BEFORE UPDATE ON ip_rule_events
+BEGIN
+    SELECT RAISE(ABORT, 'ip_rule_events is append-only');
+END;
+CREATE TRIGGER ip_rule_events_no_delete BEFORE DELETE ON ip_rule_events
+BEGIN
+    SELECT RAISE(ABORT, 'ip_rule_events is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ip_rule_events;
+DROP TABLE IF EXISTS ip_rules;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE UNIQUE INDEX ip_rules_type_ip_net_uniq ON ip_rules (type, ip_net);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS ip_rules_type_ip_net_uniq;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- очередь уведомлений вебхуков, удаляются после доставки или последней попытки.
+CREATE TABLE notification_outbox (
+    id TEXT NOT NULL PRIMARY KEY,
+    webhook TEXT NOT NULL,
+    type TEXT NOT NULL,
+    payload BLOB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at INTEGER NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL
+);
+CREATE INDEX notification_outbox_next_attempt_at_idx ON notification_outbox (next_attempt_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notification_outbox;
+-- +goose StatementEnd
@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leporo/sqlf"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+type NotificationOutboxRepo struct {
+	db *sql.DB
+}
+
+func (or NotificationOutboxRepo) Add(ctx context.Context, item model.Notification) error {
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	_, err := sqlf.NoDialect.InsertInto("notification_outbox").
+		Set("id", item.ID.String()).
+		Set("webhook", item.Webhook).
+		Set("type", string(item.Type)).
+		Set("payload", item.Payload).
+		Set("attempts", item.Attempts).
+		Set("next_attempt_at", item.NextAttemptAt.UnixNano()).
+		Set("last_error", item.LastError).
+		Set("created_at", item.CreatedAt.UnixNano()).
+		ExecAndClose(ctx, executor(ctx, or.db))
+	return err
+}
+
+// GetDue выбор и продление одним запросом, записи в файл SQLite и так выполняются по очереди.
+func (or NotificationOutboxRepo) GetDue(
+	ctx context.Context, now time.Time, lease time.Duration, limit int,
+) ([]model.Notification, error) {
+	stmt := sqlf.NoDialect.Update("notification_outbox").
+		Set("next_attempt_at", now.Add(lease).UnixNano()).
+		Where(`id IN (SELECT id FROM notification_outbox WHERE next_attempt_at <= ?
+			ORDER BY created_at, rowid LIMIT ?)`, now.UnixNano(), limit).
+		Returning("id, webhook, type, payload, attempts, next_attempt_at, last_error, created_at, rowid")
+	items := make([]model.Notification, 0)
+	rowIDs := make(map[uuid.UUID]int64)
+	rows, err := executor(ctx, or.db).QueryContext(ctx, stmt.String(), stmt.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var (
+			id, typ                     string
+			nextAttempt, created, rowID int64
+			item                        model.Notification
+		)
+		err := rows.Scan(
+			&id, &item.Webhook, &typ, &item.Payload, &item.Attempts, &nextAttempt, &item.LastError, &created, &rowID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if item.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		item.Type = model.NotifyType(typ)
+		item.NextAttemptAt = time.Unix(0, nextAttempt)
+		item.CreatedAt = time.Unix(0, created)
+		rowIDs[item.ID] = rowID
+		items = append(items, item)
+	}
+	// RETURNING не сохраняет порядок подзапроса.
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return rowIDs[items[i].ID] < rowIDs[items[j].ID]
+	})
+	return items, rows.Err()
+}
+
+func (or NotificationOutboxRepo) Retry(ctx context.Context, id uuid.UUID, next time.Time, lastError string) error {
+	res, err := sqlf.NoDialect.Update("notification_outbox").
+		SetExpr("attempts", "attempts + 1").
+		Set("next_attempt_at", next.UnixNano()).
+		Set("last_error", lastError).
+		Where("id = ?", id.String()).
+		ExecAndClose(ctx, executor(ctx, or.db))
+	return notFoundIfNone(res, err)
+}
+
+func (or NotificationOutboxRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := sqlf.NoDialect.DeleteFrom("notification_outbox").
+		Where("id = ?", id.String()).
+		ExecAndClose(ctx, executor(ctx, or.db))
+	return notFoundIfNone(res, err)
+}
+
+func notFoundIfNone(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func NewNotificationOutboxRepo(db *sql.DB) repository.NotificationOutbox {
+	return &NotificationOutboxRepo{db: db}
+}
@@ -0,0 +1,15 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/internal/repository/repotest"
+)
+
+func TestNotificationOutboxSqliteRepo(t *testing.T) {
+	repotest.RunNotificationOutbox(t, func(t *testing.T) repository.NotificationOutbox {
+		t.Helper()
+		return NewNotificationOutboxRepo(openTestDB(t))
+	})
+}
@@ -0,0 +1,45 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	_ "github.com/mattn/go-sqlite3" //nolint:blank-imports // регистрация драйвера sqlite3
+	"github.com/vitermakov/otusgo-final/pkg/utils/closer"
+)
+
+/*
+Хранилище в файле SQLite для установок на одном узле: правила переживают перезапуск,
+отдельный сервер БД не нужен. Схема поставляется вместе с бинарником (Migrations).
+Запросы строятся с явным sqlf.NoDialect, так как глобальный диалект sqlf настраивается под pgsql.
+*/
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrations схема SQLite в формате goose.
+func Migrations() fs.FS {
+	sub, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		// путь задан константой и проверен go:embed.
+		panic(err)
+	}
+	return sub
+}
+
+// Open открывает (создает) файл базы. Запись в SQLite однопоточная, поэтому используется
+// одно соединение, транзакции сразу берут блокировку записи. Схему создает migrate.Migrator с Migrations().
+func Open(_ context.Context, fileName string) (*sql.DB, closer.CloseFunc, error) {
+	dsn := fmt.Sprintf("file:%s?_busy_timeout=5000&_txlock=immediate", fileName)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	db.SetMaxOpenConns(1)
+	return db, func(_ context.Context) error {
+		return db.Close()
+	}, nil
+}
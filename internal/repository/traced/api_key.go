@@ -0,0 +1,51 @@
+package traced
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+type APIKeyRepo struct {
+	repo   repository.APIKey
+	system string
+}
+
+func (ar APIKeyRepo) Add(ctx context.Context, input model.APIKeyInput) (*model.APIKey, error) {
+	ctx, span := start(ctx, ar.system, "APIKeyRepo.Add")
+	key, err := ar.repo.Add(ctx, input)
+	end(span, err)
+	return key, err
+}
+
+// GetByHash ненайденный ключ - обычный ответ на неизвестный токен, а не ошибка спана.
+func (ar APIKeyRepo) GetByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	ctx, span := start(ctx, ar.system, "APIKeyRepo.GetByHash")
+	key, err := ar.repo.GetByHash(ctx, hash)
+	if errors.Is(err, model.ErrAPIKeyNotFound) {
+		end(span, nil)
+	} else {
+		end(span, err)
+	}
+	return key, err
+}
+
+func (ar APIKeyRepo) Delete(ctx context.Context, name string) error {
+	ctx, span := start(ctx, ar.system, "APIKeyRepo.Delete")
+	err := ar.repo.Delete(ctx, name)
+	end(span, err)
+	return err
+}
+
+func (ar APIKeyRepo) GetList(ctx context.Context) ([]model.APIKey, error) {
+	ctx, span := start(ctx, ar.system, "APIKeyRepo.GetList")
+	keys, err := ar.repo.GetList(ctx)
+	end(span, err)
+	return keys, err
+}
+
+func NewAPIKeyRepo(repo repository.APIKey, system string) repository.APIKey {
+	return &APIKeyRepo{repo: repo, system: system}
+}
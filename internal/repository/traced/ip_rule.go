@@ -0,0 +1,76 @@
+package traced
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type IPRuleRepo struct {
+	repo   repository.IPRule
+	system string
+}
+
+func (ir IPRuleRepo) Add(ctx context.Context, input model.IPRuleInput) (*model.IPRule, error) {
+	ctx, span := start(ctx, ir.system, "IPRuleRepo.Add")
+	rule, err := ir.repo.Add(ctx, input)
+	end(span, err)
+	return rule, err
+}
+
+func (ir IPRuleRepo) AddBatch(ctx context.Context, inputs []model.IPRuleInput) ([]model.IPRule, error) {
+	ctx, span := start(ctx, ir.system, "IPRuleRepo.AddBatch")
+	span.SetAttributes(attribute.Int("brutefp.rules.count", len(inputs)))
+	rules, err := ir.repo.AddBatch(ctx, inputs)
+	end(span, err)
+	return rules, err
+}
+
+func (ir IPRuleRepo) Delete(ctx context.Context, input model.IPRuleInput) error {
+	ctx, span := start(ctx, ir.system, "IPRuleRepo.Delete")
+	err := ir.repo.Delete(ctx, input)
+	end(span, err)
+	return err
+}
+
+func (ir IPRuleRepo) Update(ctx context.Context, update model.IPRuleUpdate) (*model.IPRule, *model.IPRule, error) {
+	ctx, span := start(ctx, ir.system, "IPRuleRepo.Update")
+	before, after, err := ir.repo.Update(ctx, update)
+	end(span, err)
+	return before, after, err
+}
+
+func (ir IPRuleRepo) Replace(
+	ctx context.Context, remove []uuid.UUID, inputs []model.IPRuleInput,
+) ([]model.IPRule, error) {
+	ctx, span := start(ctx, ir.system, "IPRuleRepo.Replace")
+	span.SetAttributes(
+		attribute.Int("brutefp.rules.removed", len(remove)),
+		attribute.Int("brutefp.rules.count", len(inputs)),
+	)
+	rules, err := ir.repo.Replace(ctx, remove, inputs)
+	end(span, err)
+	return rules, err
+}
+
+func (ir IPRuleRepo) GetList(ctx context.Context, search model.IPRuleSearch) ([]model.IPRule, error) {
+	ctx, span := start(ctx, ir.system, "IPRuleRepo.GetList")
+	rules, err := ir.repo.GetList(ctx, search)
+	span.SetAttributes(attribute.Int("brutefp.rules.count", len(rules)))
+	end(span, err)
+	return rules, err
+}
+
+func (ir IPRuleRepo) Count(ctx context.Context, search model.IPRuleSearch) (map[model.RuleType]int, error) {
+	ctx, span := start(ctx, ir.system, "IPRuleRepo.Count")
+	counts, err := ir.repo.Count(ctx, search)
+	end(span, err)
+	return counts, err
+}
+
+func NewIPRuleRepo(repo repository.IPRule, system string) repository.IPRule {
+	return &IPRuleRepo{repo: repo, system: system}
+}
@@ -0,0 +1,31 @@
+package traced
+
+import (
+	"context"
+
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+type IPRuleEventRepo struct {
+	repo   repository.IPRuleEvent
+	system string
+}
+
+func (er IPRuleEventRepo) Add(ctx context.Context, event model.IPRuleEvent) error {
+	ctx, span := start(ctx, er.system, "IPRuleEventRepo.Add")
+	err := er.repo.Add(ctx, event)
+	end(span, err)
+	return err
+}
+
+func (er IPRuleEventRepo) GetList(ctx context.Context, search model.IPRuleEventSearch) ([]model.IPRuleEvent, error) {
+	ctx, span := start(ctx, er.system, "IPRuleEventRepo.GetList")
+	events, err := er.repo.GetList(ctx, search)
+	end(span, err)
+	return events, err
+}
+
+func NewIPRuleEventRepo(repo repository.IPRuleEvent, system string) repository.IPRuleEvent {
+	return &IPRuleEventRepo{repo: repo, system: system}
+}
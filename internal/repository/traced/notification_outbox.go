@@ -0,0 +1,49 @@
+package traced
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+type NotificationOutboxRepo struct {
+	repo   repository.NotificationOutbox
+	system string
+}
+
+func (or NotificationOutboxRepo) Add(ctx context.Context, item model.Notification) error {
+	ctx, span := start(ctx, or.system, "NotificationOutboxRepo.Add")
+	err := or.repo.Add(ctx, item)
+	end(span, err)
+	return err
+}
+
+func (or NotificationOutboxRepo) GetDue(
+	ctx context.Context, now time.Time, lease time.Duration, limit int,
+) ([]model.Notification, error) {
+	ctx, span := start(ctx, or.system, "NotificationOutboxRepo.GetDue")
+	items, err := or.repo.GetDue(ctx, now, lease, limit)
+	end(span, err)
+	return items, err
+}
+
+func (or NotificationOutboxRepo) Retry(ctx context.Context, id uuid.UUID, next time.Time, lastError string) error {
+	ctx, span := start(ctx, or.system, "NotificationOutboxRepo.Retry")
+	err := or.repo.Retry(ctx, id, next, lastError)
+	end(span, err)
+	return err
+}
+
+func (or NotificationOutboxRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := start(ctx, or.system, "NotificationOutboxRepo.Delete")
+	err := or.repo.Delete(ctx, id)
+	end(span, err)
+	return err
+}
+
+func NewNotificationOutboxRepo(repo repository.NotificationOutbox, system string) repository.NotificationOutbox {
+	return &NotificationOutboxRepo{repo: repo, system: system}
+}
@@ -0,0 +1,25 @@
+// Package traced спаны OpenTelemetry на каждый вызов репозиториев, поверх любой реализации.
+package traced
+
+import (
+	"context"
+
+	"github.com/vitermakov/otusgo-final/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("github.com/vitermakov/otusgo-final/internal/repository")
+
+// start спан вызова name, system - тип хранилища (db.system).
+func start(ctx context.Context, system, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", system),
+	))
+}
+
+// end завершение спана с ошибкой вызова.
+func end(span trace.Span, err error) {
+	tracing.SpanError(span, err)
+	span.End()
+}
@@ -0,0 +1,24 @@
+package traced
+
+import (
+	"context"
+
+	"github.com/vitermakov/otusgo-final/internal/repository"
+)
+
+type Transactor struct {
+	tx     repository.Transactor
+	system string
+}
+
+// InTx спаны вызовов внутри транзакции - дочерние к спану InTx.
+func (t Transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, span := start(ctx, t.system, "Transactor.InTx")
+	err := t.tx.InTx(ctx, fn)
+	end(span, err)
+	return err
+}
+
+func NewTransactor(tx repository.Transactor, system string) repository.Transactor {
+	return &Transactor{tx: tx, system: system}
+}
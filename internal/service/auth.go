@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/pkg/servers"
+	"github.com/vitermakov/otusgo-final/pkg/utils/errx"
+)
+
+const (
+	apiKeyPrefix = "bfp_"
+	apiKeyBytes  = 32
+)
+
+// AuthSrv ключи из конфигурации проверяются раньше ключей хранилища и не могут быть отозваны через API.
+// Клиенты с сертификатом (mTLS) авторизуются по CN, если роль для него задана в конфигурации.
+type AuthSrv struct {
+	repo   repository.APIKey
+	static map[string]model.APIKey // по хэшу
+	certs  map[string]model.Role   // по CN
+}
+
+func (as AuthSrv) Authorize(ctx context.Context, token string) (*servers.AuthUser, error) {
+	if token == "" {
+		return nil, nil
+	}
+	hash := model.HashAPIKey(token)
+	key, ok := as.static[hash]
+	if !ok {
+		found, err := as.repo.GetByHash(ctx, hash)
+		if errors.Is(err, model.ErrAPIKeyNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, errx.FatalNew(err)
+		}
+		key = *found
+	}
+	return &servers.AuthUser{ID: key.ID.String(), Login: key.Name, Name: key.Name, Role: string(key.Role)}, nil
+}
+
+func (as AuthSrv) AuthorizeCert(_ context.Context, identity servers.ClientIdentity) (*servers.AuthUser, error) {
+	role, ok := as.certs[identity.CommonName]
+	if !ok || identity.CommonName == "" {
+		return nil, nil
+	}
+	return &servers.AuthUser{
+		ID: identity.Fingerprint, Login: identity.CommonName, Name: identity.CommonName, Role: string(role),
+	}, nil
+}
+
+// CreateKey ключ возвращается только здесь, в хранилище попадает его хэш.
+func (as AuthSrv) CreateKey(ctx context.Context, name string, role model.Role) (string, *model.APIKey, error) {
+	var errs errx.NamedErrors
+	if name == "" {
+		errs.Add(errx.NamedError{Field: "Name", Err: model.ErrAPIKeyNameEmpty})
+	}
+	if _, err := model.ParseRole(string(role)); err != nil {
+		errs.Add(errx.NamedError{Field: "Role", Err: err})
+	}
+	for _, key := range as.static {
+		if key.Name == name {
+			errs.Add(errx.NamedError{Field: "Name", Err: model.ErrAPIKeyDuplicate})
+		}
+	}
+	if !errs.Empty() {
+		return "", nil, errx.InvalidNew("api key is invalid", errs)
+	}
+	raw := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, errx.FatalNew(fmt.Errorf("generate api key: %w", err))
+	}
+	token := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	key, err := as.repo.Add(ctx, model.APIKeyInput{Name: name, Hash: model.HashAPIKey(token), Role: role})
+	if errors.Is(err, model.ErrAPIKeyDuplicate) {
+		return "", nil, errx.InvalidNew("api key is invalid", errx.NamedErrors{{Field: "Name", Err: err}})
+	}
+	if err != nil {
+		return "", nil, errx.FatalNew(err)
+	}
+	return token, key, nil
+}
+
+func (as AuthSrv) RevokeKey(ctx context.Context, name string) error {
+	err := as.repo.Delete(ctx, name)
+	if errors.Is(err, model.ErrAPIKeyNotFound) {
+		return errx.NotFoundNew(err, map[string]interface{}{"name": name})
+	}
+	if err != nil {
+		return errx.FatalNew(err)
+	}
+	return nil
+}
+
+// GetKeys ключи хранилища и конфигурации по имени.
+func (as AuthSrv) GetKeys(ctx context.Context) ([]model.APIKey, error) {
+	keys, err := as.repo.GetList(ctx)
+	if err != nil {
+		return nil, errx.FatalNew(err)
+	}
+	for _, key := range as.static {
+		keys = append(keys, key)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+	return keys, nil
+}
+
+func NewAuthSrv(repo repository.APIKey, static []model.APIKey, certs map[string]model.Role) Auth {
+	byHash := make(map[string]model.APIKey, len(static))
+	for _, key := range static {
+		byHash[key.Hash] = key
+	}
+	return &AuthSrv{repo: repo, static: byHash, certs: certs}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository/memory"
+	"github.com/vitermakov/otusgo-final/pkg/servers"
+	"github.com/vitermakov/otusgo-final/pkg/utils/errx"
+)
+
+func TestAuthSrv(t *testing.T) {
+	ctx := context.Background()
+	auth := NewAuthSrv(memory.NewAPIKeyRepo(), []model.APIKey{
+		{Name: "static", Hash: model.HashAPIKey("static-key"), Role: model.RoleAdmin},
+	}, map[string]model.Role{"php-login.internal": model.RoleChecker})
+
+	user, err := auth.Authorize(ctx, "static-key")
+	require.NoError(t, err)
+	require.Equal(t, "static", user.Login)
+	require.Equal(t, string(model.RoleAdmin), user.Role)
+
+	user, err = auth.AuthorizeCert(ctx, servers.ClientIdentity{CommonName: "php-login.internal"})
+	require.NoError(t, err)
+	require.Equal(t, string(model.RoleChecker), user.Role)
+	user, err = auth.AuthorizeCert(ctx, servers.ClientIdentity{CommonName: "unknown.internal"})
+	require.NoError(t, err)
+	require.Nil(t, user)
+
+	user, err = auth.Authorize(ctx, "unknown-key")
+	require.NoError(t, err)
+	require.Nil(t, user)
+	user, err = auth.Authorize(ctx, "")
+	require.NoError(t, err)
+	require.Nil(t, user)
+
+	token, key, err := auth.CreateKey(ctx, "ops", model.RoleOperator)
+	require.NoError(t, err)
+	require.NotContains(t, key.Hash, token)
+	user, err = auth.Authorize(ctx, token)
+	require.NoError(t, err)
+	require.Equal(t, "ops", user.Login)
+	require.Equal(t, string(model.RoleOperator), user.Role)
+
+	// имя ключа из конфигурации занято.
+	_, _, err = auth.CreateKey(ctx, "static", model.RoleChecker)
+	var invalid errx.Invalid
+	require.ErrorAs(t, err, &invalid)
+	_, _, err = auth.CreateKey(ctx, "", "root")
+	require.ErrorAs(t, err, &invalid)
+	require.Len(t, invalid.Errors(), 2)
+
+	keys, err := auth.GetKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	require.NoError(t, auth.RevokeKey(ctx, "ops"))
+	user, err = auth.Authorize(ctx, token)
+	require.NoError(t, err)
+	require.Nil(t, user)
+	var notFound errx.NotFound
+	require.ErrorAs(t, auth.RevokeKey(ctx, "ops"), &notFound)
+}
+
+func TestRoles(t *testing.T) {
+	require.True(t, model.RoleAdmin.Allows(model.RoleChecker))
+	require.True(t, model.RoleOperator.Allows(model.RoleOperator))
+	require.False(t, model.RoleChecker.Allows(model.RoleOperator))
+	require.False(t, model.Role("root").Allows(model.RoleChecker))
+	require.Equal(t, []string{"operator", "admin"}, model.RolesFrom(model.RoleOperator))
+}
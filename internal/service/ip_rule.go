@@ -3,15 +3,24 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 
 	"github.com/vitermakov/otusgo-final/internal/model"
 	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/pkg/tracing"
 	"github.com/vitermakov/otusgo-final/pkg/utils/errx"
+	"github.com/vitermakov/otusgo-final/pkg/utils/netlist"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type IPRuleSrv struct {
-	repo repository.IPRule
+	repo       repository.IPRule
+	events     repository.IPRuleEvent
+	tx         repository.Transactor
+	precedence model.Precedence
+	onConflict model.ConflictPolicy
 }
 
 func (irs IPRuleSrv) validateAdd(ctx context.Context, input model.IPRuleInput) error {
@@ -31,54 +40,214 @@ func (irs IPRuleSrv) validateAdd(ctx context.Context, input model.IPRuleInput) e
 	return nil
 }
 
-func (irs IPRuleSrv) Add(ctx context.Context, input model.IPRuleInput) (*model.IPRule, error) {
+// Add добавление правила с проверкой пересечений с существующими, policy "" - политика по умолчанию.
+// Если задан input.Range, добавляется набор сетей диапазона (см. addRange).
+// При ConflictMerge удаление покрываемых правил и добавление нового выполняются в одной транзакции.
+func (irs IPRuleSrv) Add(
+	ctx context.Context, input model.IPRuleInput, policy model.ConflictPolicy,
+) (model.IPRuleAddResult, error) {
+	if input.Range != "" {
+		return irs.addRange(ctx, input, policy)
+	}
+	var result model.IPRuleAddResult
 	if err := irs.validateAdd(ctx, input); err != nil {
 		errs := errx.NamedErrors{}
 		if errors.As(err, &errs) {
-			return nil, errx.InvalidNew("неверные параметры", errs)
+			return result, errx.InvalidNew("неверные параметры", errs)
 		}
-		return nil, err
+		return result, err
 	}
-	ipRule, err := irs.repo.Add(ctx, input)
+	overlaps, err := irs.repo.GetList(ctx, model.IPRuleSearch{IPNet: &input.IPNet, IPNetOverlap: true})
 	if err != nil {
-		return nil, errx.FatalNew(err)
+		return result, errx.FatalNew(err)
+	}
+	result.Conflicts = findConflicts(input, overlaps)
+
+	actor := actorOrDefault(ctx, input.CreatedBy)
+	if policy == "" {
+		policy = irs.onConflict
+	}
+	switch policy {
+	case model.ConflictReject:
+		if len(result.Conflicts) > 0 {
+			return result, errx.LogicNew(conflictError(result.Conflicts), model.ErrIPRuleConflictCode)
+		}
+	case model.ConflictMerge:
+		covering, covered := mergeable(input, result.Conflicts)
+		if covering != nil {
+			result.Rule, result.Skipped = covering, true
+			return result, nil
+		}
+		result.Merged = covered
+	case model.ConflictWarn:
 	}
-	return ipRule, nil
+
+	err = irs.inTx(ctx, func(ctx context.Context) error {
+		for _, rule := range result.Merged {
+			if err := irs.delete(ctx, actor, rule); err != nil {
+				return err
+			}
+		}
+		rule, err := irs.repo.Add(ctx, input)
+		if err != nil {
+			return storeError(err)
+		}
+		result.Rule = rule
+		return irs.recordAdded(ctx, actor, *rule)
+	})
+	if err != nil {
+		result.Merged = nil
+		return result, err
+	}
+	return result, nil
 }
 
 func (irs IPRuleSrv) Delete(ctx context.Context, rule model.IPRule) error {
-	if err := irs.repo.Delete(ctx, model.IPRuleInput{Type: rule.Type, IPNet: rule.IPNet}); err != nil {
+	return irs.inTx(ctx, func(ctx context.Context) error {
+		return irs.delete(ctx, actorOrDefault(ctx, ""), rule)
+	})
+}
+
+func (irs IPRuleSrv) delete(ctx context.Context, actor string, rule model.IPRule) error {
+	input := model.IPRuleInput{Type: rule.Type, IPNet: rule.IPNet, Source: rule.Source, Feed: rule.Feed}
+	if err := irs.repo.Delete(ctx, input); err != nil {
 		// неустранимая пользователем ошибка.
 		return errx.FatalNew(err)
 	}
+	return irs.record(ctx, model.NewIPRuleEvent(model.EventActionDelete, actor, &rule, nil))
+}
+
+// Update изменение списка и/или метаданных правила одной операцией хранилища, без промежутка,
+// когда сеть не входит ни в один список. Правило фида, перенесенное в другой список, становится
+// ручным, чтобы синхронизация фида его больше не трогала.
+func (irs IPRuleSrv) Update(ctx context.Context, update model.IPRuleUpdate) (*model.IPRule, error) {
+	if err := update.Validate(); err != nil {
+		errs := errx.NamedErrors{}
+		if errors.As(err, &errs) {
+			return nil, errx.InvalidNew("неверные параметры", errs)
+		}
+		return nil, err
+	}
+	if update.Type != nil {
+		source, feed := model.RuleSourceManual, ""
+		update.Source, update.Feed = &source, &feed
+	}
+	var updated *model.IPRule
+	err := irs.inTx(ctx, func(ctx context.Context) error {
+		before, after, err := irs.repo.Update(ctx, update)
+		if errors.Is(err, model.ErrRuleNotFound) {
+			return errx.NotFoundNew(err, map[string]interface{}{"ip_net": update.IPNet.String()})
+		}
+		if err != nil {
+			return storeError(err)
+		}
+		updated = after
+		return irs.record(ctx, model.NewIPRuleEvent(model.EventActionUpdate, actorOrDefault(ctx, ""), before, after))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// GetHistory история изменений правил, от новых записей к старым.
+func (irs IPRuleSrv) GetHistory(ctx context.Context, search model.IPRuleEventSearch) ([]model.IPRuleEvent, error) {
+	events, err := irs.events.GetList(ctx, search)
+	if err != nil {
+		return nil, errx.FatalNew(err)
+	}
+	return events, nil
+}
+
+func (irs IPRuleSrv) recordAdded(ctx context.Context, actor string, rules ...model.IPRule) error {
+	for i := range rules {
+		if err := irs.record(ctx, model.NewIPRuleEvent(model.EventActionAdd, actor, nil, &rules[i])); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// GetRuleTypeForIP по условию, если ip подходит к white списку, то разрешаем без проверки ограничения black.
-func (irs IPRuleSrv) GetRuleTypeForIP(ctx context.Context, ip net.IP) (model.RuleType, error) {
-	rules, err := irs.repo.GetList(ctx, model.IPRuleSearch{
-		IPNet: &net.IPNet{
-			IP:   ip,
-			Mask: []byte{255, 255, 255, 255},
-		},
-		IPNetExact: false,
+// record запись в журнал изменений. Вызывается в транзакции изменения правила (см. inTx),
+// при ошибке журнала изменение откатывается.
+func (irs IPRuleSrv) record(ctx context.Context, event model.IPRuleEvent) error {
+	if err := irs.events.Add(ctx, event); err != nil {
+		return errx.FatalNew(fmt.Errorf("audit event not saved: %w", err))
+	}
+	return nil
+}
+
+// inTx изменение правил и его запись в журнал в одной транзакции хранилища. Ошибки fn
+// возвращаются как есть, ошибки открытия и фиксации транзакции - неустранимые.
+func (irs IPRuleSrv) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	var fnErr error
+	err := irs.tx.InTx(ctx, func(ctx context.Context) error {
+		fnErr = fn(ctx)
+		return fnErr
 	})
+	if err != nil && fnErr == nil {
+		return errx.FatalNew(err)
+	}
+	return err
+}
+
+// actorOrDefault автор изменения: явно указанный или автор запроса из контекста.
+func actorOrDefault(ctx context.Context, actor string) string {
+	if actor != "" {
+		return actor
+	}
+	return model.ActorFromContext(ctx)
+}
+
+// GetRuleTypeForIP тип правила для ip; если ip подходит под несколько правил, решение принимается
+// согласно настроенному порядку применения (по умолчанию white список важнее black).
+func (irs IPRuleSrv) GetRuleTypeForIP(ctx context.Context, ip net.IP) (model.RuleType, error) {
+	ctx, span := tracer.Start(ctx, "IPRule.GetRuleTypeForIP")
+	defer span.End()
+
+	host := netlist.HostNet(ip)
+	rules, err := irs.repo.GetList(ctx, model.IPRuleSearch{IPNet: &host, IPNetExact: false})
 	if err != nil {
+		tracing.SpanError(span, err)
 		return model.RuleTypeNone, errx.FatalNew(err)
 	}
-	var deny bool
-	for _, rule := range rules {
-		if rule.Type == model.RuleTypeAllow {
-			return model.RuleTypeAllow, nil
-		}
-		if rule.Type == model.RuleTypeDeny {
-			deny = true
-		}
+	ruleType := resolveRuleType(irs.precedence, rules)
+	span.SetAttributes(
+		attribute.Int("brutefp.rules.matched", len(rules)),
+		attribute.String("brutefp.rule.type", ruleType.String()),
+	)
+	return ruleType, nil
+}
+
+// GetRuleTypesForIPs типы правил для набора адресов одним запросом к хранилищу, в порядке ips.
+func (irs IPRuleSrv) GetRuleTypesForIPs(ctx context.Context, ips []net.IP) ([]model.RuleType, error) {
+	ctx, span := tracer.Start(ctx, "IPRule.GetRuleTypesForIPs", trace.WithAttributes(
+		attribute.Int("brutefp.ips", len(ips)),
+	))
+	defer span.End()
+
+	ruleTypes := make([]model.RuleType, len(ips))
+	if len(ips) == 0 {
+		return ruleTypes, nil
 	}
-	if deny {
-		return model.RuleTypeDeny, nil
+	rules, err := irs.repo.GetList(ctx, model.IPRuleSearch{IPs: ips})
+	if err != nil {
+		tracing.SpanError(span, err)
+		return nil, errx.FatalNew(err)
 	}
-	return model.RuleTypeNone, nil
+	matched := make([]model.IPRule, 0, len(rules))
+	for i, ip := range ips {
+		host := netlist.HostNet(ip)
+		matched = matched[:0]
+		for _, rule := range rules {
+			if netlist.Contains(rule.IPNet, host) {
+				matched = append(matched, rule)
+			}
+		}
+		ruleTypes[i] = resolveRuleType(irs.precedence, matched)
+	}
+	span.SetAttributes(attribute.Int("brutefp.rules.matched", len(rules)))
+	return ruleTypes, nil
 }
 
 func (irs IPRuleSrv) GetByIPNet(ctx context.Context, typ model.RuleType, ipNet net.IPNet) (*model.IPRule, error) {
@@ -95,6 +264,145 @@ func (irs IPRuleSrv) GetByIPNet(ctx context.Context, typ model.RuleType, ipNet n
 	return nil, err
 }
 
+func (irs IPRuleSrv) GetList(ctx context.Context, search model.IPRuleSearch) ([]model.IPRule, error) {
+	rules, err := irs.repo.GetList(ctx, search)
+	if err != nil {
+		return nil, errx.FatalNew(err)
+	}
+	return rules, nil
+}
+
+func (irs IPRuleSrv) Count(ctx context.Context, search model.IPRuleSearch) (map[model.RuleType]int, error) {
+	counts, err := irs.repo.Count(ctx, search)
+	if err != nil {
+		return nil, errx.FatalNew(err)
+	}
+	return counts, nil
+}
+
+// Import массовое добавление правил. Сети, уже присутствующие в том же списке, пропускаются,
+// присутствующие в другом списке или повторяющиеся в наборе - считаются ошибкой.
+func (irs IPRuleSrv) Import(ctx context.Context, imp model.IPRuleImport) (model.IPRuleImportResult, error) {
+	var result model.IPRuleImportResult
+	if len(imp.Rules) == 0 {
+		return result, errx.LogicNew(model.ErrIPRuleImportNone, model.ErrIPRuleImportNoneCode)
+	}
+	existing, err := irs.repo.GetList(ctx, model.IPRuleSearch{})
+	if err != nil {
+		return result, errx.FatalNew(err)
+	}
+	known := make(map[string]model.RuleType, len(existing))
+	for _, rule := range existing {
+		known[rule.IPNet.String()] = rule.Type
+	}
+
+	batch := make(map[string]struct{}, len(imp.Rules))
+	result.Items = make([]model.IPRuleImportItem, 0, len(imp.Rules))
+	for _, input := range imp.Rules {
+		if input.Source == "" {
+			input.Source = model.RuleSourceImport
+		}
+		item := model.IPRuleImportItem{Input: input, Action: model.ImportActionAdd}
+		key := input.IPNet.String()
+		typ, exists := known[key]
+		_, inBatch := batch[key]
+		invalid := input.Validate()
+		switch {
+		case invalid != nil:
+			item.Err = invalid
+		case inBatch:
+			item.Err = model.ErrIPRuleNetInBatch
+		case exists && typ == input.Type:
+			item.Action = model.ImportActionExists
+		case exists:
+			item.Err = model.ErrIPRuleNetDuplicate
+		}
+		batch[key] = struct{}{}
+		if item.Err != nil {
+			item.Action = model.ImportActionError
+		}
+		result.Items = append(result.Items, item)
+	}
+	result.Count()
+
+	if imp.DryRun || (imp.Atomic && result.Failed > 0) {
+		return result, nil
+	}
+	if imp.Atomic {
+		return irs.importAtomic(ctx, result)
+	}
+	for i, item := range result.Items {
+		if item.Action != model.ImportActionAdd {
+			continue
+		}
+		var addErr error
+		err := irs.inTx(ctx, func(ctx context.Context) error {
+			rule, err := irs.repo.Add(ctx, item.Input)
+			if err != nil {
+				addErr = err
+				return err
+			}
+			return irs.recordAdded(ctx, actorOrDefault(ctx, item.Input.CreatedBy), *rule)
+		})
+		if errors.Is(addErr, model.ErrRuleDuplicate) {
+			addErr = model.ErrIPRuleNetDuplicate
+		}
+		if addErr != nil {
+			result.Items[i].Action = model.ImportActionError
+			result.Items[i].Err = addErr
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+	result.Applied = true
+	result.Count()
+	return result, nil
+}
+
+func (irs IPRuleSrv) importAtomic(
+	ctx context.Context, result model.IPRuleImportResult,
+) (model.IPRuleImportResult, error) {
+	inputs := make([]model.IPRuleInput, 0, result.Added)
+	for _, item := range result.Items {
+		if item.Action == model.ImportActionAdd {
+			inputs = append(inputs, item.Input)
+		}
+	}
+	if len(inputs) > 0 {
+		err := irs.inTx(ctx, func(ctx context.Context) error {
+			rules, err := irs.repo.AddBatch(ctx, inputs)
+			if err != nil {
+				return storeError(err)
+			}
+			for _, rule := range rules {
+				if err := irs.recordAdded(ctx, actorOrDefault(ctx, rule.CreatedBy), rule); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// storeError ошибка изменения хранилища. Дубликат возможен при параллельном добавлении той же сети,
+// проверка в validateAdd его не исключает, - это логическая ошибка, остальное фатально.
+func storeError(err error) error {
+	if errors.Is(err, model.ErrRuleDuplicate) {
+		return errx.LogicNew(model.ErrIPRuleNetDuplicate, model.ErrIPRuleNetDuplicateCode)
+	}
+	if errors.Is(err, model.ErrRuleAmbiguous) {
+		return errx.LogicNew(model.ErrIPRuleNetAmbiguous, model.ErrIPRuleNetAmbiguousCode)
+	}
+	return errx.FatalNew(err)
+}
+
 func (irs IPRuleSrv) getOne(ctx context.Context, search model.IPRuleSearch) (*model.IPRule, error) {
 	rules, err := irs.repo.GetList(ctx, search)
 	if err != nil {
@@ -106,8 +414,15 @@ func (irs IPRuleSrv) getOne(ctx context.Context, search model.IPRuleSearch) (*mo
 	return &rules[0], nil
 }
 
-func NewIPRuleSrv(repo repository.IPRule) IPRule {
+func NewIPRuleSrv(
+	repo repository.IPRule, events repository.IPRuleEvent, tx repository.Transactor,
+	precedence model.Precedence, onConflict model.ConflictPolicy,
+) IPRule {
 	return &IPRuleSrv{
-		repo: repo,
+		repo:       repo,
+		events:     events,
+		tx:         tx,
+		precedence: precedence,
+		onConflict: onConflict,
 	}
 }
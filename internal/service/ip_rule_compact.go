@@ -0,0 +1,167 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/utils/errx"
+	"github.com/vitermakov/otusgo-final/pkg/utils/netlist"
+)
+
+// compactGroup правила, сжимаемые вместе: один список, приоритет и семейство адресов.
+type compactGroup struct {
+	typ      model.RuleType
+	priority int
+	bits     int
+}
+
+// Compact заменяет смежные и вложенные сети каждого списка минимальным покрытием.
+// Не трогает правила фидов (их переписала бы следующая синхронизация) и диапазонов (удаляются
+// по исходному диапазону). При порядках most-specific и priority не трогает и правила,
+// пересекающиеся с правилами другого списка, - иначе сжатие изменило бы результат проверки.
+func (irs IPRuleSrv) Compact(ctx context.Context, req model.IPRuleCompact) (model.IPRuleCompactResult, error) {
+	var result model.IPRuleCompactResult
+	rules, err := irs.repo.GetList(ctx, model.IPRuleSearch{})
+	if err != nil {
+		return result, errx.FatalNew(err)
+	}
+
+	actor := actorOrDefault(ctx, req.Actor)
+	// сети, уже занятые правилами вне группы (фиды, другой приоритет): такое покрытие не добавить.
+	taken := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		taken[rule.Type.String()+" "+rule.IPNet.String()] = struct{}{}
+	}
+	groups := make(map[compactGroup][]model.IPRule)
+	for _, rule := range irs.compactable(req.Type, rules) {
+		_, bits := rule.IPNet.Mask.Size()
+		key := compactGroup{typ: rule.Type, priority: rule.Priority, bits: bits}
+		groups[key] = append(groups[key], rule)
+	}
+	for key, group := range groups {
+		existing := make(map[string]struct{}, len(group))
+		nets := make([]net.IPNet, 0, len(group))
+		for _, rule := range group {
+			existing[rule.IPNet.String()] = struct{}{}
+			nets = append(nets, rule.IPNet)
+		}
+		cover := netlist.Compact(nets)
+		kept := make(map[string]struct{}, len(cover))
+		for _, ipNet := range cover {
+			kept[ipNet.String()] = struct{}{}
+			if _, ok := existing[ipNet.String()]; ok {
+				continue
+			}
+			if _, ok := taken[key.typ.String()+" "+ipNet.String()]; ok {
+				keepCovered(kept, group, ipNet)
+				continue
+			}
+			result.Added = append(result.Added, model.IPRule{
+				Type:      key.typ,
+				IPNet:     ipNet,
+				Source:    model.RuleSourceManual,
+				Comment:   "compacted",
+				CreatedBy: actor,
+				Priority:  key.priority,
+			})
+		}
+		for _, rule := range group {
+			if _, ok := kept[rule.IPNet.String()]; !ok {
+				result.Removed = append(result.Removed, rule)
+			}
+		}
+	}
+	sortRules(result.Removed)
+	sortRules(result.Added)
+	if req.DryRun || (len(result.Removed) == 0 && len(result.Added) == 0) {
+		return result, nil
+	}
+
+	remove := make([]uuid.UUID, 0, len(result.Removed))
+	for _, rule := range result.Removed {
+		remove = append(remove, rule.ID)
+	}
+	inputs := make([]model.IPRuleInput, 0, len(result.Added))
+	for _, rule := range result.Added {
+		inputs = append(inputs, model.IPRuleInput{
+			Type:      rule.Type,
+			IPNet:     rule.IPNet,
+			Source:    rule.Source,
+			Comment:   rule.Comment,
+			CreatedBy: rule.CreatedBy,
+			Priority:  rule.Priority,
+		})
+	}
+	// покрытие заменяет уже заблокированные сети, новой блокировки нет.
+	err = irs.inTx(model.ContextWithoutBanNotify(ctx), func(ctx context.Context) error {
+		added, err := irs.repo.Replace(ctx, remove, inputs)
+		if err != nil {
+			return errx.FatalNew(fmt.Errorf("compaction not applied: %w", err))
+		}
+		for i := range result.Removed {
+			event := model.NewIPRuleEvent(model.EventActionDelete, actor, &result.Removed[i], nil)
+			if err := irs.record(ctx, event); err != nil {
+				return err
+			}
+		}
+		result.Added = added
+		return irs.recordAdded(ctx, actor, added...)
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// compactable правила указанного списка (RuleTypeNone - обоих), которые можно сжимать.
+func (irs IPRuleSrv) compactable(typ model.RuleType, rules []model.IPRule) []model.IPRule {
+	strict := irs.precedence == model.PrecedenceMostSpecific || irs.precedence == model.PrecedencePriority
+	result := make([]model.IPRule, 0, len(rules))
+	for _, rule := range rules {
+		if (typ != model.RuleTypeNone && rule.Type != typ) || rule.Source == model.RuleSourceFeed || rule.Range != "" {
+			continue
+		}
+		if strict && overlapsOther(rule, rules) {
+			continue
+		}
+		result = append(result, rule)
+	}
+	return result
+}
+
+// keepCovered оставляет правила группы внутри сети покрытия без изменений.
+func keepCovered(kept map[string]struct{}, group []model.IPRule, cover net.IPNet) {
+	for _, rule := range group {
+		if netlist.Contains(cover, rule.IPNet) {
+			kept[rule.IPNet.String()] = struct{}{}
+		}
+	}
+}
+
+func overlapsOther(rule model.IPRule, rules []model.IPRule) bool {
+	for _, other := range rules {
+		if other.Type != rule.Type && netlist.Overlaps(rule.IPNet, other.IPNet) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortRules по списку, затем по адресу сети.
+func sortRules(rules []model.IPRule) {
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Type != rules[j].Type {
+			return rules[i].Type < rules[j].Type
+		}
+		if len(rules[i].IPNet.IP) != len(rules[j].IPNet.IP) {
+			return len(rules[i].IPNet.IP) < len(rules[j].IPNet.IP)
+		}
+		return bytes.Compare(rules[i].IPNet.IP, rules[j].IPNet.IP) < 0
+	})
+}
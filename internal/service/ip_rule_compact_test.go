@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository/memory"
+)
+
+func TestCompact(t *testing.T) {
+	ctx := context.Background()
+	newSrv := func(t *testing.T, precedence model.Precedence, inputs ...model.IPRuleInput) IPRule {
+		t.Helper()
+		srv := NewIPRuleSrv(
+			memory.NewIPRuleRepo(), memory.NewIPRuleEventRepo(), memory.NewTransactor(), precedence, model.ConflictWarn,
+		)
+		for _, in := range inputs {
+			_, err := srv.Add(ctx, in, "")
+			require.NoError(t, err)
+		}
+		return srv
+	}
+	input := func(typ model.RuleType, network string, source model.RuleSource) model.IPRuleInput {
+		_, ipNet, err := net.ParseCIDR(network)
+		require.NoError(t, err)
+		return model.IPRuleInput{Type: typ, IPNet: *ipNet, Source: source}
+	}
+	nets := func(rules []model.IPRule) []string {
+		result := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			result = append(result, rule.IPNet.String())
+		}
+		return result
+	}
+	deny := model.RuleTypeDeny
+
+	t.Run("dry run and apply", func(t *testing.T) {
+		srv := newSrv(t, model.PrecedenceAllowWins,
+			input(deny, "10.0.0.0/32", ""), input(deny, "10.0.0.1/32", ""),
+			input(deny, "10.0.0.2/31", ""), input(deny, "10.1.0.0/16", ""),
+			input(deny, "10.1.2.0/24", model.RuleSourceAutoBan),
+			input(deny, "5.0.0.0/8", model.RuleSourceFeed),
+			input(deny, "5.1.0.0/16", ""),
+			input(model.RuleTypeAllow, "10.0.0.4/32", ""),
+		)
+		res, err := srv.Compact(ctx, model.IPRuleCompact{Type: deny, DryRun: true})
+		require.NoError(t, err)
+		require.False(t, res.Applied)
+		require.Equal(t, []string{"10.0.0.0/32", "10.0.0.1/32", "10.0.0.2/31", "10.1.2.0/24"}, nets(res.Removed))
+		require.Equal(t, []string{"10.0.0.0/30"}, nets(res.Added))
+		all, err := srv.GetList(ctx, model.IPRuleSearch{})
+		require.NoError(t, err)
+		require.Len(t, all, 8)
+
+		res, err = srv.Compact(ctx, model.IPRuleCompact{Type: deny})
+		require.NoError(t, err)
+		require.True(t, res.Applied)
+		all, err = srv.GetList(ctx, model.IPRuleSearch{Type: &deny})
+		require.NoError(t, err)
+		// правила фида не сжимаются, поэтому 5.1.0.0/16 остается.
+		require.ElementsMatch(t, []string{"10.0.0.0/30", "10.1.0.0/16", "5.0.0.0/8", "5.1.0.0/16"}, nets(all))
+
+		history, err := srv.GetHistory(ctx, model.IPRuleEventSearch{Limit: 5})
+		require.NoError(t, err)
+		require.Equal(t, model.EventActionAdd, history[0].Action)
+		require.Equal(t, model.EventActionDelete, history[1].Action)
+
+		res, err = srv.Compact(ctx, model.IPRuleCompact{})
+		require.NoError(t, err)
+		require.Empty(t, res.Removed)
+		require.Empty(t, res.Added)
+	})
+
+	t.Run("most specific keeps contradicted rules", func(t *testing.T) {
+		srv := newSrv(t, model.PrecedenceMostSpecific,
+			input(deny, "10.0.0.0/8", ""),
+			input(model.RuleTypeAllow, "10.1.0.0/16", ""),
+			input(deny, "10.1.2.0/24", ""),
+			input(deny, "11.0.0.0/8", ""),
+			input(deny, "11.1.0.0/16", ""),
+		)
+		res, err := srv.Compact(ctx, model.IPRuleCompact{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"11.1.0.0/16"}, nets(res.Removed))
+		require.Empty(t, res.Added)
+
+		typ, err := srv.GetRuleTypeForIP(ctx, net.ParseIP("10.1.2.3"))
+		require.NoError(t, err)
+		require.Equal(t, deny, typ)
+	})
+
+	t.Run("cover taken by feed rule", func(t *testing.T) {
+		srv := newSrv(t, model.PrecedenceAllowWins,
+			input(deny, "10.0.0.0/30", model.RuleSourceFeed),
+			input(deny, "10.0.0.0/31", ""), input(deny, "10.0.0.2/31", ""),
+			input(deny, "10.0.1.0/32", ""), input(deny, "10.0.1.1/32", ""),
+		)
+		res, err := srv.Compact(ctx, model.IPRuleCompact{})
+		require.NoError(t, err)
+		require.True(t, res.Applied)
+		require.Equal(t, []string{"10.0.1.0/32", "10.0.1.1/32"}, nets(res.Removed))
+		require.Equal(t, []string{"10.0.1.0/31"}, nets(res.Added))
+	})
+}
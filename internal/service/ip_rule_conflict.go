@@ -0,0 +1,90 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/utils/netlist"
+)
+
+// findConflicts классифицирует пересекающиеся с input правила, от более широких сетей к более узким.
+// Точное совпадение сети сюда не попадает: оно отклоняется как дубликат еще при проверке.
+func findConflicts(input model.IPRuleInput, overlaps []model.IPRule) []model.RuleConflict {
+	conflicts := make([]model.RuleConflict, 0, len(overlaps))
+	for _, rule := range overlaps {
+		if rule.IPNet.String() == input.IPNet.String() {
+			continue
+		}
+		conflict := model.RuleConflict{
+			Rule:        rule,
+			Relation:    model.ConflictCovered,
+			Contradicts: rule.Type != input.Type,
+		}
+		if netlist.Contains(rule.IPNet, input.IPNet) {
+			conflict.Relation = model.ConflictCovering
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		iOnes, _ := conflicts[i].Rule.IPNet.Mask.Size()
+		jOnes, _ := conflicts[j].Rule.IPNet.Mask.Size()
+		return iOnes < jOnes
+	})
+	return conflicts
+}
+
+// mergeable избыточные относительно input правила того же списка и приоритета: покрывающее input
+// и покрываемые им. Правило не считается избыточным, если между ним и input лежит правило другого
+// списка - иначе объединение изменит результат проверки при порядке most-specific.
+// Правила фидов и диапазонов не объединяются: фид вернет удаленное правило при следующей
+// синхронизации, а из диапазона выпала бы часть сетей и DeleteRange удалил бы его не целиком.
+func mergeable(input model.IPRuleInput, conflicts []model.RuleConflict) (*model.IPRule, []model.IPRule) {
+	var (
+		covering *model.IPRule
+		covered  []model.IPRule
+	)
+	for i, conflict := range conflicts {
+		if conflict.Contradicts || conflict.Rule.Priority != input.Priority || !canMerge(conflict.Rule) {
+			continue
+		}
+		outer, inner := input.IPNet, conflict.Rule.IPNet
+		if conflict.Relation == model.ConflictCovering {
+			outer, inner = inner, outer
+		}
+		if contradictedBetween(outer, inner, conflicts) {
+			continue
+		}
+		if conflict.Relation == model.ConflictCovering {
+			// conflicts отсортированы от широких сетей к узким, берем ближайшее покрывающее.
+			covering = &conflicts[i].Rule
+			continue
+		}
+		covered = append(covered, conflict.Rule)
+	}
+	return covering, covered
+}
+
+func canMerge(rule model.IPRule) bool {
+	return rule.Source != model.RuleSourceFeed && rule.Range == ""
+}
+
+func contradictedBetween(outer, inner net.IPNet, conflicts []model.RuleConflict) bool {
+	for _, conflict := range conflicts {
+		if conflict.Contradicts && netlist.Contains(outer, conflict.Rule.IPNet) &&
+			netlist.Contains(conflict.Rule.IPNet, inner) {
+			return true
+		}
+	}
+	return false
+}
+
+func conflictError(conflicts []model.RuleConflict) error {
+	descs := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		descs = append(descs, conflict.String())
+	}
+	return fmt.Errorf("%w: %s", model.ErrIPRuleConflict, strings.Join(descs, "; "))
+}
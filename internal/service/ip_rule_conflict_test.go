@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository/memory"
+)
+
+func TestAddConflicts(t *testing.T) {
+	ctx := context.Background()
+	input := func(typ model.RuleType, network string) model.IPRuleInput {
+		_, ipNet, err := net.ParseCIDR(network)
+		require.NoError(t, err)
+		return model.IPRuleInput{Type: typ, IPNet: *ipNet}
+	}
+	newSrv := func(t *testing.T, inputs ...model.IPRuleInput) IPRule {
+		t.Helper()
+		srv := NewIPRuleSrv(
+			memory.NewIPRuleRepo(), memory.NewIPRuleEventRepo(), memory.NewTransactor(),
+			model.PrecedenceMostSpecific, model.ConflictWarn,
+		)
+		for _, in := range inputs {
+			_, err := srv.Add(ctx, in, "")
+			require.NoError(t, err)
+		}
+		return srv
+	}
+
+	t.Run("warn by default", func(t *testing.T) {
+		srv := newSrv(t, input(model.RuleTypeDeny, "10.0.0.0/8"), input(model.RuleTypeAllow, "10.1.2.0/24"))
+		res, err := srv.Add(ctx, input(model.RuleTypeDeny, "10.1.0.0/16"), "")
+		require.NoError(t, err)
+		require.NotNil(t, res.Rule)
+		require.Len(t, res.Conflicts, 2)
+		require.Equal(t, "covered by deny rule 10.0.0.0/8", res.Conflicts[0].String())
+		require.Equal(t, "covers allow rule 10.1.2.0/24 (contradicts)", res.Conflicts[1].String())
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		srv := newSrv(t, input(model.RuleTypeDeny, "10.0.0.0/8"))
+		_, err := srv.Add(ctx, input(model.RuleTypeDeny, "10.1.0.0/16"), model.ConflictReject)
+		require.ErrorContains(t, err, model.ErrIPRuleConflict.Error())
+
+		_, err = srv.Add(ctx, input(model.RuleTypeDeny, "11.0.0.0/8"), model.ConflictReject)
+		require.NoError(t, err)
+	})
+
+	t.Run("merge keeps rules separated by contradiction", func(t *testing.T) {
+		srv := newSrv(t,
+			input(model.RuleTypeAllow, "10.1.0.0/16"),
+			input(model.RuleTypeDeny, "10.1.2.0/24"),
+			input(model.RuleTypeDeny, "10.200.0.0/16"),
+		)
+		res, err := srv.Add(ctx, input(model.RuleTypeDeny, "10.0.0.0/8"), model.ConflictMerge)
+		require.NoError(t, err)
+		require.False(t, res.Skipped)
+		// 10.1.2.0/24 лежит внутри allow 10.1.0.0/16 и при most-specific по-прежнему нужен.
+		require.Len(t, res.Merged, 1)
+		require.Equal(t, "10.200.0.0/16", res.Merged[0].IPNet.String())
+
+		typ, err := srv.GetRuleTypeForIP(ctx, net.ParseIP("10.1.2.3"))
+		require.NoError(t, err)
+		require.Equal(t, model.RuleTypeDeny, typ)
+		typ, err = srv.GetRuleTypeForIP(ctx, net.ParseIP("10.1.3.3"))
+		require.NoError(t, err)
+		require.Equal(t, model.RuleTypeAllow, typ)
+
+		res, err = srv.Add(ctx, input(model.RuleTypeDeny, "10.1.2.128/25"), model.ConflictMerge)
+		require.NoError(t, err)
+		require.True(t, res.Skipped)
+		require.Equal(t, "10.1.2.0/24", res.Rule.IPNet.String())
+	})
+
+	t.Run("merge keeps feed and range rules", func(t *testing.T) {
+		repo := memory.NewIPRuleRepo()
+		srv := NewIPRuleSrv(
+			repo, memory.NewIPRuleEventRepo(), memory.NewTransactor(),
+			model.PrecedenceMostSpecific, model.ConflictWarn,
+		)
+		feedRule := input(model.RuleTypeDeny, "10.1.0.0/16")
+		feedRule.Source, feedRule.Feed = model.RuleSourceFeed, "drop"
+		_, err := repo.Add(ctx, feedRule)
+		require.NoError(t, err)
+		_, err = srv.Add(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, Range: "10.2.0.0-10.2.2.255"}, "")
+		require.NoError(t, err)
+
+		// покрывающее правило фида не делает новое правило избыточным.
+		res, err := srv.Add(ctx, input(model.RuleTypeDeny, "10.1.2.0/24"), model.ConflictMerge)
+		require.NoError(t, err)
+		require.False(t, res.Skipped)
+
+		// из покрываемых удаляется только ручное правило.
+		res, err = srv.Add(ctx, input(model.RuleTypeDeny, "10.0.0.0/8"), model.ConflictMerge)
+		require.NoError(t, err)
+		require.False(t, res.Skipped)
+		require.Len(t, res.Merged, 1)
+		require.Equal(t, "10.1.2.0/24", res.Merged[0].IPNet.String())
+		rules, err := srv.GetList(ctx, model.IPRuleSearch{})
+		require.NoError(t, err)
+		// /8, правило фида и две сети диапазона.
+		require.Len(t, rules, 4)
+	})
+}
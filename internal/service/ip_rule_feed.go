@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/utils/errx"
+)
+
+var errFeedName = errors.New("feed name is empty")
+
+// SyncFeed приводит правила фида в соответствие с его текущим содержимым.
+// Правила, добавленные вручную или другими фидами, не изменяются: если сеть уже есть
+// в списках, она пропускается. Удаления и добавления фида выполняются в одной транзакции.
+func (irs IPRuleSrv) SyncFeed(ctx context.Context, sync model.FeedSync) (model.FeedSyncResult, error) {
+	var (
+		result model.FeedSyncResult
+		errs   errx.NamedErrors
+	)
+	if sync.Feed == "" {
+		errs.Add(errx.NamedError{Field: "Feed", Err: errFeedName})
+	}
+	if !sync.Type.Valid() {
+		errs.Add(errx.NamedError{Field: "Type", Err: model.ErrRuleTypeUnk})
+	}
+	if !errs.Empty() {
+		return result, errx.InvalidNew("неверные параметры", errs)
+	}
+	// пустой фид скорее всего означает сбой источника, а не реальную очистку списка.
+	if len(sync.Nets) == 0 {
+		return result, errx.LogicNew(model.ErrFeedEmpty, model.ErrFeedEmptyCode)
+	}
+
+	rules, err := irs.repo.GetList(ctx, model.IPRuleSearch{})
+	if err != nil {
+		return result, errx.FatalNew(err)
+	}
+	owned := make(map[string]model.IPRule)
+	foreign := make(map[string]struct{})
+	for _, rule := range rules {
+		if rule.Source == model.RuleSourceFeed && rule.Feed == sync.Feed {
+			owned[rule.IPNet.String()] = rule
+		} else {
+			foreign[rule.IPNet.String()] = struct{}{}
+		}
+	}
+
+	desired := make(map[string]struct{}, len(sync.Nets))
+	toAdd := make([]model.IPRuleInput, 0)
+	for _, ipNet := range sync.Nets {
+		key := ipNet.String()
+		if _, ok := desired[key]; ok {
+			continue
+		}
+		desired[key] = struct{}{}
+		if rule, ok := owned[key]; ok && rule.Type == sync.Type {
+			result.Unchanged++
+			continue
+		}
+		if _, ok := foreign[key]; ok {
+			result.Skipped++
+			continue
+		}
+		toAdd = append(toAdd, model.IPRuleInput{
+			Type: sync.Type, IPNet: ipNet, Source: model.RuleSourceFeed, Feed: sync.Feed,
+		})
+	}
+
+	actor := string(model.RuleSourceFeed) + ":" + sync.Feed
+	toRemove := make([]model.IPRule, 0)
+	for key, rule := range owned {
+		if _, ok := desired[key]; ok && rule.Type == sync.Type {
+			continue
+		}
+		toRemove = append(toRemove, rule)
+	}
+	// о сетях фида не оповещаем, он загружает их сотнями.
+	err = irs.inTx(model.ContextWithoutBanNotify(ctx), func(ctx context.Context) error {
+		for _, rule := range toRemove {
+			if err := irs.delete(ctx, actor, rule); err != nil {
+				return err
+			}
+		}
+		if len(toAdd) == 0 {
+			return nil
+		}
+		rules, err := irs.repo.AddBatch(ctx, toAdd)
+		if err != nil {
+			return errx.FatalNew(err)
+		}
+		return irs.recordAdded(ctx, actor, rules...)
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Removed, result.Added = len(toRemove), len(toAdd)
+	return result, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/utils/errx"
+	"github.com/vitermakov/otusgo-final/pkg/utils/netlist"
+)
+
+// addRange диапазон разбивается на минимальный набор CIDR, которые добавляются одной пачкой
+// с пометкой исходного диапазона. Объединение при ConflictMerge для диапазонов не выполняется,
+// пересечения только возвращаются, как при ConflictWarn.
+func (irs IPRuleSrv) addRange(
+	ctx context.Context, input model.IPRuleInput, policy model.ConflictPolicy,
+) (model.IPRuleAddResult, error) {
+	var result model.IPRuleAddResult
+	errs := errx.NamedErrors{}
+	if err := input.Validate(); err != nil && !errors.As(err, &errs) {
+		return result, err
+	}
+	rng, err := netlist.ParseRange(input.Range)
+	if err != nil {
+		errs.Add(errx.NamedError{Field: "Range", Err: err})
+	}
+	if !errs.Empty() {
+		return result, errx.InvalidNew("неверные параметры", errs)
+	}
+	input.Range = rng.String()
+	existing, err := irs.repo.GetList(ctx, model.IPRuleSearch{Range: &input.Range})
+	if err != nil {
+		return result, errx.FatalNew(err)
+	}
+	if len(existing) > 0 {
+		return result, errx.LogicNew(model.ErrIPRuleNetDuplicate, model.ErrIPRuleNetDuplicateCode)
+	}
+
+	nets := rng.CIDRs()
+	inputs := make([]model.IPRuleInput, 0, len(nets))
+	seen := make(map[string]struct{})
+	for _, ipNet := range nets {
+		ipNet := ipNet
+		netInput := input
+		netInput.IPNet = ipNet
+		overlaps, err := irs.repo.GetList(ctx, model.IPRuleSearch{IPNet: &ipNet, IPNetOverlap: true})
+		if err != nil {
+			return result, errx.FatalNew(err)
+		}
+		for _, rule := range overlaps {
+			if rule.IPNet.String() == ipNet.String() {
+				return result, errx.LogicNew(model.ErrIPRuleNetDuplicate, model.ErrIPRuleNetDuplicateCode)
+			}
+		}
+		for _, conflict := range findConflicts(netInput, overlaps) {
+			if _, ok := seen[conflict.Rule.ID.String()]; ok {
+				continue
+			}
+			seen[conflict.Rule.ID.String()] = struct{}{}
+			result.Conflicts = append(result.Conflicts, conflict)
+		}
+		inputs = append(inputs, netInput)
+	}
+	if policy == "" {
+		policy = irs.onConflict
+	}
+	if policy == model.ConflictReject && len(result.Conflicts) > 0 {
+		return result, errx.LogicNew(conflictError(result.Conflicts), model.ErrIPRuleConflictCode)
+	}
+
+	err = irs.inTx(ctx, func(ctx context.Context) error {
+		rules, err := irs.repo.AddBatch(ctx, inputs)
+		if err != nil {
+			return storeError(err)
+		}
+		result.Rules = rules
+		return irs.recordAdded(ctx, actorOrDefault(ctx, input.CreatedBy), rules...)
+	})
+	if err != nil {
+		result.Rules = nil
+		return result, err
+	}
+	return result, nil
+}
+
+// DeleteRange удаление всех правил, полученных разбиением диапазона, в одной транзакции.
+func (irs IPRuleSrv) DeleteRange(ctx context.Context, typ model.RuleType, rng string) error {
+	parsed, err := netlist.ParseRange(rng)
+	if err != nil {
+		return errx.InvalidNew("неверные параметры", errx.NamedErrors{{Field: "Range", Err: err}})
+	}
+	canonical := parsed.String()
+	rules, err := irs.repo.GetList(ctx, model.IPRuleSearch{Type: &typ, Range: &canonical})
+	if err != nil {
+		return errx.FatalNew(err)
+	}
+	if len(rules) == 0 {
+		return errx.NotFoundNew(model.ErrRuleNotFound, map[string]interface{}{"range": canonical})
+	}
+	actor := actorOrDefault(ctx, "")
+	return irs.inTx(ctx, func(ctx context.Context) error {
+		for _, rule := range rules {
+			if err := irs.delete(ctx, actor, rule); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
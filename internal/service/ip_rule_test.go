@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository"
+	"github.com/vitermakov/otusgo-final/internal/repository/memory"
+	"github.com/vitermakov/otusgo-final/internal/repository/sqlite"
+	"github.com/vitermakov/otusgo-final/pkg/utils/errx"
+	"github.com/vitermakov/otusgo-final/pkg/utils/migrate"
+)
+
+// racingRepo не видит правил при проверке дубликата, как при параллельном добавлении той же сети.
+type racingRepo struct {
+	repository.IPRule
+}
+
+func (rr racingRepo) GetList(ctx context.Context, search model.IPRuleSearch) ([]model.IPRule, error) {
+	if search.IPNetExact {
+		return nil, nil
+	}
+	return rr.IPRule.GetList(ctx, search)
+}
+
+func TestAddDuplicateRace(t *testing.T) {
+	ctx := context.Background()
+	srv := NewIPRuleSrv(
+		racingRepo{memory.NewIPRuleRepo()}, memory.NewIPRuleEventRepo(), memory.NewTransactor(),
+		model.PrecedenceAllowWins, model.ConflictWarn,
+	)
+	_, ipNet, _ := net.ParseCIDR("10.0.0.0/8")
+	input := model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: *ipNet}
+	_, err := srv.Add(ctx, input, "")
+	require.NoError(t, err)
+
+	_, err = srv.Add(ctx, input, "")
+	var logic errx.Logic
+	require.True(t, errors.As(err, &logic))
+	require.Equal(t, model.ErrIPRuleNetDuplicateCode, logic.Code())
+}
+
+// failingEvents журнал, запись в который всегда завершается ошибкой.
+type failingEvents struct {
+	repository.IPRuleEvent
+}
+
+func (failingEvents) Add(context.Context, model.IPRuleEvent) error {
+	return errors.New("disk full")
+}
+
+func TestAuditInRuleTransaction(t *testing.T) {
+	ctx := context.Background()
+	db, closeFn, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "brutefp.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = closeFn(ctx)
+	})
+	_, err = migrate.New(db, sqlite.Migrations(), migrate.SQLite).Up(ctx)
+	require.NoError(t, err)
+
+	repo := sqlite.NewIPRuleRepo(db)
+	srv := NewIPRuleSrv(
+		repo, failingEvents{sqlite.NewIPRuleEventRepo(db)}, sqlite.NewTransactor(db),
+		model.PrecedenceAllowWins, model.ConflictMerge,
+	)
+	_, net16, _ := net.ParseCIDR("10.1.0.0/16")
+	_, net8, _ := net.ParseCIDR("10.0.0.0/8")
+	_, err = repo.Add(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: *net16, Source: model.RuleSourceManual})
+	require.NoError(t, err)
+
+	// слияние удаляет /16 и добавляет /8, ошибка журнала откатывает оба изменения.
+	_, err = srv.Add(ctx, model.IPRuleInput{Type: model.RuleTypeDeny, IPNet: *net8}, "")
+	require.ErrorContains(t, err, "audit event not saved")
+	rules, err := repo.GetList(ctx, model.IPRuleSearch{})
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, net16.String(), rules[0].IPNet.String())
+
+	allow := model.RuleTypeAllow
+	_, err = srv.Update(ctx, model.IPRuleUpdate{IPNet: *net16, Type: &allow})
+	require.ErrorContains(t, err, "audit event not saved")
+	rules, err = repo.GetList(ctx, model.IPRuleSearch{})
+	require.NoError(t, err)
+	require.Equal(t, model.RuleTypeDeny, rules[0].Type)
+}
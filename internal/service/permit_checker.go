@@ -3,77 +3,247 @@ package service
 import (
 	"context"
 	"fmt"
+	"net"
 	"time"
 
 	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
 	"github.com/vitermakov/otusgo-final/internal/model"
 	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+	"github.com/vitermakov/otusgo-final/pkg/tracing"
 	"github.com/vitermakov/otusgo-final/pkg/utils/errx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type PermitCheckerSrv struct {
 	ipRule      IPRule
 	rateLimiter ratelimit.Limiter
 	limits      config.Limits
-	checks      []struct {
-		name    string
-		limit   int
-		err     error
-		errCode int
-	}
+	checks      []limitCheck
+}
+
+// limitCheck проверка одного параметра запроса, у каждого параметра свои бакеты.
+type limitCheck struct {
+	name    string
+	limit   int
+	err     error
+	errCode int
+	reason  model.PermitReason
 }
 
+// Check спан на всю проверку и отдельные на white/black списки и на каждый лимит.
 func (p PermitCheckerSrv) Check(ctx context.Context, query model.PermitQuery) (model.PermitResult, error) {
+	ctx, span := tracer.Start(ctx, "PermitChecker.Check")
+	defer span.End()
+
+	res, err := p.check(ctx, query)
+	span.SetAttributes(
+		attribute.Bool("brutefp.permit.success", res.Success),
+		attribute.String("brutefp.permit.reason", string(res.Reason)),
+	)
+	tracing.SpanError(span, err)
+	return res, err
+}
+
+func (p PermitCheckerSrv) check(ctx context.Context, query model.PermitQuery) (model.PermitResult, error) {
 	// сначала проверяем, если ли IP в white/black списках.
 	ruleType, err := p.ipRule.GetRuleTypeForIP(ctx, query.IP)
 	if err != nil {
-		return model.PermitResult{Err: model.ErrDeniedInternal, ErrCode: model.ErrDeniedInternalCode}, err
-	}
-	if ruleType.Valid() {
-		switch ruleType { //nolint:exhaustive // и не должно быть
-		case model.RuleTypeAllow:
-			return model.PermitResult{Success: true}, nil
-		case model.RuleTypeDeny:
-			return model.PermitResult{
-				Err:     fmt.Errorf("%w: %s", model.ErrDeniedByRule, query.IP),
-				ErrCode: model.ErrDeniedByRuleCode,
-			}, nil
+		return model.PermitResult{
+			Err: model.ErrDeniedInternal, ErrCode: model.ErrDeniedInternalCode, Reason: model.PermitReasonInternal,
+		}, err
+	}
+	if res, ok := ruleResult(query, ruleType); ok {
+		return res, nil
+	}
+	return p.checkLimits(ctx, query)
+}
+
+// CheckBatch спан на весь набор, правила для всех IP набора читаются одним запросом к хранилищу.
+func (p PermitCheckerSrv) CheckBatch(ctx context.Context, batch model.PermitBatch) ([]model.PermitResult, error) {
+	ctx, span := tracer.Start(ctx, "PermitChecker.CheckBatch", trace.WithAttributes(
+		attribute.Int("brutefp.batch.size", len(batch.Queries)),
+		attribute.Bool("brutefp.batch.atomic", batch.Atomic),
+	))
+	defer span.End()
+
+	results, err := p.checkBatch(ctx, batch)
+	tracing.SpanError(span, err)
+	return results, err
+}
+
+func (p PermitCheckerSrv) checkBatch(ctx context.Context, batch model.PermitBatch) ([]model.PermitResult, error) {
+	ips := make([]net.IP, len(batch.Queries))
+	for i, query := range batch.Queries {
+		ips[i] = query.IP
+	}
+	ruleTypes, err := p.ipRule.GetRuleTypesForIPs(ctx, ips)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]model.PermitResult, len(batch.Queries))
+	// limited запросы, не решенные списками, - проверяются по лимитам.
+	limited := make([]int, 0, len(batch.Queries))
+	denied := false
+	for i, query := range batch.Queries {
+		res, ok := ruleResult(query, ruleTypes[i])
+		if !ok {
+			limited = append(limited, i)
+			continue
 		}
+		results[i] = res
+		denied = denied || !res.Success
 	}
 
-	period, err := p.limits.BaseDuration.AsDuration()
-	if err != nil || period.Nanoseconds() <= 0 {
-		period = time.Minute
+	if !batch.Atomic {
+		// предыдущие запросы уже учтены в лимитах, поэтому ошибка ограничителя не прерывает набор:
+		// запрос получает внутренний отказ, ошибка записана в его спан.
+		for _, i := range limited {
+			results[i], _ = p.checkLimits(ctx, batch.Queries[i])
+		}
+		return results, nil
 	}
-	var exceed bool
-	for _, check := range p.checks {
-		var checkValue string
-		switch check.name {
-		case model.LimitParamNameLogin:
-			checkValue = query.Login
-		case model.LimitParamNamePassword:
-			checkValue = query.Password
-		case model.LimitParamNameIP:
-			checkValue = query.IP.String()
+	// запрет по black списку уже отклоняет набор, лимиты тогда не проверяются.
+	if !denied && len(limited) > 0 {
+		if denied, err = p.chargeAll(ctx, batch.Queries, limited, results); err != nil {
+			return nil, err
+		}
+	}
+	if denied {
+		for _, i := range limited {
+			if results[i].Reason == "" {
+				results[i] = model.PermitResult{
+					Err:     model.ErrDeniedByBatch,
+					ErrCode: model.ErrDeniedByBatchCode,
+					Reason:  model.PermitReasonBatch,
+				}
+			}
 		}
-		exceed, err = p.rateLimiter.ExceedLimit(
-			model.LimitBucket{Param: check.name, Value: checkValue}.Name(),
-			ratelimit.Limits{
-				Period: period,
-				Limit:  int64(check.limit),
-			},
-		)
+	}
+	return results, nil
+}
+
+// ruleResult решение по white/black спискам, false - IP нет в списках и нужна проверка лимитов.
+func ruleResult(query model.PermitQuery, ruleType model.RuleType) (model.PermitResult, bool) {
+	switch ruleType { //nolint:exhaustive // и не должно быть
+	case model.RuleTypeAllow:
+		return model.PermitResult{Success: true, Reason: model.PermitReasonWhiteList}, true
+	case model.RuleTypeDeny:
+		return model.PermitResult{
+			Err:     fmt.Errorf("%w: %s", model.ErrDeniedByRule, query.IP),
+			ErrCode: model.ErrDeniedByRuleCode,
+			Reason:  model.PermitReasonBlackList,
+		}, true
+	}
+	return model.PermitResult{}, false
+}
+
+func (p PermitCheckerSrv) checkLimits(ctx context.Context, query model.PermitQuery) (model.PermitResult, error) {
+	for _, check := range p.checks {
+		bucket := check.bucket(query)
+		exceed, err := p.exceedLimit(ctx, bucket, p.limitsFor(check))
 		if err != nil {
-			return model.PermitResult{Err: model.ErrDeniedInternal}, errx.FatalNew(err)
+			internal := model.PermitResult{
+				Err: model.ErrDeniedInternal, ErrCode: model.ErrDeniedInternalCode, Reason: model.PermitReasonInternal,
+			}
+			return internal, errx.FatalNew(err)
 		}
 		if exceed {
-			return model.PermitResult{
-				Err: fmt.Errorf("%w: %s", check.err, checkValue),
-			}, nil
+			return check.exceeded(bucket), nil
+		}
+	}
+	return model.PermitResult{Success: true, Reason: model.PermitReasonWithinLimits}, nil
+}
+
+// chargeAll учет лимитов запросов limited по принципу "все или ничего". Превысившим лимит
+// записывается результат в results; true - лимит превышен и ничего не учтено.
+func (p PermitCheckerSrv) chargeAll(
+	ctx context.Context, queries []model.PermitQuery, limited []int, results []model.PermitResult,
+) (bool, error) {
+	_, span := tracer.Start(ctx, "PermitChecker.LimitAll", trace.WithAttributes(
+		attribute.Int("brutefp.limit.queries", len(limited)),
+	))
+	defer span.End()
+
+	charges := make([]ratelimit.Charge, 0, len(limited)*len(p.checks))
+	for _, i := range limited {
+		for _, check := range p.checks {
+			charges = append(charges, ratelimit.Charge{
+				BucketCode: check.bucket(queries[i]).Name(),
+				Limits:     p.limitsFor(check),
+			})
+		}
+	}
+	exceeds, err := p.rateLimiter.ExceedLimitAll(charges)
+	tracing.SpanError(span, err)
+	if err != nil {
+		return false, errx.FatalNew(err)
+	}
+
+	denied := false
+	for n, i := range limited {
+		for c, check := range p.checks {
+			if exceeds[n*len(p.checks)+c] {
+				results[i] = check.exceeded(check.bucket(queries[i]))
+				denied = true
+				break
+			}
+		}
+	}
+	span.SetAttributes(attribute.Bool("brutefp.limit.exceeded", denied))
+	if !denied {
+		for _, i := range limited {
+			results[i] = model.PermitResult{Success: true, Reason: model.PermitReasonWithinLimits}
 		}
 	}
+	return denied, nil
+}
+
+func (p PermitCheckerSrv) limitsFor(check limitCheck) ratelimit.Limits {
+	period, err := p.limits.BaseDuration.AsDuration()
+	if err != nil || period.Nanoseconds() <= 0 {
+		period = time.Minute
+	}
+	return ratelimit.Limits{Period: period, Limit: int64(check.limit)}
+}
+
+// bucket бакет проверяемого параметра запроса.
+func (c limitCheck) bucket(query model.PermitQuery) model.LimitBucket {
+	var value string
+	switch c.name {
+	case model.LimitParamNameLogin:
+		value = query.Login
+	case model.LimitParamNamePassword:
+		value = query.Password
+	case model.LimitParamNameIP:
+		value = query.IP.String()
+	}
+	return model.LimitBucket{Param: c.name, Value: value}
+}
+
+// exceeded значение бакета попадает в ошибку только для логина и IP: ошибку получает клиент,
+// и она может попасть в журнал, а пароль не должен попадать никуда.
+func (c limitCheck) exceeded(bucket model.LimitBucket) model.PermitResult {
+	err := c.err
+	if bucket.Param != model.LimitParamNamePassword {
+		err = fmt.Errorf("%w: %s", c.err, bucket.Value)
+	}
+	return model.PermitResult{Err: err, Reason: c.reason}
+}
+
+// exceedLimit учет запроса в бакете, значение в спан не пишется - это логин или пароль.
+func (p PermitCheckerSrv) exceedLimit(
+	ctx context.Context, bucket model.LimitBucket, limits ratelimit.Limits,
+) (bool, error) {
+	_, span := tracer.Start(ctx, "PermitChecker.Limit", trace.WithAttributes(
+		attribute.String("brutefp.limit.param", bucket.Param),
+	))
+	defer span.End()
 
-	return model.PermitResult{Success: true}, nil
+	exceed, err := p.rateLimiter.ExceedLimit(bucket.Name(), limits)
+	span.SetAttributes(attribute.Bool("brutefp.limit.exceeded", exceed))
+	tracing.SpanError(span, err)
+	return exceed, err
 }
 
 func (p PermitCheckerSrv) Reset(_ context.Context, bucket model.LimitBucket) (bool, error) {
@@ -91,27 +261,25 @@ func NewPermitCheckerSrv(
 	ipRule IPRule, limiter ratelimit.RateLimiter, bd time.Duration, cfg config.Limits,
 ) PermitChecker {
 	// проверки на лимиты. Для каждого типа лимита свой ключ.
-	checks := []struct {
-		name    string
-		limit   int
-		err     error
-		errCode int
-	}{
+	checks := []limitCheck{
 		{
 			name:    "login",
 			limit:   cfg.LoginPerMin,
 			err:     model.ErrDeniedByLoginLimit,
 			errCode: model.ErrDeniedByLoginLimitCode,
+			reason:  model.PermitReasonLoginLimit,
 		}, {
 			name:    "password",
 			limit:   cfg.PasswordPerMin,
 			err:     model.ErrDeniedByPasswordLimit,
 			errCode: model.ErrDeniedByPasswordLimitCode,
+			reason:  model.PermitReasonPasswordLimit,
 		}, {
 			name:    "ip",
 			limit:   cfg.IPPerMin,
 			err:     model.ErrDeniedByIPLimit,
 			errCode: model.ErrDeniedByIPLimitCode,
+			reason:  model.PermitReasonIPLimit,
 		},
 	}
 	return &PermitCheckerSrv{ipRule: ipRule, rateLimiter: limiter, limits: cfg, checks: checks}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	config "github.com/vitermakov/otusgo-final/internal/app/config/brutefp"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/ratelimit"
+	"github.com/vitermakov/otusgo-final/internal/repository/memory"
+)
+
+// brokenLimiter ограничитель, который не отвечает для бакетов IP failIP.
+type brokenLimiter struct {
+	ratelimit.RateLimiter
+	failIP string
+}
+
+func (bl brokenLimiter) ExceedLimit(bucketCode string, limits ratelimit.Limits) (bool, error) {
+	if strings.HasSuffix(bucketCode, "_"+bl.failIP) {
+		return false, errors.New("limiter is down")
+	}
+	return bl.RateLimiter.ExceedLimit(bucketCode, limits)
+}
+
+func TestCheckBatchLimiterError(t *testing.T) {
+	ctx := context.Background()
+	ipRule := NewIPRuleSrv(
+		memory.NewIPRuleRepo(), memory.NewIPRuleEventRepo(), memory.NewTransactor(),
+		model.PrecedenceAllowWins, model.ConflictWarn,
+	)
+	limiter := ratelimit.NewFixedMemory()
+	t.Cleanup(func() {
+		_ = limiter.Destroy()
+	})
+	checker := NewPermitCheckerSrv(ipRule, brokenLimiter{RateLimiter: limiter, failIP: "10.0.0.2"}, time.Minute,
+		config.Limits{LoginPerMin: 2, PasswordPerMin: 100, IPPerMin: 100})
+
+	query := func(ip string) model.PermitQuery {
+		return model.PermitQuery{Login: "user", Password: "secret", IP: net.ParseIP(ip)}
+	}
+	// ошибка на втором запросе не отменяет результат первого, третий проверяется как обычно.
+	results, err := checker.CheckBatch(ctx, model.PermitBatch{
+		Queries: []model.PermitQuery{query("10.0.0.1"), query("10.0.0.2"), query("10.0.0.3")},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.True(t, results[0].Success)
+	require.Equal(t, model.PermitReasonInternal, results[1].Reason)
+	require.Equal(t, model.ErrDeniedInternalCode, results[1].ErrCode)
+	// логин второго запроса учтен до ошибки на IP, поэтому третий превышает лимит.
+	require.False(t, results[2].Success)
+	require.Equal(t, model.PermitReasonLoginLimit, results[2].Reason)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"github.com/vitermakov/otusgo-final/internal/model"
+)
+
+// resolveRuleType выбирает итоговый тип из подходящих к IP правил согласно precedence.
+func resolveRuleType(precedence model.Precedence, rules []model.IPRule) model.RuleType {
+	if len(rules) == 0 {
+		return model.RuleTypeNone
+	}
+	switch precedence {
+	case model.PrecedenceDenyWins:
+		return firstOfType(rules, model.RuleTypeDeny, model.RuleTypeAllow)
+	case model.PrecedenceMostSpecific, model.PrecedencePriority:
+		winner := rules[0]
+		for _, rule := range rules[1:] {
+			if outranks(precedence, rule, winner) {
+				winner = rule
+			}
+		}
+		return winner.Type
+	case model.PrecedenceAllowWins:
+	}
+	return firstOfType(rules, model.RuleTypeAllow, model.RuleTypeDeny)
+}
+
+// firstOfType winner, если есть хоть одно правило этого типа, иначе other (правила есть всегда).
+func firstOfType(rules []model.IPRule, winner, other model.RuleType) model.RuleType {
+	for _, rule := range rules {
+		if rule.Type == winner {
+			return winner
+		}
+	}
+	return other
+}
+
+// outranks true, если rule важнее current. При полном равенстве побеждает запрет.
+func outranks(precedence model.Precedence, rule, current model.IPRule) bool {
+	if precedence == model.PrecedencePriority && rule.Priority != current.Priority {
+		return rule.Priority > current.Priority
+	}
+	ruleOnes, _ := rule.IPNet.Mask.Size()
+	currentOnes, _ := current.IPNet.Mask.Size()
+	if ruleOnes != currentOnes {
+		return ruleOnes > currentOnes
+	}
+	return rule.Type == model.RuleTypeDeny && current.Type != model.RuleTypeDeny
+}
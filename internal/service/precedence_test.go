@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/internal/repository/memory"
+)
+
+func TestGetRuleTypeForIP(t *testing.T) {
+	// 10.0.0.0/8 запрещена, 10.1.0.0/16 внутри нее разрешена, 10.1.2.3 отдельно запрещен
+	// с наибольшим приоритетом, 10.1.9.0/24 запрещена с приоритетом ниже, чем у /16.
+	rules := []struct {
+		typ      model.RuleType
+		network  string
+		priority int
+	}{
+		{model.RuleTypeDeny, "10.0.0.0/8", 0},
+		{model.RuleTypeAllow, "10.1.0.0/16", 5},
+		{model.RuleTypeDeny, "10.1.2.3/32", 10},
+		{model.RuleTypeDeny, "10.1.9.0/24", 1},
+		{model.RuleTypeAllow, "2001:db8::/32", 0},
+	}
+	cases := []struct {
+		ip       string
+		expected map[model.Precedence]model.RuleType
+	}{
+		{
+			ip: "10.200.0.1",
+			expected: map[model.Precedence]model.RuleType{
+				model.PrecedenceAllowWins:    model.RuleTypeDeny,
+				model.PrecedenceDenyWins:     model.RuleTypeDeny,
+				model.PrecedenceMostSpecific: model.RuleTypeDeny,
+				model.PrecedencePriority:     model.RuleTypeDeny,
+			},
+		},
+		{
+			ip: "10.1.5.5",
+			expected: map[model.Precedence]model.RuleType{
+				model.PrecedenceAllowWins:    model.RuleTypeAllow,
+				model.PrecedenceDenyWins:     model.RuleTypeDeny,
+				model.PrecedenceMostSpecific: model.RuleTypeAllow,
+				model.PrecedencePriority:     model.RuleTypeAllow,
+			},
+		},
+		{
+			ip: "10.1.2.3",
+			expected: map[model.Precedence]model.RuleType{
+				model.PrecedenceAllowWins:    model.RuleTypeAllow,
+				model.PrecedenceDenyWins:     model.RuleTypeDeny,
+				model.PrecedenceMostSpecific: model.RuleTypeDeny,
+				model.PrecedencePriority:     model.RuleTypeDeny,
+			},
+		},
+		{
+			ip: "10.1.9.9",
+			expected: map[model.Precedence]model.RuleType{
+				model.PrecedenceAllowWins:    model.RuleTypeAllow,
+				model.PrecedenceDenyWins:     model.RuleTypeDeny,
+				model.PrecedenceMostSpecific: model.RuleTypeDeny,
+				model.PrecedencePriority:     model.RuleTypeAllow,
+			},
+		},
+		{
+			ip: "2001:db8::1",
+			expected: map[model.Precedence]model.RuleType{
+				model.PrecedenceAllowWins:    model.RuleTypeAllow,
+				model.PrecedenceDenyWins:     model.RuleTypeAllow,
+				model.PrecedenceMostSpecific: model.RuleTypeAllow,
+				model.PrecedencePriority:     model.RuleTypeAllow,
+			},
+		},
+		{
+			ip: "192.168.0.1",
+			expected: map[model.Precedence]model.RuleType{
+				model.PrecedenceAllowWins:    model.RuleTypeNone,
+				model.PrecedenceDenyWins:     model.RuleTypeNone,
+				model.PrecedenceMostSpecific: model.RuleTypeNone,
+				model.PrecedencePriority:     model.RuleTypeNone,
+			},
+		},
+	}
+
+	ctx := context.Background()
+	repo := memory.NewIPRuleRepo()
+	for _, rule := range rules {
+		_, ipNet, err := net.ParseCIDR(rule.network)
+		require.NoError(t, err)
+		_, err = repo.Add(ctx, model.IPRuleInput{Type: rule.typ, IPNet: *ipNet, Priority: rule.priority})
+		require.NoError(t, err)
+	}
+	for _, precedence := range []model.Precedence{
+		model.PrecedenceAllowWins, model.PrecedenceDenyWins, model.PrecedenceMostSpecific, model.PrecedencePriority,
+	} {
+		precedence := precedence
+		t.Run(string(precedence), func(t *testing.T) {
+			srv := NewIPRuleSrv(repo, memory.NewIPRuleEventRepo(), memory.NewTransactor(), precedence, model.ConflictWarn)
+			ips := make([]net.IP, 0, len(cases))
+			for _, tc := range cases {
+				typ, err := srv.GetRuleTypeForIP(ctx, net.ParseIP(tc.ip))
+				require.NoError(t, err)
+				require.Equal(t, tc.expected[precedence], typ, tc.ip)
+				ips = append(ips, net.ParseIP(tc.ip))
+			}
+			// набор адресов одним запросом дает те же решения.
+			types, err := srv.GetRuleTypesForIPs(ctx, ips)
+			require.NoError(t, err)
+			for i, tc := range cases {
+				require.Equal(t, tc.expected[precedence], types[i], tc.ip)
+			}
+		})
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	precedence, err := model.ParsePrecedence("")
+	require.NoError(t, err)
+	require.Equal(t, model.PrecedenceAllowWins, precedence)
+
+	precedence, err = model.ParsePrecedence("most-specific")
+	require.NoError(t, err)
+	require.Equal(t, model.PrecedenceMostSpecific, precedence)
+
+	_, err = model.ParsePrecedence("first-match")
+	require.ErrorIs(t, err, model.ErrPrecedenceUnk)
+}
@@ -5,18 +5,44 @@ import (
 	"net"
 
 	"github.com/vitermakov/otusgo-final/internal/model"
+	"github.com/vitermakov/otusgo-final/pkg/servers"
 )
 
 // IPRule управление white/black списками.
 type IPRule interface {
-	Add(context.Context, model.IPRuleInput) (*model.IPRule, error)
+	Add(context.Context, model.IPRuleInput, model.ConflictPolicy) (model.IPRuleAddResult, error)
 	Delete(context.Context, model.IPRule) error
+	// DeleteRange удаление диапазона, добавленного через IPRuleInput.Range.
+	DeleteRange(context.Context, model.RuleType, string) error
+	Update(context.Context, model.IPRuleUpdate) (*model.IPRule, error)
 	GetByIPNet(context.Context, model.RuleType, net.IPNet) (*model.IPRule, error)
 	GetRuleTypeForIP(context.Context, net.IP) (model.RuleType, error)
+	// GetRuleTypesForIPs типы правил для набора адресов, в порядке адресов.
+	GetRuleTypesForIPs(context.Context, []net.IP) ([]model.RuleType, error)
+	GetList(context.Context, model.IPRuleSearch) ([]model.IPRule, error)
+	// Count число правил по спискам.
+	Count(context.Context, model.IPRuleSearch) (map[model.RuleType]int, error)
+	Import(context.Context, model.IPRuleImport) (model.IPRuleImportResult, error)
+	Compact(context.Context, model.IPRuleCompact) (model.IPRuleCompactResult, error)
+	SyncFeed(context.Context, model.FeedSync) (model.FeedSyncResult, error)
+	GetHistory(context.Context, model.IPRuleEventSearch) ([]model.IPRuleEvent, error)
 }
 
 // PermitChecker проверка разрешения на совершение действия, основываясь на политике лимитов.
 type PermitChecker interface {
 	Check(context.Context, model.PermitQuery) (model.PermitResult, error)
+	// CheckBatch проверка набора запросов, результаты в порядке запросов. Если ограничитель
+	// не ответил для запроса неатомарного набора, этот запрос получает внутренний отказ.
+	CheckBatch(context.Context, model.PermitBatch) ([]model.PermitResult, error)
 	Reset(context.Context, model.LimitBucket) (bool, error)
 }
+
+// Auth авторизация клиентов API по ключам и управление ключами.
+type Auth interface {
+	servers.AuthService
+	servers.CertAuthService
+	// CreateKey новый ключ с ролью, возвращает сам ключ - он больше нигде не сохраняется.
+	CreateKey(context.Context, string, model.Role) (string, *model.APIKey, error)
+	RevokeKey(context.Context, string) error
+	GetKeys(context.Context) ([]model.APIKey, error)
+}
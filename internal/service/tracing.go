@@ -0,0 +1,5 @@
+package service
+
+import "github.com/vitermakov/otusgo-final/pkg/tracing"
+
+var tracer = tracing.Tracer("github.com/vitermakov/otusgo-final/internal/service")
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE public.ip_rules
+    ADD COLUMN source varchar(16) NOT NULL DEFAULT 'manual',
+    ADD COLUMN feed varchar(64) NOT NULL DEFAULT '';
+CREATE INDEX ip_rules_source_feed_idx ON public.ip_rules (source, feed);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS public.ip_rules_source_feed_idx;
+ALTER TABLE public.ip_rules
+    DROP COLUMN IF EXISTS feed,
+    DROP COLUMN IF EXISTS source;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE public.ip_rules
+    ADD COLUMN comment text NOT NULL DEFAULT '',
+    ADD COLUMN created_by varchar(255) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE public.ip_rules
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS comment;
+-- +goose StatementEnd
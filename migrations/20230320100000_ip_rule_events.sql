@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE public.ip_rule_events (
+    id uuid NOT NULL,
+    rule_id uuid NOT NULL,
+    action varchar(16) NOT NULL,
+    actor varchar(255) NOT NULL DEFAULT '',
+    ip_net cidr NOT NULL,
+    before jsonb,
+    after jsonb,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX ip_rule_events_ip_net_idx ON public.ip_rule_events USING gist (ip_net inet_ops);
+CREATE INDEX ip_rule_events_rule_id_idx ON public.ip_rule_events (rule_id);
+CREATE INDEX ip_rule_events_created_at_idx ON public.ip_rule_events (created_at);
+
+-- журнал только пополняется: изменение и удаление записей запрещено.
+CREATE FUNCTION public.ip_rule_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ip_rule_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER ip_rule_events_append_only
+    BEFORE UPDATE OR DELETE ON public.ip_rule_events
+    FOR EACH ROW EXECUTE FUNCTION public.ip_rule_events_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS public.ip_rule_events;
+DROP FUNCTION IF EXISTS public.ip_rule_events_append_only();
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE public.ip_rules
+    ADD COLUMN priority integer NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE public.ip_rules
+    DROP COLUMN IF EXISTS priority;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE public.ip_rules
+    ADD COLUMN ip_range varchar(100) NOT NULL DEFAULT '';
+CREATE INDEX ip_rules_ip_range_idx ON public.ip_rules (ip_range) WHERE ip_range <> '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS public.ip_rules_ip_range_idx;
+ALTER TABLE public.ip_rules
+    DROP COLUMN IF EXISTS ip_range;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- до уникального ограничения дубликаты могли появиться при параллельном добавлении: оставляем первое правило.
+DELETE FROM public.ip_rules a
+    USING public.ip_rules b
+    WHERE a.type = b.type AND a.ip_net = b.ip_net
+      AND (a.updated_at, a.id::text) > (b.updated_at, b.id::text);
+ALTER TABLE public.ip_rules
+    ADD CONSTRAINT ip_rules_type_ip_net_key UNIQUE (type, ip_net);
+-- поиск сетей, содержащих адрес (<<=) и пересекающихся с сетью (&&), без последовательного чтения таблицы.
+CREATE INDEX ip_rules_ip_net_idx ON public.ip_rules USING gist (ip_net inet_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS public.ip_rules_ip_net_idx;
+ALTER TABLE public.ip_rules
+    DROP CONSTRAINT IF EXISTS ip_rules_type_ip_net_key;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ключи клиентов API, хранится только sha256 ключа.
+CREATE TABLE public.api_keys (
+    id uuid NOT NULL,
+    name varchar(100) NOT NULL,
+    key_hash char(64) NOT NULL,
+    role varchar(16) NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    CONSTRAINT api_keys_name_key UNIQUE (name),
+    CONSTRAINT api_keys_key_hash_key UNIQUE (key_hash)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS public.api_keys;
+-- +goose StatementEnd
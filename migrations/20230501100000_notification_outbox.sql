@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- очередь уведомлений вебхуков, удаляются после доставки или последней попытки.
+CREATE TABLE public.notification_outbox (
+    id uuid NOT NULL,
+    webhook varchar(100) NOT NULL,
+    type varchar(32) NOT NULL,
+    payload bytea NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamp with time zone NOT NULL,
+    last_error text NOT NULL DEFAULT '',
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX notification_outbox_next_attempt_at_idx ON public.notification_outbox (next_attempt_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS public.notification_outbox;
+-- +goose StatementEnd
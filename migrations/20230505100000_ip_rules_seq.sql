@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- порядок добавления для GetList: updated_at меняется при изменении, а id случаен.
+ALTER TABLE public.ip_rules
+    ADD COLUMN seq bigserial;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE public.ip_rules
+    DROP COLUMN IF EXISTS seq;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+// Package migrations схема PostgreSQL, встроенная в бинарник brutefp.
+package migrations
+
+import "embed"
+
+// FS файлы миграций в формате goose.
+//
+//go:embed *.sql
+var FS embed.FS
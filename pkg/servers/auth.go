@@ -2,6 +2,7 @@ package servers
 
 import (
 	"context"
+	"strings"
 )
 
 // AuthUser авторизованный пользователь.
@@ -9,9 +10,58 @@ type AuthUser struct {
 	ID    string
 	Login string
 	Name  string
+	Role  string
 }
 
 // AuthService интерфейс микросервиса авторизации.
 type AuthService interface {
+	// Authorize пользователь по токену запроса, nil - если токен неизвестен.
 	Authorize(context.Context, string) (*AuthUser, error)
 }
+
+// RoleAnonymous метод доступен без авторизации, например, health-check.
+const RoleAnonymous = "anonymous"
+
+// MethodRoles роли, которым разрешен вызов метода. Метод, которого нет в списке, запрещен всем.
+type MethodRoles map[string][]string
+
+// Allowed разрешен ли роли вызов метода.
+func (mr MethodRoles) Allowed(method, role string) bool {
+	return HasRole(mr[method], role)
+}
+
+// Public метод доступен без авторизации.
+func (mr MethodRoles) Public(method string) bool {
+	return HasRole(mr[method], RoleAnonymous)
+}
+
+// HasRole есть ли role среди roles.
+func HasRole(roles []string, role string) bool {
+	for _, allowed := range roles {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenFromHeader токен из заголовка Authorization, схема Bearer необязательна.
+func TokenFromHeader(value string) string {
+	const bearer = "bearer "
+	value = strings.TrimSpace(value)
+	if len(value) > len(bearer) && strings.EqualFold(value[:len(bearer)], bearer) {
+		value = strings.TrimSpace(value[len(bearer):])
+	}
+	return value
+}
+
+// ContextWithUser сохраняет авторизованного пользователя в контексте запроса.
+func ContextWithUser(ctx context.Context, user *AuthUser) context.Context {
+	return context.WithValue(ctx, CtxKey{}, user)
+}
+
+// UserFromContext авторизованный пользователь запроса, если запрос прошел авторизацию.
+func UserFromContext(ctx context.Context) (*AuthUser, bool) {
+	user, ok := ctx.Value(CtxKey{}).(*AuthUser)
+	return user, ok && user != nil
+}
@@ -1,5 +1,7 @@
 package servers
 
+import "crypto/tls"
+
 const (
 	defaultHost = "localhost"
 	defaultPort = 8080
@@ -8,9 +10,12 @@ const (
 type CtxKey struct{}
 
 type Config struct {
-	host  string
-	port  int
-	debug bool
+	host   string
+	port   int
+	debug  bool
+	tls    *tls.Config
+	socket string
+	quiet  bool
 }
 
 func (cfg Config) GetHost() string {
@@ -31,6 +36,39 @@ func (cfg Config) IsDebug() bool {
 	return cfg.debug
 }
 
+// TLS настройки TLS, nil - соединения без шифрования.
+func (cfg Config) TLS() *tls.Config {
+	return cfg.tls
+}
+
+// WithTLS копия настроек с TLS.
+func (cfg Config) WithTLS(tlsConfig *tls.Config) Config {
+	cfg.tls = tlsConfig
+	return cfg
+}
+
+// Socket путь к unix-сокету, если задан - host и port не используются.
+func (cfg Config) Socket() string {
+	return cfg.socket
+}
+
+// WithSocket копия настроек с unix-сокетом.
+func (cfg Config) WithSocket(path string) Config {
+	cfg.socket = path
+	return cfg
+}
+
+// IsQuiet запросы не пишутся в лог, только ошибки.
+func (cfg Config) IsQuiet() bool {
+	return cfg.quiet
+}
+
+// WithQuiet копия настроек без журнала запросов.
+func (cfg Config) WithQuiet(quiet bool) Config {
+	cfg.quiet = quiet
+	return cfg
+}
+
 func NewConfig(host string, port int, debug bool) Config {
 	return Config{
 		host:  host,
@@ -12,10 +12,11 @@ import (
 
 type AuthInterceptor struct {
 	authService servers.AuthService
+	roles       servers.MethodRoles
 }
 
-func NewAuthInterceptor(authService servers.AuthService) *AuthInterceptor {
-	return &AuthInterceptor{authService}
+func NewAuthInterceptor(authService servers.AuthService, roles servers.MethodRoles) *AuthInterceptor {
+	return &AuthInterceptor{authService: authService, roles: roles}
 }
 
 func (i *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
@@ -25,35 +26,74 @@ func (i *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
-		user, err := i.authorize(ctx)
+		user, err := i.authorize(ctx, info.FullMethod)
 		if err != nil {
 			return nil, err
 		}
-		ctx = context.WithValue(ctx, servers.CtxKey{}, map[string]string{
-			"id":    user.ID,
-			"name":  user.Name,
-			"login": user.Login,
+		return handler(servers.ContextWithUser(ctx, user), req)
+	}
+}
+
+func (i *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		user, err := i.authorize(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &ctxStream{
+			ServerStream: stream,
+			ctx:          servers.ContextWithUser(stream.Context(), user),
 		})
-		return handler(ctx, req)
 	}
 }
 
-func (i *AuthInterceptor) authorize(ctx context.Context) (*servers.AuthUser, error) {
-	meta, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, status.Errorf(codes.Unauthenticated, "metadata is not provided")
+// authorize по токену из метаданных, без токена - по клиентскому сертификату, если сервис это умеет.
+// Для публичных методов пользователь nil.
+func (i *AuthInterceptor) authorize(ctx context.Context, method string) (*servers.AuthUser, error) {
+	if i.roles.Public(method) {
+		return nil, nil
 	}
-	values := meta["authorization"]
-	if len(values) == 0 {
+	var token string
+	if meta, ok := metadata.FromIncomingContext(ctx); ok && len(meta["authorization"]) > 0 {
+		token = servers.TokenFromHeader(meta["authorization"][0])
+	}
+	var (
+		user *servers.AuthUser
+		err  error
+	)
+	identity, hasCert := IdentityFromContext(ctx)
+	certAuth, canCert := i.authService.(servers.CertAuthService)
+	switch {
+	case token != "":
+		user, err = i.authService.Authorize(ctx, token)
+	case hasCert && canCert:
+		user, err = certAuth.AuthorizeCert(ctx, *identity)
+	default:
 		return nil, status.Errorf(codes.Unauthenticated, "authorization token is not provided")
 	}
-	userEmail := values[0]
-	user, err := i.authService.Authorize(ctx, userEmail)
 	if err != nil {
 		return nil, status.Errorf(codes.Unauthenticated, "error authorize: %v", err)
 	}
 	if user == nil {
-		return nil, status.Errorf(codes.Unauthenticated, "user e-mail is invalid: %s", userEmail)
+		return nil, status.Errorf(codes.Unauthenticated, "authorization token or certificate is invalid")
+	}
+	if !i.roles.Allowed(method, user.Role) {
+		return nil, status.Errorf(codes.PermissionDenied, "role '%s' is not allowed to call %s", user.Role, method)
 	}
 	return user, nil
 }
+
+// ctxStream поток с подмененным контекстом: авторизованный пользователь, спан трассировки.
+type ctxStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *ctxStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,142 @@
+package grpc
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthCheck проверка зависимости сервиса, nil - зависимость доступна.
+type HealthCheck func(context.Context) error
+
+// HealthChecker сервис grpc.health.v1.Health, статусы обновляются фоновой проверкой зависимостей.
+// Каждая зависимость видна отдельным сервисом с ее именем; общий статус ("") и статусы сервисов
+// API - SERVING, только если доступны все зависимости.
+type HealthChecker struct {
+	server   *health.Server
+	checks   map[string]HealthCheck
+	services []string
+	interval time.Duration
+	timeout  time.Duration
+	logger   logger.Logger
+
+	mu      sync.Mutex
+	stopped bool
+	errs    map[string]error
+}
+
+// NewHealthChecker services - полные имена сервисов API, interval - период проверок,
+// каждая проверка ограничена timeout.
+func NewHealthChecker(
+	checks map[string]HealthCheck, services []string, interval, timeout time.Duration, logger logger.Logger,
+) *HealthChecker {
+	hc := &HealthChecker{
+		server:   health.NewServer(),
+		checks:   checks,
+		services: services,
+		interval: interval,
+		timeout:  timeout,
+		logger:   logger,
+		errs:     make(map[string]error),
+	}
+	// до первой проверки сервис не готов принимать запросы.
+	for _, name := range hc.names() {
+		hc.server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return hc
+}
+
+// Register регистрация на сервере, один HealthChecker можно зарегистрировать на нескольких серверах.
+func (hc *HealthChecker) Register(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, hc.server)
+}
+
+// Start первая проверка выполняется сразу, далее - в фоне до отмены ctx или Shutdown.
+func (hc *HealthChecker) Start(ctx context.Context) {
+	hc.Check(ctx)
+	go func() {
+		ticker := time.NewTicker(hc.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				hc.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Check однократная проверка всех зависимостей и обновление статусов.
+func (hc *HealthChecker) Check(ctx context.Context) {
+	healthy := true
+	for name, check := range hc.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, hc.timeout)
+		err := check(checkCtx)
+		cancel()
+		hc.setStatus(name, err)
+		healthy = healthy && err == nil
+	}
+	status := healthpb.HealthCheckResponse_SERVING
+	if !healthy {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if hc.stopped {
+		return
+	}
+	hc.server.SetServingStatus("", status)
+	for _, service := range hc.services {
+		hc.server.SetServingStatus(service, status)
+	}
+}
+
+// setStatus статус зависимости, в лог пишется только смена состояния.
+func (hc *HealthChecker) setStatus(name string, err error) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if hc.stopped {
+		return
+	}
+	prev, seen := hc.errs[name]
+	hc.errs[name] = err
+	switch {
+	case err != nil && (prev == nil || !seen):
+		hc.logger.Error("health: %s is unavailable: %s", name, err.Error())
+	case err == nil && prev != nil:
+		hc.logger.Info("health: %s is available again", name)
+	}
+	status := healthpb.HealthCheckResponse_SERVING
+	if err != nil {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	hc.server.SetServingStatus(name, status)
+}
+
+// Shutdown все сервисы переводятся в NOT_SERVING и больше не меняют статус. Сигнатура closer.CloseFunc.
+func (hc *HealthChecker) Shutdown(context.Context) error {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.stopped = true
+	hc.server.Shutdown()
+	return nil
+}
+
+// names общий статус, сервисы API и зависимости.
+func (hc *HealthChecker) names() []string {
+	names := append([]string{""}, hc.services...)
+	deps := make([]string, 0, len(hc.checks))
+	for name := range hc.checks {
+		deps = append(deps, name)
+	}
+	sort.Strings(deps)
+	return append(names, deps...)
+}
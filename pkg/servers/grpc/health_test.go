@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealthChecker(t *testing.T) {
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+
+	var storageDown atomic.Bool
+	checks := map[string]HealthCheck{
+		"storage": func(context.Context) error {
+			if storageDown.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+		// проверка, которая не укладывается в таймаут.
+		"limiter": func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	hc := NewHealthChecker(checks, []string{"brutefp.Permit"}, time.Hour, 10*time.Millisecond, log)
+	ctx := context.Background()
+	status := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		res, err := hc.server.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return res.GetStatus()
+	}
+
+	// до первой проверки никто не готов.
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(""))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status("storage"))
+
+	hc.Check(ctx)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, status("storage"))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status("limiter"))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(""))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status("brutefp.Permit"))
+
+	delete(checks, "limiter")
+	hc.Check(ctx)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, status(""))
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, status("brutefp.Permit"))
+
+	storageDown.Store(true)
+	hc.Check(ctx)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status("storage"))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(""))
+
+	storageDown.Store(false)
+	hc.Check(ctx)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, status(""))
+
+	// после Shutdown статус не возвращается в SERVING.
+	require.NoError(t, hc.Shutdown(ctx))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(""))
+	hc.Check(ctx)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(""))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status("storage"))
+}
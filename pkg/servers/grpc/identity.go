@@ -0,0 +1,22 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/vitermakov/otusgo-final/pkg/servers"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// IdentityFromContext клиент запроса по проверенному сертификату соединения (mTLS).
+func IdentityFromContext(ctx context.Context) (*servers.ClientIdentity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, false
+	}
+	return servers.IdentityFromTLS(&info.State)
+}
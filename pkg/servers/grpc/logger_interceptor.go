@@ -41,3 +41,26 @@ func (i *LoggerInterceptor) Unary() grpc.UnaryServerInterceptor {
 		return resp, err
 	}
 }
+
+func (i *LoggerInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		timeStart := time.Now()
+		meta, ok := metadata.FromIncomingContext(stream.Context())
+		err := handler(srv, stream)
+		ua := ""
+		if ok {
+			ua = strings.Join(meta.Get("user-agent"), " ")
+		}
+		i.logger.Info(
+			fmt.Sprintf(
+				"Stream: %s\tDuration: %s\tError: %v\tUser-Agent: \"%s\"", info.FullMethod, time.Since(timeStart).String(), err, ua,
+			),
+		)
+		return err
+	}
+}
@@ -0,0 +1,94 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	grpcTypeUnary  = "unary"
+	grpcTypeStream = "stream"
+)
+
+// MetricsInterceptor число вызовов по коду ответа и время обработки каждого метода.
+// Имена метрик как в go-grpc-prometheus, чтобы подходили готовые дашборды.
+type MetricsInterceptor struct {
+	handled  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewMetricsInterceptor() *MetricsInterceptor {
+	return &MetricsInterceptor{
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Total number of RPCs completed on the server, regardless of success or failure.",
+		}, []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "grpc_server_handling_seconds",
+			Help: "Histogram of response latency (seconds) of gRPC that had been " +
+				"application-level handled by the server.",
+			// CheckQuery отвечает за миллисекунды, экспорт и импорт списков - за секунды.
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+	}
+}
+
+// Describe реализация prometheus.Collector.
+func (i *MetricsInterceptor) Describe(ch chan<- *prometheus.Desc) {
+	i.handled.Describe(ch)
+	i.duration.Describe(ch)
+}
+
+// Collect реализация prometheus.Collector.
+func (i *MetricsInterceptor) Collect(ch chan<- prometheus.Metric) {
+	i.handled.Collect(ch)
+	i.duration.Collect(ch)
+}
+
+func (i *MetricsInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		timeStart := time.Now()
+		resp, err := handler(ctx, req)
+		i.observe(grpcTypeUnary, info.FullMethod, timeStart, err)
+		return resp, err
+	}
+}
+
+func (i *MetricsInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		timeStart := time.Now()
+		err := handler(srv, stream)
+		i.observe(grpcTypeStream, info.FullMethod, timeStart, err)
+		return err
+	}
+}
+
+func (i *MetricsInterceptor) observe(grpcType, fullMethod string, timeStart time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+	i.handled.WithLabelValues(grpcType, service, method, status.Code(err).String()).Inc()
+	i.duration.WithLabelValues(grpcType, service, method).Observe(time.Since(timeStart).Seconds())
+}
+
+// splitMethod "/package.Service/Method" на имя сервиса и метода.
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if pos := strings.LastIndex(fullMethod, "/"); pos >= 0 {
+		return fullMethod[:pos], fullMethod[pos+1:]
+	}
+	return "unknown", fullMethod
+}
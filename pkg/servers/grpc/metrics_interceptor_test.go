@@ -0,0 +1,38 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricsInterceptor(t *testing.T) {
+	metrics := NewMetricsInterceptor()
+	unary := metrics.Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: "/brutefp.Permit/CheckQuery"}
+	call := func(err error) {
+		_, _ = unary(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+			return nil, err
+		})
+	}
+	call(nil)
+	call(nil)
+	call(status.Error(codes.PermissionDenied, "denied"))
+
+	handled := func(code codes.Code) float64 {
+		counter := metrics.handled.WithLabelValues("unary", "brutefp.Permit", "CheckQuery", code.String())
+		return testutil.ToFloat64(counter)
+	}
+	require.Equal(t, 2.0, handled(codes.OK))
+	require.Equal(t, 1.0, handled(codes.PermissionDenied))
+	require.Equal(t, 1, testutil.CollectAndCount(metrics.duration))
+
+	service, method := splitMethod("/grpc.health.v1.Health/Check")
+	require.Equal(t, "grpc.health.v1.Health", service)
+	require.Equal(t, "Check", method)
+}
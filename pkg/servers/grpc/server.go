@@ -2,12 +2,11 @@ package grpc
 
 import (
 	"errors"
-	"net"
-	"strconv"
 
 	"github.com/vitermakov/otusgo-final/pkg/logger"
 	"github.com/vitermakov/otusgo-final/pkg/servers"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 type RegisterHandlerFunc func(s *grpc.Server)
@@ -19,12 +18,40 @@ type Server struct {
 	AuthService servers.AuthService
 }
 
-func NewServer(config servers.Config, authSrv servers.AuthService, logger logger.Logger) *Server {
-	unaryChain := grpc.ChainUnaryInterceptor(
-		NewLoggerInterceptor(logger).Unary(),
-		// NewAuthInterceptor(authSrv).Unary(),
+// NewServer при authSrv = nil авторизация не проверяется, при metrics = nil метрики не собираются.
+// TLS и журнал запросов включаются настройками config.
+func NewServer(
+	config servers.Config, authSrv servers.AuthService, roles servers.MethodRoles,
+	metrics *MetricsInterceptor, logger logger.Logger,
+) *Server {
+	var (
+		unary  []grpc.UnaryServerInterceptor
+		stream []grpc.StreamServerInterceptor
 	)
-	return &Server{Server: grpc.NewServer(unaryChain), config: config, Logger: logger, AuthService: authSrv}
+	// трассировка и метрики первыми, чтобы учитывались и отказы авторизации.
+	tracing := NewTracingInterceptor()
+	unary = append(unary, tracing.Unary())
+	stream = append(stream, tracing.Stream())
+	if metrics != nil {
+		unary = append(unary, metrics.Unary())
+		stream = append(stream, metrics.Stream())
+	}
+	if !config.IsQuiet() {
+		unary = append(unary, NewLoggerInterceptor(logger).Unary())
+		stream = append(stream, NewLoggerInterceptor(logger).Stream())
+	}
+	if authSrv != nil {
+		auth := NewAuthInterceptor(authSrv, roles)
+		unary = append(unary, auth.Unary())
+		stream = append(stream, auth.Stream())
+	}
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...)}
+	if config.TLS() != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config.TLS())))
+	}
+	return &Server{
+		Server: grpc.NewServer(opts...), config: config, Logger: logger, AuthService: authSrv,
+	}
 }
 
 func (s *Server) RegisterHandler(handlerFunc RegisterHandlerFunc) {
@@ -35,8 +62,7 @@ func (s *Server) RegisterHandler(handlerFunc RegisterHandlerFunc) {
 }
 
 func (s *Server) Start() error {
-	address := net.JoinHostPort(s.config.GetHost(), strconv.Itoa(s.config.GetPort()))
-	socket, err := net.Listen("tcp", address)
+	socket, err := s.config.Listen()
 	if err != nil {
 		return err
 	}
@@ -47,6 +73,11 @@ func (s *Server) Start() error {
 	return err
 }
 
+// Address адрес, на котором слушает сервер.
+func (s *Server) Address() string {
+	return s.config.Address()
+}
+
 func (s *Server) Stop() {
 	s.Server.GracefulStop()
 }
@@ -0,0 +1,24 @@
+package grpc
+
+import (
+	"context"
+)
+
+// TokenCredentials токен клиента в заголовке authorization каждого запроса.
+type TokenCredentials struct {
+	token      string
+	requireTLS bool
+}
+
+// NewTokenCredentials при requireTLS = false токен передается и без шифрования, например, на localhost.
+func NewTokenCredentials(token string, requireTLS bool) TokenCredentials {
+	return TokenCredentials{token: token, requireTLS: requireTLS}
+}
+
+func (c TokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+func (c TokenCredentials) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
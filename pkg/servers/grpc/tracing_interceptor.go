@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const tracerName = "github.com/vitermakov/otusgo-final/pkg/servers/grpc"
+
+// TracingInterceptor серверный спан на каждый вызов, продолжающий трассу клиента из метаданных
+// (traceparent). Провайдер и propagator - глобальные, без настройки трассировки спаны не пишутся.
+type TracingInterceptor struct{}
+
+func NewTracingInterceptor() *TracingInterceptor {
+	return &TracingInterceptor{}
+}
+
+func (i *TracingInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, span := i.start(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		i.end(span, err)
+		return resp, err
+	}
+}
+
+func (i *TracingInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, span := i.start(stream.Context(), info.FullMethod)
+		err := handler(srv, &ctxStream{ServerStream: stream, ctx: ctx})
+		i.end(span, err)
+		return err
+	}
+}
+
+func (i *TracingInterceptor) start(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	if meta, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(meta))
+	}
+	service, method := splitMethod(fullMethod)
+	return otel.Tracer(tracerName).Start(ctx, service+"/"+method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", method),
+		),
+	)
+}
+
+func (i *TracingInterceptor) end(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int64("rpc.grpc.status_code", int64(code)))
+	if code != codes.OK {
+		span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+	}
+	span.End()
+}
+
+// metadataCarrier метаданные GRPC как propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
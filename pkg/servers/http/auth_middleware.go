@@ -0,0 +1,60 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/vitermakov/otusgo-final/pkg/servers"
+	"github.com/vitermakov/otusgo-final/pkg/servers/http/rqres"
+)
+
+type AuthMiddleware struct {
+	authService servers.AuthService
+}
+
+func NewAuthMiddleware(authService servers.AuthService) *AuthMiddleware {
+	return &AuthMiddleware{authService: authService}
+}
+
+// Require маршрут доступен только ролям roles. Токен передается в заголовке Authorization,
+// без токена клиент авторизуется по сертификату, если сервис это умеет.
+func (m *AuthMiddleware) Require(roles []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			user *servers.AuthUser
+			err  error
+		)
+		token := servers.TokenFromHeader(r.Header.Get("Authorization"))
+		identity, hasCert := servers.IdentityFromTLS(r.TLS)
+		certAuth, canCert := m.authService.(servers.CertAuthService)
+		switch {
+		case token != "":
+			user, err = m.authService.Authorize(r.Context(), token)
+		case hasCert && canCert:
+			user, err = certAuth.AuthorizeCert(r.Context(), *identity)
+		default:
+			unauthorized(w, "authorization token is not provided")
+			return
+		}
+		if err != nil {
+			unauthorized(w, fmt.Sprintf("error authorize: %v", err))
+			return
+		}
+		if user == nil {
+			unauthorized(w, "authorization token or certificate is invalid")
+			return
+		}
+		if !servers.HasRole(roles, user.Role) {
+			rqres.WriteJSON(w, http.StatusForbidden, rqres.ErrorBody{
+				Message: fmt.Sprintf("role '%s' is not allowed to call %s %s", user.Role, r.Method, r.URL.Path),
+			})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(servers.ContextWithUser(r.Context(), user)))
+	})
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	rqres.WriteJSON(w, http.StatusUnauthorized, rqres.ErrorBody{Message: message})
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+)
+
+type LoggerMiddleware struct {
+	logger logger.Logger
+}
+
+func NewLoggerMiddleware(logger logger.Logger) *LoggerMiddleware {
+	return &LoggerMiddleware{logger: logger}
+}
+
+func (m *LoggerMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeStart := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		m.logger.Info(
+			fmt.Sprintf(
+				"Method: %s %s\tDuration: %s\tStatus: %d\tUser-Agent: \"%s\"",
+				r.Method, r.URL.Path, time.Since(timeStart).String(), rec.status, r.UserAgent(),
+			),
+		)
+	})
+}
+
+// statusRecorder запоминает код ответа для журнала.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,70 @@
+package rqres
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/vitermakov/otusgo-final/pkg/utils/errx"
+)
+
+// ErrorBody тело ответа с ошибкой, Code - код внутренней классификации для логических ошибок.
+type ErrorBody struct {
+	Code    int          `json:"code,omitempty"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+/*
+Соответствие видов ошибок errx HTTP статусам повторяет grpc/rqres.FromError.
+*/
+
+func FromError(err error) (int, ErrorBody) {
+	logErr := errx.Logic{}
+	if errors.As(err, &logErr) {
+		return http.StatusBadRequest, ErrorBody{Code: logErr.Code(), Message: logErr.Error()}
+	}
+	nfErr := errx.NotFound{}
+	if errors.As(err, &nfErr) {
+		return http.StatusNotFound, ErrorBody{Message: nfErr.Error()}
+	}
+	invErr := errx.Invalid{}
+	if errors.As(err, &invErr) {
+		body := ErrorBody{Message: invErr.Error()}
+		for _, named := range invErr.Errors() {
+			body.Fields = append(body.Fields, FieldError{Field: named.Field, Message: named.Err.Error()})
+		}
+		return http.StatusBadRequest, body
+	}
+	base := errx.Base{}
+	if errors.As(err, &base) {
+		switch base.Kind() {
+		case errx.TypePerms:
+			return http.StatusForbidden, ErrorBody{Message: base.Error()}
+		case errx.TypeFatal:
+			return http.StatusInternalServerError, ErrorBody{Message: base.Error()}
+		}
+	}
+	return http.StatusBadRequest, ErrorBody{Message: err.Error()}
+}
+
+// WriteJSON ответ с телом JSON, при nil body - только статус.
+func WriteJSON(w http.ResponseWriter, status int, body interface{}) {
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func WriteError(w http.ResponseWriter, err error) {
+	status, body := FromError(err)
+	WriteJSON(w, status, body)
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	"github.com/vitermakov/otusgo-final/pkg/servers"
+)
+
+const readHeaderTimeout = 10 * time.Second
+
+type Server struct {
+	*http.Server
+	config servers.Config
+	Logger logger.Logger
+}
+
+func NewServer(config servers.Config, handler http.Handler, logger logger.Logger) *Server {
+	if !config.IsQuiet() {
+		handler = NewLoggerMiddleware(logger).Handler(handler)
+	}
+	return &Server{
+		Server: &http.Server{
+			Addr:              config.Address(),
+			Handler:           handler,
+			ReadHeaderTimeout: readHeaderTimeout,
+			TLSConfig:         config.TLS(),
+		},
+		config: config,
+		Logger: logger,
+	}
+}
+
+func (s *Server) Start() error {
+	listener, err := s.config.Listen()
+	if err != nil {
+		return err
+	}
+	if s.config.TLS() != nil {
+		// сертификаты берутся из TLSConfig.
+		err = s.Server.ServeTLS(listener, "", "")
+	} else {
+		err = s.Server.Serve(listener)
+	}
+	if err == nil || errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.Server.Shutdown(ctx)
+}
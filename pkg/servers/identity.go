@@ -0,0 +1,42 @@
+package servers
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+)
+
+// ClientIdentity клиент, предъявивший проверенный сертификат (mTLS).
+type ClientIdentity struct {
+	CommonName string
+	DNSNames   []string
+	URIs       []string
+	// Fingerprint sha256 сертификата в hex
+	Fingerprint string
+}
+
+// CertAuthService авторизация по клиентскому сертификату, когда токен не передан.
+// Реализуется AuthService дополнительно.
+type CertAuthService interface {
+	// AuthorizeCert пользователь по сертификату, nil - если сертификат неизвестен.
+	AuthorizeCert(context.Context, ClientIdentity) (*AuthUser, error)
+}
+
+// IdentityFromTLS клиент из проверенной цепочки сертификатов соединения.
+func IdentityFromTLS(state *tls.ConnectionState) (*ClientIdentity, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	cert := state.VerifiedChains[0][0]
+	sum := sha256.Sum256(cert.Raw)
+	identity := &ClientIdentity{
+		CommonName:  cert.Subject.CommonName,
+		DNSNames:    cert.DNSNames,
+		Fingerprint: hex.EncodeToString(sum[:]),
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity, true
+}
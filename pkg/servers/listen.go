@@ -0,0 +1,87 @@
+package servers
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// socketMode доступ к unix-сокету только владельцу и группе.
+const socketMode = 0o660
+
+var ErrSocketBusy = errors.New("socket path is not a socket")
+
+// Address адрес для журнала: host:port или путь к сокету.
+func (cfg Config) Address() string {
+	if cfg.socket != "" {
+		return "unix:" + cfg.socket
+	}
+	return net.JoinHostPort(cfg.GetHost(), strconv.Itoa(cfg.GetPort()))
+}
+
+// Listen слушает TCP или unix-сокет. Сокет, оставшийся после аварийной остановки, удаляется,
+// файл другого типа по тому же пути не трогается.
+func (cfg Config) Listen() (net.Listener, error) {
+	if cfg.socket == "" {
+		return net.Listen("tcp", cfg.Address())
+	}
+	info, err := os.Lstat(cfg.socket)
+	switch {
+	case err == nil && info.Mode()&fs.ModeSocket == 0:
+		return nil, fmt.Errorf("%s: %w", cfg.socket, ErrSocketBusy)
+	case err == nil:
+		if err := os.Remove(cfg.socket); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+	return listenSocket(cfg.socket)
+}
+
+// listenSocket сокет создается во временном каталоге с правами 0700 рядом с path и переносится
+// на место только после смены прав: до этого подключиться к нему может лишь владелец процесса.
+// umask не меняется - он общий для всех горутин процесса.
+func listenSocket(path string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".sock-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	tmp := filepath.Join(dir, "s")
+	listener, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	// после переноса сокет по пути tmp не существует, удаляет его socketListener.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(tmp, socketMode); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return socketListener{Listener: listener, path: path}, nil
+}
+
+// socketListener удаляет файл сокета при закрытии.
+type socketListener struct {
+	net.Listener
+	path string
+}
+
+func (sl socketListener) Close() error {
+	err := sl.Listener.Close()
+	if rmErr := os.Remove(sl.path); err == nil && !errors.Is(rmErr, fs.ErrNotExist) {
+		err = rmErr
+	}
+	return err
+}
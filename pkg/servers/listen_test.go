@@ -0,0 +1,45 @@
+package servers
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenSocket(t *testing.T) {
+	dir := t.TempDir()
+	config := NewConfig("", 0, false).WithSocket(filepath.Join(dir, "admin.sock"))
+	require.Equal(t, "unix:"+config.Socket(), config.Address())
+
+	// сокет, оставшийся от упавшего процесса.
+	stale, err := net.Listen("unix", config.Socket())
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	listener, err := config.Listen()
+	require.NoError(t, err)
+	info, err := os.Stat(config.Socket())
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(socketMode), info.Mode().Perm())
+	conn, err := net.Dial("unix", config.Socket())
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	require.NoError(t, listener.Close())
+	_, err = os.Stat(config.Socket())
+	require.ErrorIs(t, err, os.ErrNotExist)
+	// временный каталог удален.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	notSocket := config.WithSocket(filepath.Join(dir, "config.json"))
+	require.NoError(t, os.WriteFile(notSocket.Socket(), []byte("{}"), 0o600))
+	_, err = notSocket.Listen()
+	require.ErrorIs(t, err, ErrSocketBusy)
+	_, err = os.Stat(notSocket.Socket())
+	require.NoError(t, err)
+}
@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+)
+
+const tracesPath = "/v1/traces"
+
+var ErrEndpoint = errors.New("endpoint must be http(s)://host:port")
+
+// NewOTLPExporter экспорт по OTLP/HTTP (protobuf, коллектор принимает его на порту 4318).
+// endpoint - адрес коллектора вида http://collector:4318, путь /v1/traces добавляется,
+// headers - например, ключ доступа облачного коллектора.
+func NewOTLPExporter(endpoint string, headers map[string]string, timeout time.Duration) (*otlptrace.Exporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("otlp '%s': %w", endpoint, ErrEndpoint)
+	}
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(u.Host),
+		otlptracehttp.WithURLPath(strings.TrimRight(u.Path, "/") + tracesPath),
+		otlptracehttp.WithHeaders(headers),
+		otlptracehttp.WithTimeout(timeout),
+	}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	// HTTP-клиент не устанавливает соединение при запуске, контекст здесь не используется.
+	return otlptracehttp.New(context.Background(), opts...)
+}
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	requests := make(chan *coltracepb.ExportTraceServiceRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/otel"+tracesPath || r.Header.Get("X-Api-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		req := &coltracepb.ExportTraceServiceRequest{}
+		if err == nil {
+			err = proto.Unmarshal(body, req)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requests <- req
+	}))
+	defer collector.Close()
+
+	exporter, err := NewOTLPExporter(collector.URL+"/otel/", map[string]string{"X-Api-Key": "secret"}, time.Second)
+	require.NoError(t, err)
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceNameKey.String("brutefp"))),
+	)
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	_, child := provider.Tracer("test").Start(ctx, "child", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int64("count", 3)))
+	SpanError(child, errors.New("boom"))
+	child.End()
+
+	req := <-requests
+	require.Len(t, req.ResourceSpans, 1)
+	require.Equal(t, "service.name", req.ResourceSpans[0].Resource.Attributes[0].Key)
+	require.Equal(t, "brutefp", req.ResourceSpans[0].Resource.Attributes[0].Value.GetStringValue())
+	require.Equal(t, "test", req.ResourceSpans[0].ScopeSpans[0].Scope.Name)
+	span := req.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	require.Equal(t, "child", span.Name)
+	require.Equal(t, tracepb.Span_SPAN_KIND_CLIENT, span.Kind)
+	traceID, spanID := parent.SpanContext().TraceID(), parent.SpanContext().SpanID()
+	require.Equal(t, traceID[:], span.TraceId)
+	require.Equal(t, spanID[:], span.ParentSpanId)
+	require.Equal(t, tracepb.Status_STATUS_CODE_ERROR, span.Status.Code)
+	require.Equal(t, "boom", span.Status.Message)
+	require.Equal(t, int64(3), span.Attributes[0].Value.GetIntValue())
+
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	for _, endpoint := range []string{"127.0.0.1:4318", "grpc://collector:4317", "http://"} {
+		_, err := NewOTLPExporter(endpoint, nil, time.Second)
+		require.ErrorIs(t, err, ErrEndpoint, endpoint)
+	}
+}
@@ -0,0 +1,46 @@
+// Package tracing трассировка OpenTelemetry: глобальный провайдер, экспорт в OTLP и
+// распространение контекста в формате W3C Trace Context.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Propagator W3C traceparent/tracestate и baggage.
+var Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Setup глобальный провайдер со спанами сервиса serviceName. Доля sampleRatio новых трасс записывается,
+// для продолженных решение принимает клиент. Возвращает функцию выгрузки оставшихся спанов.
+func Setup(serviceName string, sampleRatio float64, exporter sdktrace.SpanExporter) func(context.Context) error {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(Propagator)
+	return provider.Shutdown
+}
+
+// Tracer трассировщик пакета из глобального провайдера. Можно получать до Setup:
+// глобальный провайдер переключит его на настоящий, до этого спаны не записываются.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
+
+// SpanError отметка ошибки в спане, nil игнорируется.
+func SpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -15,9 +15,16 @@ type CloseFunc func(ctx context.Context) error
 
 type Closer struct {
 	mu        sync.Mutex
+	first     []namedFunc
+	last      []namedFunc
 	closeFunc map[string]CloseFunc
 }
 
+type namedFunc struct {
+	name      string
+	closeFunc CloseFunc
+}
+
 func NewCloser() *Closer {
 	return &Closer{closeFunc: make(map[string]CloseFunc)}
 }
@@ -31,6 +38,28 @@ func (c *Closer) Register(name string, closeFunc CloseFunc) {
 	c.closeFunc[name] = closeFunc
 }
 
+// RegisterFirst функции вызываются в порядке регистрации до всех остальных, например, перевод
+// health-check в NOT_SERVING, чтобы балансировщик перестал присылать запросы до остановки серверов.
+func (c *Closer) RegisterFirst(name string, closeFunc CloseFunc) {
+	if closeFunc == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.first = append(c.first, namedFunc{name: name, closeFunc: closeFunc})
+}
+
+// RegisterLast функции вызываются в порядке регистрации после всех остальных, например, выгрузка
+// спанов, которые серверы записывают до самой остановки.
+func (c *Closer) RegisterLast(name string, closeFunc CloseFunc) {
+	if closeFunc == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.last = append(c.last, namedFunc{name: name, closeFunc: closeFunc})
+}
+
 func (c *Closer) Close(ctx context.Context, logger logger.Logger) {
 	complete := make(chan struct{}, 1)
 	c.mu.Lock()
@@ -38,12 +67,14 @@ func (c *Closer) Close(ctx context.Context, logger logger.Logger) {
 
 	go func() {
 		defer close(complete)
+		for _, first := range c.first {
+			closeOne(ctx, logger, first.name, first.closeFunc)
+		}
 		for name, closer := range c.closeFunc {
-			logger.Info("%s: closing", name)
-			if err := closer(ctx); err != nil {
-				logger.Error("error closing %s: %s", name, err.Error())
-			}
-			logger.Info("%s: closed successfully", name)
+			closeOne(ctx, logger, name, closer)
+		}
+		for _, last := range c.last {
+			closeOne(ctx, logger, last.name, last.closeFunc)
 		}
 	}()
 
@@ -55,3 +86,11 @@ func (c *Closer) Close(ctx context.Context, logger logger.Logger) {
 		logger.Error("closer exited by timeout")
 	}
 }
+
+func closeOne(ctx context.Context, logger logger.Logger, name string, closer CloseFunc) {
+	logger.Info("%s: closing", name)
+	if err := closer(ctx); err != nil {
+		logger.Error("error closing %s: %s", name, err.Error())
+	}
+	logger.Info("%s: closed successfully", name)
+}
@@ -0,0 +1,365 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leporo/sqlf"
+)
+
+/*
+Применение SQL-миграций в формате goose из fs.FS (обычно embed.FS), без внешней утилиты.
+Версии хранятся в таблице goose_db_version той же структуры, что создает goose,
+поэтому базы, которые мигрировались контейнером с goose, продолжают обслуживаться этим пакетом и наоборот.
+Поддерживаются только SQL-миграции; секция целиком выполняется одним запросом,
+в транзакции вместе с записью версии, если в файле нет "-- +goose NO TRANSACTION".
+В PostgreSQL Up и Down выполняются под advisory-блокировкой: несколько экземпляров,
+запущенных одновременно с автомиграцией, применяют миграции по очереди.
+*/
+
+const (
+	versionTable = "goose_db_version"
+
+	markerUp   = "-- +goose Up"
+	markerDown = "-- +goose Down"
+	markerNoTx = "-- +goose NO TRANSACTION"
+
+	// lockID ключ advisory-блокировки миграций, общий для всех экземпляров.
+	lockID = 7206348190215117243
+)
+
+var (
+	ErrFileName       = errors.New("migration file name must start with a numeric version")
+	ErrNoUpSection    = errors.New("migration has no '-- +goose Up' section")
+	ErrVersionDup     = errors.New("duplicate migration version")
+	ErrNothingToDown  = errors.New("no applied migrations")
+	ErrUnknownApplied = errors.New("applied migration not found in sources")
+)
+
+// Dialect особенности хранилища версий в конкретной СУБД.
+type Dialect struct {
+	builder     *sqlf.Dialect
+	createTable string
+	// lock, unlock захват и освобождение блокировки миграций на время Up и Down, пустые - без блокировки.
+	lock, unlock string
+}
+
+var (
+	Postgres = Dialect{
+		builder: sqlf.PostgreSQL,
+		createTable: `CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+			id serial NOT NULL,
+			version_id bigint NOT NULL,
+			is_applied boolean NOT NULL,
+			tstamp timestamp NULL DEFAULT now(),
+			PRIMARY KEY (id)
+		)`,
+		lock:   `SELECT pg_advisory_lock($1)`,
+		unlock: `SELECT pg_advisory_unlock($1)`,
+	}
+	SQLite = Dialect{
+		builder: sqlf.NoDialect,
+		createTable: `CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			version_id INTEGER NOT NULL,
+			is_applied INTEGER NOT NULL,
+			tstamp TIMESTAMP DEFAULT (datetime('now'))
+		)`,
+	}
+)
+
+// Migration файл миграции.
+type Migration struct {
+	Version int64
+	Name    string
+	up      string
+	down    string
+	noTx    bool
+}
+
+// Status состояние миграции, AppliedAt нулевое у неприменённой.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator применение миграций из fsys (*.sql в корне) к базе db.
+type Migrator struct {
+	db      *sql.DB
+	fsys    fs.FS
+	dialect Dialect
+}
+
+func New(db *sql.DB, fsys fs.FS, dialect Dialect) *Migrator {
+	return &Migrator{db: db, fsys: fsys, dialect: dialect}
+}
+
+// Up применяет все неприменённые миграции по возрастанию версий, возвращает примененные.
+func (m *Migrator) Up(ctx context.Context) (applied []Migration, err error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if unlockErr := unlock(); err == nil {
+			err = unlockErr
+		}
+	}()
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied = make([]Migration, 0)
+	for _, status := range statuses {
+		if status.Applied {
+			continue
+		}
+		if err := m.apply(ctx, status.Migration, true); err != nil {
+			return applied, fmt.Errorf("%s: %w", status.Name, err)
+		}
+		applied = append(applied, status.Migration)
+	}
+	return applied, nil
+}
+
+// Down откатывает последнюю примененную миграцию.
+func (m *Migrator) Down(ctx context.Context) (_ Migration, err error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return Migration{}, err
+	}
+	defer func() {
+		if unlockErr := unlock(); err == nil {
+			err = unlockErr
+		}
+	}()
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return Migration{}, err
+	}
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if !statuses[i].Applied {
+			continue
+		}
+		if err := m.apply(ctx, statuses[i].Migration, false); err != nil {
+			return Migration{}, fmt.Errorf("%s: %w", statuses[i].Name, err)
+		}
+		return statuses[i].Migration, nil
+	}
+	return Migration{}, ErrNothingToDown
+}
+
+// Status все миграции по возрастанию версий с отметкой о применении.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, err := m.collect()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(migrations))
+	for _, migration := range migrations {
+		status := Status{Migration: migration}
+		if at, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = at
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	// в базе версия новее бинарника - откатывать или накатывать поверх нее нельзя.
+	for version := range applied {
+		return nil, fmt.Errorf("version %d: %w", version, ErrUnknownApplied)
+	}
+	return statuses, nil
+}
+
+// lock блокировка миграций. Advisory-блокировка принадлежит сессии, поэтому удерживается
+// отдельным соединением до вызова возвращенной функции; сами миграции идут через пул.
+func (m *Migrator) lock(ctx context.Context) (func() error, error) {
+	if m.dialect.lock == "" {
+		return func() error { return nil }, nil
+	}
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, m.dialect.lock, lockID); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("migration lock: %w", err)
+	}
+	return func() error {
+		// ctx может быть уже отменен, а соединение с неснятой блокировкой вернулось бы в пул.
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := conn.ExecContext(unlockCtx, m.dialect.unlock, lockID)
+		if err != nil {
+			// соединение закрывается вместе с сессией, блокировка снимается сервером.
+			_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		if closeErr := conn.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("migration unlock: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+func (m *Migrator) collect() ([]Migration, error) {
+	files, err := fs.Glob(m.fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	migrations := make([]Migration, 0, len(files))
+	versions := make(map[int64]string, len(files))
+	for _, file := range files {
+		name := path.Base(file)
+		version, err := strconv.ParseInt(strings.SplitN(name, "_", 2)[0], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("%s: %w", name, ErrFileName)
+		}
+		if other, ok := versions[version]; ok {
+			return nil, fmt.Errorf("%s, %s: %w", other, name, ErrVersionDup)
+		}
+		versions[version] = name
+		content, err := fs.ReadFile(m.fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		migration, err := parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		migration.Version = version
+		migration.Name = name
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func parse(content string) (Migration, error) {
+	var migration Migration
+	upIdx := strings.Index(content, markerUp)
+	if upIdx < 0 {
+		return migration, ErrNoUpSection
+	}
+	migration.noTx = strings.Contains(content, markerNoTx)
+	up := content[upIdx+len(markerUp):]
+	if downIdx := strings.Index(up, markerDown); downIdx >= 0 {
+		migration.down = up[downIdx+len(markerDown):]
+		up = up[:downIdx]
+	}
+	migration.up = up
+	return migration, nil
+}
+
+// applied версии, последняя запись по которым отмечает применение, и время применения.
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	stmt := m.dialect.builder.From(versionTable).
+		Select("version_id, is_applied, tstamp").
+		OrderBy("id DESC")
+	rows, err := m.db.QueryContext(ctx, stmt.String(), stmt.Args()...)
+	stmt.Close()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	seen := make(map[int64]struct{})
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			version   int64
+			isApplied bool
+			tstamp    sql.NullTime
+		)
+		if err := rows.Scan(&version, &isApplied, &tstamp); err != nil {
+			return nil, err
+		}
+		// версия 0 - служебная запись goose о создании таблицы.
+		if _, ok := seen[version]; ok || version == 0 {
+			continue
+		}
+		seen[version] = struct{}{}
+		if isApplied {
+			applied[version] = tstamp.Time
+		}
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	var count int
+	err := m.dialect.builder.From(versionTable).
+		Select("count(*)").To(&count).
+		QueryRowAndClose(ctx, m.db)
+	if err == nil {
+		return nil
+	}
+	if _, err := m.db.ExecContext(ctx, m.dialect.createTable); err != nil {
+		return err
+	}
+	_, err = m.dialect.builder.InsertInto(versionTable).
+		Set("version_id", 0).
+		Set("is_applied", true).
+		ExecAndClose(ctx, m.db)
+	return err
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration, up bool) error {
+	query := migration.up
+	record := m.dialect.builder.InsertInto(versionTable).
+		Set("version_id", migration.Version).
+		Set("is_applied", true)
+	if !up {
+		query = migration.down
+		record = m.dialect.builder.DeleteFrom(versionTable).
+			Where("version_id = ?", migration.Version)
+	}
+	defer record.Close()
+
+	if migration.noTx {
+		if _, err := m.db.ExecContext(ctx, query); err != nil {
+			return err
+		}
+		_, err := record.Exec(ctx, m.db)
+		return err
+	}
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// после Commit вернет sql.ErrTxDone, это нормально.
+		_ = tx.Rollback()
+	}()
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+	if _, err := record.Exec(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
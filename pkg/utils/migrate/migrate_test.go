@@ -0,0 +1,154 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	_ "github.com/jackc/pgx/v4/stdlib" //nolint:blank-imports // регистрация драйвера pgx
+	_ "github.com/mattn/go-sqlite3"    //nolint:blank-imports // регистрация драйвера sqlite3
+	"github.com/stretchr/testify/require"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return db
+}
+
+func sqlFile(up, down string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte("-- +goose Up\n" + up + "\n\n-- +goose Down\n" + down + "\n")}
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	fsys := fstest.MapFS{
+		"20230101000000_first.sql":  sqlFile("CREATE TABLE first (id INTEGER);", "DROP TABLE first;"),
+		"20230102000000_second.sql": sqlFile("CREATE TABLE second (id INTEGER);", "DROP TABLE second;"),
+		"README.md":                 &fstest.MapFile{Data: []byte("not a migration")},
+	}
+
+	t.Run("up, status, down", func(t *testing.T) {
+		db := openDB(t)
+		migrator := New(db, fsys, SQLite)
+
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		require.Len(t, statuses, 2)
+		require.False(t, statuses[0].Applied)
+		require.Equal(t, int64(20230101000000), statuses[0].Version)
+
+		applied, err := migrator.Up(ctx)
+		require.NoError(t, err)
+		require.Len(t, applied, 2)
+		require.Equal(t, "20230102000000_second.sql", applied[1].Name)
+
+		statuses, err = migrator.Status(ctx)
+		require.NoError(t, err)
+		require.True(t, statuses[1].Applied)
+		require.False(t, statuses[1].AppliedAt.IsZero())
+
+		down, err := migrator.Down(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(20230102000000), down.Version)
+		_, err = db.ExecContext(ctx, "SELECT * FROM second")
+		require.Error(t, err)
+		_, err = db.ExecContext(ctx, "SELECT * FROM first")
+		require.NoError(t, err)
+
+		applied, err = migrator.Up(ctx)
+		require.NoError(t, err)
+		require.Len(t, applied, 1)
+	})
+	t.Run("database migrated by goose", func(t *testing.T) {
+		db := openDB(t)
+		_, err := db.ExecContext(ctx, SQLite.createTable)
+		require.NoError(t, err)
+		// так goose записывает создание таблицы, применение первой миграции, ее откат и повторное применение.
+		_, err = db.ExecContext(ctx, `CREATE TABLE first (id INTEGER);
+			INSERT INTO goose_db_version (version_id, is_applied) VALUES
+			(0, 1), (20230101000000, 1), (20230101000000, 0), (20230101000000, 1)`)
+		require.NoError(t, err)
+
+		applied, err := New(db, fsys, SQLite).Up(ctx)
+		require.NoError(t, err)
+		require.Len(t, applied, 1)
+		require.Equal(t, int64(20230102000000), applied[0].Version)
+
+		// версия из базы, которой нет в бинарнике.
+		_, err = New(db, fstest.MapFS{"20230101000000_first.sql": fsys["20230101000000_first.sql"]}, SQLite).Status(ctx)
+		require.ErrorIs(t, err, ErrUnknownApplied)
+	})
+	t.Run("failed migration is rolled back", func(t *testing.T) {
+		db := openDB(t)
+		broken := fstest.MapFS{
+			"1_ok.sql":     sqlFile("CREATE TABLE ok (id INTEGER);", "DROP TABLE ok;"),
+			"2_broken.sql": sqlFile("CREATE TABLE half (id INTEGER); INSERT INTO missing VALUES (1);", ""),
+		}
+		migrator := New(db, broken, SQLite)
+		applied, err := migrator.Up(ctx)
+		require.ErrorContains(t, err, "2_broken.sql")
+		require.Len(t, applied, 1)
+
+		_, err = db.ExecContext(ctx, "SELECT * FROM half")
+		require.Error(t, err)
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		require.True(t, statuses[0].Applied)
+		require.False(t, statuses[1].Applied)
+	})
+	t.Run("wrong files", func(t *testing.T) {
+		db := openDB(t)
+		_, err := New(db, fstest.MapFS{"init.sql": sqlFile("", "")}, SQLite).Up(ctx)
+		require.ErrorIs(t, err, ErrFileName)
+		_, err = New(db, fstest.MapFS{"1_a.sql": sqlFile("", ""), "01_b.sql": sqlFile("", "")}, SQLite).Up(ctx)
+		require.ErrorIs(t, err, ErrVersionDup)
+		_, err = New(db, fstest.MapFS{"1_a.sql": {Data: []byte("CREATE TABLE a (id INTEGER);")}}, SQLite).Up(ctx)
+		require.ErrorIs(t, err, ErrNoUpSection)
+		_, err = New(db, fstest.MapFS{}, SQLite).Down(ctx)
+		require.ErrorIs(t, err, ErrNothingToDown)
+	})
+}
+
+func TestMigratorPostgresLock(t *testing.T) {
+	dsn := os.Getenv("BRUTEFP_TEST_PGSQL_DSN")
+	if dsn == "" {
+		t.Skip("BRUTEFP_TEST_PGSQL_DSN is not set")
+	}
+	ctx := context.Background()
+	db, err := sql.Open("pgx", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	migrator := New(db, fstest.MapFS{}, Postgres)
+
+	// пока блокировку держит другой экземпляр, Up ждет ее.
+	unlock, err := migrator.lock(ctx)
+	require.NoError(t, err)
+	waitCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	_, err = migrator.Up(waitCtx)
+	require.ErrorContains(t, err, "migration lock")
+	require.NoError(t, unlock())
+
+	// после unlock блокировка свободна для любой сессии.
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	var locked bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", int64(lockID)).Scan(&locked)
+	require.NoError(t, err)
+	require.True(t, locked)
+	_, err = conn.ExecContext(ctx, Postgres.unlock, int64(lockID))
+	require.NoError(t, err)
+}
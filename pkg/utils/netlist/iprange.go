@@ -0,0 +1,110 @@
+package netlist
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"net"
+	"strings"
+)
+
+var (
+	ErrRangeFormat  = errors.New("range must be in form start-end")
+	ErrRangeFamily  = errors.New("range start and end must be of the same address family")
+	ErrRangeReverse = errors.New("range start must not be greater than end")
+)
+
+// rangeSeparators дефис и короткое тире, в котором диапазоны часто приходят из документов.
+const rangeSeparators = "-–"
+
+// IPRange диапазон адресов от Start до End включительно.
+type IPRange struct {
+	Start net.IP
+	End   net.IP
+}
+
+// IsRange похожа ли строка на диапазон, а не на CIDR или IP.
+func IsRange(s string) bool {
+	return strings.ContainsAny(s, rangeSeparators)
+}
+
+// ParseRange разбирает диапазон вида 203.0.113.10-203.0.113.77.
+func ParseRange(s string) (IPRange, error) {
+	idx := strings.IndexAny(s, rangeSeparators)
+	if idx < 0 {
+		return IPRange{}, ErrRangeFormat
+	}
+	sep := strings.IndexAny(s[idx:], "0123456789abcdefABCDEF:")
+	if sep < 0 {
+		return IPRange{}, ErrRangeFormat
+	}
+	start := net.ParseIP(strings.TrimSpace(s[:idx]))
+	end := net.ParseIP(strings.TrimSpace(s[idx+sep:]))
+	if start == nil || end == nil {
+		return IPRange{}, ErrRangeFormat
+	}
+	start4, end4 := start.To4(), end.To4()
+	if (start4 == nil) != (end4 == nil) {
+		return IPRange{}, ErrRangeFamily
+	}
+	if start4 != nil {
+		start, end = start4, end4
+	}
+	if bytes.Compare(start, end) > 0 {
+		return IPRange{}, ErrRangeReverse
+	}
+	return IPRange{Start: start, End: end}, nil
+}
+
+// ParseNetOrRange сеть CIDR или диапазон start-end. Диапазон, в точности равный одной сети,
+// возвращается как сеть, иначе - пустая сеть и диапазон в каноническом виде.
+func ParseNetOrRange(s string) (net.IPNet, string, error) {
+	if IsRange(s) {
+		rng, err := ParseRange(s)
+		if err != nil {
+			return net.IPNet{}, "", err
+		}
+		if nets := rng.CIDRs(); len(nets) == 1 {
+			return nets[0], "", nil
+		}
+		return net.IPNet{}, rng.String(), nil
+	}
+	_, mask, err := net.ParseCIDR(s)
+	if err != nil {
+		return net.IPNet{}, "", err
+	}
+	return *mask, "", nil
+}
+
+func (r IPRange) String() string {
+	return r.Start.String() + "-" + r.End.String()
+}
+
+// CIDRs минимальный набор сетей, в точности покрывающий диапазон, по возрастанию адресов.
+func (r IPRange) CIDRs() []net.IPNet {
+	bits := len(r.Start) * 8
+	cur := new(big.Int).SetBytes(r.Start)
+	end := new(big.Int).SetBytes(r.End)
+	one := big.NewInt(1)
+
+	var nets []net.IPNet
+	for cur.Cmp(end) <= 0 {
+		// самый большой блок, выровненный по cur и не выходящий за end.
+		size := int(cur.TrailingZeroBits())
+		if cur.Sign() == 0 || size > bits {
+			size = bits
+		}
+		for ; size > 0; size-- {
+			last := new(big.Int).Lsh(one, uint(size))
+			last.Add(last, cur).Sub(last, one)
+			if last.Cmp(end) <= 0 {
+				break
+			}
+		}
+		ip := make(net.IP, len(r.Start))
+		cur.FillBytes(ip)
+		nets = append(nets, net.IPNet{IP: ip, Mask: net.CIDRMask(bits-size, bits)})
+		cur.Add(cur, new(big.Int).Lsh(one, uint(size)))
+	}
+	return nets
+}
@@ -0,0 +1,70 @@
+package netlist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPRange(t *testing.T) {
+	t.Run("minimal cover", func(t *testing.T) {
+		r, err := ParseRange("203.0.113.10 – 203.0.113.77")
+		require.NoError(t, err)
+		require.Equal(t, "203.0.113.10-203.0.113.77", r.String())
+
+		var nets []string
+		for _, ipNet := range r.CIDRs() {
+			nets = append(nets, ipNet.String())
+		}
+		require.Equal(t, []string{
+			"203.0.113.10/31", "203.0.113.12/30", "203.0.113.16/28", "203.0.113.32/27",
+			"203.0.113.64/29", "203.0.113.72/30", "203.0.113.76/31",
+		}, nets)
+	})
+
+	t.Run("aligned and edge ranges", func(t *testing.T) {
+		for src, expected := range map[string][]string{
+			"10.0.0.0-10.0.255.255":           {"10.0.0.0/16"},
+			"10.0.0.5-10.0.0.5":               {"10.0.0.5/32"},
+			"0.0.0.0-255.255.255.255":         {"0.0.0.0/0"},
+			"255.255.255.254-255.255.255.255": {"255.255.255.254/31"},
+			"2001:db8::-2001:db8::1:ffff":     {"2001:db8::/111"},
+		} {
+			r, err := ParseRange(src)
+			require.NoError(t, err, src)
+			var nets []string
+			for _, ipNet := range r.CIDRs() {
+				nets = append(nets, ipNet.String())
+			}
+			require.Equal(t, expected, nets, src)
+		}
+	})
+
+	t.Run("bad ranges", func(t *testing.T) {
+		_, err := ParseRange("10.0.0.9-10.0.0.1")
+		require.ErrorIs(t, err, ErrRangeReverse)
+		_, err = ParseRange("10.0.0.1-2001:db8::1")
+		require.ErrorIs(t, err, ErrRangeFamily)
+		_, err = ParseRange("10.0.0.1-")
+		require.ErrorIs(t, err, ErrRangeFormat)
+		require.False(t, IsRange("10.0.0.0/8"))
+	})
+	t.Run("net or range", func(t *testing.T) {
+		ipNet, rng, err := ParseNetOrRange("10.0.0.0-10.0.255.255")
+		require.NoError(t, err)
+		require.Equal(t, "10.0.0.0/16", ipNet.String())
+		require.Empty(t, rng)
+
+		ipNet, rng, err = ParseNetOrRange("10.0.0.1-10.0.0.6")
+		require.NoError(t, err)
+		require.Nil(t, ipNet.IP)
+		require.Equal(t, "10.0.0.1-10.0.0.6", rng)
+
+		ipNet, _, err = ParseNetOrRange("10.1.2.3/8")
+		require.NoError(t, err)
+		require.Equal(t, "10.0.0.0/8", ipNet.String())
+
+		_, _, err = ParseNetOrRange("10.1.2.3")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,174 @@
+package netlist
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+)
+
+/*
+Разбор простых списков сетей: одна сеть (CIDR или отдельный IP) на строку.
+Поддерживаются комментарии, принятые в netset-файлах FireHOL ("# ...") и в списках Spamhaus DROP ("; ...").
+Текст комментария в конце строки сохраняется в Entry.Comment.
+*/
+
+// Entry элемент списка.
+type Entry struct {
+	IPNet   net.IPNet
+	Comment string
+}
+
+// Parse разбирает список, пустые строки и строки-комментарии пропускаются.
+func Parse(r io.Reader) ([]Entry, error) {
+	var (
+		entries []Entry
+		lineNum int
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNum++
+		line, comment := splitComment(scanner.Text())
+		if line == "" {
+			continue
+		}
+		ipNet, err := ParseNet(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		entries = append(entries, Entry{IPNet: ipNet, Comment: comment})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Write записывает список в формате, который понимает Parse.
+func Write(w io.Writer, entries []Entry) error {
+	for _, entry := range entries {
+		line := entry.IPNet.String()
+		if entry.Comment != "" {
+			line += " # " + entry.Comment
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseNet разбирает CIDR, одиночный IP считается сетью из одного адреса.
+func ParseNet(s string) (net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return net.IPNet{}, err
+		}
+		return *ipNet, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return net.IPNet{}, &net.ParseError{Type: "IP address", Text: s}
+	}
+	return HostNet(ip), nil
+}
+
+// HostNet сеть из одного адреса ip, IPv4 в 4-байтовом виде.
+func HostNet(ip net.IP) net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		return net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// ContainsAny true, если сеть включает хотя бы один из адресов.
+func ContainsAny(ipNet net.IPNet, ips []net.IP) bool {
+	for _, ip := range ips {
+		if Contains(ipNet, HostNet(ip)) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitComment(line string) (string, string) {
+	var comment string
+	if pos := strings.IndexAny(line, "#;"); pos >= 0 {
+		comment = strings.TrimSpace(line[pos+1:])
+		line = line[:pos]
+	}
+	return strings.TrimSpace(line), comment
+}
+
+// Contains true, если сеть outer целиком включает сеть inner (в том числе совпадает с ней).
+func Contains(outer, inner net.IPNet) bool {
+	outerOnes, outerBits := outer.Mask.Size()
+	innerOnes, innerBits := inner.Mask.Size()
+	if outerBits != innerBits || outerOnes > innerOnes {
+		return false
+	}
+	return outer.Contains(inner.IP)
+}
+
+// Overlaps true, если у сетей есть общие адреса, т.е. одна включает другую.
+func Overlaps(a, b net.IPNet) bool {
+	return Contains(a, b) || Contains(b, a)
+}
+
+// Compact минимальный набор сетей, покрывающий в точности те же адреса: вложенные сети
+// удаляются, соседние сети одного размера объединяются. Результат отсортирован по адресам.
+func Compact(nets []net.IPNet) []net.IPNet {
+	sorted := make([]net.IPNet, 0, len(nets))
+	for _, ipNet := range nets {
+		if ip4 := ipNet.IP.To4(); ip4 != nil && len(ipNet.Mask) == net.IPv4len {
+			ipNet.IP = ip4
+		}
+		sorted = append(sorted, net.IPNet{IP: ipNet.IP.Mask(ipNet.Mask), Mask: ipNet.Mask})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i].IP) != len(sorted[j].IP) {
+			return len(sorted[i].IP) < len(sorted[j].IP)
+		}
+		if cmp := bytes.Compare(sorted[i].IP, sorted[j].IP); cmp != 0 {
+			return cmp < 0
+		}
+		iOnes, _ := sorted[i].Mask.Size()
+		jOnes, _ := sorted[j].Mask.Size()
+		return iOnes < jOnes
+	})
+
+	stack := make([]net.IPNet, 0, len(sorted))
+	for _, ipNet := range sorted {
+		if len(stack) > 0 && Contains(stack[len(stack)-1], ipNet) {
+			continue
+		}
+		stack = append(stack, ipNet)
+		// объединение с соседом может дать сеть, соседнюю с предыдущей в стеке.
+		for len(stack) > 1 {
+			parent, ok := siblingsParent(stack[len(stack)-2], stack[len(stack)-1])
+			if !ok {
+				break
+			}
+			stack = append(stack[:len(stack)-2], parent)
+		}
+	}
+	return stack
+}
+
+// siblingsParent родительская сеть, если a и b - две половины одной сети (a - младшая).
+func siblingsParent(a, b net.IPNet) (net.IPNet, bool) {
+	aOnes, aBits := a.Mask.Size()
+	bOnes, bBits := b.Mask.Size()
+	if aBits != bBits || aOnes != bOnes || aOnes == 0 {
+		return net.IPNet{}, false
+	}
+	mask := net.CIDRMask(aOnes-1, aBits)
+	if !a.IP.Mask(mask).Equal(a.IP) || !b.IP.Mask(mask).Equal(a.IP) {
+		return net.IPNet{}, false
+	}
+	return net.IPNet{IP: a.IP, Mask: mask}, true
+}
@@ -0,0 +1,86 @@
+package netlist
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("netset and drop formats", func(t *testing.T) {
+		src := `# FireHOL-like header
+;
+1.10.16.0/20 ; SBL256894
+192.168.1.10
+2001:db8::/32 # documentation
+
+10.0.0.0/8`
+		entries, err := Parse(strings.NewReader(src))
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+		require.Equal(t, "1.10.16.0/20", entries[0].IPNet.String())
+		require.Equal(t, "SBL256894", entries[0].Comment)
+		require.Equal(t, "192.168.1.10/32", entries[1].IPNet.String())
+		require.Equal(t, "2001:db8::/32", entries[2].IPNet.String())
+		require.Equal(t, "documentation", entries[2].Comment)
+		require.Equal(t, "10.0.0.0/8", entries[3].IPNet.String())
+	})
+
+	t.Run("bad line", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("10.0.0.0/8\n300.1.1.1\n"))
+		require.ErrorContains(t, err, "line 2")
+	})
+
+	t.Run("write and parse back", func(t *testing.T) {
+		entries, err := Parse(strings.NewReader("10.0.0.0/8 # office\n192.168.0.0/16\n"))
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, entries))
+		require.Equal(t, "10.0.0.0/8 # office\n192.168.0.0/16\n", buf.String())
+	})
+}
+
+func TestContains(t *testing.T) {
+	mustNet := func(s string) net.IPNet {
+		ipNet, err := ParseNet(s)
+		require.NoError(t, err)
+		return ipNet
+	}
+	require.True(t, Contains(mustNet("10.0.0.0/8"), mustNet("10.1.0.0/16")))
+	require.True(t, Contains(mustNet("10.0.0.0/8"), mustNet("10.0.0.0/8")))
+	require.True(t, Contains(mustNet("10.0.0.0/8"), mustNet("10.2.3.4")))
+	require.False(t, Contains(mustNet("10.1.0.0/16"), mustNet("10.0.0.0/8")))
+	require.False(t, Contains(mustNet("10.0.0.0/8"), mustNet("11.0.0.0/16")))
+	require.False(t, Contains(mustNet("::/0"), mustNet("10.0.0.0/8")))
+
+	require.True(t, Overlaps(mustNet("10.1.0.0/16"), mustNet("10.0.0.0/8")))
+	require.False(t, Overlaps(mustNet("10.1.0.0/16"), mustNet("10.2.0.0/16")))
+}
+
+func TestCompact(t *testing.T) {
+	compact := func(src ...string) []string {
+		nets := make([]net.IPNet, 0, len(src))
+		for _, s := range src {
+			ipNet, err := ParseNet(s)
+			require.NoError(t, err)
+			nets = append(nets, ipNet)
+		}
+		result := make([]string, 0)
+		for _, ipNet := range Compact(nets) {
+			result = append(result, ipNet.String())
+		}
+		return result
+	}
+	// 4 соседних /32 -> /30, вложенная сеть поглощается, смежные /25 -> /24 -> /23 с соседней /24.
+	require.Equal(t, []string{"10.0.0.0/30", "192.168.0.0/23"}, compact(
+		"10.0.0.3", "10.0.0.1", "10.0.0.0", "10.0.0.2",
+		"192.168.0.128/25", "192.168.0.0/25", "192.168.1.0/24", "192.168.1.7",
+	))
+	// несмежные половины разных родителей не объединяются.
+	require.Equal(t, []string{"10.0.0.1/32", "10.0.0.2/32"}, compact("10.0.0.2", "10.0.0.1"))
+	require.Equal(t, []string{"10.0.0.0/8", "2001:db8::/32"}, compact("2001:db8::/33", "10.0.0.0/8", "2001:db8:8000::/33"))
+	require.Empty(t, compact())
+}
@@ -0,0 +1,152 @@
+package tlsx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+)
+
+// defCheckInterval как часто при новых подключениях проверяется изменение файлов.
+const defCheckInterval = 10 * time.Second
+
+// Reloader сертификат сервера и CA клиентов, перечитываемые при изменении файлов без перезапуска.
+// Файлы проверяются при подключении клиентов не чаще checkInterval, если новые файлы не удалось
+// загрузить (например, сертификат уже обновлен, а ключ еще нет), используются прежние.
+type Reloader struct {
+	files         Files
+	minVersion    uint16
+	logger        logger.Logger
+	checkInterval time.Duration
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime map[string]time.Time
+	checked time.Time
+}
+
+func NewReloader(files Files, logger logger.Logger) (*Reloader, error) {
+	if files.CertFile == "" || files.KeyFile == "" {
+		return nil, ErrNoCertKey
+	}
+	minVersion, err := ParseVersion(files.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reloader{files: files, minVersion: minVersion, logger: logger, checkInterval: defCheckInterval}
+	modTime, err := r.modTimes()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ServerConfig настройки сервера, при заданном CAFile клиентский сертификат обязателен.
+// Конфигурация подключения строится заново для каждого клиента и берет протоколы ALPN
+// из возвращенной конфигурации (h2 для GRPC, http/1.1), а не из ее копий: credentials.NewTLS
+// добавляет h2 в свою копию, которую GetConfigForClient не видит.
+func (r *Reloader) ServerConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion:     r.minVersion,
+		GetCertificate: r.getCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.reloadIfChanged()
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		cfg := &tls.Config{
+			MinVersion:   r.minVersion,
+			Certificates: []tls.Certificate{*r.cert},
+			NextProtos:   base.NextProtos,
+		}
+		if r.pool != nil {
+			cfg.ClientCAs = r.pool
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		return cfg, nil
+	}
+	return base
+}
+
+func (r *Reloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.reloadIfChanged()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+func (r *Reloader) reloadIfChanged() {
+	r.mu.RLock()
+	due := time.Since(r.checked) >= r.checkInterval
+	r.mu.RUnlock()
+	if !due {
+		return
+	}
+	modTime, err := r.modTimes()
+	r.mu.Lock()
+	r.checked = time.Now()
+	changed := err == nil && r.changed(modTime)
+	r.mu.Unlock()
+	if err != nil {
+		r.logger.Error("TLS files check failed, keeping current certificate: %s", err.Error())
+		return
+	}
+	if !changed {
+		return
+	}
+	if err := r.load(modTime); err != nil {
+		r.logger.Error("TLS reload failed, keeping current certificate: %s", err.Error())
+		return
+	}
+	r.logger.Info("TLS certificate reloaded from %s", r.files.CertFile)
+}
+
+func (r *Reloader) load(modTime map[string]time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load server certificate: %w", err)
+	}
+	var pool *x509.CertPool
+	if r.files.CAFile != "" {
+		if pool, err = loadCA(r.files.CAFile); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	r.cert, r.pool, r.modTime = &cert, pool, modTime
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *Reloader) modTimes() (map[string]time.Time, error) {
+	modTime := make(map[string]time.Time, 3)
+	for _, fileName := range []string{r.files.CertFile, r.files.KeyFile, r.files.CAFile} {
+		if fileName == "" {
+			continue
+		}
+		info, err := os.Stat(fileName)
+		if err != nil {
+			return nil, err
+		}
+		modTime[fileName] = info.ModTime()
+	}
+	return modTime, nil
+}
+
+func (r *Reloader) changed(modTime map[string]time.Time) bool {
+	for fileName, mt := range modTime {
+		if !mt.Equal(r.modTime[fileName]) {
+			return true
+		}
+	}
+	return false
+}
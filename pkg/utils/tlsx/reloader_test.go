@@ -0,0 +1,134 @@
+package tlsx
+
+import (
+	"crypto/tls"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vitermakov/otusgo-final/pkg/logger"
+	"github.com/vitermakov/otusgo-final/pkg/utils/tlsx/tlsxtest"
+)
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	serverCA := tlsxtest.NewCA(t, dir, "server-ca")
+	clientCA := tlsxtest.NewCA(t, dir, "client-ca")
+	server := serverCA.Server(t, dir, "server-1")
+	client := clientCA.Client(t, dir, "php-login")
+
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+	reloader, err := NewReloader(Files{
+		CertFile: server.CertFile, KeyFile: server.KeyFile, CAFile: clientCA.File, MinVersion: "1.3",
+	}, log)
+	require.NoError(t, err)
+	reloader.checkInterval = 0
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", reloader.ServerConfig())
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				// рукопожатие выполняется при первом чтении/записи.
+				_ = conn.(*tls.Conn).Handshake()
+				_, _ = conn.Write([]byte{1})
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	dial := func(files Files) (string, error) {
+		files.CAFile = serverCA.File
+		cfg, err := ClientConfig(files)
+		require.NoError(t, err)
+		conn, err := tls.Dial("tcp", listener.Addr().String(), cfg)
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+		// при TLS 1.3 отказ в клиентском сертификате приходит при чтении.
+		if _, err := conn.Read(make([]byte, 1)); err != nil {
+			return "", err
+		}
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName, nil
+	}
+
+	t.Run("client certificate required", func(t *testing.T) {
+		_, err := dial(Files{})
+		require.Error(t, err)
+
+		cn, err := dial(Files{CertFile: client.CertFile, KeyFile: client.KeyFile})
+		require.NoError(t, err)
+		require.Equal(t, "server-1", cn)
+	})
+
+	t.Run("alpn h2 for grpc", func(t *testing.T) {
+		cfg, err := ClientConfig(Files{CertFile: client.CertFile, KeyFile: client.KeyFile, CAFile: serverCA.File})
+		require.NoError(t, err)
+		cfg.NextProtos = []string{"h2"}
+		conn, err := tls.Dial("tcp", listener.Addr().String(), cfg)
+		require.NoError(t, err)
+		defer conn.Close()
+		require.Equal(t, "h2", conn.ConnectionState().NegotiatedProtocol)
+	})
+
+	t.Run("reload on change", func(t *testing.T) {
+		next := serverCA.Server(t, t.TempDir(), "server-2")
+		replace(t, next.CertFile, server.CertFile)
+		replace(t, next.KeyFile, server.KeyFile)
+
+		cn, err := dial(Files{CertFile: client.CertFile, KeyFile: client.KeyFile})
+		require.NoError(t, err)
+		require.Equal(t, "server-2", cn)
+	})
+
+	t.Run("broken files keep current certificate", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(server.KeyFile, []byte("broken"), 0o600))
+		touch(t, server.KeyFile)
+
+		cn, err := dial(Files{CertFile: client.CertFile, KeyFile: client.KeyFile})
+		require.NoError(t, err)
+		require.Equal(t, "server-2", cn)
+	})
+}
+
+func TestConfigErrors(t *testing.T) {
+	_, err := ParseVersion("1.1")
+	require.ErrorIs(t, err, ErrVersionUnk)
+	_, err = ClientConfig(Files{CertFile: "cert.pem"})
+	require.ErrorIs(t, err, ErrNoCertKey)
+
+	dir := t.TempDir()
+	notCA := dir + "/ca.pem"
+	require.NoError(t, os.WriteFile(notCA, []byte("not a certificate"), 0o600))
+	_, err = ClientConfig(Files{CAFile: notCA})
+	require.ErrorIs(t, err, ErrCANoCerts)
+
+	log, err := logger.NewLogrus(logger.Config{Level: logger.LevelError})
+	require.NoError(t, err)
+	_, err = NewReloader(Files{CertFile: dir + "/none.pem", KeyFile: dir + "/none-key.pem"}, log)
+	require.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+// replace подмена файла, время изменения заведомо отличается от прежнего.
+func replace(t *testing.T, from, to string) {
+	t.Helper()
+	bs, err := os.ReadFile(from)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(to, bs, 0o600))
+	touch(t, to)
+}
+
+func touch(t *testing.T, fileName string) {
+	t.Helper()
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(fileName, future, future))
+}
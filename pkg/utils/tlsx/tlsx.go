@@ -0,0 +1,76 @@
+// Package tlsx настройка TLS серверов и клиентов из файлов PEM.
+package tlsx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var (
+	ErrVersionUnk = errors.New("unknown TLS version, expected 1.2 or 1.3")
+	ErrCANoCerts  = errors.New("no certificates found in CA file")
+	ErrNoCertKey  = errors.New("certificate and key files must be specified together")
+)
+
+// Files сертификат, ключ и CA в формате PEM.
+type Files struct {
+	CertFile string
+	KeyFile  string
+	// CAFile для сервера - CA клиентских сертификатов, при нем клиент обязан предъявить сертификат (mTLS).
+	// Для клиента - CA сервера, по умолчанию системные.
+	CAFile string
+	// MinVersion 1.2 (по умолчанию) или 1.3
+	MinVersion string
+	// ServerName только для клиента: имя в сертификате сервера, если отличается от адреса.
+	ServerName string
+}
+
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, ErrVersionUnk
+}
+
+// ClientConfig настройки клиента, сертификат клиента необязателен.
+func ClientConfig(files Files) (*tls.Config, error) {
+	minVersion, err := ParseVersion(files.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{MinVersion: minVersion, ServerName: files.ServerName}
+	if files.CAFile != "" {
+		if cfg.RootCAs, err = loadCA(files.CAFile); err != nil {
+			return nil, err
+		}
+	}
+	if files.CertFile != "" || files.KeyFile != "" {
+		if files.CertFile == "" || files.KeyFile == "" {
+			return nil, ErrNoCertKey
+		}
+		cert, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func loadCA(fileName string) (*x509.CertPool, error) {
+	bs, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("load CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bs) {
+		return nil, fmt.Errorf("'%s': %w", fileName, ErrCANoCerts)
+	}
+	return pool, nil
+}
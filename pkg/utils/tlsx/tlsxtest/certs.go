@@ -0,0 +1,105 @@
+// Package tlsxtest сертификаты для тестов TLS и mTLS.
+package tlsxtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// CA удостоверяющий центр, выпускающий сертификаты серверов и клиентов.
+type CA struct {
+	File string
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// Pair файлы сертификата и ключа.
+type Pair struct {
+	CertFile string
+	KeyFile  string
+}
+
+// NewCA создает CA и записывает его сертификат в dir/name.pem.
+func NewCA(t *testing.T, dir, name string) *CA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber:          serial(t),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	ca := &CA{File: filepath.Join(dir, name+".pem"), cert: cert, key: key}
+	writePEM(t, ca.File, "CERTIFICATE", der)
+	return ca
+}
+
+// Server сертификат сервера для localhost и 127.0.0.1 с CN = name.
+func (ca *CA) Server(t *testing.T, dir, name string) Pair {
+	t.Helper()
+	return ca.issue(t, dir, name, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+}
+
+// Client сертификат клиента с CN = name.
+func (ca *CA) Client(t *testing.T, dir, name string) Pair {
+	t.Helper()
+	return ca.issue(t, dir, name, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+}
+
+func (ca *CA) issue(t *testing.T, dir, name string, tpl *x509.Certificate) Pair {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tpl.SerialNumber = serial(t)
+	tpl.NotBefore = time.Now().Add(-time.Hour)
+	tpl.NotAfter = time.Now().Add(time.Hour)
+	tpl.KeyUsage = x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, tpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	pair := Pair{CertFile: filepath.Join(dir, name+".pem"), KeyFile: filepath.Join(dir, name+"-key.pem")}
+	writePEM(t, pair.CertFile, "CERTIFICATE", der)
+	writePEM(t, pair.KeyFile, "EC PRIVATE KEY", keyDER)
+	return pair
+}
+
+func serial(t *testing.T) *big.Int {
+	t.Helper()
+	n, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	require.NoError(t, err)
+	return n
+}
+
+func writePEM(t *testing.T, fileName, typ string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(fileName, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600))
+}
@@ -45,7 +45,19 @@ func (is *IPRuleSuiteTest) TearDownTest() {
 }
 
 func (is *IPRuleSuiteTest) TestComplex() {
-	type actionFn func(ctx context.Context, in *pb.IPNet, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	type actionFn func(ctx context.Context, in *pb.IPNet) error
+	add := func(fn func(context.Context, *pb.IPNet, ...grpc.CallOption) (*pb.AddResult, error)) actionFn {
+		return func(ctx context.Context, in *pb.IPNet) error {
+			_, err := fn(ctx, in)
+			return err
+		}
+	}
+	remove := func(fn func(context.Context, *pb.IPNet, ...grpc.CallOption) (*emptypb.Empty, error)) actionFn {
+		return func(ctx context.Context, in *pb.IPNet) error {
+			_, err := fn(ctx, in)
+			return err
+		}
+	}
 
 	badNet := &pb.IPNet{IPNet: "292.168.1.0/24"}
 	goodNet := &pb.IPNet{IPNet: "192.168.1.0/24"}
@@ -59,52 +71,52 @@ func (is *IPRuleSuiteTest) TestComplex() {
 		{
 			name:         "white wrong net",
 			expectedCode: codes.InvalidArgument,
-			actionFn:     is.client.AddToWhiteList,
+			actionFn:     add(is.client.AddToWhiteList),
 			arg:          badNet,
 		}, {
 			name:         "white ok",
 			expectedCode: codes.OK,
-			actionFn:     is.client.AddToWhiteList,
+			actionFn:     add(is.client.AddToWhiteList),
 			arg:          goodNet,
 		}, {
 			name:         "white duplicate",
 			expectedCode: codes.InvalidArgument,
-			actionFn:     is.client.AddToWhiteList,
+			actionFn:     add(is.client.AddToWhiteList),
 			arg:          goodNet,
 		}, {
 			name:         "white remove ok",
 			expectedCode: codes.OK,
-			actionFn:     is.client.DeleteFromWhiteList,
+			actionFn:     remove(is.client.DeleteFromWhiteList),
 			arg:          goodNet,
 		}, {
 			name:         "white remove not found",
 			expectedCode: codes.NotFound,
-			actionFn:     is.client.DeleteFromWhiteList,
+			actionFn:     remove(is.client.DeleteFromWhiteList),
 			arg:          goodNet,
 		}, {
 			name:         "black wrong net",
 			expectedCode: codes.InvalidArgument,
-			actionFn:     is.client.AddToBlackList,
+			actionFn:     add(is.client.AddToBlackList),
 			arg:          badNet,
 		}, {
 			name:         "black ok",
 			expectedCode: codes.OK,
-			actionFn:     is.client.AddToBlackList,
+			actionFn:     add(is.client.AddToBlackList),
 			arg:          goodNet,
 		}, {
 			name:         "black duplicate",
 			expectedCode: codes.InvalidArgument,
-			actionFn:     is.client.AddToBlackList,
+			actionFn:     add(is.client.AddToBlackList),
 			arg:          goodNet,
 		}, {
 			name:         "black remove ok",
 			expectedCode: codes.OK,
-			actionFn:     is.client.DeleteFromBlackList,
+			actionFn:     remove(is.client.DeleteFromBlackList),
 			arg:          goodNet,
 		}, {
 			name:         "black remove not found",
 			expectedCode: codes.NotFound,
-			actionFn:     is.client.DeleteFromBlackList,
+			actionFn:     remove(is.client.DeleteFromBlackList),
 			arg:          goodNet,
 		},
 	}
@@ -115,7 +127,7 @@ func (is *IPRuleSuiteTest) TestComplex() {
 			defer cancel()
 
 			// неверный адрес сети
-			_, err := tc.actionFn(ctx, tc.arg)
+			err := tc.actionFn(ctx, tc.arg)
 			e, ok := status.FromError(err)
 
 			is.Suite.Require().True(ok, "error is not status")